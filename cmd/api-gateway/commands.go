@@ -0,0 +1,209 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"gateway.example/go-gateway/internal/config"
+	"gateway.example/go-gateway/internal/core"
+	"gateway.example/go-gateway/internal/plugin"
+	"gateway.example/go-gateway/pkg/logger"
+)
+
+const (
+	defaultConfigPath = "./configs/config.yaml"
+	logConfigPath     = "./configs/logs/api-gateway-log.yaml"
+)
+
+const usage = `用法:
+  gateway [参数]                   启动网关
+  gateway validate [-c 配置文件]   校验配置，有问题时以非零状态退出
+  gateway routes [-c 配置文件]     打印合并 include 与环境配置后的路由表
+  gateway schema                   输出配置文件的 JSON Schema，供编辑器补全与 CI 校验
+
+参数:
+`
+
+// cliOptions 命令行参数
+type cliOptions struct {
+	configPath string
+	env        string
+	port       string
+	logLevel   string
+}
+
+// newFlagSet 创建子命令共用的参数: --config (-c) 与 --env
+func newFlagSet(name string, opts *cliOptions) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), usage)
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&opts.configPath, "config", defaultConfigPath, "配置文件路径")
+	fs.StringVar(&opts.configPath, "c", defaultConfigPath, "--config 的简写")
+	// 环境名: 叠加同目录下的 config.<env>.yaml，命令行参数优先于 GATEWAY_ENV
+	fs.StringVar(&opts.env, "env", os.Getenv("GATEWAY_ENV"), "环境名，叠加同目录下的 config.<env>.yaml (默认取 GATEWAY_ENV)")
+	return fs
+}
+
+// parseServeFlags 解析启动网关时的参数
+func parseServeFlags(args []string) *cliOptions {
+	opts := &cliOptions{}
+	fs := newFlagSet("gateway", opts)
+	fs.StringVar(&opts.port, "port", "", "覆盖 server.port，如 8080 或 127.0.0.1:8080")
+	fs.StringVar(&opts.logLevel, "log-level", "", "覆盖日志配置中的级别: debug / info / warn / error")
+	_ = fs.Parse(args)
+	return opts
+}
+
+// load 加载配置文件并应用命令行覆盖
+func (o *cliOptions) load() (*config.GatewayConfig, error) {
+	cfg, err := config.LoadEnv(o.configPath, o.env)
+	if err != nil {
+		return nil, err
+	}
+	o.apply(cfg)
+	return cfg, nil
+}
+
+// apply 把命令行参数覆盖到配置上，重新加载配置后同样需要调用
+func (o *cliOptions) apply(cfg *config.GatewayConfig) {
+	if o.port == "" {
+		return
+	}
+	if strings.Contains(o.port, ":") {
+		cfg.Server.Port = o.port
+	} else {
+		cfg.Server.Port = ":" + o.port
+	}
+}
+
+// runValidate 实现 validate 子命令: 按启动流程构建网关 (含插件配置校验)，随即释放资源。
+// 以 ValidateOnly 构建，不探测上游，也不读写熔断器持久化状态等共享数据
+func runValidate(args []string) int {
+	opts := &cliOptions{}
+	_ = newFlagSet("validate", opts).Parse(args)
+
+	cfg, err := opts.load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	// 只输出错误日志，校验结果直接打印
+	log, err := logger.NewWithConfigFile(logConfigPath, logger.WithLevel("error"))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "初始化日志失败:", err)
+		return 1
+	}
+	gw, err := core.NewGateway(cfg, log, core.ValidateOnly())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "配置 '%s' 无效: %v\n", opts.configPath, err)
+		return 1
+	}
+	gw.Shutdown()
+	fmt.Printf("配置 '%s' 有效: %d 个服务，%d 条路由\n", opts.configPath, len(cfg.Services), len(cfg.Routes))
+	return 0
+}
+
+// runRoutes 实现 routes 子命令: 按匹配顺序打印路由及其生效的插件链
+func runRoutes(args []string) int {
+	opts := &cliOptions{}
+	_ = newFlagSet("routes", opts).Parse(args)
+
+	cfg, err := opts.load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	fmt.Printf("全局 pre_routing 插件: %s\n\n", pluginNames(plugin.MergeChains(cfg.Plugins.PreRouting)))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "#\tPATH_PREFIX\tMETHODS\tSERVICE\tLOAD_BALANCER\tINSTANCES\tAUTH\tPLUGINS")
+	for i, route := range cfg.Routes {
+		if route == nil {
+			continue
+		}
+		methods := "*"
+		if len(route.Methods) > 0 {
+			methods = strings.Join(route.Methods, ",")
+		}
+		lb, instances := "-", "-"
+		if svc, ok := cfg.Services[route.ServiceName]; ok {
+			lb = svc.LoadBalancer
+			if lb == "" {
+				lb = "round_robin"
+			}
+			urls := make([]string, 0, len(svc.Instances))
+			for _, inst := range svc.Instances {
+				urls = append(urls, inst.URL)
+			}
+			instances = strings.Join(urls, ",")
+		} else if route.ServiceName != "all-services" {
+			instances = "(服务未定义)"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%t\t%s\n", i+1, route.PathPrefix, methods, route.ServiceName,
+			lb, instances, route.RequiresAuth, pluginNames(plugin.MergeChains(cfg.Plugins.PostRouting, route.Plugins)))
+	}
+	if err := w.Flush(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	unused := unusedServices(cfg)
+	if len(unused) > 0 {
+		fmt.Printf("\n未被任何路由引用的服务: %s\n", strings.Join(unused, ", "))
+	}
+	return 0
+}
+
+// pluginNames 返回插件链中按执行顺序排列的插件名
+func pluginNames(chain []config.PluginSpec) string {
+	if len(chain) == 0 {
+		return "-"
+	}
+	names := make([]string, 0, len(chain))
+	for _, spec := range chain {
+		name, _ := spec["name"].(string)
+		names = append(names, name)
+	}
+	return strings.Join(names, " > ")
+}
+
+func unusedServices(cfg *config.GatewayConfig) []string {
+	used := make(map[string]bool, len(cfg.Routes))
+	for _, route := range cfg.Routes {
+		if route != nil {
+			used[route.ServiceName] = true
+		}
+	}
+	var unused []string
+	for name := range cfg.Services {
+		if !used[name] {
+			unused = append(unused, name)
+		}
+	}
+	sort.Strings(unused)
+	return unused
+}
+
+// runSchema 实现 schema 子命令: 输出配置结构的 JSON Schema
+func runSchema(args []string) int {
+	fs := flag.NewFlagSet("schema", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), usage)
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	schema, err := config.JSONSchema()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Println(string(schema))
+	return 0
+}
@@ -3,6 +3,10 @@ package main
 import (
 	"context"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"gateway.example/go-gateway/internal/config"
 	"gateway.example/go-gateway/internal/core"
@@ -12,19 +16,50 @@ import (
 var log logger.Logger
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "validate":
+			os.Exit(runValidate(os.Args[2:]))
+		case "routes":
+			os.Exit(runRoutes(os.Args[2:]))
+		case "schema":
+			os.Exit(runSchema(os.Args[2:]))
+		}
+	}
+	opts := parseServeFlags(os.Args[1:])
+
 	// --- 1. 初始化日志 ---
-	log, err := logger.NewWithConfigFile("./configs/logs/api-gateway-log.yaml")
+	var logOpts []logger.Option
+	if opts.logLevel != "" {
+		logOpts = append(logOpts, logger.WithLevel(opts.logLevel))
+	}
+	log, err := logger.NewWithConfigFile(logConfigPath, logOpts...)
 	if err != nil {
 		panic(err)
 	}
 	ctx := context.Background()
 
 	// --- 2. 加载配置 ---
-	log.Info(ctx, "加载配置中...")
-	cfg, err := config.Load("./configs/config.yaml")
+	log.Info(ctx, "加载配置中...", "path", opts.configPath, "env", opts.env)
+	cfg, err := opts.load()
 	if err != nil {
 		log.Fatal(ctx, "致命错误: 加载配置失败", "error", err)
 	}
+	// config.source 为 etcd/consul 时本地文件只用于引导，完整配置从远程读取
+	remote, err := config.NewRemoteConfig(cfg.ConfigSource)
+	if err != nil {
+		log.Fatal(ctx, "致命错误: 创建远程配置失败", "error", err)
+	}
+	if remote != nil {
+		log.Info(ctx, "从远程配置中心加载配置...", "source", remote.Name())
+		cfg, err = remote.LoadWithRetry(ctx, func(err error, retryIn time.Duration) {
+			log.Warn(ctx, "远程配置中心不可用，稍后重试", "source", remote.Name(), "error", err, "retry_in", retryIn)
+		})
+		if err != nil {
+			log.Fatal(ctx, "致命错误: 加载远程配置失败", "source", remote.Name(), "error", err)
+		}
+		opts.apply(cfg)
+	}
 	log.Info(ctx, "配置加载成功。")
 
 	// --- 3. 依赖注入：创建网关实例 ---
@@ -36,10 +71,12 @@ func main() {
 	log.Info(ctx, "网关层初始化成功。")
 
 	// --- 4. 创建并启动 HTTP 服务器 ---
-	srv, err := core.NewServer(cfg.Server.Port, gw, log)
+	srv, err := core.NewServer(cfg.Server, gw, log)
 	if err != nil {
 		log.Fatal(ctx, "致命错误: 创建服务器失败", "error", err)
 	}
+	// 服务器开始关闭时主动结束 SSE 等长连接，避免阻塞优雅关闭
+	srv.RegisterOnShutdown(gw.CloseStreams)
 	log.Info(ctx, "HTTP 服务器正在端口上启动", "port", cfg.Server.Port)
 
 	// 在一个 Goroutine 中启动服务器，以便主 Goroutine 可以监听信号
@@ -49,7 +86,93 @@ func main() {
 		}
 	}()
 
+	// 收到 SIGHUP 时重新读取配置，热更新路由、服务、插件链与限流规则；远程配置变化时自动热更新
+	go reloadOnSignal(gw, opts, remote, log)
+	watchCtx, stopWatch := context.WithCancel(ctx)
+	if remote != nil {
+		go watchRemote(watchCtx, gw, opts, remote, log)
+	}
+	// 定期重新读取配置中引用的密钥，值变化 (如轮换) 时重新加载配置
+	if cfg.Secrets.RefreshInterval > 0 {
+		go refreshSecrets(watchCtx, cfg.Secrets.RefreshInterval, gw, opts, remote, log)
+	}
+
 	// --- 5. 平滑关机处理 ---
 	// 创建一个通道来接收停止信号
 	srv.GracefulShutdown()
+	stopWatch()
+
+	// --- 6. 释放网关持有的资源 (健康检查、限流器、熔断器等) ---
+	gw.Shutdown()
+}
+
+// reloadOnSignal 每次收到 SIGHUP 重新加载配置 (配置文件或远程配置的最新值)，校验失败时继续使用原有配置
+func reloadOnSignal(gw *core.Gateway, opts *cliOptions, remote *config.RemoteConfig, log logger.Logger) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	for range hup {
+		ctx := context.Background()
+		log.Info(ctx, "收到 SIGHUP，重新加载配置...")
+		reloadConfig(ctx, gw, opts, remote, log)
+	}
+}
+
+// reloadConfig 重新读取配置文件或远程配置并应用到网关
+func reloadConfig(ctx context.Context, gw *core.Gateway, opts *cliOptions, remote *config.RemoteConfig, log logger.Logger) {
+	var (
+		cfg *config.GatewayConfig
+		err error
+	)
+	if remote != nil {
+		if cfg, err = remote.Load(ctx); err == nil {
+			opts.apply(cfg)
+		}
+	} else {
+		cfg, err = opts.load()
+	}
+	if err != nil {
+		log.Error(ctx, "重新加载配置失败", "error", err)
+		return
+	}
+	if err := gw.Reload(cfg); err != nil {
+		log.Error(ctx, "新配置未生效，继续使用原有配置", "error", err)
+	}
+}
+
+// refreshSecrets 按间隔重新读取密钥，有值变化时重新加载配置；读取失败时继续使用缓存的值
+func refreshSecrets(ctx context.Context, interval time.Duration, gw *core.Gateway, opts *cliOptions, remote *config.RemoteConfig, log logger.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		changed, err := config.RefreshSecrets(ctx)
+		if err != nil {
+			log.Warn(ctx, "刷新密钥失败，继续使用缓存的值", "error", err)
+		}
+		if changed {
+			log.Info(ctx, "密钥已变化，重新加载配置...")
+			reloadConfig(ctx, gw, opts, remote, log)
+		}
+	}
+}
+
+// watchRemote 监听远程配置的变化并热更新，远程配置中心不可用时按指数退避重试，期间继续使用当前配置
+func watchRemote(ctx context.Context, gw *core.Gateway, opts *cliOptions, remote *config.RemoteConfig, log logger.Logger) {
+	remote.Watch(ctx, func(cfg *config.GatewayConfig) {
+		opts.apply(cfg)
+		log.Info(ctx, "远程配置已变化，重新加载配置...", "source", remote.Name())
+		if err := gw.Reload(cfg); err != nil {
+			log.Error(ctx, "新配置未生效，继续使用原有配置", "error", err)
+		}
+	}, func(err error, retryIn time.Duration) {
+		if retryIn == 0 {
+			log.Error(ctx, "远程配置无效，继续使用原有配置", "source", remote.Name(), "error", err)
+			return
+		}
+		log.Warn(ctx, "监听远程配置失败，稍后重试", "source", remote.Name(), "error", err, "retry_in", retryIn)
+	})
 }
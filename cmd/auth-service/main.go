@@ -9,8 +9,10 @@ import (
 	"syscall"
 	"time"
 
+	"gateway.example/go-gateway/internal/cache"
 	"gateway.example/go-gateway/internal/config"
 	authHandler "gateway.example/go-gateway/internal/handler/auth"
+	"gateway.example/go-gateway/internal/redisclient"
 	"gateway.example/go-gateway/internal/repository"
 	authSvc "gateway.example/go-gateway/internal/service/auth"
 	"gateway.example/go-gateway/pkg/logger"
@@ -33,17 +35,53 @@ func main() {
 		log.Fatal(ctx, "could not load config", "error", err)
 	}
 
+	// 令牌吊销列表，与网关配置相同的共享缓存后端时网关可以直接查询
+	store, err := cache.New(cfg.Cache)
+	if err != nil {
+		log.Fatal(ctx, "could not create cache", "error", err)
+	}
+
 	// 2. 初始化用户仓库 - 使用内存存储用户数据
 	userRepo := repository.NewInMemoryUserRepository()
+	if cfg.AuthService.UserCacheTTL > 0 {
+		userRepo = repository.NewCachedUserRepository(userRepo, store, cfg.AuthService.UserCacheTTL)
+	}
+	tokenRepo := repository.NewInMemoryRefreshTokenRepository()
+	var sessionRepo repository.SessionRepository
+	switch cfg.AuthService.SessionStore {
+	case "", "memory":
+		sessionRepo = repository.NewInMemorySessionRepository()
+	case "redis":
+		client, err := redisclient.New(cfg.Cache.Redis)
+		if err != nil {
+			log.Fatal(ctx, "session_store redis requires cache.redis", "error", err)
+		}
+		sessionRepo = repository.NewRedisSessionRepository(client, cfg.Cache.Redis.KeyPrefix)
+	default:
+		log.Fatal(ctx, "unsupported session store", "session_store", cfg.AuthService.SessionStore)
+	}
+
+	// 安全审计日志 - 与运行日志分开输出，记录登录、改密、吊销与管理操作
+	auditConfig := cfg.AuthService.AuditLogConfig
+	if auditConfig == "" {
+		auditConfig = "./configs/logs/auth-audit-log.yaml"
+	}
+	audit, err := logger.NewWithConfigFile(auditConfig)
+	if err != nil {
+		log.Fatal(ctx, "could not create audit logger", "error", err)
+	}
 
 	// 3. 创建认证服务 - 负责用户认证的核心业务逻辑
-	authService, err := authSvc.NewAuthService(userRepo, cfg.JWT.SecretKey, cfg.JWT.DurationMinutes, log)
+	authService, err := authSvc.NewAuthService(userRepo, tokenRepo, sessionRepo, store, cfg.JWT, cfg.AuthService, log, audit)
 	if err != nil {
 		log.Fatal(ctx, "could not create auth service", "error", err)
 	}
 
 	// 4. 创建认证处理器 - HTTP请求处理入口
-	authHandler := authHandler.NewAuthHandler(authService)
+	authHandler, err := authHandler.NewAuthHandler(authService, cfg.AuthService.TrustedProxies)
+	if err != nil {
+		log.Fatal(ctx, "could not create auth handler", "error", err)
+	}
 
 	// 5. 创建HTTP请求多路复用器 - 路由分发器
 	mux := http.NewServeMux()
@@ -57,6 +95,60 @@ func main() {
 		authHandler.LoginHandler(w, r)
 	})
 
+	// 注销接口 - 吊销访问令牌 (及可选的刷新令牌)
+	mux.HandleFunc("/logout", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		authHandler.LogoutHandler(w, r)
+	})
+
+	// 公钥发布接口 - 供网关与上游服务校验 RS256/ES256 令牌
+	mux.HandleFunc("GET /.well-known/jwks.json", authHandler.JWKSHandler)
+
+	// 吊销列表接口 - 供网关本地校验模式定期同步
+	mux.HandleFunc("GET /revocations", authHandler.RevocationsHandler)
+
+	// 刷新接口 - 用刷新令牌换取新的令牌对
+	mux.HandleFunc("/refresh", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		authHandler.RefreshHandler(w, r)
+	})
+
+	// 机器令牌接口 - 服务客户端以 client_credentials 换取短期访问令牌
+	mux.HandleFunc("POST /token", authHandler.TokenHandler)
+
+	// 会话管理接口 - 查看登录会话、远程注销某台设备或全部设备
+	mux.HandleFunc("GET /sessions", authHandler.ListSessionsHandler)
+	mux.HandleFunc("DELETE /sessions", authHandler.RevokeAllSessionsHandler)
+	mux.HandleFunc("DELETE /sessions/{id}", authHandler.RevokeSessionHandler)
+
+	// 修改密码接口 - 需要访问令牌与当前密码
+	mux.HandleFunc("POST /password", authHandler.ChangePasswordHandler)
+
+	// 注册接口 - 仅在配置开放自助注册时提供
+	if cfg.AuthService.AllowRegistration {
+		mux.HandleFunc("/register", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			authHandler.RegisterHandler(w, r)
+		})
+	}
+
+	// 用户管理接口 - 需要具有 admin 角色的访问令牌
+	mux.HandleFunc("GET /admin/users", authHandler.ListUsersHandler)
+	mux.HandleFunc("GET /admin/users/{username}", authHandler.GetUserHandler)
+	mux.HandleFunc("DELETE /admin/users/{username}", authHandler.DeleteUserHandler)
+	mux.HandleFunc("POST /admin/users/{username}/disable", authHandler.DisableUserHandler)
+	mux.HandleFunc("POST /admin/users/{username}/enable", authHandler.EnableUserHandler)
+	mux.HandleFunc("POST /admin/users/{username}/reset-password", authHandler.ResetPasswordHandler)
+
 	// 7. 注册健康检查接口 - 用于服务健康状态监控
 	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -83,7 +175,7 @@ func main() {
 	// 11. 创建HTTP服务器实例 - 支持优雅关闭
 	server := &http.Server{
 		Addr:         port,
-		Handler:      mux,
+		Handler:      authHandler.RequestContext(mux),
 		ReadTimeout:  5 * time.Second,
 		WriteTimeout: 10 * time.Second,
 	}
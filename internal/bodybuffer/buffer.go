@@ -0,0 +1,225 @@
+// package bodybuffer 提供可重复读取的请求体缓冲，
+// 小请求体保存在内存中，超过阈值后溢出到临时文件，供重试、镜像和需要读取请求体的插件共用。
+package bodybuffer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+)
+
+// 缓冲默认参数
+const (
+	DefaultMemoryLimit = 1 << 20  // 超过该字节数后溢出到临时文件
+	DefaultMaxSize     = 32 << 20 // 允许缓冲的最大请求体
+)
+
+// ErrTooLarge 请求体超过允许缓冲的最大字节数
+var ErrTooLarge = errors.New("请求体超过缓冲上限")
+
+// Options 缓冲参数，零值字段使用默认值
+type Options struct {
+	MemoryLimit int64
+	MaxSize     int64
+	TempDir     string // 临时文件目录，为空时使用系统临时目录
+}
+
+// Buffer 是一份已完整读取的请求体，可以创建任意多个独立的读取器
+type Buffer struct {
+	data []byte
+	file *os.File
+	size int64
+}
+
+// New 完整读取 src 并缓冲
+func New(src io.Reader, opts Options) (*Buffer, error) {
+	if opts.MemoryLimit <= 0 {
+		opts.MemoryLimit = DefaultMemoryLimit
+	}
+	if opts.MaxSize <= 0 {
+		opts.MaxSize = DefaultMaxSize
+	}
+	memoryLimit := min(opts.MemoryLimit, opts.MaxSize)
+
+	mem := &bytes.Buffer{}
+	n, err := io.CopyN(mem, src, memoryLimit+1)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if n <= memoryLimit {
+		return &Buffer{data: mem.Bytes(), size: n}, nil
+	}
+	if n > opts.MaxSize {
+		return nil, ErrTooLarge
+	}
+
+	// 超过内存阈值，溢出到临时文件
+	f, err := os.CreateTemp(opts.TempDir, "gateway-body-*")
+	if err != nil {
+		return nil, fmt.Errorf("创建请求体临时文件失败: %w", err)
+	}
+	b := &Buffer{file: f}
+	written, err := io.Copy(f, io.MultiReader(mem, io.LimitReader(src, opts.MaxSize-n+1)))
+	if err != nil {
+		b.Close()
+		return nil, fmt.Errorf("写入请求体临时文件失败: %w", err)
+	}
+	if written > opts.MaxSize {
+		b.Close()
+		return nil, ErrTooLarge
+	}
+	b.size = written
+	return b, nil
+}
+
+// Size 返回请求体字节数
+func (b *Buffer) Size() int64 {
+	return b.size
+}
+
+// InMemory 报告请求体是否完全保存在内存中
+func (b *Buffer) InMemory() bool {
+	return b.file == nil
+}
+
+// Reader 返回一个从头读取请求体的新读取器，多个读取器之间互不影响
+func (b *Buffer) Reader() io.ReadCloser {
+	if b.file == nil {
+		return io.NopCloser(bytes.NewReader(b.data))
+	}
+	return io.NopCloser(io.NewSectionReader(b.file, 0, b.size))
+}
+
+// Bytes 返回完整的请求体内容，溢出到文件时会读入内存
+func (b *Buffer) Bytes() ([]byte, error) {
+	if b.file == nil {
+		return b.data, nil
+	}
+	return io.ReadAll(b.Reader())
+}
+
+// Close 释放缓冲，删除临时文件
+func (b *Buffer) Close() error {
+	if b.file == nil {
+		return nil
+	}
+	name := b.file.Name()
+	err := b.file.Close()
+	if removeErr := os.Remove(name); err == nil {
+		err = removeErr
+	}
+	b.file = nil
+	return err
+}
+
+// Wrap 缓冲请求体并把 r.Body 与 r.GetBody 替换为可重复读取的版本
+// 调用方需要在请求处理结束后调用 Buffer.Close
+func Wrap(r *http.Request, opts Options) (*Buffer, error) {
+	maxSize := opts.MaxSize
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+	if r.ContentLength > maxSize {
+		return nil, ErrTooLarge
+	}
+
+	if r.Body == nil || r.Body == http.NoBody {
+		b := &Buffer{}
+		attach(r, b)
+		return b, nil
+	}
+
+	b, err := New(r.Body, opts)
+	r.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	attach(r, b)
+	return b, nil
+}
+
+func attach(r *http.Request, b *Buffer) {
+	r.Body = b.Reader()
+	r.GetBody = func() (io.ReadCloser, error) {
+		return b.Reader(), nil
+	}
+	r.ContentLength = b.size
+	r.TransferEncoding = nil
+	if b.size == 0 {
+		r.Body = http.NoBody
+	}
+}
+
+// Rewind 把 r.Body 重置到请求体开头，请求体不可重复读取时返回错误
+func Rewind(r *http.Request) error {
+	if r.GetBody == nil {
+		return errors.New("请求体不可重复读取")
+	}
+	body, err := r.GetBody()
+	if err != nil {
+		return err
+	}
+	r.Body = body
+	return nil
+}
+
+// ReadAll 读取完整的请求体而不消耗 r.Body，最多读取 limit 字节，超过时返回 ErrTooLarge。
+// 请求体尚未缓冲时会在内存中缓冲，超限时保持 r.Body 可继续读取；
+// 已经可重复读取 (如 request_buffering 溢出到临时文件) 时同样只读取 limit 字节
+func ReadAll(r *http.Request, limit int64) ([]byte, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, nil
+	}
+	if limit <= 0 {
+		limit = DefaultMemoryLimit
+	}
+	if r.GetBody != nil {
+		if r.ContentLength > limit {
+			return nil, ErrTooLarge
+		}
+		body, err := r.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer body.Close()
+		data, err := io.ReadAll(io.LimitReader(body, limit+1))
+		if err != nil {
+			return nil, err
+		}
+		if int64(len(data)) > limit {
+			return nil, ErrTooLarge
+		}
+		return data, nil
+	}
+
+	buf := &bytes.Buffer{}
+	n, err := io.CopyN(buf, r.Body, limit+1)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if n > limit {
+		r.Body = Replay(buf, r.Body)
+		return nil, ErrTooLarge
+	}
+	r.Body.Close()
+	data := buf.Bytes()
+	attach(r, &Buffer{data: data, size: int64(len(data))})
+	return data, nil
+}
+
+// Replay 返回先读出 prefix (已从 body 读出的数据)、再继续读取 body 的读取器，关闭时关闭 body
+func Replay(prefix io.Reader, body io.ReadCloser) io.ReadCloser {
+	return &replayBody{Reader: io.MultiReader(prefix, body), closer: body}
+}
+
+type replayBody struct {
+	io.Reader
+	closer io.Closer
+}
+
+func (b *replayBody) Close() error {
+	return b.closer.Close()
+}
@@ -0,0 +1,45 @@
+// package cache 定义了网关通用的键值缓存接口及其实现，
+// 供响应缓存、幂等去重、配额计数等功能共用。
+package cache
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// ErrNotFound 键不存在或已过期
+var ErrNotFound = errors.New("cache: key not found")
+
+// Cache 是键值缓存的通用接口
+// ttl <= 0 表示永不过期
+type Cache interface {
+	// Get 读取键值，不存在时返回 ErrNotFound
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Set 写入键值
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete 删除键
+	Delete(ctx context.Context, key string) error
+	// DeletePrefix 删除所有以 prefix 开头的键，返回删除数量
+	DeletePrefix(ctx context.Context, prefix string) (int, error)
+	// Close 释放缓存持有的资源
+	Close() error
+}
+
+// Counter 是支持原子计数的缓存额外实现的接口，配额等长期计数器依赖它在并发下保持准确
+type Counter interface {
+	// Incr 把键的值加上 delta 并返回新值；键不存在时从 0 开始并设置 ttl，已存在时保留原有过期时间
+	Incr(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error)
+}
+
+// Adder 是支持原子 "不存在时写入" 的缓存额外实现的接口，幂等占位等需要在多个副本之间互斥的场景依赖它
+type Adder interface {
+	// SetNX 仅在键不存在 (或已过期) 时写入，返回是否写入成功
+	SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error)
+}
+
+// ParseCount 解析计数器的值，计数器的值以十进制字符串保存，与 Redis INCRBY 保持一致
+func ParseCount(value []byte) (int64, error) {
+	return strconv.ParseInt(string(value), 10, 64)
+}
@@ -0,0 +1,24 @@
+package cache
+
+import (
+	"fmt"
+
+	"gateway.example/go-gateway/internal/config"
+	"gateway.example/go-gateway/internal/redisclient"
+)
+
+// New 根据配置创建缓存实例
+func New(cfg config.CacheConfig) (Cache, error) {
+	switch cfg.Backend {
+	case "", "memory":
+		return NewMemoryCache(cfg.MaxEntries), nil
+	case "redis":
+		client, err := redisclient.New(cfg.Redis)
+		if err != nil {
+			return nil, fmt.Errorf("缓存后端 redis 需要 cache.redis 配置: %w", err)
+		}
+		return NewRedisCache(client, cfg.Redis.KeyPrefix), nil
+	default:
+		return nil, fmt.Errorf("不支持的缓存后端: '%s'", cfg.Backend)
+	}
+}
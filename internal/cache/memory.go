@@ -0,0 +1,226 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 内存缓存默认参数
+const (
+	defaultMaxEntries      = 10000
+	defaultCleanupInterval = time.Minute
+)
+
+// memoryEntry 内存缓存中的一条记录
+type memoryEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time // 零值表示永不过期
+}
+
+// MemoryCache 是基于 LRU 的进程内缓存实现，超过容量时淘汰最久未使用的键
+type MemoryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	ll         *list.List
+	items      map[string]*list.Element
+	stopChan   chan struct{}
+	closeOnce  sync.Once
+}
+
+// NewMemoryCache 创建内存缓存，maxEntries <= 0 时使用默认容量
+// 后台定期清理过期的键
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	if maxEntries <= 0 {
+		maxEntries = defaultMaxEntries
+	}
+	c := &MemoryCache{
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+		stopChan:   make(chan struct{}),
+	}
+	go c.cleanupLoop(defaultCleanupInterval)
+	return c
+}
+
+// Get 实现 Cache 接口
+func (c *MemoryCache) Get(ctx context.Context, key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	entry := elem.Value.(*memoryEntry)
+	if entry.expired(time.Now()) {
+		c.removeElement(elem)
+		return nil, ErrNotFound
+	}
+	c.ll.MoveToFront(elem)
+	return entry.value, nil
+}
+
+// Set 实现 Cache 接口
+func (c *MemoryCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl)
+	}
+
+	if elem, ok := c.items[key]; ok {
+		entry := elem.Value.(*memoryEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		c.ll.MoveToFront(elem)
+		return nil
+	}
+
+	c.items[key] = c.ll.PushFront(&memoryEntry{key: key, value: value, expiresAt: expiresAt})
+	for c.ll.Len() > c.maxEntries {
+		c.removeElement(c.ll.Back())
+	}
+	return nil
+}
+
+// SetNX 实现 Adder 接口
+func (c *MemoryCache) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if elem, ok := c.items[key]; ok {
+		if !elem.Value.(*memoryEntry).expired(now) {
+			return false, nil
+		}
+		c.removeElement(elem)
+	}
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = now.Add(ttl)
+	}
+	c.items[key] = c.ll.PushFront(&memoryEntry{key: key, value: value, expiresAt: expiresAt})
+	for c.ll.Len() > c.maxEntries {
+		c.removeElement(c.ll.Back())
+	}
+	return true, nil
+}
+
+// Incr 实现 Counter 接口
+func (c *MemoryCache) Incr(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if elem, ok := c.items[key]; ok {
+		entry := elem.Value.(*memoryEntry)
+		if !entry.expired(now) {
+			n, err := ParseCount(entry.value)
+			if err != nil {
+				return 0, fmt.Errorf("cache: key '%s' is not a counter", key)
+			}
+			n += delta
+			entry.value = []byte(strconv.FormatInt(n, 10))
+			c.ll.MoveToFront(elem)
+			return n, nil
+		}
+		c.removeElement(elem)
+	}
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = now.Add(ttl)
+	}
+	c.items[key] = c.ll.PushFront(&memoryEntry{key: key, value: []byte(strconv.FormatInt(delta, 10)), expiresAt: expiresAt})
+	for c.ll.Len() > c.maxEntries {
+		c.removeElement(c.ll.Back())
+	}
+	return delta, nil
+}
+
+// Delete 实现 Cache 接口
+func (c *MemoryCache) Delete(ctx context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		c.removeElement(elem)
+	}
+	return nil
+}
+
+// DeletePrefix 实现 Cache 接口
+func (c *MemoryCache) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	count := 0
+	for key, elem := range c.items {
+		if strings.HasPrefix(key, prefix) {
+			c.removeElement(elem)
+			count++
+		}
+	}
+	return count, nil
+}
+
+// Len 返回当前缓存的键数量（包含尚未清理的过期键）
+func (c *MemoryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+// Close 停止后台清理任务
+func (c *MemoryCache) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.stopChan)
+	})
+	return nil
+}
+
+// cleanupLoop 定期清理过期的键
+func (c *MemoryCache) cleanupLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.deleteExpired()
+		case <-c.stopChan:
+			return
+		}
+	}
+}
+
+func (c *MemoryCache) deleteExpired() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for _, elem := range c.items {
+		if elem.Value.(*memoryEntry).expired(now) {
+			c.removeElement(elem)
+		}
+	}
+}
+
+// removeElement 调用方需持有锁
+func (c *MemoryCache) removeElement(elem *list.Element) {
+	c.ll.Remove(elem)
+	delete(c.items, elem.Value.(*memoryEntry).key)
+}
+
+func (e *memoryEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && now.After(e.expiresAt)
+}
@@ -0,0 +1,112 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisScanCount DeletePrefix 每次 SCAN 的建议数量，同时也是每批 DEL 的最大键数
+const redisScanCount = 500
+
+// incrScript 原子地累加计数器，只在键原本不存在时设置过期时间，与 MemoryCache.Incr 的语义一致
+var incrScript = redis.NewScript(`
+local existed = redis.call('EXISTS', KEYS[1])
+local n = redis.call('INCRBY', KEYS[1], ARGV[1])
+local ttl = tonumber(ARGV[2])
+if existed == 0 and ttl > 0 then
+  redis.call('PEXPIRE', KEYS[1], ttl)
+end
+return n
+`)
+
+// RedisCache 是基于 Redis 的缓存实现，多个网关实例与认证服务共用同一组键，
+// 吊销列表、登录限制计数、熔断状态等在副本之间共享。
+type RedisCache struct {
+	client    *redis.Client
+	keyPrefix string
+}
+
+// NewRedisCache 创建 Redis 缓存，键名为 <keyPrefix>cache:<key>
+func NewRedisCache(client *redis.Client, keyPrefix string) *RedisCache {
+	return &RedisCache{client: client, keyPrefix: keyPrefix + "cache:"}
+}
+
+// Get 实现 Cache 接口
+func (c *RedisCache) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := c.client.Get(ctx, c.keyPrefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrNotFound
+	}
+	return value, err
+}
+
+// Set 实现 Cache 接口
+func (c *RedisCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if ttl < 0 {
+		ttl = 0
+	}
+	return c.client.Set(ctx, c.keyPrefix+key, value, ttl).Err()
+}
+
+// SetNX 实现 Adder 接口，对应 SET key value NX PX ttl
+func (c *RedisCache) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	if ttl < 0 {
+		ttl = 0
+	}
+	return c.client.SetNX(ctx, c.keyPrefix+key, value, ttl).Result()
+}
+
+// Incr 实现 Counter 接口
+func (c *RedisCache) Incr(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	return incrScript.Run(ctx, c.client, []string{c.keyPrefix + key}, delta, ttl.Milliseconds()).Int64()
+}
+
+// Delete 实现 Cache 接口
+func (c *RedisCache) Delete(ctx context.Context, key string) error {
+	return c.client.Del(ctx, c.keyPrefix+key).Err()
+}
+
+// DeletePrefix 实现 Cache 接口，使用 SCAN 逐批删除，不会像 KEYS 一样阻塞 Redis
+func (c *RedisCache) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	match := escapeGlob(c.keyPrefix+prefix) + "*"
+	count := 0
+	var cursor uint64
+	for {
+		keys, next, err := c.client.Scan(ctx, cursor, match, redisScanCount).Result()
+		if err != nil {
+			return count, err
+		}
+		if len(keys) > 0 {
+			n, err := c.client.Del(ctx, keys...).Result()
+			count += int(n)
+			if err != nil {
+				return count, err
+			}
+		}
+		if next == 0 {
+			return count, nil
+		}
+		cursor = next
+	}
+}
+
+// Close 关闭 Redis 连接池
+func (c *RedisCache) Close() error {
+	return c.client.Close()
+}
+
+// escapeGlob 转义 Redis MATCH 模式中的特殊字符，前缀中的 * ? [ ] \ 按字面匹配
+func escapeGlob(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(`*?[]\`, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
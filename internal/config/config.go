@@ -4,6 +4,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"time"
 
 	"gopkg.in/yaml.v2"
@@ -12,14 +13,25 @@ import (
 // Config 是整个网关配置的根结构
 
 type GatewayConfig struct {
-	Server         ServerConfig             `yaml:"server"`
-	HealthCheck    HealthCheckConfig        `yaml:"health_check"`
-	Services       map[string]ServiceConfig `yaml:"services"`
-	Routes         []*RouteConfig           `yaml:"routes"`
-	RateLimiting   RateLimitingConfig       `yaml:"rate_limiting"`
-	JWT            JWTConfig                `yaml:"jwt"`
-	AuthService    AuthServiceConfig        `yaml:"auth_service"`
-	CircuitBreaker CircuitBreakerConfig     `yaml:"circuit_breaker"`
+	Server          ServerConfig             `yaml:"server"`
+	HealthCheck     HealthCheckConfig        `yaml:"health_check"`
+	Services        map[string]ServiceConfig `yaml:"services"`
+	Routes          []*RouteConfig           `yaml:"routes"`
+	RateLimiting    RateLimitingConfig       `yaml:"rate_limiting"`
+	Quota           QuotaConfig              `yaml:"quota"`
+	JWT             JWTConfig                `yaml:"jwt"`
+	AuthService     AuthServiceConfig        `yaml:"auth_service"`
+	CircuitBreaker  CircuitBreakerConfig     `yaml:"circuit_breaker"`
+	Transport       TransportConfig          `yaml:"transport"`
+	Cache           CacheConfig              `yaml:"cache"`
+	Admin           AdminConfig              `yaml:"admin"`
+	RequestID       RequestIDConfig          `yaml:"request_id"`
+	Plugins         GlobalPluginsConfig      `yaml:"plugins"`
+	ExternalPlugins []ExternalPluginConfig   `yaml:"external_plugins"`
+	ConfigSource    ConfigSourceConfig       `yaml:"config"`
+	// Include 需要合并的配置片段文件 (glob，相对于本文件所在目录)，片段中只能定义 services 与 routes
+	Include []string      `yaml:"include,omitempty"`
+	Secrets SecretsConfig `yaml:"secrets,omitempty"`
 }
 
 // ServiceConfig 定义了一个可被路由的上游服务
@@ -29,24 +41,155 @@ type ServiceConfig struct {
 	Instances       []InstanceConfig `yaml:"instances"`
 	HealthCheckPath string           `yaml:"health_check_path"`
 	LoadBalancer    string           `yaml:"load_balancer"`
+	// Protocol 上游协议: "http"(默认), "h2c"(明文 HTTP/2), "grpc"(HTTP/2, http:// 实例走 h2c)
+	Protocol string `yaml:"protocol,omitempty"`
+	// TLS 访问 https:// 实例时使用的 TLS 配置，未配置时使用全局传输层设置
+	TLS *UpstreamTLSConfig `yaml:"tls,omitempty"`
+	// Timeouts 访问该服务的超时设置
+	Timeouts UpstreamTimeoutConfig `yaml:"timeouts,omitempty"`
+	// Signing 配置后网关对转发到该服务的请求进行 HMAC 签名
+	Signing *UpstreamSigningConfig `yaml:"signing,omitempty"`
+	// CircuitBreaker 该服务的熔断阈值，未设置的字段沿用全局 circuit_breaker 配置
+	CircuitBreaker *CircuitBreakerConfig `yaml:"circuit_breaker,omitempty"`
+}
+
+// UpstreamSigningConfig 定义网关对上游请求的签名参数
+// 签名覆盖 方法、路径(含查询串)、时间戳 与 请求体哈希，上游可使用 pkg/signing 校验
+
+type UpstreamSigningConfig struct {
+	Secret          string   `yaml:"secret"`                     // HMAC 密钥
+	KeyID           string   `yaml:"key_id,omitempty"`           // 密钥标识，用于上游按标识选择密钥以支持轮换
+	Header          string   `yaml:"header,omitempty"`           // 签名请求头，默认 X-Gateway-Signature
+	TimestampHeader string   `yaml:"timestamp_header,omitempty"` // 时间戳请求头，默认 X-Gateway-Timestamp
+	MaxBodySize     ByteSize `yaml:"max_body_size,omitempty"`    // 参与签名的最大请求体，超过时请求体记为 UNSIGNED-PAYLOAD，默认 1MB
+}
+
+// UpstreamTimeoutConfig 定义访问上游服务的超时，未配置的项不做限制或使用全局传输层设置
+
+type UpstreamTimeoutConfig struct {
+	Dial           time.Duration `yaml:"dial,omitempty"`            // 建立连接超时
+	ResponseHeader time.Duration `yaml:"response_header,omitempty"` // 单次尝试等待响应头的超时
+	Request        time.Duration `yaml:"request,omitempty"`         // 整个上游请求（含响应体）的超时
+}
+
+// UpstreamTLSConfig 定义网关连接上游服务时的 TLS 参数
+
+type UpstreamTLSConfig struct {
+	CAFile             string `yaml:"ca_file,omitempty"`     // 用于校验上游证书的 CA 证书包
+	CertFile           string `yaml:"cert_file,omitempty"`   // 客户端证书（向上游发起 mTLS）
+	KeyFile            string `yaml:"key_file,omitempty"`    // 客户端私钥
+	ServerName         string `yaml:"server_name,omitempty"` // 覆盖 SNI 与证书校验使用的主机名
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify,omitempty"`
 }
 
 // RouteConfig 定义了一条路由规则
 
 type RouteConfig struct {
-	PathPrefix       string       `yaml:"path_prefix,omitempty"`
-	Path             string       `yaml:"path,omitempty"`
-	ServiceName      string       `yaml:"service_name"`
-	Plugins          []PluginSpec `yaml:"plugins,omitempty"`
-	Methods          []string     `yaml:"methods,omitempty"`
-	RequiresAuth     bool         `yaml:"requires_auth,omitempty"`
-	HealthCheckScope string       `yaml:"health_check_scope,omitempty"`
+	PathPrefix       string             `yaml:"path_prefix,omitempty"`
+	Path             string             `yaml:"path,omitempty"`
+	ServiceName      string             `yaml:"service_name"`
+	Plugins          []PluginSpec       `yaml:"plugins,omitempty"`
+	Methods          []string           `yaml:"methods,omitempty"`
+	RequiresAuth     bool               `yaml:"requires_auth,omitempty"`
+	HealthCheckScope string             `yaml:"health_check_scope,omitempty"`
+	RequestHeaders   HeaderRules        `yaml:"request_headers,omitempty"`
+	ResponseHeaders  HeaderRules        `yaml:"response_headers,omitempty"`
+	Compression      *CompressionConfig `yaml:"compression,omitempty"`
+	// Streaming 标记为流式路由 (SSE、长轮询、大文件下载)：立即刷新响应、不受服务器写超时
+	// 和服务级整体超时限制。text/event-stream 响应即使未标记也会被自动识别
+	Streaming bool `yaml:"streaming,omitempty"`
+	// AllowedUpgrades 允许的协议升级，如 ["websocket"]；未列出的升级请求返回 403
+	AllowedUpgrades []string `yaml:"allowed_upgrades,omitempty"`
+	// Timeout 该路由的上游整体超时，优先于服务级别的 timeouts.request
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+	// MaxTransformBodySize 请求体/响应体转换时允许缓冲的最大字节数，超过则跳过转换
+	MaxTransformBodySize ByteSize `yaml:"max_transform_body_size,omitempty"`
+	// MaxResponseSize 上游响应体的最大字节数，0 表示不限制
+	// 声明了 Content-Length 的超限响应返回 502；未声明长度的响应在超限时中断连接
+	MaxResponseSize ByteSize `yaml:"max_response_size,omitempty"`
+	// BufferSize 向客户端复制响应体时使用的缓冲区大小，默认 32KB
+	BufferSize ByteSize `yaml:"buffer_size,omitempty"`
+	// Cache 响应缓存规则，未配置时不缓存
+	Cache *RouteCacheConfig `yaml:"cache,omitempty"`
+	// RequestBuffering 在插件链执行前完整缓冲请求体，使其在插件链和代理中可重复读取
+	RequestBuffering *RequestBufferingConfig `yaml:"request_buffering,omitempty"`
+	// Transcoding 配置后该路由把 HTTP/JSON 请求转码为对上游 gRPC 方法的调用
+	Transcoding *TranscodingConfig `yaml:"transcoding,omitempty"`
+}
+
+// RequestBufferingConfig 定义请求体缓冲策略，超过内存阈值的请求体溢出到临时文件
+
+type RequestBufferingConfig struct {
+	Enabled     bool     `yaml:"enabled"`
+	MemoryLimit ByteSize `yaml:"memory_limit,omitempty"` // 内存中保存的最大字节数，默认 1MB
+	MaxSize     ByteSize `yaml:"max_size,omitempty"`     // 允许的最大请求体，超过返回 413，默认 32MB
+	TempDir     string   `yaml:"temp_dir,omitempty"`     // 临时文件目录，默认系统临时目录
+}
+
+// TranscodingConfig 定义 HTTP/JSON 到 gRPC 的转码规则
+// 上游服务的 protocol 必须为 grpc 或 h2c
+
+type TranscodingConfig struct {
+	// DescriptorFiles 由 protoc --include_imports --descriptor_set_out 生成的描述文件
+	DescriptorFiles []string             `yaml:"descriptor_files"`
+	Bindings        []TranscodingBinding `yaml:"bindings"`
+}
+
+// TranscodingBinding 把一个 HTTP 方法 + 路径模板映射到一个 gRPC 方法
+
+type TranscodingBinding struct {
+	Method     string `yaml:"method"`         // HTTP 方法
+	Path       string `yaml:"path"`           // 去掉路由前缀后的路径模板，如 /v1/users/{id}、/v1/files/{name=**}
+	GRPCMethod string `yaml:"grpc_method"`    // 完整方法名，如 user.v1.UserService/GetUser
+	Body       string `yaml:"body,omitempty"` // "*" 表示请求体映射为整个请求消息，字段名表示映射到该字段，空表示无请求体
+}
+
+// RouteCacheConfig 定义路由的响应缓存规则
+// 默认遵循上游的 Cache-Control (s-maxage/max-age/no-store/private)，上游未给出时使用 TTL
+
+type RouteCacheConfig struct {
+	Enabled            bool          `yaml:"enabled"`
+	TTL                time.Duration `yaml:"ttl,omitempty"`                  // 上游未声明缓存时间时使用的默认 TTL
+	IgnoreCacheControl bool          `yaml:"ignore_cache_control,omitempty"` // 忽略上游 Cache-Control，始终使用 TTL
+	Methods            []string      `yaml:"methods,omitempty"`              // 可缓存的方法，默认 GET、HEAD
+	Vary               []string      `yaml:"vary,omitempty"`                 // 参与缓存键计算的请求头；带 Authorization 的请求只有在此列出时才缓存
+	MaxBodySize        ByteSize      `yaml:"max_body_size,omitempty"`        // 超过该字节数的响应不缓存，默认 1MB
+}
+
+// CompressionConfig 定义路由的响应压缩策略
+
+type CompressionConfig struct {
+	Enabled      bool     `yaml:"enabled"`
+	Algorithms   []string `yaml:"algorithms,omitempty"`    // 按优先级排列: br, gzip
+	MinSize      ByteSize `yaml:"min_size,omitempty"`      // 小于该字节数的响应不压缩
+	ContentTypes []string `yaml:"content_types,omitempty"` // 允许压缩的内容类型前缀
+	Level        int      `yaml:"level,omitempty"`         // 压缩级别，0 表示默认
+}
+
+// HeaderRules 定义请求头/响应头的改写规则，按 remove → set → add 的顺序执行
+// 值支持模板变量，例如 {client_ip}、{route}、{service}、{jwt.sub}
+
+type HeaderRules struct {
+	Add    map[string]string `yaml:"add,omitempty"`
+	Set    map[string]string `yaml:"set,omitempty"`
+	Remove []string          `yaml:"remove,omitempty"`
 }
 
 // ServerConfig 定义服务器配置
 
 type ServerConfig struct {
 	Port string `yaml:"port"`
+	// TLSCertFile/TLSKeyFile 同时配置时启用 HTTPS，并自动协商 HTTP/2
+	TLSCertFile string `yaml:"tls_cert_file,omitempty"`
+	TLSKeyFile  string `yaml:"tls_key_file,omitempty"`
+	// ClientAuth 启用 TLS 时对客户端证书的要求: none (默认) / optional (提供时校验) / require (必须提供并通过校验)
+	ClientAuth string `yaml:"client_auth,omitempty"`
+	// ClientCAFile 校验客户端证书的 CA 证书 (PEM)，client_auth 不为 none 时必须配置
+	ClientCAFile string `yaml:"client_ca_file,omitempty"`
+	// EnableH2C 允许客户端在明文连接上直接使用 HTTP/2 (gRPC 客户端常用)
+	EnableH2C bool `yaml:"enable_h2c,omitempty"`
+	// TrustedProxies 可信代理的 CIDR 列表，只有来自这些地址的 X-Forwarded-* 头才会被信任
+	TrustedProxies []string `yaml:"trusted_proxies,omitempty"`
 }
 
 // HealthCheckConfig 定义健康检查配置
@@ -63,6 +206,148 @@ type InstanceConfig struct {
 	Weight int    `yaml:"weight"`
 }
 
+// TransportConfig 定义网关到上游服务的共享连接池与拨号参数
+
+type TransportConfig struct {
+	MaxIdleConns        int           `yaml:"max_idle_conns"`
+	MaxIdleConnsPerHost int           `yaml:"max_idle_conns_per_host"`
+	MaxConnsPerHost     int           `yaml:"max_conns_per_host"`
+	IdleConnTimeout     time.Duration `yaml:"idle_conn_timeout"`
+	DialTimeout         time.Duration `yaml:"dial_timeout"`
+	KeepAlive           time.Duration `yaml:"keep_alive"`
+	TLSHandshakeTimeout time.Duration `yaml:"tls_handshake_timeout"`
+	InsecureSkipVerify  bool          `yaml:"insecure_skip_verify"`
+	// MaxResponseHeaderBytes 上游响应头的最大字节数，超过时返回 502，默认 1MB
+	MaxResponseHeaderBytes ByteSize `yaml:"max_response_header_bytes,omitempty"`
+	// ReadBufferSize/WriteBufferSize 上游连接的读写缓冲区大小，默认 4KB
+	ReadBufferSize  ByteSize `yaml:"read_buffer_size,omitempty"`
+	WriteBufferSize ByteSize `yaml:"write_buffer_size,omitempty"`
+}
+
+// CacheConfig 定义网关共享缓存的后端
+
+type CacheConfig struct {
+	Backend    string      `yaml:"backend,omitempty"`     // 缓存后端: "memory"(默认) / "redis"
+	MaxEntries int         `yaml:"max_entries,omitempty"` // 内存缓存的最大键数量
+	Redis      RedisConfig `yaml:"redis,omitempty"`       // redis 后端的连接参数
+}
+
+// RequestIDConfig 定义全局请求 ID 策略，启用后在所有插件之前为每个请求生成或沿用请求 ID
+
+type RequestIDConfig struct {
+	Enabled           bool   `yaml:"enabled"`
+	Header            string `yaml:"header,omitempty"`             // 默认 X-Request-ID
+	CorrelationHeader string `yaml:"correlation_header,omitempty"` // 默认 X-Correlation-ID
+}
+
+// GlobalPluginsConfig 定义对所有路由生效的全局插件链
+// 插件可通过 priority 字段控制执行顺序: 数值越大越先执行，默认 0，相同优先级保持配置顺序
+
+type GlobalPluginsConfig struct {
+	PreRouting  []PluginSpec `yaml:"pre_routing,omitempty"`  // 路由匹配之前执行，适合与路由无关的插件 (如 IP 黑名单)
+	PostRouting []PluginSpec `yaml:"post_routing,omitempty"` // 路由匹配之后与路由插件合并执行
+}
+
+// ExternalPluginConfig 定义由外部进程实现的插件
+// 网关把请求元数据以 JSON POST 到 URL，由外部服务决定放行、拦截或改写请求头；
+// 注册后即可在路由的 plugins 中按 name 引用
+
+type ExternalPluginConfig struct {
+	Name        string        `yaml:"name"`
+	URL         string        `yaml:"url"`
+	Timeout     time.Duration `yaml:"timeout,omitempty"`       // 单次调用超时，默认 1s
+	FailOpen    bool          `yaml:"fail_open,omitempty"`     // 调用失败时放行请求，默认返回 503
+	ForwardBody bool          `yaml:"forward_body,omitempty"`  // 是否携带请求体
+	MaxBodySize ByteSize      `yaml:"max_body_size,omitempty"` // 携带请求体的上限，默认 64KB，超过时不携带
+}
+
+// AdminConfig 定义管理接口配置
+// 未配置 Token 时只允许本机访问
+
+type AdminConfig struct {
+	Enabled    bool   `yaml:"enabled"`
+	PathPrefix string `yaml:"path_prefix,omitempty"` // 管理接口路径前缀，默认 /admin
+	Token      string `yaml:"token,omitempty"`       // 访问令牌，通过 Authorization: Bearer <token> 传递
+}
+
+// ConfigSourceConfig 定义网关配置的来源，只在本地配置文件中生效。
+// source 为 etcd 或 consul 时，本地文件只用于引导: 网关从远程 KV 读取完整配置并监听其变化，
+// 同一集群的网关共用一份配置
+
+type ConfigSourceConfig struct {
+	Source  string             `yaml:"source,omitempty"` // file (默认) / etcd / consul
+	Etcd    EtcdSourceConfig   `yaml:"etcd,omitempty"`
+	Consul  ConsulSourceConfig `yaml:"consul,omitempty"`
+	Timeout time.Duration      `yaml:"timeout,omitempty"` // 单次读取的超时 (不含等待变化的时间)，默认 10s
+	// Backoff 后端不可用时的重试间隔，从 initial 开始每次翻倍直到 max
+	Backoff BackoffConfig `yaml:"backoff,omitempty"`
+}
+
+// EtcdSourceConfig 通过 etcd v3 的 HTTP/JSON 网关读取配置，多个 endpoint 依次尝试
+
+type EtcdSourceConfig struct {
+	Endpoints []string `yaml:"endpoints"` // 如 http://127.0.0.1:2379
+	Key       string   `yaml:"key"`       // 保存完整 YAML 配置的键
+}
+
+// ConsulSourceConfig 通过 Consul KV 的 HTTP API 读取配置，使用阻塞查询等待变化
+
+type ConsulSourceConfig struct {
+	Address    string `yaml:"address"` // 如 http://127.0.0.1:8500
+	Key        string `yaml:"key"`
+	Token      string `yaml:"token,omitempty"`
+	Datacenter string `yaml:"datacenter,omitempty"`
+}
+
+// BackoffConfig 定义指数退避的重试间隔
+
+type BackoffConfig struct {
+	Initial time.Duration `yaml:"initial,omitempty"` // 默认 1s
+	Max     time.Duration `yaml:"max,omitempty"`     // 默认 1m
+}
+
+// SecretsConfig 定义从密钥管理服务读取配置值的参数。
+// 配置中任意字符串值写作 vault:<路径>#<字段>、aws-sm:<secret id>[#字段]、gcp-sm:<secret 名称>[#字段] 时，
+// 加载配置时从对应服务读取，密钥不必写入磁盘
+
+type SecretsConfig struct {
+	Vault VaultSecretsConfig `yaml:"vault,omitempty"`
+	AWS   AWSSecretsConfig   `yaml:"aws,omitempty"`
+	GCP   GCPSecretsConfig   `yaml:"gcp,omitempty"`
+	// CacheTTL 读取到的值在进程内缓存的时间，期间重新加载配置不再访问密钥服务，默认 5m。
+	// 缓存过期后读取失败时继续使用缓存的值
+	CacheTTL time.Duration `yaml:"cache_ttl,omitempty"`
+	// RefreshInterval 定期重新读取全部引用的间隔，值有变化时自动重新加载配置；0 表示不刷新
+	RefreshInterval time.Duration `yaml:"refresh_interval,omitempty"`
+	Timeout         time.Duration `yaml:"timeout,omitempty"` // 单次读取的超时，默认 10s
+}
+
+// VaultSecretsConfig HashiCorp Vault 的 KV 引擎
+
+type VaultSecretsConfig struct {
+	Address string `yaml:"address,omitempty"` // 默认取环境变量 VAULT_ADDR
+	// Token 默认取环境变量 VAULT_TOKEN；配置 token_file 时每次读取前重新读取该文件，
+	// 适合由 Vault Agent 自动续期并写入令牌
+	Token     string `yaml:"token,omitempty"`
+	TokenFile string `yaml:"token_file,omitempty"`
+	Namespace string `yaml:"namespace,omitempty"`  // Vault Enterprise 命名空间
+	KVVersion int    `yaml:"kv_version,omitempty"` // KV 引擎版本 1 或 2，默认 2
+}
+
+// AWSSecretsConfig AWS Secrets Manager，凭证取自环境变量 AWS_ACCESS_KEY_ID、AWS_SECRET_ACCESS_KEY 与 AWS_SESSION_TOKEN
+
+type AWSSecretsConfig struct {
+	Region   string `yaml:"region,omitempty"`   // 默认取环境变量 AWS_REGION 或 AWS_DEFAULT_REGION
+	Endpoint string `yaml:"endpoint,omitempty"` // 覆盖服务地址，如 VPC endpoint 或本地模拟服务
+}
+
+// GCPSecretsConfig Google Cloud Secret Manager，访问令牌取自环境变量 GOOGLE_OAUTH_ACCESS_TOKEN，
+// 未设置时从 GCE/GKE 元数据服务获取
+
+type GCPSecretsConfig struct {
+	Endpoint string `yaml:"endpoint,omitempty"` // 覆盖服务地址，默认 https://secretmanager.googleapis.com
+}
+
 // PluginSpec 定义插件配置
 
 type PluginSpec map[string]interface{}
@@ -71,14 +356,112 @@ type PluginSpec map[string]interface{}
 
 type RateLimitingConfig struct {
 	Rules []RateLimiterRule `yaml:"rules"`
+	Redis RedisConfig       `yaml:"redis,omitempty"` // redis_token_bucket 等分布式限流器共用的 Redis 连接
+	// Cluster cluster_window 规则在副本之间交换计数的设置，不需要 Redis
+	Cluster ClusterConfig `yaml:"cluster,omitempty"`
+}
+
+// ClusterConfig 定义副本之间交换限流计数的参数。
+// 每个副本定期把本地计数推送到其他副本的管理接口 (POST <peer>/ratelimit/cluster/sync)
+
+type ClusterConfig struct {
+	NodeID       string        `yaml:"node_id,omitempty"`       // 本副本的标识，默认为主机名，各副本必须不同
+	Peers        []string      `yaml:"peers,omitempty"`         // 其他副本管理接口的地址，如 "http://10.0.0.2:8080/admin"
+	SyncInterval time.Duration `yaml:"sync_interval,omitempty"` // 推送周期，默认 1s，应远小于规则的窗口
+	// Token 副本之间共享的同步令牌，推送时作为 Bearer 令牌发送，接收时只用于同步接口；配置 peers 时必须设置。
+	// 与 admin.token 相互独立，副本不需要持有彼此的管理员令牌
+	Token string `yaml:"token,omitempty"`
 }
 
 // RateLimiterRule 定义限流规则
 
 type RateLimiterRule struct {
-	Name        string              `yaml:"name"`
-	Type        string              `yaml:"type"`
-	TokenBucket TokenBucketSettings `yaml:"tokenBucket,omitempty"`
+	Name          string                `yaml:"name"`
+	Type          string                `yaml:"type"`
+	TokenBucket   TokenBucketSettings   `yaml:"tokenBucket,omitempty"`
+	SlidingWindow SlidingWindowSettings `yaml:"slidingWindow,omitempty"`
+	Concurrency   ConcurrencySettings   `yaml:"concurrency,omitempty"`
+	LeakyBucket   LeakyBucketSettings   `yaml:"leakyBucket,omitempty"`
+	ClusterWindow ClusterWindowSettings `yaml:"clusterWindow,omitempty"`
+	FailurePolicy string                `yaml:"failure_policy,omitempty"` // 后端 (如 Redis) 不可用时: "open"(默认，放行) / "closed"(拒绝)
+	Adaptive      *AdaptiveSettings     `yaml:"adaptive,omitempty"`       // 按上游健康状况自动收紧限额
+	Bypass        *BypassSettings       `yaml:"bypass,omitempty"`         // 免限流名单，命中的请求不经过限流器
+}
+
+// SlidingWindowSettings 定义滑动窗口设置
+
+type SlidingWindowSettings struct {
+	Window      time.Duration `yaml:"window"`      // 窗口长度，如 "1m"
+	MaxRequests int           `yaml:"maxRequests"` // 窗口内允许的最大请求数
+}
+
+// ConcurrencySettings 定义并发限流 (舱壁) 设置
+
+type ConcurrencySettings struct {
+	MaxInFlight  int           `yaml:"maxInFlight"`            // 同时在途的最大请求数
+	QueueSize    int           `yaml:"queueSize,omitempty"`    // 名额用尽时最多排队的请求数，0 表示直接拒绝
+	QueueTimeout time.Duration `yaml:"queueTimeout,omitempty"` // 排队的最长等待时间
+}
+
+// ClusterWindowSettings 定义集群固定窗口设置，上限由所有副本共同遵守
+
+type ClusterWindowSettings struct {
+	Window      time.Duration `yaml:"window"`      // 窗口长度，如 "1m"
+	MaxRequests int           `yaml:"maxRequests"` // 整个集群在窗口内允许的最大请求数
+}
+
+// LeakyBucketSettings 定义漏桶整形设置，超出速率的请求排队等待而不是立即拒绝
+
+type LeakyBucketSettings struct {
+	Rate     int           `yaml:"rate"`     // 每秒放行的请求数
+	MaxDelay time.Duration `yaml:"maxDelay"` // 请求最长等待时间，超过时拒绝
+}
+
+// AdaptiveSettings 定义自适应限流：上游熔断器半开/打开或错误率升高时收紧限额，恢复后逐步放宽
+
+type AdaptiveSettings struct {
+	Service            string  `yaml:"service"`                      // 跟随其熔断器状态的上游服务名
+	MinFactor          float64 `yaml:"minFactor,omitempty"`          // 上游异常时的限额比例，默认 0.5
+	ErrorRateThreshold float64 `yaml:"errorRateThreshold,omitempty"` // 触发收紧的错误率 (0-1)，默认 0.5
+	MinRequests        int     `yaml:"minRequests,omitempty"`        // 计算错误率所需的最少请求数，默认 10
+	RecoveryStep       float64 `yaml:"recoveryStep,omitempty"`       // 上游恢复后每个周期放宽的比例，默认 0.1
+}
+
+// BypassSettings 定义规则的免限流名单，满足任意一项即跳过限流
+
+type BypassSettings struct {
+	CIDRs   []string            `yaml:"cidrs,omitempty"`   // 客户端 IP 或网段，如 "10.0.0.0/8"
+	APIKeys []string            `yaml:"apiKeys,omitempty"` // ratelimit 插件 api_key_header 请求头中的 API Key
+	Headers map[string][]string `yaml:"headers,omitempty"` // 请求头名 -> 允许的值
+}
+
+// QuotaConfig 定义按天/按月计数的长期配额，计数保存在共享缓存中
+
+type QuotaConfig struct {
+	Rules []QuotaRule `yaml:"rules"`
+}
+
+// QuotaRule 定义一条配额规则
+
+type QuotaRule struct {
+	Name     string `yaml:"name"`
+	Period   string `yaml:"period"`             // 计数周期: "daily" / "monthly"
+	Limit    int64  `yaml:"limit"`              // 每个周期允许的请求数
+	Timezone string `yaml:"timezone,omitempty"` // 周期边界所在的时区，如 "Asia/Shanghai"，默认 UTC
+}
+
+// RedisConfig 定义 Redis 连接参数
+
+type RedisConfig struct {
+	Addr        string        `yaml:"addr"`               // host:port
+	Username    string        `yaml:"username,omitempty"` // Redis 6 ACL 用户名
+	Password    string        `yaml:"password,omitempty"`
+	DB          int           `yaml:"db,omitempty"`
+	KeyPrefix   string        `yaml:"key_prefix,omitempty"`   // 所有键的前缀，多个网关集群共用一个 Redis 时区分
+	PoolSize    int           `yaml:"pool_size,omitempty"`    // 连接池大小，默认每个 CPU 10 个
+	DialTimeout time.Duration `yaml:"dial_timeout,omitempty"` // 建立连接超时，默认 5s
+	Timeout     time.Duration `yaml:"timeout,omitempty"`      // 单次命令读写超时，默认 100ms，限流判断不应拖慢请求
+	TLS         bool          `yaml:"tls,omitempty"`          // 是否使用 TLS 连接
 }
 
 // TokenBucketSettings 定义令牌桶设置
@@ -86,40 +469,265 @@ type RateLimiterRule struct {
 type TokenBucketSettings struct {
 	Capacity   int `yaml:"capacity"`
 	RefillRate int `yaml:"refillRate"`
+	MaxBuckets int `yaml:"maxBuckets,omitempty"` // memory_token_bucket 最多保存的桶 (标识符) 数量，默认 100000
 }
 
 // JWTConfig 定义JWT配置
 
 type JWTConfig struct {
-	SecretKey       string `yaml:"secret_key"`
-	DurationMinutes int    `yaml:"duration_minutes"`
+	SecretKey string `yaml:"secret_key"`
+	// Duration 访问令牌有效期，如 1h
+	Duration time.Duration `yaml:"duration,omitempty"`
+	// RefreshDuration 刷新令牌有效期，默认 168h (7 天)
+	RefreshDuration time.Duration `yaml:"refresh_duration,omitempty"`
+	// DurationMinutes/RefreshDurationMinutes 已废弃，以分钟为单位的旧写法，未配置 duration/refresh_duration 时使用
+	DurationMinutes        int `yaml:"duration_minutes,omitempty"`
+	RefreshDurationMinutes int `yaml:"refresh_duration_minutes,omitempty"`
+	// Algorithm 签名算法: HS256 (默认，使用 secret_key) / RS256 / ES256 (使用 private_key_file，公钥通过 JWKS 发布)
+	Algorithm      string `yaml:"algorithm,omitempty"`
+	PrivateKeyFile string `yaml:"private_key_file,omitempty"` // PEM 格式私钥 (PKCS#8、PKCS#1 或 SEC 1)
+	KeyID          string `yaml:"key_id,omitempty"`           // 令牌头中的 kid，非对称密钥默认使用公钥的 JWK 指纹
+	// Keys 密钥轮换使用的密钥环: current_key_id 指定的密钥 (默认第一个) 签发新令牌，其余密钥只用于校验旧令牌直到过期。
+	// 同时配置了上面的单个密钥时，它用于校验轮换前签发的、没有 kid 的令牌
+	Keys         []JWTKeyConfig `yaml:"keys,omitempty"`
+	CurrentKeyID string         `yaml:"current_key_id,omitempty"`
+}
+
+// JWTKeyConfig 密钥环中的一个密钥
+type JWTKeyConfig struct {
+	ID             string `yaml:"id"`                         // 令牌头中的 kid，必须唯一
+	Algorithm      string `yaml:"algorithm,omitempty"`        // HS256 (默认) / RS256 / ES256
+	SecretKey      string `yaml:"secret_key,omitempty"`       // HS256 的密钥
+	PrivateKeyFile string `yaml:"private_key_file,omitempty"` // RS256 / ES256 的 PEM 私钥
+}
+
+// AccessTokenTTL 返回访问令牌有效期，duration 优先于已废弃的 duration_minutes
+func (c JWTConfig) AccessTokenTTL() time.Duration {
+	if c.Duration != 0 {
+		return c.Duration
+	}
+	return time.Duration(c.DurationMinutes) * time.Minute
+}
+
+// RefreshTokenTTL 返回刷新令牌有效期，refresh_duration 优先于已废弃的 refresh_duration_minutes，都未配置时返回 0
+func (c JWTConfig) RefreshTokenTTL() time.Duration {
+	if c.RefreshDuration != 0 {
+		return c.RefreshDuration
+	}
+	return time.Duration(c.RefreshDurationMinutes) * time.Minute
 }
 
 // AuthServiceConfig 定义认证服务配置
 
 type AuthServiceConfig struct {
 	ValidateURL string `yaml:"validate_url"`
+	// 以下为独立认证服务 (cmd/auth-service) 自身的配置
+	BcryptCost        int  `yaml:"bcrypt_cost,omitempty"`        // 密码哈希的 bcrypt 成本，默认 10；调整后旧哈希在用户下次登录时按新成本重新计算
+	AllowRegistration bool `yaml:"allow_registration,omitempty"` // 是否开放 POST /register 自助注册
+	// TrustedProxies 认证服务前面的可信代理 (通常是网关) 的 CIDR 列表，只信任来自这些地址的 X-Forwarded-For
+	TrustedProxies []string             `yaml:"trusted_proxies,omitempty"`
+	LoginThrottle  LoginThrottleConfig  `yaml:"login_throttle,omitempty"`
+	PasswordPolicy PasswordPolicyConfig `yaml:"password_policy,omitempty"`
+	// Clients 可以通过 POST /token (client_credentials) 获取机器令牌的服务客户端
+	Clients        []ServiceClientConfig `yaml:"clients,omitempty"`
+	ClientTokenTTL time.Duration         `yaml:"client_token_ttl,omitempty"` // 机器令牌有效期，默认 5m
+	// AuditLogConfig 安全审计日志的日志配置文件，默认 ./configs/logs/auth-audit-log.yaml
+	AuditLogConfig string `yaml:"audit_log_config,omitempty"`
+	// IdentityProvider 登录时校验用户名与密码的身份源，默认使用认证服务自己的用户表
+	IdentityProvider IdentityProviderConfig `yaml:"identity_provider,omitempty"`
+	// SessionStore 登录会话的存储: memory (默认，单实例) / redis (使用 cache.redis 的连接，多个副本共享会话)
+	SessionStore string `yaml:"session_store,omitempty"`
+	// UserCacheTTL 大于 0 时把按用户名、ID 的用户查询缓存在共享缓存 (cache) 中，为 0 时不缓存
+	UserCacheTTL time.Duration `yaml:"user_cache_ttl,omitempty"`
+}
+
+// IdentityProviderConfig 选择登录凭证的校验方式: local (默认，本地用户表) / ldap / oidc。
+// 使用外部身份源时，用户首次登录成功后在本地创建同名账户，用于会话、停用与角色管理
+type IdentityProviderConfig struct {
+	Type string        `yaml:"type,omitempty"`
+	LDAP LDAPConfig    `yaml:"ldap,omitempty"`
+	OIDC OIDCIdPConfig `yaml:"oidc,omitempty"`
+	// DefaultRoles 外部身份源没有提供角色时，新建账户获得的角色
+	DefaultRoles []string `yaml:"default_roles,omitempty"`
+}
+
+// LDAPConfig 以用户的凭证向目录服务发起 simple bind 校验密码
+type LDAPConfig struct {
+	URL string `yaml:"url"` // ldap://host:389 或 ldaps://host:636
+	// BindDN 用户 DN 模板，{username} 会被替换为转义后的用户名，
+	// 如 "uid={username},ou=people,dc=example,dc=com" 或 Active Directory 的 "{username}@corp.example.com"
+	BindDN             string        `yaml:"bind_dn"`
+	StartTLS           bool          `yaml:"start_tls,omitempty"` // ldap:// 连接上先升级为 TLS
+	InsecureSkipVerify bool          `yaml:"insecure_skip_verify,omitempty"`
+	Timeout            time.Duration `yaml:"timeout,omitempty"` // 默认 5s
+}
+
+// OIDCIdPConfig 以 OAuth 2.0 password grant 向外部 OIDC 提供方校验用户凭证
+type OIDCIdPConfig struct {
+	TokenURL     string        `yaml:"token_url"`
+	ClientID     string        `yaml:"client_id"`
+	ClientSecret string        `yaml:"client_secret,omitempty"`
+	Scope        string        `yaml:"scope,omitempty"`       // 默认 "openid"
+	RolesClaim   string        `yaml:"roles_claim,omitempty"` // 从 ID Token 中读取角色的 claim，配置后每次登录同步角色
+	Timeout      time.Duration `yaml:"timeout,omitempty"`     // 默认 5s
+}
+
+// ServiceClientConfig 服务间调用使用的客户端凭证
+type ServiceClientConfig struct {
+	ID     string `yaml:"client_id"`
+	Secret string `yaml:"client_secret"` // bcrypt 哈希；非 bcrypt 格式的值按明文比较，仅用于开发环境
+	// Scopes 客户端可以申请的 scope，请求未指定 scope 时授予全部
+	Scopes   []string `yaml:"scopes,omitempty"`
+	TenantID string   `yaml:"tenant_id,omitempty"`
+}
+
+// PasswordPolicyConfig 注册、修改密码与管理员重置密码时对新密码的要求。内置的常见弱密码总是被拒绝
+type PasswordPolicyConfig struct {
+	MinLength           int      `yaml:"min_length,omitempty"` // 最小长度 (字符数)，默认 8；bcrypt 限制最长 72 字节
+	RequireUppercase    bool     `yaml:"require_uppercase,omitempty"`
+	RequireLowercase    bool     `yaml:"require_lowercase,omitempty"`
+	RequireDigit        bool     `yaml:"require_digit,omitempty"`
+	RequireSymbol       bool     `yaml:"require_symbol,omitempty"`
+	AllowUsername       bool     `yaml:"allow_username,omitempty"`        // 默认不允许密码包含用户名
+	BannedPasswords     []string `yaml:"banned_passwords,omitempty"`      // 额外禁止的密码 (不区分大小写)
+	BannedPasswordsFile string   `yaml:"banned_passwords_file,omitempty"` // 每行一个禁止的密码
+	// BreachCheck 通过 Have I Been Pwned 的 k-匿名接口检查密码是否已泄露，只发送摘要前缀；接口不可用时放行
+	BreachCheck    bool   `yaml:"breach_check,omitempty"`
+	BreachCheckURL string `yaml:"breach_check_url,omitempty"` // 默认 https://api.pwnedpasswords.com/range/
+}
+
+// LoginThrottleConfig 登录失败限制: 同一用户名每次失败后按指数退避暂停登录，达到上限后锁定；同一 IP 达到上限后锁定
+type LoginThrottleConfig struct {
+	Enabled         bool          `yaml:"enabled"`
+	MaxFailures     int           `yaml:"max_failures,omitempty"`     // 同一用户名在 window 内失败多少次后锁定，默认 5
+	MaxIPFailures   int           `yaml:"max_ip_failures,omitempty"`  // 同一 IP 在 window 内失败多少次后锁定，默认 20
+	Window          time.Duration `yaml:"window,omitempty"`           // 失败计数的统计窗口，默认 15m
+	BaseDelay       time.Duration `yaml:"base_delay,omitempty"`       // 第一次失败后的暂停时间，之后每次翻倍，默认 1s
+	LockoutDuration time.Duration `yaml:"lockout_duration,omitempty"` // 锁定时长，也是退避的上限，默认 15m
 }
 
 // CircuitBreakerConfig 定义断路器配置
 
 type CircuitBreakerConfig struct {
+	// Mode 触发熔断的方式: "consecutive"(默认，连续失败 failure_threshold 次) /
+	// "error_rate"(window 内至少 min_requests 个请求且失败比例达到 error_rate_threshold)
+	Mode             string        `yaml:"mode,omitempty"`
 	FailureThreshold int           `yaml:"failure_threshold"`
 	SuccessThreshold int           `yaml:"success_threshold"`
 	ResetTimeout     time.Duration `yaml:"reset_timeout"`
+	// HalfOpenMaxRequests 半开状态下同时放行的试探请求数，0 表示不限制
+	HalfOpenMaxRequests int           `yaml:"half_open_max_requests,omitempty"`
+	ErrorRateThreshold  float64       `yaml:"error_rate_threshold,omitempty"` // error_rate 模式的失败比例，默认 0.5
+	MinRequests         int           `yaml:"min_requests,omitempty"`         // error_rate 模式下窗口内的最少请求数，默认 20
+	Window              time.Duration `yaml:"window,omitempty"`               // error_rate 模式的统计窗口，默认 10s
+	// FailureStatuses 计为失败的上游响应状态码，可以是具体状态码 ("429") 或状态码类别 ("5xx")，默认 ["5xx"]。
+	// 连接失败与超时总是计为失败
+	FailureStatuses []string `yaml:"failure_statuses,omitempty"`
+	// PerInstance 为每个实例单独熔断，熔断中的实例不参与负载均衡；服务级配置只能开启
+	PerInstance bool `yaml:"per_instance,omitempty"`
+	// HealthSignals 跟随主动健康检查: 服务所有实例都不健康时立即打开熔断器，恢复健康后进入半开状态，
+	// 试探成功后才关闭；服务级配置只能开启
+	HealthSignals bool `yaml:"health_signals,omitempty"`
+	// MaxConcurrentRequests 服务同时在途的请求数上限 (舱壁)，超出的请求直接返回 503 并计为熔断失败，0 表示不限制
+	MaxConcurrentRequests int `yaml:"max_concurrent_requests,omitempty"`
+	// IdleTTL 超过该时长未被使用的关闭状态熔断器会被回收，默认 1h，仅全局配置生效
+	IdleTTL time.Duration `yaml:"idle_ttl,omitempty"`
+	// Notify 熔断器状态变化时的 webhook 通知，仅全局配置生效
+	Notify *CircuitBreakerNotifyConfig `yaml:"notify,omitempty"`
+	// Persist 保存打开状态的熔断器，重启后恢复，仅全局配置生效
+	Persist *CircuitBreakerPersistConfig `yaml:"persist,omitempty"`
+}
+
+// CircuitBreakerPersistConfig 熔断器状态持久化
+type CircuitBreakerPersistConfig struct {
+	Backend string        `yaml:"backend"`        // file / cache (共享缓存，Redis 后端时多个副本共享)
+	Path    string        `yaml:"path,omitempty"` // backend 为 file 时的状态文件路径
+	TTL     time.Duration `yaml:"ttl,omitempty"`  // 保存的状态的有效期，超过后重启时不再恢复，默认 10m
+}
+
+// CircuitBreakerNotifyConfig 熔断器状态变化通知
+type CircuitBreakerNotifyConfig struct {
+	URL     string            `yaml:"url"`
+	Format  string            `yaml:"format,omitempty"`  // json (默认，POST 事件本身) / slack (POST {"text": ...})
+	States  []string          `yaml:"states,omitempty"`  // 只通知变化到这些状态 (open / half-open / closed) 的事件，默认全部
+	Timeout time.Duration     `yaml:"timeout,omitempty"` // 单次通知的超时，默认 5s
+	Headers map[string]string `yaml:"headers,omitempty"` // 附加的请求头，如鉴权
 }
 
-// Load 从指定路径加载配置文件
+// Load 从指定路径加载配置文件，解析前先替换其中的 ${VAR} 环境变量引用 (见 expandEnv)
 
 func Load(path string) (*GatewayConfig, error) {
+	return LoadEnv(path, "")
+}
+
+// LoadEnv 加载基础配置文件，env 非空时再叠加同目录下的环境配置文件 (见 OverlayPath 与 mergeYAML)，
+// 替换其中的密钥引用 (见 resolveSecrets)，最后合并 include 的配置片段
+
+func LoadEnv(path, env string) (*GatewayConfig, error) {
+	data, err := readConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+	name := path
+	if env != "" {
+		overlayPath, err := OverlayPath(path, env)
+		if err != nil {
+			return nil, err
+		}
+		overlay, err := readConfigFile(overlayPath)
+		if err != nil {
+			return nil, err
+		}
+		if data, err = mergeYAML(data, overlay); err != nil {
+			return nil, fmt.Errorf("叠加环境配置 '%s' 失败: %w", overlayPath, err)
+		}
+		name = path + " + " + overlayPath
+	}
+
+	if data, err = resolveSecrets(data, nil); err != nil {
+		return nil, fmt.Errorf("解析配置 '%s' 中的密钥引用失败: %w", name, err)
+	}
+	if err := checkFields(data, GatewayConfig{}); err != nil {
+		return nil, fmt.Errorf("解析配置 '%s' 失败: %w", name, err)
+	}
+	var cfg GatewayConfig
+	if err := yaml.UnmarshalStrict(data, &cfg); err != nil {
+		return nil, fmt.Errorf("解析配置 '%s' 失败: %w", name, err)
+	}
+	if err := cfg.mergeIncludes(filepath.Dir(path)); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// readConfigFile 读取配置文件并替换环境变量引用
+func readConfigFile(path string) ([]byte, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("读取配置文件 '%s' 失败: %w", path, err)
 	}
+	if data, err = expandEnv(data); err != nil {
+		return nil, fmt.Errorf("加载配置文件 '%s' 失败: %w", path, err)
+	}
+	return data, nil
+}
+
+// parse 解析配置文本，name 为配置来源，用于错误信息
+func parse(data []byte, name string) (*GatewayConfig, error) {
+	data, err := expandEnv(data)
+	if err != nil {
+		return nil, fmt.Errorf("加载配置 '%s' 失败: %w", name, err)
+	}
+	if data, err = resolveSecrets(data, nil); err != nil {
+		return nil, fmt.Errorf("解析配置 '%s' 中的密钥引用失败: %w", name, err)
+	}
 
+	if err := checkFields(data, GatewayConfig{}); err != nil {
+		return nil, fmt.Errorf("解析配置 '%s' 失败: %w", name, err)
+	}
 	var config GatewayConfig
-	if err := yaml.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("解析配置文件 '%s' 失败: %w", path, err)
+	if err := yaml.UnmarshalStrict(data, &config); err != nil {
+		return nil, fmt.Errorf("解析配置 '%s' 失败: %w", name, err)
 	}
 
 	return &config, nil
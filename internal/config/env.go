@@ -0,0 +1,69 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// envPattern 匹配 ${VAR} 与 ${VAR:-default}，$${ 为转义，保留字面的 ${
+var envPattern = regexp.MustCompile(`\$\$\{|\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// expandEnv 在解析 YAML 之前替换配置文本中的环境变量引用:
+//   - ${VAR}            取环境变量 VAR，未设置时报错
+//   - ${VAR:-default}   VAR 未设置或为空时使用 default
+//   - VAR 未设置而 VAR_FILE 指向一个文件时读取文件内容 (去掉末尾换行)，用于 Docker/Kubernetes secrets
+//
+// 只识别 ${...} 形式，bcrypt 哈希等含 $ 的值不受影响；注释行不做替换。
+// 替换是纯文本的，值中可能含有 YAML 特殊字符时应在配置中给引用加引号
+func expandEnv(data []byte) ([]byte, error) {
+	var missing []string
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	for i, line := range lines {
+		if bytes.HasPrefix(bytes.TrimSpace(line), []byte("#")) {
+			continue
+		}
+		lines[i] = envPattern.ReplaceAllFunc(line, func(m []byte) []byte {
+			if string(m) == "$${" {
+				return []byte("${")
+			}
+			sub := envPattern.FindSubmatch(m)
+			name, hasDefault := string(sub[1]), sub[2] != nil
+			value, ok, err := lookupEnv(name)
+			if err != nil {
+				missing = append(missing, err.Error())
+				return m
+			}
+			if hasDefault && value == "" {
+				return sub[3]
+			}
+			if !ok && !hasDefault {
+				missing = append(missing, fmt.Sprintf("环境变量 '%s' 未设置", name))
+				return m
+			}
+			return []byte(value)
+		})
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("配置中的环境变量引用无法解析: %s", strings.Join(missing, "; "))
+	}
+	return bytes.Join(lines, nil), nil
+}
+
+// lookupEnv 读取环境变量 name，未设置时尝试 name_FILE 指向的文件
+func lookupEnv(name string) (string, bool, error) {
+	if value, ok := os.LookupEnv(name); ok {
+		return value, true, nil
+	}
+	path, ok := os.LookupEnv(name + "_FILE")
+	if !ok || path == "" {
+		return "", false, nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", false, fmt.Errorf("读取 %s_FILE '%s' 失败: %v", name, path, err)
+	}
+	return strings.TrimRight(string(content), "\r\n"), true, nil
+}
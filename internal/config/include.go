@@ -0,0 +1,123 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v2"
+)
+
+// fragment 配置片段文件的内容，团队可以在各自的文件中维护服务与路由
+type fragment struct {
+	Services map[string]ServiceConfig `yaml:"services"`
+	Routes   []*RouteConfig           `yaml:"routes"`
+}
+
+// mergeIncludes 把 include 匹配到的片段文件合并进配置，合并规则:
+//   - 各 pattern 按配置顺序展开，每个 pattern 匹配到的文件按路径字典序排列，重复匹配的文件只合并一次
+//   - 片段中的服务加入 services，服务名与主配置或先合并的片段重复时报错
+//   - 片段中的路由按文件顺序追加在主配置的路由之后；路由按前缀顺序匹配，
+//     path_prefix 与已有路由相同时后者永远不会命中，因此视为冲突并报错
+//
+// 片段文件同样支持 ${VAR} 环境变量与密钥引用，但不能再 include 其他文件
+func (c *GatewayConfig) mergeIncludes(baseDir string) error {
+	files, err := expandIncludes(baseDir, c.Include)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return nil
+	}
+
+	serviceOwner := make(map[string]string, len(c.Services))
+	for name := range c.Services {
+		serviceOwner[name] = "主配置"
+	}
+	routeOwner := make(map[string]string, len(c.Routes))
+	for _, r := range c.Routes {
+		if r != nil {
+			routeOwner[r.PathPrefix] = "主配置"
+		}
+	}
+	if c.Services == nil {
+		c.Services = make(map[string]ServiceConfig)
+	}
+
+	for _, file := range files {
+		frag, err := loadFragment(file, &c.Secrets)
+		if err != nil {
+			return err
+		}
+		names := make([]string, 0, len(frag.Services))
+		for name := range frag.Services {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if owner, ok := serviceOwner[name]; ok {
+				return fmt.Errorf("配置片段 '%s' 中的服务 '%s' 已在 %s 中定义", file, name, owner)
+			}
+			serviceOwner[name] = file
+			c.Services[name] = frag.Services[name]
+		}
+		for _, r := range frag.Routes {
+			if r == nil {
+				continue
+			}
+			if owner, ok := routeOwner[r.PathPrefix]; ok {
+				return fmt.Errorf("配置片段 '%s' 中 path_prefix 为 '%s' 的路由已在 %s 中定义", file, r.PathPrefix, owner)
+			}
+			routeOwner[r.PathPrefix] = file
+			c.Routes = append(c.Routes, r)
+		}
+	}
+	return nil
+}
+
+// expandIncludes 展开 include 的 glob，返回去重后的文件列表
+func expandIncludes(baseDir string, patterns []string) ([]string, error) {
+	var files []string
+	seen := make(map[string]bool)
+	for _, pattern := range patterns {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(baseDir, pattern)
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("include 中的 '%s' 不是有效的 glob: %w", pattern, err)
+		}
+		sort.Strings(matches)
+		for _, m := range matches {
+			if !seen[m] {
+				seen[m] = true
+				files = append(files, m)
+			}
+		}
+	}
+	return files, nil
+}
+
+// loadFragment 读取并解析一个片段文件，出现 services 与 routes 以外的字段时报错。
+// 片段中的密钥引用按主配置的 secrets 段读取
+func loadFragment(path string, secrets *SecretsConfig) (*fragment, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取配置片段 '%s' 失败: %w", path, err)
+	}
+	if data, err = expandEnv(data); err != nil {
+		return nil, fmt.Errorf("加载配置片段 '%s' 失败: %w", path, err)
+	}
+	if data, err = resolveSecrets(data, secrets); err != nil {
+		return nil, fmt.Errorf("解析配置片段 '%s' 中的密钥引用失败: %w", path, err)
+	}
+	if err := checkFields(data, fragment{}); err != nil {
+		return nil, fmt.Errorf("解析配置片段 '%s' 失败: %w", path, err)
+	}
+	var frag fragment
+	if err := yaml.UnmarshalStrict(data, &frag); err != nil {
+		return nil, fmt.Errorf("解析配置片段 '%s' 失败 (片段中只能定义 services 与 routes): %w", path, err)
+	}
+	return &frag, nil
+}
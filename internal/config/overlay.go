@@ -0,0 +1,57 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// envNamePattern 环境名只允许字母、数字、下划线与连字符，避免拼出其他目录下的文件
+var envNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// OverlayPath 返回基础配置文件对应的环境配置文件，如 configs/config.yaml + prod -> configs/config.prod.yaml
+func OverlayPath(path, env string) (string, error) {
+	if !envNamePattern.MatchString(env) {
+		return "", fmt.Errorf("无效的环境名 '%s'，只允许字母、数字、下划线与连字符", env)
+	}
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + env + ext, nil
+}
+
+// mergeYAML 把 overlay 叠加到 base 上，规则:
+//   - 两边都是映射时逐键递归合并，overlay 中没有的键保留 base 的值
+//   - 其他情况 (标量、列表、类型不同) 以 overlay 为准，列表整体替换而不是追加
+//   - overlay 中显式写 null (~) 的键会被删除，恢复为默认值
+func mergeYAML(base, overlay []byte) ([]byte, error) {
+	var b, o map[interface{}]interface{}
+	if err := yaml.Unmarshal(base, &b); err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(overlay, &o); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(mergeMaps(b, o))
+}
+
+func mergeMaps(base, overlay map[interface{}]interface{}) map[interface{}]interface{} {
+	if base == nil {
+		base = make(map[interface{}]interface{}, len(overlay))
+	}
+	for k, ov := range overlay {
+		if ov == nil {
+			delete(base, k)
+			continue
+		}
+		bm, baseIsMap := base[k].(map[interface{}]interface{})
+		om, overlayIsMap := ov.(map[interface{}]interface{})
+		if baseIsMap && overlayIsMap {
+			base[k] = mergeMaps(bm, om)
+		} else {
+			base[k] = ov
+		}
+	}
+	return base
+}
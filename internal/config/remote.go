@@ -0,0 +1,415 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 远程配置后端的默认参数
+const (
+	defaultSourceTimeout  = 10 * time.Second
+	defaultBackoffInitial = time.Second
+	defaultBackoffMax     = time.Minute
+	// watchWait 单次等待配置变化的最长时间，超时后重新发起等待
+	watchWait = 5 * time.Minute
+)
+
+// remoteSource 远程配置后端
+type remoteSource interface {
+	// Fetch 读取完整配置文本及其版本。version 非空时先等待配置版本变为不同于 version，
+	// 等待超时仍未变化时返回 nil 数据与原版本
+	Fetch(ctx context.Context, version string) (data []byte, newVersion string, err error)
+	// Name 返回后端与键的描述，用于日志与错误信息
+	Name() string
+}
+
+// RemoteConfig 从 etcd 或 Consul KV 读取并监听网关配置。
+// 远程配置中的 config 段被忽略，配置来源始终以本地引导配置为准
+type RemoteConfig struct {
+	src  remoteSource
+	boot ConfigSourceConfig
+
+	mu      sync.Mutex
+	version string // 最近读取到的配置版本
+	last    []byte // 最近读取到的配置文本，内容未变时不重复通知
+}
+
+// NewRemoteConfig 按本地配置的 config 段创建远程配置，source 为空或 file 时返回 nil
+func NewRemoteConfig(boot ConfigSourceConfig) (*RemoteConfig, error) {
+	timeout := boot.Timeout
+	if timeout <= 0 {
+		timeout = defaultSourceTimeout
+	}
+	var src remoteSource
+	switch boot.Source {
+	case "", "file":
+		return nil, nil
+	case "etcd":
+		if len(boot.Etcd.Endpoints) == 0 || boot.Etcd.Key == "" {
+			return nil, errors.New("config.etcd 需要配置 endpoints 与 key")
+		}
+		src = &etcdSource{endpoints: boot.Etcd.Endpoints, key: boot.Etcd.Key, timeout: timeout, client: &http.Client{}}
+	case "consul":
+		if boot.Consul.Address == "" || boot.Consul.Key == "" {
+			return nil, errors.New("config.consul 需要配置 address 与 key")
+		}
+		src = &consulSource{cfg: boot.Consul, timeout: timeout, client: &http.Client{}}
+	default:
+		return nil, fmt.Errorf("不支持的配置来源 '%s'", boot.Source)
+	}
+	return &RemoteConfig{src: src, boot: boot}, nil
+}
+
+// Name 返回远程配置的位置，如 etcd://gateway/config.yaml
+func (r *RemoteConfig) Name() string {
+	return r.src.Name()
+}
+
+// Load 读取一次远程配置的最新值
+func (r *RemoteConfig) Load(ctx context.Context) (*GatewayConfig, error) {
+	data, version, err := r.src.Fetch(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	r.version, r.last = version, data
+	r.mu.Unlock()
+	return r.parse(data)
+}
+
+// LoadWithRetry 读取远程配置，后端不可用时调用 onError 并按指数退避重试，直到成功或 ctx 结束。
+// 配置本身无效时直接返回错误
+func (r *RemoteConfig) LoadWithRetry(ctx context.Context, onError func(err error, retryIn time.Duration)) (*GatewayConfig, error) {
+	b := newBackoff(r.boot.Backoff)
+	for {
+		data, version, err := r.src.Fetch(ctx, "")
+		if err == nil {
+			r.mu.Lock()
+			r.version, r.last = version, data
+			r.mu.Unlock()
+			return r.parse(data)
+		}
+		wait := b.next()
+		if ctx.Err() == nil && onError != nil {
+			onError(err, wait)
+		}
+		if ctx.Err() != nil || !sleepContext(ctx, wait) {
+			return nil, err
+		}
+	}
+}
+
+// Watch 监听远程配置，内容变化且解析成功时调用 onChange，配置无效时调用 onError 后等待下一次变化；
+// 后端出错时调用 onError 并按指数退避重试。阻塞直到 ctx 结束
+func (r *RemoteConfig) Watch(ctx context.Context, onChange func(cfg *GatewayConfig), onError func(err error, retryIn time.Duration)) {
+	b := newBackoff(r.boot.Backoff)
+	for ctx.Err() == nil {
+		r.mu.Lock()
+		version := r.version
+		r.mu.Unlock()
+
+		data, next, err := r.src.Fetch(ctx, version)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			wait := b.next()
+			onError(err, wait)
+			// 原版本可能已失效 (如 etcd 压缩了历史)，恢复后重新读取最新值
+			r.mu.Lock()
+			r.version = ""
+			r.mu.Unlock()
+			sleepContext(ctx, wait)
+			continue
+		}
+		b.reset()
+
+		r.mu.Lock()
+		changed := data != nil && !bytes.Equal(data, r.last)
+		r.version = next
+		if changed {
+			r.last = data
+		}
+		r.mu.Unlock()
+		if !changed {
+			continue
+		}
+		cfg, err := r.parse(data)
+		if err != nil {
+			onError(err, 0)
+			continue
+		}
+		onChange(cfg)
+	}
+}
+
+func (r *RemoteConfig) parse(data []byte) (*GatewayConfig, error) {
+	cfg, err := parse(data, r.src.Name())
+	if err != nil {
+		return nil, err
+	}
+	cfg.ConfigSource = r.boot
+	return cfg, nil
+}
+
+// backoff 指数退避: 从 initial 开始每次翻倍，最大为 max
+type backoff struct {
+	initial, max, current time.Duration
+}
+
+func newBackoff(cfg BackoffConfig) *backoff {
+	b := &backoff{initial: cfg.Initial, max: cfg.Max}
+	if b.initial <= 0 {
+		b.initial = defaultBackoffInitial
+	}
+	if b.max < b.initial {
+		b.max = defaultBackoffMax
+		if b.max < b.initial {
+			b.max = b.initial
+		}
+	}
+	return b
+}
+
+func (b *backoff) next() time.Duration {
+	if b.current == 0 {
+		b.current = b.initial
+	} else if b.current *= 2; b.current > b.max {
+		b.current = b.max
+	}
+	return b.current
+}
+
+func (b *backoff) reset() { b.current = 0 }
+
+// sleepContext 等待 d，ctx 先结束时返回 false
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// consulSource 读取 Consul KV 中的一个键，通过阻塞查询 (?index=) 等待变化
+type consulSource struct {
+	cfg     ConsulSourceConfig
+	timeout time.Duration
+	client  *http.Client
+}
+
+func (s *consulSource) Name() string {
+	return "consul://" + strings.TrimLeft(s.cfg.Key, "/")
+}
+
+func (s *consulSource) Fetch(ctx context.Context, version string) ([]byte, string, error) {
+	query := url.Values{"raw": {""}}
+	if s.cfg.Datacenter != "" {
+		query.Set("dc", s.cfg.Datacenter)
+	}
+	timeout := s.timeout
+	if version != "" {
+		query.Set("index", version)
+		query.Set("wait", watchWait.String())
+		timeout += watchWait
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	u := strings.TrimRight(s.cfg.Address, "/") + "/v1/kv/" + strings.TrimLeft(s.cfg.Key, "/") + "?" + query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	if s.cfg.Token != "" {
+		req.Header.Set("X-Consul-Token", s.cfg.Token)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("请求 Consul 失败: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("读取 Consul 响应失败: %w", err)
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, "", fmt.Errorf("Consul 中不存在键 '%s'", s.cfg.Key)
+	case resp.StatusCode != http.StatusOK:
+		return nil, "", fmt.Errorf("Consul 返回 %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	index := resp.Header.Get("X-Consul-Index")
+	if version != "" && index == version {
+		return nil, version, nil
+	}
+	return body, index, nil
+}
+
+// etcdSource 通过 etcd v3 的 HTTP/JSON 网关 (/v3/kv/range、/v3/watch) 读取一个键，
+// 版本为 etcd 的 revision。多个 endpoint 时从上次成功的开始依次尝试
+type etcdSource struct {
+	endpoints []string
+	key       string
+	timeout   time.Duration
+	client    *http.Client
+	current   atomic.Int32 // 上次成功的 endpoint 下标
+}
+
+// etcd JSON 网关的响应，64 位整数以字符串表示，bytes 字段为 base64 ([]byte 由 encoding/json 直接编解码)
+type etcdKeyValue struct {
+	Value       []byte `json:"value"`
+	ModRevision int64  `json:"mod_revision,string"`
+}
+
+type etcdHeader struct {
+	Revision int64 `json:"revision,string"`
+}
+
+type etcdRangeResponse struct {
+	Header etcdHeader     `json:"header"`
+	Kvs    []etcdKeyValue `json:"kvs"`
+}
+
+type etcdWatchResponse struct {
+	Result struct {
+		Header   etcdHeader `json:"header"`
+		Canceled bool       `json:"canceled"`
+		Reason   string     `json:"cancel_reason"`
+		Events   []struct {
+			Type string       `json:"type"` // PUT 时省略
+			Kv   etcdKeyValue `json:"kv"`
+		} `json:"events"`
+	} `json:"result"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (s *etcdSource) Name() string {
+	return "etcd://" + s.key
+}
+
+func (s *etcdSource) Fetch(ctx context.Context, version string) ([]byte, string, error) {
+	var lastErr error
+	for i := 0; i < len(s.endpoints); i++ {
+		idx := (int(s.current.Load()) + i) % len(s.endpoints)
+		endpoint := strings.TrimRight(s.endpoints[idx], "/")
+		var (
+			data []byte
+			next string
+			err  error
+		)
+		if version == "" {
+			data, next, err = s.get(ctx, endpoint)
+		} else {
+			data, next, err = s.watch(ctx, endpoint, version)
+		}
+		if err == nil {
+			s.current.Store(int32(idx))
+			return data, next, nil
+		}
+		if ctx.Err() != nil {
+			return nil, "", err
+		}
+		lastErr = fmt.Errorf("etcd %s: %w", endpoint, err)
+	}
+	return nil, "", lastErr
+}
+
+// get 读取键的当前值，版本为响应头中的集群 revision，之后的变化都从它之后开始监听
+func (s *etcdSource) get(ctx context.Context, endpoint string) ([]byte, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	resp, err := s.post(ctx, endpoint+"/v3/kv/range", map[string]interface{}{"key": []byte(s.key)})
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	var out etcdRangeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, "", fmt.Errorf("解析响应失败: %w", err)
+	}
+	if len(out.Kvs) == 0 {
+		return nil, "", fmt.Errorf("不存在键 '%s'", s.key)
+	}
+	return out.Kvs[0].Value, strconv.FormatInt(out.Header.Revision, 10), nil
+}
+
+// watch 从 version 之后的 revision 开始监听键的变化，返回第一批事件中最后的值
+func (s *etcdSource) watch(ctx context.Context, endpoint, version string) ([]byte, string, error) {
+	rev, err := strconv.ParseInt(version, 10, 64)
+	if err != nil {
+		return nil, "", fmt.Errorf("无效的 revision '%s'", version)
+	}
+	ctx, cancel := context.WithTimeout(ctx, watchWait)
+	defer cancel()
+	resp, err := s.post(ctx, endpoint+"/v3/watch", map[string]interface{}{
+		"create_request": map[string]interface{}{"key": []byte(s.key), "start_revision": strconv.FormatInt(rev+1, 10)},
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var msg etcdWatchResponse
+		if err := dec.Decode(&msg); err != nil {
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return nil, version, nil // 等待超时，配置没有变化
+			}
+			return nil, "", fmt.Errorf("监听中断: %w", err)
+		}
+		switch {
+		case msg.Error != nil:
+			return nil, "", fmt.Errorf("监听失败: %s", msg.Error.Message)
+		case msg.Result.Canceled:
+			// 起始 revision 已被压缩等情况，由调用方退避后重新读取最新值
+			return nil, "", fmt.Errorf("监听被取消: %s", msg.Result.Reason)
+		case len(msg.Result.Events) == 0:
+			continue // 创建确认或进度通知
+		}
+		ev := msg.Result.Events[len(msg.Result.Events)-1]
+		if ev.Type == "DELETE" {
+			return nil, "", fmt.Errorf("键 '%s' 已被删除", s.key)
+		}
+		return ev.Kv.Value, strconv.FormatInt(ev.Kv.ModRevision, 10), nil
+	}
+}
+
+func (s *etcdSource) post(ctx context.Context, u string, body interface{}) (*http.Response, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("返回 %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return resp, nil
+}
@@ -0,0 +1,106 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// 与 checkFields 的规则对应: 时长必须带单位 (0 除外)，大小为字节数或带单位的字符串
+const (
+	durationPattern = `^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
+	byteSizePattern = `^\s*[0-9]+(\.[0-9]+)?\s*([kKmMgG][iI]?)?[bB]?\s*$`
+)
+
+var pluginSpecType = reflect.TypeOf(PluginSpec{})
+
+// JSONSchema 按配置结构体生成 JSON Schema (draft 2020-12)，供编辑器补全 (如 yaml-language-server) 与 CI 校验使用。
+// 与加载配置时一样不允许未知字段；取值之间的约束 (引用的服务是否存在等) 仍由 Validate 检查
+func JSONSchema() ([]byte, error) {
+	g := &schemaGenerator{defs: make(map[string]interface{})}
+	root := g.schemaFor(reflect.TypeOf(GatewayConfig{}))
+	schema := map[string]interface{}{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"title":   "API Gateway configuration",
+		"$ref":    root["$ref"],
+		"$defs":   g.defs,
+	}
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+type schemaGenerator struct {
+	defs map[string]interface{} // 结构体类型名 -> schema，结构体以 $ref 引用，避免重复展开
+}
+
+func (g *schemaGenerator) schemaFor(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t {
+	case durationType:
+		return map[string]interface{}{
+			"anyOf": []interface{}{
+				map[string]interface{}{"type": "string", "pattern": durationPattern},
+				map[string]interface{}{"const": 0},
+			},
+			"description": "带单位的时长，如 500ms、30s、5m、1h",
+		}
+	case byteSizeType:
+		return map[string]interface{}{
+			"anyOf": []interface{}{
+				map[string]interface{}{"type": "integer", "minimum": 0},
+				map[string]interface{}{"type": "string", "pattern": byteSizePattern},
+			},
+			"description": "字节数或带单位的大小，如 4KB、10MB、1GB",
+		}
+	case pluginSpecType:
+		return map[string]interface{}{
+			"type":                 "object",
+			"required":             []string{"name"},
+			"properties":           map[string]interface{}{"name": map[string]interface{}{"type": "string"}},
+			"additionalProperties": true,
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": g.schemaFor(t.Elem())}
+	case reflect.Map:
+		if t.Elem().Kind() == reflect.Interface {
+			return map[string]interface{}{"type": "object"}
+		}
+		return map[string]interface{}{"type": "object", "additionalProperties": g.schemaFor(t.Elem())}
+	case reflect.Struct:
+		return g.structRef(t)
+	}
+	return map[string]interface{}{} // interface{} 等任意值
+}
+
+// structRef 把结构体加入 $defs 并返回对它的引用
+func (g *schemaGenerator) structRef(t reflect.Type) map[string]interface{} {
+	ref := map[string]interface{}{"$ref": "#/$defs/" + t.Name()}
+	if _, ok := g.defs[t.Name()]; ok {
+		return ref
+	}
+	properties := make(map[string]interface{})
+	def := map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	g.defs[t.Name()] = def // 先占位，支持自引用的结构体
+
+	for name, f := range yamlFields(t) {
+		properties[name] = g.schemaFor(f.Type)
+	}
+	return ref
+}
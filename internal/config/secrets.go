@@ -0,0 +1,542 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// 密钥引用的前缀，配置中整个字符串值以这些前缀开头时视为密钥引用:
+//
+//	vault:<路径>#<字段>              如 vault:secret/gateway#jwt，读取 KV 引擎中 secret/gateway 的 jwt 字段
+//	aws-sm:<secret id>[#字段]        SecretString 为 JSON 时可用 #字段 取其中一项
+//	gcp-sm:<secret 名称>[#字段]      如 gcp-sm:projects/p/secrets/jwt 或 .../versions/3，默认读取 latest 版本
+const (
+	vaultPrefix  = "vault:"
+	awsSMPrefix  = "aws-sm:"
+	gcpSMPrefix  = "gcp-sm:"
+	maxSecretLen = 1 << 20
+)
+
+var secretPrefixes = []string{vaultPrefix, awsSMPrefix, gcpSMPrefix}
+
+const (
+	defaultSecretsCacheTTL = 5 * time.Minute
+	defaultSecretsTimeout  = 10 * time.Second
+	defaultGCPSMEndpoint   = "https://secretmanager.googleapis.com"
+	defaultGCEMetadataHost = "metadata.google.internal"
+)
+
+// secretEntry 缓存的密钥值，cfg 为读取时使用的 secrets 配置，刷新时沿用
+type secretEntry struct {
+	value   string
+	fetched time.Time
+	cfg     SecretsConfig
+}
+
+// secretCache 进程内的密钥缓存，以引用字符串为键，多次加载配置 (SIGHUP、远程配置变化) 共用
+type secretCache struct {
+	mu      sync.Mutex
+	entries map[string]*secretEntry
+}
+
+var secretStore = &secretCache{entries: make(map[string]*secretEntry)}
+
+var secretHTTPClient = &http.Client{}
+
+// isSecretRef 判断字符串是否为密钥引用
+func isSecretRef(s string) bool {
+	for _, prefix := range secretPrefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveSecrets 把配置文本中的密钥引用替换为实际的值，一次报告全部读取失败的引用及其 YAML 路径。
+// sc 为空时使用配置文本自身的 secrets 段；secrets 段本身不做替换
+func resolveSecrets(data []byte, sc *SecretsConfig) ([]byte, error) {
+	found := false
+	for _, prefix := range secretPrefixes {
+		if bytes.Contains(data, []byte(prefix)) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return data, nil
+	}
+
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return data, nil // 语法错误交给正式解码报告
+	}
+	root, ok := raw.(map[interface{}]interface{})
+	if !ok {
+		return data, nil
+	}
+	if sc == nil {
+		sc = &SecretsConfig{}
+		if section, ok := root["secrets"]; ok {
+			out, err := yaml.Marshal(section)
+			if err != nil {
+				return nil, err
+			}
+			if err := yaml.Unmarshal(out, sc); err != nil {
+				return nil, fmt.Errorf("secrets: %w", err)
+			}
+		}
+	}
+
+	r := &secretResolver{cfg: *sc}
+	for key, value := range root {
+		if key == "secrets" {
+			continue
+		}
+		root[key] = r.walk(value, fmt.Sprint(key))
+	}
+	if len(r.problems) > 0 {
+		sort.Strings(r.problems)
+		return nil, &ValidationError{Problems: r.problems}
+	}
+	if !r.replaced {
+		return data, nil
+	}
+	return yaml.Marshal(root)
+}
+
+type secretResolver struct {
+	cfg      SecretsConfig
+	problems []string
+	replaced bool
+}
+
+func (r *secretResolver) walk(v interface{}, path string) interface{} {
+	switch val := v.(type) {
+	case string:
+		if !isSecretRef(val) {
+			return val
+		}
+		secret, err := secretStore.get(val, r.cfg)
+		if err != nil {
+			r.problems = append(r.problems, fmt.Sprintf("%s: %v", path, err))
+			return val
+		}
+		r.replaced = true
+		return secret
+	case map[interface{}]interface{}:
+		for k, item := range val {
+			val[k] = r.walk(item, joinPath(path, fmt.Sprint(k)))
+		}
+	case []interface{}:
+		for i, item := range val {
+			val[i] = r.walk(item, fmt.Sprintf("%s[%d]", path, i))
+		}
+	}
+	return v
+}
+
+// get 返回引用的值，缓存未过期时直接使用缓存；读取失败但有缓存时继续使用缓存的值，避免密钥服务短暂不可用导致重新加载失败
+func (c *secretCache) get(ref string, sc SecretsConfig) (string, error) {
+	ttl := sc.CacheTTL
+	if ttl <= 0 {
+		ttl = defaultSecretsCacheTTL
+	}
+	c.mu.Lock()
+	entry, ok := c.entries[ref]
+	c.mu.Unlock()
+	if ok && time.Since(entry.fetched) < ttl {
+		return entry.value, nil
+	}
+
+	value, err := fetchSecretWithTimeout(context.Background(), ref, sc)
+	if err != nil {
+		if ok {
+			return entry.value, nil
+		}
+		return "", err
+	}
+	c.mu.Lock()
+	c.entries[ref] = &secretEntry{value: value, fetched: time.Now(), cfg: sc}
+	c.mu.Unlock()
+	return value, nil
+}
+
+// RefreshSecrets 重新读取所有已缓存的密钥引用，changed 表示有值发生了变化，需要重新加载配置才能生效。
+// 读取失败的引用保留原有的值，错误合并后返回
+func RefreshSecrets(ctx context.Context) (changed bool, err error) {
+	secretStore.mu.Lock()
+	refs := make(map[string]SecretsConfig, len(secretStore.entries))
+	for ref, entry := range secretStore.entries {
+		refs[ref] = entry.cfg
+	}
+	secretStore.mu.Unlock()
+
+	var errs []error
+	for ref, sc := range refs {
+		value, fetchErr := fetchSecretWithTimeout(ctx, ref, sc)
+		if fetchErr != nil {
+			errs = append(errs, fetchErr)
+			continue
+		}
+		secretStore.mu.Lock()
+		if entry, ok := secretStore.entries[ref]; ok && entry.value != value {
+			changed = true
+		}
+		secretStore.entries[ref] = &secretEntry{value: value, fetched: time.Now(), cfg: sc}
+		secretStore.mu.Unlock()
+	}
+	return changed, errors.Join(errs...)
+}
+
+func fetchSecretWithTimeout(ctx context.Context, ref string, sc SecretsConfig) (string, error) {
+	timeout := sc.Timeout
+	if timeout <= 0 {
+		timeout = defaultSecretsTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	value, err := fetchSecret(ctx, ref, sc)
+	if err != nil {
+		return "", fmt.Errorf("读取密钥 '%s' 失败: %w", ref, err)
+	}
+	return value, nil
+}
+
+// fetchSecret 按引用的前缀从对应的密钥服务读取值
+func fetchSecret(ctx context.Context, ref string, sc SecretsConfig) (string, error) {
+	var (
+		prefix string
+		body   string
+	)
+	for _, p := range secretPrefixes {
+		if strings.HasPrefix(ref, p) {
+			prefix, body = p, strings.TrimPrefix(ref, p)
+			break
+		}
+	}
+	name, field, _ := strings.Cut(body, "#")
+	if name == "" {
+		return "", errors.New("引用中缺少密钥路径")
+	}
+
+	switch prefix {
+	case vaultPrefix:
+		if field == "" {
+			return "", errors.New("vault 引用需要用 #字段 指定读取的字段，如 vault:secret/gateway#jwt")
+		}
+		return fetchVault(ctx, sc.Vault, name, field)
+	case awsSMPrefix:
+		secret, err := fetchAWSSecret(ctx, sc.AWS, name)
+		if err != nil {
+			return "", err
+		}
+		return jsonField(secret, field)
+	case gcpSMPrefix:
+		secret, err := fetchGCPSecret(ctx, sc.GCP, name)
+		if err != nil {
+			return "", err
+		}
+		return jsonField(secret, field)
+	}
+	return "", fmt.Errorf("不支持的密钥引用 '%s'", ref)
+}
+
+// jsonField 从 JSON 格式的密钥中取出字段，field 为空时返回整个密钥
+func jsonField(secret, field string) (string, error) {
+	if field == "" {
+		return secret, nil
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(secret), &fields); err != nil {
+		return "", fmt.Errorf("密钥不是 JSON 对象，无法读取字段 '%s'", field)
+	}
+	return fieldValue(fields, field)
+}
+
+func fieldValue(fields map[string]interface{}, field string) (string, error) {
+	value, ok := fields[field]
+	if !ok || value == nil {
+		return "", fmt.Errorf("密钥中没有字段 '%s'", field)
+	}
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case float64, bool:
+		return fmt.Sprint(v), nil
+	default:
+		return "", fmt.Errorf("密钥字段 '%s' 不是字符串", field)
+	}
+}
+
+// fetchVault 读取 Vault KV 引擎中的字段，KV v2 的 API 路径为 <挂载点>/data/<路径>
+func fetchVault(ctx context.Context, c VaultSecretsConfig, path, field string) (string, error) {
+	addr := c.Address
+	if addr == "" {
+		addr = os.Getenv("VAULT_ADDR")
+	}
+	if addr == "" {
+		return "", errors.New("未配置 Vault 地址 (secrets.vault.address 或环境变量 VAULT_ADDR)")
+	}
+	token := c.Token
+	if c.TokenFile != "" {
+		data, err := os.ReadFile(c.TokenFile)
+		if err != nil {
+			return "", fmt.Errorf("读取 Vault 令牌文件失败: %w", err)
+		}
+		token = strings.TrimSpace(string(data))
+	}
+	if token == "" {
+		token = os.Getenv("VAULT_TOKEN")
+	}
+
+	path = strings.Trim(path, "/")
+	apiPath := path
+	if c.KVVersion != 1 {
+		mount, rest, ok := strings.Cut(path, "/")
+		if !ok || rest == "" {
+			return "", fmt.Errorf("'%s' 应为 <挂载点>/<路径>", path)
+		}
+		apiPath = mount + "/data/" + rest
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(addr, "/")+"/v1/"+apiPath, nil)
+	if err != nil {
+		return "", err
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if c.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", c.Namespace)
+	}
+	data, err := doSecretRequest(req)
+	if err != nil {
+		return "", err
+	}
+
+	var body struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return "", fmt.Errorf("解析 Vault 响应失败: %w", err)
+	}
+	fields := body.Data
+	if c.KVVersion != 1 {
+		fields, _ = body.Data["data"].(map[string]interface{})
+	}
+	return fieldValue(fields, field)
+}
+
+// fetchAWSSecret 调用 Secrets Manager 的 GetSecretValue，请求使用 SigV4 签名
+func fetchAWSSecret(ctx context.Context, c AWSSecretsConfig, secretID string) (string, error) {
+	region := c.Region
+	if region == "" {
+		region = os.Getenv("AWS_REGION")
+	}
+	if region == "" {
+		region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if region == "" {
+		return "", errors.New("未配置 AWS 区域 (secrets.aws.region 或环境变量 AWS_REGION)")
+	}
+	accessKey, secretKey := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY")
+	if accessKey == "" || secretKey == "" {
+		return "", errors.New("未设置 AWS 凭证 (环境变量 AWS_ACCESS_KEY_ID 与 AWS_SECRET_ACCESS_KEY)")
+	}
+	endpoint := c.Endpoint
+	if endpoint == "" {
+		endpoint = "https://secretsmanager." + region + ".amazonaws.com"
+	}
+
+	payload, err := json.Marshal(map[string]string{"SecretId": secretID})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(endpoint, "/")+"/", bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	if token := os.Getenv("AWS_SESSION_TOKEN"); token != "" {
+		req.Header.Set("X-Amz-Security-Token", token)
+	}
+	signAWSv4(req, payload, accessKey, secretKey, region, "secretsmanager", time.Now().UTC())
+
+	data, err := doSecretRequest(req)
+	if err != nil {
+		return "", err
+	}
+	var body struct {
+		SecretString *string `json:"SecretString"`
+	}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return "", fmt.Errorf("解析 Secrets Manager 响应失败: %w", err)
+	}
+	if body.SecretString == nil {
+		return "", errors.New("只支持 SecretString 类型的密钥")
+	}
+	return *body.SecretString, nil
+}
+
+// signAWSv4 按 AWS Signature Version 4 为请求签名，签名的请求头为 content-type、host 与全部 x-amz-*
+func signAWSv4(req *http.Request, payload []byte, accessKey, secretKey, region, service string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if lower == "content-type" || strings.HasPrefix(lower, "x-amz-") {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	payloadHash := sha256.Sum256(payload)
+	canonicalRequest := strings.Join([]string{
+		req.Method, path, req.URL.RawQuery, canonicalHeaders.String(), signedHeaders, hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// fetchGCPSecret 读取 Secret Manager 中的密钥版本，name 未指定版本时读取 latest
+func fetchGCPSecret(ctx context.Context, c GCPSecretsConfig, name string) (string, error) {
+	name = strings.Trim(name, "/")
+	if !strings.HasPrefix(name, "projects/") || !strings.Contains(name, "/secrets/") {
+		return "", fmt.Errorf("'%s' 应为 projects/<项目>/secrets/<名称>[/versions/<版本>]", name)
+	}
+	if !strings.Contains(name, "/versions/") {
+		name += "/versions/latest"
+	}
+	token, err := gcpAccessToken(ctx)
+	if err != nil {
+		return "", err
+	}
+	endpoint := c.Endpoint
+	if endpoint == "" {
+		endpoint = defaultGCPSMEndpoint
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(endpoint, "/")+"/v1/"+name+":access", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	data, err := doSecretRequest(req)
+	if err != nil {
+		return "", err
+	}
+	var body struct {
+		Payload struct {
+			Data string `json:"data"`
+		} `json:"payload"`
+	}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return "", fmt.Errorf("解析 Secret Manager 响应失败: %w", err)
+	}
+	secret, err := base64.StdEncoding.DecodeString(body.Payload.Data)
+	if err != nil {
+		return "", fmt.Errorf("解析 Secret Manager 响应失败: %w", err)
+	}
+	return string(secret), nil
+}
+
+// gcpAccessToken 优先使用环境变量 GOOGLE_OAUTH_ACCESS_TOKEN，否则从元数据服务获取实例服务账号的令牌
+func gcpAccessToken(ctx context.Context) (string, error) {
+	if token := os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"); token != "" {
+		return token, nil
+	}
+	host := os.Getenv("GCE_METADATA_HOST")
+	if host == "" {
+		host = defaultGCEMetadataHost
+	}
+	u := url.URL{Scheme: "http", Host: host, Path: "/computeMetadata/v1/instance/service-accounts/default/token"}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	data, err := doSecretRequest(req)
+	if err != nil {
+		return "", fmt.Errorf("从元数据服务获取 GCP 访问令牌失败 (可设置环境变量 GOOGLE_OAUTH_ACCESS_TOKEN): %w", err)
+	}
+	var body struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.Unmarshal(data, &body); err != nil || body.AccessToken == "" {
+		return "", errors.New("元数据服务返回的 GCP 访问令牌无效")
+	}
+	return body.AccessToken, nil
+}
+
+// doSecretRequest 发送请求并返回响应体，非 2xx 响应时错误中带上响应体的开头部分
+func doSecretRequest(req *http.Request) ([]byte, error) {
+	resp, err := secretHTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSecretLen))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg := strings.TrimSpace(string(data))
+		if len(msg) > 200 {
+			msg = msg[:200] + "..."
+		}
+		return nil, fmt.Errorf("%s 返回 %d: %s", req.URL.Host, resp.StatusCode, msg)
+	}
+	return data, nil
+}
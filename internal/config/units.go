@@ -0,0 +1,257 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ByteSize 以字节为单位的大小，配置中可以写整数 (字节) 或带单位的字符串，如 "64KB"、"10MB"、"1GB"。
+// KB/MB/GB 与 KiB/MiB/GiB 同样按 1024 进位
+type ByteSize int64
+
+// 大小单位，按从大到小排列，String 优先使用最大的整除单位
+var byteUnits = []struct {
+	suffix string
+	size   int64
+}{
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"B", 1},
+}
+
+// ParseByteSize 解析带单位的大小，单位不区分大小写，数值可以带小数 (如 1.5MB)
+func ParseByteSize(s string) (ByteSize, error) {
+	text := strings.ToUpper(strings.TrimSpace(s))
+	text = strings.Replace(text, "IB", "B", 1) // KiB -> KB
+	num, mult := text, int64(1)
+	for _, unit := range byteUnits {
+		if strings.HasSuffix(text, unit.suffix) {
+			num, mult = strings.TrimSpace(strings.TrimSuffix(text, unit.suffix)), unit.size
+			break
+		}
+	}
+	if num == "" {
+		return 0, fmt.Errorf("'%s' 不是有效的大小，应为字节数或带单位的值，如 512KB、10MB", s)
+	}
+	if n, err := strconv.ParseInt(num, 10, 64); err == nil {
+		if n < 0 {
+			return 0, fmt.Errorf("大小不能为负数: '%s'", s)
+		}
+		if n > math.MaxInt64/mult {
+			return 0, fmt.Errorf("大小 '%s' 超出范围", s)
+		}
+		return ByteSize(n * mult), nil
+	}
+	f, err := strconv.ParseFloat(num, 64)
+	if err != nil || math.IsNaN(f) || f < 0 || f*float64(mult) >= math.MaxInt64 {
+		return 0, fmt.Errorf("'%s' 不是有效的大小，应为字节数或带单位的值，如 512KB、10MB", s)
+	}
+	return ByteSize(f * float64(mult)), nil
+}
+
+// ParseByteSizeValue 解析插件参数等通用值中的大小: 非负整数 (字节) 或带单位的字符串
+func ParseByteSizeValue(v interface{}) (ByteSize, error) {
+	switch val := v.(type) {
+	case int:
+		if val < 0 {
+			return 0, fmt.Errorf("大小不能为负数: %d", val)
+		}
+		return ByteSize(val), nil
+	case string:
+		return ParseByteSize(val)
+	default:
+		return 0, fmt.Errorf("'%v' 不是有效的大小，应为字节数或带单位的值，如 512KB、10MB", v)
+	}
+}
+
+// UnmarshalYAML 接受整数或带单位的字符串
+func (b *ByteSize) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var raw interface{}
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+	size, err := ParseByteSizeValue(raw)
+	if err != nil {
+		return err
+	}
+	*b = size
+	return nil
+}
+
+// MarshalYAML 输出为带单位的字符串，如 "10MB"
+func (b ByteSize) MarshalYAML() (interface{}, error) {
+	return b.String(), nil
+}
+
+// String 使用能整除的最大单位表示大小，如 1048576 -> "1MB"、1500 -> "1500B"
+func (b ByteSize) String() string {
+	for _, unit := range byteUnits {
+		if b != 0 && int64(b)%unit.size == 0 {
+			return strconv.FormatInt(int64(b)/unit.size, 10) + unit.suffix
+		}
+	}
+	return "0B"
+}
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	byteSizeType = reflect.TypeOf(ByteSize(0))
+)
+
+// checkFields 在解码到 target 之前检查配置文本，一次报告全部问题并给出 YAML 路径:
+//   - 结构体中不存在的字段 (如把 load_balancer 拼写成 load_balancar)，给出最接近的字段名
+//   - 时长与大小字段的取值。time.Duration 字段写成整数时 YAML 库会按纳秒解析 (timeout: 5 实际是 5ns)，这里要求时长必须带单位
+func checkFields(data []byte, target interface{}) error {
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil // 语法错误交给正式解码报告
+	}
+	var problems []string
+	walkFields(raw, reflect.TypeOf(target), "", &problems)
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// walkFields 沿着配置结构体的类型遍历解析出的 YAML 值
+func walkFields(v interface{}, t reflect.Type, path string, problems *[]string) {
+	if v == nil {
+		return
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch {
+	case t == durationType:
+		switch val := v.(type) {
+		case string:
+			if _, err := time.ParseDuration(val); err != nil {
+				*problems = append(*problems, fmt.Sprintf("%s: '%s' 不是有效的时长，应为带单位的值，如 500ms、30s、5m、1h", path, val))
+			}
+		case int, int64, uint64, float64:
+			if val != 0 {
+				*problems = append(*problems, fmt.Sprintf("%s: 时长需要带单位，如 30s、5m，当前为 %v", path, val))
+			}
+		}
+		return
+	case t == byteSizeType:
+		if _, err := ParseByteSizeValue(v); err != nil {
+			*problems = append(*problems, fmt.Sprintf("%s: %v", path, err))
+		}
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		m, ok := v.(map[interface{}]interface{})
+		if !ok {
+			return
+		}
+		fields := yamlFields(t)
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, fmt.Sprint(k))
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			f, ok := fields[k]
+			if !ok {
+				msg := "未知字段"
+				if hint := closestField(k, fields); hint != "" {
+					msg += fmt.Sprintf("，是否应为 %s?", hint)
+				}
+				*problems = append(*problems, fmt.Sprintf("%s: %s", joinPath(path, k), msg))
+				continue
+			}
+			walkFields(m[k], f.Type, joinPath(path, k), problems)
+		}
+	case reflect.Slice:
+		list, ok := v.([]interface{})
+		if !ok {
+			return
+		}
+		for i, item := range list {
+			walkFields(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i), problems)
+		}
+	case reflect.Map:
+		m, ok := v.(map[interface{}]interface{})
+		if !ok || t.Elem().Kind() == reflect.Interface {
+			return // PluginSpec 等自由格式的参数由使用方解析
+		}
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, fmt.Sprint(k))
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			walkFields(m[k], t.Elem(), joinPath(path, k), problems)
+		}
+	}
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// yamlFields 返回结构体在 YAML 中的字段名到字段的映射，规则与 yaml 库一致: 没有标签时使用小写的字段名
+func yamlFields(t reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		name := strings.Split(f.Tag.Get("yaml"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		fields[name] = f
+	}
+	return fields
+}
+
+// closestField 返回编辑距离不超过 2 的最接近的字段名，用于提示拼写错误
+func closestField(name string, fields map[string]reflect.StructField) string {
+	best, bestDist := "", 3
+	for candidate := range fields {
+		if d := editDistance(name, candidate); d < bestDist || (d == bestDist && candidate < best) {
+			best, bestDist = candidate, d
+		}
+	}
+	return best
+}
+
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,317 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValidationError 汇总配置校验发现的全部问题，每个问题以出错字段的 YAML 路径开头
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("配置校验发现 %d 个问题:\n  - %s", len(e.Problems), strings.Join(e.Problems, "\n  - "))
+}
+
+// ValidateOptions 提供配置包本身无法得知的信息，字段为 nil 时跳过对应的检查
+type ValidateOptions struct {
+	// PluginExists 判断插件名是否已注册 (内置插件与 external_plugins)
+	PluginExists func(name string) bool
+	// RateLimitRules 返回插件配置引用的限流规则，这些规则必须在 rate_limiting.rules 中定义
+	RateLimitRules func(spec PluginSpec) []string
+}
+
+// 路由 service_name 的特殊值，表示健康检查路由
+const allServices = "all-services"
+
+// 取值范围固定的配置项
+var (
+	validLoadBalancers   = []string{"", "round_robin", "weighted_round_robin", "least_connections"}
+	validProtocols       = []string{"", "http", "h2c", "grpc"}
+	validClientAuthModes = []string{"", "none", "optional", "require"}
+	validRateLimitTypes  = []string{"", "noop", "memory_token_bucket", "redis_token_bucket", "sliding_window", "concurrency", "leaky_bucket", "cluster_window"}
+	validCacheBackends   = []string{"", "memory", "redis"}
+	validConfigSources   = []string{"", "file", "etcd", "consul"}
+)
+
+// Validate 检查配置中的引用能否解析、取值是否合法，一次报告全部问题而不是遇到第一个就停止。
+// 插件自身的参数仍由各插件的 ValidateConfig 在注册后校验
+func (c *GatewayConfig) Validate(opts ValidateOptions) error {
+	v := &validator{cfg: c, opts: opts}
+	v.server()
+	v.nonNegative("health_check.timeout", c.HealthCheck.Timeout)
+	if c.HealthCheck.Interval <= 0 {
+		v.addf("health_check.interval", "必须为正数，当前为 %s", c.HealthCheck.Interval)
+	}
+	v.transport()
+	v.oneOf("cache.backend", c.Cache.Backend, validCacheBackends)
+	v.configSource()
+	v.secrets()
+	v.jwt()
+
+	names := make([]string, 0, len(c.Services))
+	for name := range c.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		v.service("services."+name, c.Services[name])
+	}
+
+	v.rateLimiting()
+	v.externalPlugins()
+	v.pluginChain("plugins.pre_routing", c.Plugins.PreRouting)
+	v.pluginChain("plugins.post_routing", c.Plugins.PostRouting)
+	for i, route := range c.Routes {
+		v.route(fmt.Sprintf("routes[%d]", i), route)
+	}
+
+	if len(v.problems) > 0 {
+		return &ValidationError{Problems: v.problems}
+	}
+	return nil
+}
+
+// validator 在遍历配置的过程中收集问题
+type validator struct {
+	cfg      *GatewayConfig
+	opts     ValidateOptions
+	problems []string
+}
+
+func (v *validator) addf(path, format string, args ...interface{}) {
+	v.problems = append(v.problems, path+": "+fmt.Sprintf(format, args...))
+}
+
+func (v *validator) nonNegative(path string, d time.Duration) {
+	if d < 0 {
+		v.addf(path, "不能为负数，当前为 %s", d)
+	}
+}
+
+func (v *validator) oneOf(path, value string, allowed []string) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	v.addf(path, "不支持的取值 '%s'，可选: %s", value, strings.Join(allowed[1:], ", "))
+}
+
+func (v *validator) server() {
+	s := v.cfg.Server
+	if _, port, err := net.SplitHostPort(s.Port); err != nil {
+		v.addf("server.port", "应为 host:port 或 :port 格式，当前为 '%s'", s.Port)
+	} else if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		v.addf("server.port", "端口应在 1-65535 之间，当前为 '%s'", port)
+	}
+	if (s.TLSCertFile == "") != (s.TLSKeyFile == "") {
+		v.addf("server", "tls_cert_file 与 tls_key_file 必须同时配置")
+	}
+	v.oneOf("server.client_auth", s.ClientAuth, validClientAuthModes)
+}
+
+func (v *validator) transport() {
+	t := v.cfg.Transport
+	v.nonNegative("transport.idle_conn_timeout", t.IdleConnTimeout)
+	v.nonNegative("transport.dial_timeout", t.DialTimeout)
+	v.nonNegative("transport.keep_alive", t.KeepAlive)
+	v.nonNegative("transport.tls_handshake_timeout", t.TLSHandshakeTimeout)
+}
+
+func (v *validator) configSource() {
+	s := v.cfg.ConfigSource
+	v.oneOf("config.source", s.Source, validConfigSources)
+	switch s.Source {
+	case "etcd":
+		if len(s.Etcd.Endpoints) == 0 {
+			v.addf("config.etcd.endpoints", "至少需要一个 endpoint")
+		}
+		for i, ep := range s.Etcd.Endpoints {
+			if !isHTTPURL(ep) {
+				v.addf(fmt.Sprintf("config.etcd.endpoints[%d]", i), "应为 http:// 或 https:// 开头的绝对地址，当前为 '%s'", ep)
+			}
+		}
+		if s.Etcd.Key == "" {
+			v.addf("config.etcd.key", "不能为空")
+		}
+	case "consul":
+		if !isHTTPURL(s.Consul.Address) {
+			v.addf("config.consul.address", "应为 http:// 或 https:// 开头的绝对地址，当前为 '%s'", s.Consul.Address)
+		}
+		if s.Consul.Key == "" {
+			v.addf("config.consul.key", "不能为空")
+		}
+	}
+	v.nonNegative("config.timeout", s.Timeout)
+	v.nonNegative("config.backoff.initial", s.Backoff.Initial)
+	v.nonNegative("config.backoff.max", s.Backoff.Max)
+}
+
+func (v *validator) secrets() {
+	s := v.cfg.Secrets
+	if s.Vault.Address != "" && !isHTTPURL(s.Vault.Address) {
+		v.addf("secrets.vault.address", "应为 http:// 或 https:// 开头的绝对地址，当前为 '%s'", s.Vault.Address)
+	}
+	if s.Vault.KVVersion != 0 && s.Vault.KVVersion != 1 && s.Vault.KVVersion != 2 {
+		v.addf("secrets.vault.kv_version", "应为 1 或 2，当前为 %d", s.Vault.KVVersion)
+	}
+	if s.AWS.Endpoint != "" && !isHTTPURL(s.AWS.Endpoint) {
+		v.addf("secrets.aws.endpoint", "应为 http:// 或 https:// 开头的绝对地址，当前为 '%s'", s.AWS.Endpoint)
+	}
+	if s.GCP.Endpoint != "" && !isHTTPURL(s.GCP.Endpoint) {
+		v.addf("secrets.gcp.endpoint", "应为 http:// 或 https:// 开头的绝对地址，当前为 '%s'", s.GCP.Endpoint)
+	}
+	v.nonNegative("secrets.cache_ttl", s.CacheTTL)
+	v.nonNegative("secrets.refresh_interval", s.RefreshInterval)
+	v.nonNegative("secrets.timeout", s.Timeout)
+}
+
+func (v *validator) jwt() {
+	j := v.cfg.JWT
+	if j.Duration != 0 && j.DurationMinutes != 0 {
+		v.addf("jwt.duration", "与已废弃的 duration_minutes 只能配置其一")
+	}
+	if j.RefreshDuration != 0 && j.RefreshDurationMinutes != 0 {
+		v.addf("jwt.refresh_duration", "与已废弃的 refresh_duration_minutes 只能配置其一")
+	}
+	v.nonNegative("jwt.duration", j.AccessTokenTTL())
+	v.nonNegative("jwt.refresh_duration", j.RefreshTokenTTL())
+}
+
+func isHTTPURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func (v *validator) service(path string, s ServiceConfig) {
+	if len(s.Instances) == 0 {
+		v.addf(path+".instances", "至少需要一个实例")
+	}
+	for i, inst := range s.Instances {
+		instPath := fmt.Sprintf("%s.instances[%d]", path, i)
+		if !isHTTPURL(inst.URL) {
+			v.addf(instPath+".url", "应为 http:// 或 https:// 开头的绝对地址，当前为 '%s'", inst.URL)
+		}
+		if inst.Weight < 0 {
+			v.addf(instPath+".weight", "不能为负数")
+		}
+	}
+	if s.HealthCheckPath != "" && !strings.HasPrefix(s.HealthCheckPath, "/") {
+		v.addf(path+".health_check_path", "应以 / 开头")
+	}
+	v.oneOf(path+".load_balancer", s.LoadBalancer, validLoadBalancers)
+	v.oneOf(path+".protocol", s.Protocol, validProtocols)
+	v.nonNegative(path+".timeouts.dial", s.Timeouts.Dial)
+	v.nonNegative(path+".timeouts.response_header", s.Timeouts.ResponseHeader)
+	v.nonNegative(path+".timeouts.request", s.Timeouts.Request)
+}
+
+func (v *validator) route(path string, r *RouteConfig) {
+	if r == nil {
+		v.addf(path, "路由不能为空")
+		return
+	}
+	if r.PathPrefix == "" {
+		v.addf(path+".path_prefix", "不能为空")
+	} else if !strings.HasPrefix(r.PathPrefix, "/") {
+		v.addf(path+".path_prefix", "应以 / 开头，当前为 '%s'", r.PathPrefix)
+	}
+	if r.ServiceName == "" {
+		v.addf(path+".service_name", "不能为空")
+	} else if _, ok := v.cfg.Services[r.ServiceName]; !ok && r.ServiceName != allServices {
+		v.addf(path+".service_name", "服务 '%s' 未在 services 中定义", r.ServiceName)
+	}
+	v.nonNegative(path+".timeout", r.Timeout)
+	if r.Cache != nil {
+		v.nonNegative(path+".cache.ttl", r.Cache.TTL)
+	}
+	v.pluginChain(path+".plugins", r.Plugins)
+}
+
+func (v *validator) pluginChain(path string, specs []PluginSpec) {
+	for i, spec := range specs {
+		specPath := fmt.Sprintf("%s[%d]", path, i)
+		name, ok := spec["name"].(string)
+		if !ok || name == "" {
+			v.addf(specPath+".name", "缺失或类型不正确")
+			continue
+		}
+		if v.opts.PluginExists != nil && !v.opts.PluginExists(name) {
+			v.addf(specPath+".name", "插件 '%s' 未注册", name)
+		}
+		if p, ok := spec["priority"]; ok {
+			if _, isInt := p.(int); !isInt {
+				v.addf(specPath+".priority", "必须是整数")
+			}
+		}
+		if v.opts.RateLimitRules != nil {
+			for _, rule := range v.opts.RateLimitRules(spec) {
+				if !v.hasRateLimitRule(rule) {
+					v.addf(specPath+".rule", "限流规则 '%s' 未在 rate_limiting.rules 中定义", rule)
+				}
+			}
+		}
+	}
+}
+
+func (v *validator) hasRateLimitRule(name string) bool {
+	for _, rule := range v.cfg.RateLimiting.Rules {
+		if rule.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *validator) rateLimiting() {
+	seen := make(map[string]bool)
+	for i, rule := range v.cfg.RateLimiting.Rules {
+		path := fmt.Sprintf("rate_limiting.rules[%d]", i)
+		switch {
+		case rule.Name == "":
+			v.addf(path+".name", "不能为空")
+		case seen[rule.Name]:
+			v.addf(path+".name", "规则名 '%s' 重复", rule.Name)
+		}
+		seen[rule.Name] = true
+		v.oneOf(path+".type", rule.Type, validRateLimitTypes)
+		v.nonNegative(path+".slidingWindow.window", rule.SlidingWindow.Window)
+		v.nonNegative(path+".clusterWindow.window", rule.ClusterWindow.Window)
+		v.nonNegative(path+".concurrency.queueTimeout", rule.Concurrency.QueueTimeout)
+		v.nonNegative(path+".leakyBucket.maxDelay", rule.LeakyBucket.MaxDelay)
+		if a := rule.Adaptive; a != nil {
+			if _, ok := v.cfg.Services[a.Service]; !ok {
+				v.addf(path+".adaptive.service", "服务 '%s' 未在 services 中定义", a.Service)
+			}
+		}
+	}
+	v.nonNegative("rate_limiting.cluster.sync_interval", v.cfg.RateLimiting.Cluster.SyncInterval)
+	if c := v.cfg.RateLimiting.Cluster; len(c.Peers) > 0 && c.Token == "" {
+		v.addf("rate_limiting.cluster.token", "配置 peers 时必须设置，否则其他副本会拒绝同步请求，集群限流退化为按副本计数")
+	}
+}
+
+func (v *validator) externalPlugins() {
+	seen := make(map[string]bool)
+	for i, ext := range v.cfg.ExternalPlugins {
+		path := fmt.Sprintf("external_plugins[%d]", i)
+		switch {
+		case ext.Name == "":
+			v.addf(path+".name", "不能为空")
+		case seen[ext.Name]:
+			v.addf(path+".name", "插件名 '%s' 重复", ext.Name)
+		}
+		seen[ext.Name] = true
+		if !isHTTPURL(ext.URL) {
+			v.addf(path+".url", "应为 http:// 或 https:// 开头的绝对地址，当前为 '%s'", ext.URL)
+		}
+		v.nonNegative(path+".timeout", ext.Timeout)
+	}
+}
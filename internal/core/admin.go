@@ -0,0 +1,138 @@
+package core
+
+import (
+	"crypto/subtle"
+	"net"
+	"net/http"
+	"strings"
+
+	"gateway.example/go-gateway/internal/config"
+	h_cache "gateway.example/go-gateway/internal/handler/cache"
+	h_circuitbreaker "gateway.example/go-gateway/internal/handler/circuitbreaker"
+	h_config "gateway.example/go-gateway/internal/handler/config"
+	h_faultinject "gateway.example/go-gateway/internal/handler/faultinject"
+	h_quota "gateway.example/go-gateway/internal/handler/quota"
+	h_ratelimit "gateway.example/go-gateway/internal/handler/ratelimit"
+	"gateway.example/go-gateway/internal/response"
+	svc_ratelimit "gateway.example/go-gateway/internal/service/ratelimit"
+	"gateway.example/go-gateway/pkg/metrics"
+)
+
+// defaultAdminPathPrefix 未配置 path_prefix 时管理接口的路径前缀
+const defaultAdminPathPrefix = "/admin"
+
+// adminPathPrefix 返回管理接口的路径前缀，未启用时返回空串
+func adminPathPrefix(cfg config.AdminConfig) string {
+	if !cfg.Enabled {
+		return ""
+	}
+	if cfg.PathPrefix == "" {
+		return defaultAdminPathPrefix
+	}
+	return strings.TrimSuffix(cfg.PathPrefix, "/")
+}
+
+// newAdminHandler 构建管理接口的路由
+func (g *Gateway) newAdminHandler() http.Handler {
+	prefix := adminPathPrefix(g.config.Admin)
+	mux := http.NewServeMux()
+
+	cacheHandler := h_cache.NewCacheHandler(g.proxy, g.logger)
+	mux.HandleFunc("POST "+prefix+"/cache/purge", cacheHandler.Purge)
+	mux.Handle("GET "+prefix+"/metrics", metrics.Handler())
+
+	faultHandler := h_faultinject.NewFaultHandler(g.faults, g.logger)
+	mux.HandleFunc("GET "+prefix+"/faults", faultHandler.Get)
+	mux.HandleFunc("POST "+prefix+"/faults", faultHandler.Toggle)
+	mux.HandleFunc("DELETE "+prefix+"/faults", faultHandler.Reset)
+
+	rateLimitHandler := h_ratelimit.NewRateLimitHandler(g.rateLimitSvc, g.logger)
+	mux.HandleFunc("GET "+prefix+"/ratelimit/rules", rateLimitHandler.ListRules)
+	mux.HandleFunc("PUT "+prefix+"/ratelimit/rules/{name}/override", rateLimitHandler.SetOverride)
+	mux.HandleFunc("DELETE "+prefix+"/ratelimit/rules/{name}/override", rateLimitHandler.ClearOverride)
+	mux.HandleFunc("GET "+prefix+"/ratelimit/rules/{name}/top", rateLimitHandler.Top)
+	mux.HandleFunc("GET "+prefix+"/ratelimit/top", rateLimitHandler.Top)
+
+	circuitBreakerHandler := h_circuitbreaker.NewCircuitBreakerHandler(g.config, g.circuitBreakerSvc, g.logger)
+	mux.HandleFunc("GET "+prefix+"/circuitbreakers", circuitBreakerHandler.Status)
+	mux.HandleFunc("POST "+prefix+"/circuitbreakers/{service}/reset", circuitBreakerHandler.Reset)
+	mux.HandleFunc("POST "+prefix+"/circuitbreakers/{service}/open", circuitBreakerHandler.ForceOpen)
+	mux.HandleFunc("POST "+prefix+"/circuitbreakers/{service}/close", circuitBreakerHandler.ForceClose)
+
+	quotaHandler := h_quota.NewQuotaHandler(g.quotaSvc, g.logger)
+	mux.HandleFunc("GET "+prefix+"/quotas", quotaHandler.Get)
+	mux.HandleFunc("DELETE "+prefix+"/quotas", quotaHandler.Reset)
+
+	configHandler := h_config.NewConfigHandler(g, g.logger)
+	mux.HandleFunc("GET "+prefix+"/config", configHandler.Get)
+
+	// 副本之间推送限流计数只需要专用的 cluster.token，不必持有完整的管理员令牌
+	root := http.NewServeMux()
+	root.Handle("POST "+prefix+svc_ratelimit.ClusterSyncPath, g.requireClusterToken(http.HandlerFunc(rateLimitHandler.Sync)))
+	root.Handle("/", g.requireAdmin(mux))
+	return root
+}
+
+// RedactedConfig 返回当前生效的配置并隐藏敏感值: routes、services、插件链与限流规则取自最近一次加载的配置，
+// 其余部分在启动时生效，取自启动配置
+func (g *Gateway) RedactedConfig() (uint64, map[string]interface{}, error) {
+	rs := g.proxy.currentRouting()
+	effective := *g.config
+	effective.Services = rs.config.Services
+	effective.Routes = rs.config.Routes
+	effective.Plugins = rs.config.Plugins
+	effective.RateLimiting = rs.config.RateLimiting
+	cfg, err := effective.Redacted()
+	return rs.generation, cfg, err
+}
+
+// requireAdmin 校验管理接口的访问权限
+// 配置了 token 时要求 Authorization: Bearer <token>，否则只允许本机访问
+func (g *Gateway) requireAdmin(next http.Handler) http.Handler {
+	token := g.config.Admin.Token
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token != "" {
+			provided, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+				g.logger.Warn(r.Context(), "管理接口鉴权失败", "path", r.URL.Path, "remote_addr", r.RemoteAddr)
+				response.WriteError(w, http.StatusUnauthorized, "管理接口需要有效的访问令牌")
+				return
+			}
+		} else if !isLoopback(r.RemoteAddr) {
+			g.logger.Warn(r.Context(), "拒绝非本机访问管理接口", "path", r.URL.Path, "remote_addr", r.RemoteAddr)
+			response.WriteError(w, http.StatusForbidden, "管理接口仅允许本机访问")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requireClusterToken 校验其他副本推送限流计数的请求: 要求 Authorization: Bearer <rate_limiting.cluster.token>，
+// 未配置 token 时只允许本机访问
+func (g *Gateway) requireClusterToken(next http.Handler) http.Handler {
+	token := g.config.RateLimiting.Cluster.Token
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token != "" {
+			provided, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+				g.logger.Warn(r.Context(), "限流集群同步鉴权失败", "path", r.URL.Path, "remote_addr", r.RemoteAddr)
+				response.WriteError(w, http.StatusUnauthorized, "集群同步需要有效的 cluster.token")
+				return
+			}
+		} else if !isLoopback(r.RemoteAddr) {
+			g.logger.Warn(r.Context(), "拒绝非本机的限流集群同步", "path", r.URL.Path, "remote_addr", r.RemoteAddr)
+			response.WriteError(w, http.StatusForbidden, "未配置 cluster.token 时集群同步仅允许本机访问")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func isLoopback(remoteAddr string) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,166 @@
+package core
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"gateway.example/go-gateway/internal/config"
+	"github.com/andybalholm/brotli"
+)
+
+// 压缩相关默认值
+const (
+	defaultCompressionMinSize = 1024
+)
+
+// defaultCompressibleTypes 未配置 content_types 时默认压缩的内容类型前缀
+var defaultCompressibleTypes = []string{
+	"text/",
+	"application/json",
+	"application/javascript",
+	"application/xml",
+	"image/svg+xml",
+}
+
+// compressResponse 根据路由的压缩配置和客户端 Accept-Encoding 对上游响应进行压缩
+// 压缩以流式方式进行，不会把整个响应体读入内存
+func compressResponse(resp *http.Response, cfg *config.CompressionConfig) {
+	if cfg == nil || !cfg.Enabled || !shouldCompress(resp, cfg) {
+		return
+	}
+
+	algorithms := cfg.Algorithms
+	if len(algorithms) == 0 {
+		algorithms = []string{"br", "gzip"}
+	}
+	encoding := negotiateEncoding(resp.Request.Header.Get("Accept-Encoding"), algorithms)
+	if encoding == "" {
+		return
+	}
+
+	body := resp.Body
+	pr, pw := io.Pipe()
+	go func() {
+		var cw io.WriteCloser
+		switch encoding {
+		case "br":
+			cw = brotli.NewWriterLevel(pw, brotliLevel(cfg.Level))
+		default:
+			gw, err := gzip.NewWriterLevel(pw, gzipLevel(cfg.Level))
+			if err != nil {
+				gw = gzip.NewWriter(pw)
+			}
+			cw = gw
+		}
+		_, err := io.Copy(cw, body)
+		if closeErr := cw.Close(); err == nil {
+			err = closeErr
+		}
+		body.Close()
+		pw.CloseWithError(err)
+	}()
+
+	resp.Body = pr
+	resp.ContentLength = -1
+	resp.Header.Del("Content-Length")
+	resp.Header.Set("Content-Encoding", encoding)
+	resp.Header.Add("Vary", "Accept-Encoding")
+	// 压缩后的实体与原实体字节不同，强 ETag 需降级为弱 ETag
+	if etag := resp.Header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		resp.Header.Set("ETag", "W/"+etag)
+	}
+}
+
+// shouldCompress 判断响应是否适合压缩
+func shouldCompress(resp *http.Response, cfg *config.CompressionConfig) bool {
+	if resp.Request.Method == http.MethodHead {
+		return false
+	}
+	switch resp.StatusCode {
+	case http.StatusNoContent, http.StatusNotModified, http.StatusPartialContent:
+		return false
+	}
+	if resp.Header.Get("Content-Encoding") != "" {
+		return false // 上游已经压缩
+	}
+
+	minSize := int64(cfg.MinSize)
+	if minSize <= 0 {
+		minSize = defaultCompressionMinSize
+	}
+	if resp.ContentLength >= 0 && resp.ContentLength < minSize {
+		return false
+	}
+
+	contentType := strings.ToLower(resp.Header.Get("Content-Type"))
+	// 流式响应压缩会引入缓冲延迟，交给上游处理
+	if strings.HasPrefix(contentType, "text/event-stream") || strings.HasPrefix(contentType, "application/grpc") {
+		return false
+	}
+	types := cfg.ContentTypes
+	if len(types) == 0 {
+		types = defaultCompressibleTypes
+	}
+	for _, t := range types {
+		if strings.HasPrefix(contentType, strings.ToLower(t)) {
+			return true
+		}
+	}
+	return false
+}
+
+// negotiateEncoding 按服务端优先级从 Accept-Encoding 中选择编码，q=0 表示明确拒绝
+func negotiateEncoding(acceptEncoding string, algorithms []string) string {
+	if acceptEncoding == "" {
+		return ""
+	}
+	accepted := make(map[string]float64)
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		name := strings.ToLower(strings.TrimSpace(fields[0]))
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		accepted[name] = q
+	}
+
+	for _, alg := range algorithms {
+		alg = strings.ToLower(alg)
+		if alg != "br" && alg != "gzip" {
+			continue
+		}
+		q, ok := accepted[alg]
+		if !ok {
+			q, ok = accepted["*"]
+		}
+		if ok && q > 0 {
+			return alg
+		}
+	}
+	return ""
+}
+
+// gzipLevel 将配置的压缩级别映射到 gzip 级别，0 表示默认
+func gzipLevel(level int) int {
+	if level <= 0 || level > gzip.BestCompression {
+		return gzip.DefaultCompression
+	}
+	return level
+}
+
+// brotliLevel 将配置的压缩级别映射到 brotli 级别，0 表示默认
+func brotliLevel(level int) int {
+	if level <= 0 || level > brotli.BestCompression {
+		return brotli.DefaultCompression
+	}
+	return level
+}
@@ -0,0 +1,127 @@
+package core
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// trustedProxies 保存可信代理的网段列表
+// 只有直连对端属于可信代理时，才信任其传入的 X-Forwarded-* / Forwarded 头
+type trustedProxies []*net.IPNet
+
+// parseTrustedProxies 解析 CIDR 列表，单个 IP 视为 /32 或 /128
+func parseTrustedProxies(cidrs []string) (trustedProxies, error) {
+	nets := make(trustedProxies, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		if !strings.Contains(cidr, "/") {
+			ip := net.ParseIP(cidr)
+			if ip == nil {
+				return nil, fmt.Errorf("无效的可信代理地址 '%s'", cidr)
+			}
+			bits := 32
+			if ip.To4() == nil {
+				bits = 128
+			}
+			cidr = fmt.Sprintf("%s/%d", cidr, bits)
+		}
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("无效的可信代理网段 '%s': %w", cidr, err)
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets, nil
+}
+
+// contains 判断 IP 是否属于可信代理
+func (t trustedProxies) contains(ipStr string) bool {
+	ip := net.ParseIP(strings.TrimSpace(ipStr))
+	if ip == nil {
+		return false
+	}
+	for _, ipNet := range t {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteIP 返回直连对端的 IP（去掉端口）
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// clientIP 计算真实客户端 IP
+// 对端不可信时直接使用对端地址；可信时从 X-Forwarded-For 右侧向左跳过可信代理，第一个不可信地址即为客户端
+func (t trustedProxies) clientIP(r *http.Request) string {
+	peer := remoteIP(r)
+	if !t.contains(peer) {
+		return peer
+	}
+
+	xff := r.Header.Values("X-Forwarded-For")
+	var hops []string
+	for _, v := range xff {
+		for _, hop := range strings.Split(v, ",") {
+			if hop = strings.TrimSpace(hop); hop != "" {
+				hops = append(hops, hop)
+			}
+		}
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		if !t.contains(hops[i]) {
+			return hops[i]
+		}
+	}
+	if ip := r.Header.Get("X-Real-IP"); ip != "" {
+		return ip
+	}
+	return peer
+}
+
+// setForwardedHeaders 为出站请求填充标准转发头
+// 反向代理会在 Director 之后把对端 IP 追加到 X-Forwarded-For，这里只负责决定是否保留已有链路
+func (t trustedProxies) setForwardedHeaders(req *http.Request, clientIP string) {
+	trusted := t.contains(remoteIP(req))
+
+	proto := "http"
+	if req.TLS != nil {
+		proto = "https"
+	}
+	host := req.Host
+
+	if trusted {
+		if v := req.Header.Get("X-Forwarded-Proto"); v != "" {
+			proto = v
+		}
+		if v := req.Header.Get("X-Forwarded-Host"); v != "" {
+			host = v
+		}
+	} else {
+		// 对端不可信，丢弃其伪造的转发信息
+		req.Header.Del("X-Forwarded-For")
+		req.Header.Del("Forwarded")
+	}
+
+	req.Header.Set("X-Forwarded-Proto", proto)
+	req.Header.Set("X-Forwarded-Host", host)
+	req.Header.Set("X-Real-IP", clientIP)
+
+	// RFC 7239: 追加本跳信息，IPv6 地址需要加方括号并整体加引号
+	forNode := remoteIP(req)
+	if strings.Contains(forNode, ":") {
+		forNode = fmt.Sprintf("\"[%s]\"", forNode)
+	}
+	element := fmt.Sprintf("for=%s;host=%q;proto=%s", forNode, host, proto)
+	if prior := req.Header.Get("Forwarded"); prior != "" {
+		element = prior + ", " + element
+	}
+	req.Header.Set("Forwarded", element)
+}
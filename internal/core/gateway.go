@@ -3,18 +3,41 @@ package core
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
+	"sync"
 
+	"gateway.example/go-gateway/internal/bodybuffer"
+	"gateway.example/go-gateway/internal/cache"
 	"gateway.example/go-gateway/internal/config"
 	"gateway.example/go-gateway/internal/core/health"
 	"gateway.example/go-gateway/internal/core/loadbalancer"
 	"gateway.example/go-gateway/internal/plugin"
+	pl_abtest "gateway.example/go-gateway/internal/plugin/abtest"
 	pl_auth "gateway.example/go-gateway/internal/plugin/auth"
+	pl_basicauth "gateway.example/go-gateway/internal/plugin/basicauth"
+	pl_bodytransform "gateway.example/go-gateway/internal/plugin/bodytransform"
+	pl_botdetect "gateway.example/go-gateway/internal/plugin/botdetect"
 	pl_circuitbreaker "gateway.example/go-gateway/internal/plugin/circuitbreaker"
+	pl_csrf "gateway.example/go-gateway/internal/plugin/csrf"
+	pl_external "gateway.example/go-gateway/internal/plugin/external"
+	pl_faultinject "gateway.example/go-gateway/internal/plugin/faultinject"
+	pl_geoip "gateway.example/go-gateway/internal/plugin/geoip"
+	pl_idempotency "gateway.example/go-gateway/internal/plugin/idempotency"
+	pl_mock "gateway.example/go-gateway/internal/plugin/mock"
+	pl_mtls "gateway.example/go-gateway/internal/plugin/mtls"
+	pl_oidc "gateway.example/go-gateway/internal/plugin/oidc"
+	pl_quota "gateway.example/go-gateway/internal/plugin/quota"
 	pl_ratelimit "gateway.example/go-gateway/internal/plugin/ratelimit"
+	pl_requestguard "gateway.example/go-gateway/internal/plugin/requestguard"
+	pl_requestid "gateway.example/go-gateway/internal/plugin/requestid"
+	pl_transform "gateway.example/go-gateway/internal/plugin/transform"
+	pl_webhook "gateway.example/go-gateway/internal/plugin/webhook"
+	"gateway.example/go-gateway/internal/response"
 	svc_circuitbreaker "gateway.example/go-gateway/internal/service/circuitbreaker"
+	svc_quota "gateway.example/go-gateway/internal/service/quota"
 	svc_ratelimit "gateway.example/go-gateway/internal/service/ratelimit"
 	"gateway.example/go-gateway/pkg/logger"
 )
@@ -22,18 +45,50 @@ import (
 // Gateway API网关核心引擎
 // 负责请求路由、负载均衡、健康检查和插件管理
 type Gateway struct {
-	config            *config.GatewayConfig      // 网关配置
-	router            *Router                    // 路由匹配器
-	proxy             *Proxy                     // 反向代理
-	healthChecker     *health.HealthChecker      // 健康检查器
-	pluginManager     *plugin.Manager            // 插件管理器
-	rateLimitSvc      svc_ratelimit.Service      // 限流服务
-	circuitBreakerSvc svc_circuitbreaker.Service // 熔断器服务
-	logger            logger.Logger              // 日志器
+	config            *config.GatewayConfig             // 启动时的网关配置，routes、services 与插件链以 proxy 中的当前路由状态为准
+	proxy             *Proxy                            // 反向代理，持有可以重新加载的路由状态
+	lbFactory         *loadbalancer.LoadBalancerFactory // 负载均衡器工厂
+	healthChecker     *health.HealthChecker             // 健康检查器
+	pluginManager     *plugin.Manager                   // 插件管理器
+	rateLimitSvc      svc_ratelimit.Service             // 限流服务
+	adaptiveLimits    *svc_ratelimit.AdaptiveController // 自适应限流控制器
+	circuitBreakerSvc svc_circuitbreaker.Service        // 熔断器服务
+	quotaSvc          svc_quota.Service                 // 配额服务
+	cache             cache.Cache                       // 共享缓存
+	serviceAware      []serviceAwarePlugin              // 持有服务列表的插件，重新加载时同步
+	reloadMu          sync.Mutex                        // 串行化配置重新加载
+	faults            *pl_faultinject.Plugin            // 故障注入插件，管理接口通过它在运行时开关故障
+	admin             http.Handler                      // 管理接口，为 nil 表示未启用
+	logger            logger.Logger                     // 日志器
+}
+
+// Option 调整 NewGateway 的行为
+type Option func(*gatewayOptions)
+
+type gatewayOptions struct {
+	validateOnly bool
+}
+
+// ValidateOnly 只构建组件并校验配置: 不启动健康检查、自适应限流与限流集群同步，
+// 不恢复也不写回熔断器的持久化状态，供 validate 子命令在 CI 中使用，不会访问上游或共享状态
+func ValidateOnly() Option {
+	return func(o *gatewayOptions) {
+		o.validateOnly = true
+	}
 }
 
 // NewGateway 创建网关实例并初始化所有组件
-func NewGateway(cfg *config.GatewayConfig, log logger.Logger) (*Gateway, error) {
+func NewGateway(cfg *config.GatewayConfig, log logger.Logger, opts ...Option) (*Gateway, error) {
+	var o gatewayOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	// 仅校验时服务使用去掉集群同步与持久化的配置副本，整体校验仍针对完整配置
+	rateLimitCfg, circuitBreakerCfg := cfg.RateLimiting, cfg.CircuitBreaker
+	if o.validateOnly {
+		rateLimitCfg.Cluster.Peers = nil
+		circuitBreakerCfg.Persist = nil
+	}
 
 	// 核心组件初始化
 	lbFactory := loadbalancer.NewLoadBalancerFactory()
@@ -44,19 +99,32 @@ func NewGateway(cfg *config.GatewayConfig, log logger.Logger) (*Gateway, error)
 	log.Info(context.Background(), "核心组件: 健康检查器已创建。")
 
 	// 限流服务
-	rateLimitSvc, err := svc_ratelimit.NewService(cfg.RateLimiting, log)
+	rateLimitSvc, err := svc_ratelimit.NewService(rateLimitCfg, log)
 	if err != nil {
 		return nil, fmt.Errorf("初始化限流服务失败: %w", err)
 	}
 	log.Info(context.Background(), "服务层: 限流服务已成功初始化。")
 
+	// 共享缓存
+	store, err := cache.New(cfg.Cache)
+	if err != nil {
+		return nil, fmt.Errorf("初始化缓存失败: %w", err)
+	}
+	log.Info(context.Background(), "核心组件: 缓存已创建。", "backend", cfg.Cache.Backend)
+
 	// 断路器
 	// 熔断器服务初始化
-	circuitBreakerSvc := svc_circuitbreaker.NewService(
-		cfg.CircuitBreaker.FailureThreshold,
-		cfg.CircuitBreaker.SuccessThreshold,
-		cfg.CircuitBreaker.ResetTimeout,
-		log)
+	// 服务级配置覆盖全局阈值，按服务名 (即 circuitbreaker 插件的 service) 生效
+	circuitBreakerOverrides := make(map[string]config.CircuitBreakerConfig)
+	for _, serviceCfg := range cfg.Services {
+		if serviceCfg.CircuitBreaker != nil {
+			circuitBreakerOverrides[serviceCfg.Name] = *serviceCfg.CircuitBreaker
+		}
+	}
+	circuitBreakerSvc, err := svc_circuitbreaker.NewService(circuitBreakerCfg, circuitBreakerOverrides, store, log)
+	if err != nil {
+		return nil, fmt.Errorf("初始化熔断器服务失败: %w", err)
+	}
 	log.Info(context.Background(), "服务层: 熔断器服务已成功初始化。")
 
 	// 注册服务实例到健康检查器和负载均衡器
@@ -79,15 +147,25 @@ func NewGateway(cfg *config.GatewayConfig, log logger.Logger) (*Gateway, error)
 		log.Info(context.Background(), "服务发现: 服务已注册", "service", serviceCfg.Name, "instance_count", len(instanceURLs))
 	}
 
+	// 服务所有实例都不健康时直接打开熔断器 (需开启 circuit_breaker.health_signals)
+	healthChecker.OnServiceHealthChange(func(serviceName string, healthy bool) {
+		circuitBreakerSvc.SetServiceHealth(context.Background(), serviceName, healthy)
+	})
+
 	// 启动健康检查
-	go healthChecker.Start()
+	if !o.validateOnly {
+		go healthChecker.Start()
+	}
 
-	// 创建反向代理
-	proxy := NewProxy(lbFactory, healthChecker, circuitBreakerSvc, log)
-	log.Info(context.Background(), "核心组件: 反向代理已创建并注入依赖。")
+	// 配额服务，计数保存在共享缓存中
+	quotaSvc, err := svc_quota.NewService(cfg.Quota, store, log)
+	if err != nil {
+		return nil, fmt.Errorf("初始化配额服务失败: %w", err)
+	}
+	log.Info(context.Background(), "服务层: 配额服务已成功初始化。")
 
 	// 插件初始化
-	pluginManager := plugin.NewManager()
+	pluginManager := plugin.NewManager(log)
 
 	// 限流插件
 	rateLimitPlugin := pl_ratelimit.NewPlugin(rateLimitSvc, log)
@@ -96,7 +174,7 @@ func NewGateway(cfg *config.GatewayConfig, log logger.Logger) (*Gateway, error)
 
 	// 认证插件（如果配置了认证服务）
 	if cfg.AuthService.ValidateURL != "" {
-		authPlugin, err := pl_auth.NewPlugin(lbFactory, healthChecker, "auth-service", log)
+		authPlugin, err := pl_auth.NewPlugin(lbFactory, healthChecker, "auth-service", store, log)
 		if err != nil {
 			return nil, fmt.Errorf("初始化认证插件失败: %w", err)
 		}
@@ -105,22 +183,133 @@ func NewGateway(cfg *config.GatewayConfig, log logger.Logger) (*Gateway, error)
 	}
 
 	// 熔断器插件
-	circuitBreakerPlugin := pl_circuitbreaker.NewPlugin(circuitBreakerSvc, log)
+	circuitBreakerPlugin := pl_circuitbreaker.NewPlugin(circuitBreakerSvc, cfg.Services, store, log)
 	pluginManager.Register(circuitBreakerPlugin)
 	log.Info(context.Background(), "插件: 'circuitBreaker' 已成功注册。")
 
+	// 请求体/响应体转换插件
+	pluginManager.Register(pl_bodytransform.NewPlugin(log))
+	log.Info(context.Background(), "插件: 'body_transform' 已成功注册。")
+
+	// 请求 ID 插件
+	pluginManager.Register(pl_requestid.NewPlugin(log))
+	log.Info(context.Background(), "插件: 'request_id' 已成功注册。")
+
+	// 请求静态改写插件
+	pluginManager.Register(pl_transform.NewPlugin(log))
+	log.Info(context.Background(), "插件: 'transform' 已成功注册。")
+
+	// 模拟响应插件
+	pluginManager.Register(pl_mock.NewPlugin(log))
+	log.Info(context.Background(), "插件: 'mock' 已成功注册。")
+
+	// 爬虫识别插件
+	pluginManager.Register(pl_botdetect.NewPlugin(log))
+	log.Info(context.Background(), "插件: 'bot_detection' 已成功注册。")
+
+	// CSRF 防护插件
+	pluginManager.Register(pl_csrf.NewPlugin(log))
+	log.Info(context.Background(), "插件: 'csrf' 已成功注册。")
+
+	// 请求校验插件
+	pluginManager.Register(pl_requestguard.NewPlugin(log))
+	log.Info(context.Background(), "插件: 'request_guard' 已成功注册。")
+
+	// webhook 签名校验插件
+	pluginManager.Register(pl_webhook.NewPlugin(log))
+	log.Info(context.Background(), "插件: 'webhook_signature' 已成功注册。")
+
+	// OIDC 认证插件，直接校验外部身份提供方签发的 Token
+	pluginManager.Register(pl_oidc.NewPlugin(log))
+	log.Info(context.Background(), "插件: 'oidc' 已成功注册。")
+
+	// Basic 认证插件
+	pluginManager.Register(pl_basicauth.NewPlugin(log))
+	log.Info(context.Background(), "插件: 'basicauth' 已成功注册。")
+
+	// 客户端证书 (mTLS) 认证插件
+	pluginManager.Register(pl_mtls.NewPlugin(log))
+	log.Info(context.Background(), "插件: 'mtls' 已成功注册。")
+
+	// GeoIP 访问控制插件
+	pluginManager.Register(pl_geoip.NewPlugin(log))
+	log.Info(context.Background(), "插件: 'geoip' 已成功注册。")
+
+	// A/B 实验插件
+	abTestPlugin := pl_abtest.NewPlugin(cfg.Services, log)
+	pluginManager.Register(abTestPlugin)
+	log.Info(context.Background(), "插件: 'ab_test' 已成功注册。")
+
+	// 故障注入插件，保留实例供管理接口开关
+	faultPlugin := pl_faultinject.NewPlugin(log)
+	pluginManager.Register(faultPlugin)
+	log.Info(context.Background(), "插件: 'fault_injection' 已成功注册。")
+
+	// 幂等去重插件，使用共享缓存保存响应
+	pluginManager.Register(pl_idempotency.NewPlugin(store, log))
+	log.Info(context.Background(), "插件: 'idempotency' 已成功注册。")
+
+	// 配额插件
+	pluginManager.Register(pl_quota.NewPlugin(quotaSvc, log))
+	log.Info(context.Background(), "插件: 'quota' 已成功注册。")
+
+	// 外部插件，名称不能与内置插件冲突
+	for _, extCfg := range cfg.ExternalPlugins {
+		if pluginManager.GetPlugin(extCfg.Name) != nil {
+			return nil, fmt.Errorf("外部插件 '%s' 与已注册的插件重名", extCfg.Name)
+		}
+		extPlugin, err := pl_external.NewPlugin(extCfg, log)
+		if err != nil {
+			return nil, fmt.Errorf("初始化外部插件失败: %w", err)
+		}
+		pluginManager.Register(extPlugin)
+		log.Info(context.Background(), fmt.Sprintf("插件: 外部插件 '%s' 已成功注册。", extCfg.Name), "url", extCfg.URL)
+	}
+
+	// 插件全部注册完成后校验配置，配置错误直接导致启动失败。
+	// 先做整体校验一次报告全部问题，再由各插件校验自己的参数
+	if err := cfg.Validate(validateOptions(pluginManager)); err != nil {
+		return nil, err
+	}
+	if err := validatePluginConfigs(pluginManager, cfg); err != nil {
+		return nil, fmt.Errorf("插件配置校验失败: %w", err)
+	}
+
+	// 创建反向代理
+	proxy, err := NewProxy(cfg, lbFactory, healthChecker, circuitBreakerSvc, pluginManager, store, log)
+	if err != nil {
+		return nil, fmt.Errorf("初始化反向代理失败: %w", err)
+	}
+	log.Info(context.Background(), "核心组件: 反向代理已创建并注入依赖。")
+
+	// 自适应限流: 按上游熔断器状态与错误率调整配置了 adaptive 的限流规则
+	adaptiveLimits := svc_ratelimit.NewAdaptiveController(rateLimitSvc, circuitBreakerSvc, svc_ratelimit.DefaultAdaptiveInterval, log)
+	if !o.validateOnly {
+		adaptiveLimits.Start()
+	}
+
 	// 组装网关实例
 	gw := &Gateway{
 		config:            cfg,
-		router:            NewRouter(cfg.Routes, log),
 		proxy:             proxy,
+		lbFactory:         lbFactory,
 		healthChecker:     healthChecker,
 		pluginManager:     pluginManager,
 		rateLimitSvc:      rateLimitSvc,
+		adaptiveLimits:    adaptiveLimits,
 		circuitBreakerSvc: circuitBreakerSvc,
+		quotaSvc:          quotaSvc,
+		cache:             store,
+		serviceAware:      []serviceAwarePlugin{abTestPlugin, circuitBreakerPlugin},
+		faults:            faultPlugin,
 		logger:            log,
 	}
 
+	if prefix := adminPathPrefix(cfg.Admin); prefix != "" {
+		gw.admin = gw.newAdminHandler()
+		log.Info(context.Background(), "核心组件: 管理接口已启用。", "path_prefix", prefix)
+	}
+
 	log.Info(context.Background(), "网关核心已成功初始化并准备就绪。")
 	return gw, nil
 }
@@ -128,10 +317,49 @@ func NewGateway(cfg *config.GatewayConfig, log logger.Logger) (*Gateway, error)
 // ServeHTTP 网关请求处理入口
 // 1. 路由匹配 → 2. 插件链执行 → 3. 反向代理转发
 func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	ctx := context.Background()
+	ctx := r.Context()
+
+	// 网关不是正向代理，在边缘直接拒绝 CONNECT 与 absolute-form 请求
+	if status, message, rejected := rejectNonOriginRequest(r); rejected {
+		g.logger.Warn(ctx, "拒绝代理形式的请求", "method", r.Method, "request_uri", r.RequestURI, "remote_addr", r.RemoteAddr)
+		if status == http.StatusMethodNotAllowed {
+			w.Header().Set("Allow", "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS")
+		}
+		response.WriteError(w, status, message)
+		return
+	}
+
+	// 整个请求使用同一份路由状态，重新加载不影响进行中的请求
+	rs := g.proxy.currentRouting()
+
+	// 插件通过 plugin.ClientIP 获取按可信代理规则解析出的客户端 IP
+	r = r.WithContext(plugin.WithClientIP(ctx, g.proxy.trustedProxies.clientIP(r)))
+	ctx = r.Context()
+
+	// 全局 pre_routing 插件 (如请求 ID) 在路由匹配之前执行
+	if len(rs.plugins.preRouting) > 0 {
+		block, err := g.pluginManager.ExecuteChain(w, r, rs.plugins.preRouting)
+		if err != nil {
+			g.logger.Error(ctx, "pre_routing 插件链执行因内部错误而中断", "error", err)
+			return
+		}
+		if block != nil {
+			g.logger.Info(ctx, "pre_routing 插件链中断请求", "plugin", block.Plugin, "reason", block.Reason, "status_code", block.Status)
+			return
+		}
+		ctx = r.Context()
+	}
+
+	// 管理接口优先于业务路由
+	if g.admin != nil {
+		if prefix := adminPathPrefix(g.config.Admin); r.URL.Path == prefix || strings.HasPrefix(r.URL.Path, prefix+"/") {
+			g.admin.ServeHTTP(w, r)
+			return
+		}
+	}
 
 	// 查找匹配的路由
-	route := g.router.FindRoute(r)
+	route := rs.router.FindRoute(r)
 	if route == nil {
 		g.logger.Info(ctx, "请求未匹配到任何路由", "method", r.Method, "path", r.URL.Path)
 		http.Error(w, "服务未找到", http.StatusNotFound)
@@ -144,8 +372,15 @@ func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// 协议升级策略
+	if protocol, allowed := checkUpgrade(r, route); !allowed {
+		g.logger.Warn(ctx, "路由不允许该协议升级", "path", r.URL.Path, "route", route.PathPrefix, "upgrade", protocol)
+		response.WriteError(w, http.StatusForbidden, fmt.Sprintf("该路由不允许升级到 '%s'", protocol))
+		return
+	}
+
 	// 查找对应服务
-	service, exists := g.config.Services[route.ServiceName]
+	service, exists := rs.config.Services[route.ServiceName]
 	if !exists {
 		g.logger.Info(ctx, "请求匹配到路由但服务未在配置中定义", "method", r.Method, "path", r.URL.Path, "route", route.PathPrefix, "service", route.ServiceName)
 		http.Error(w, "服务配置错误", http.StatusInternalServerError)
@@ -153,28 +388,70 @@ func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 	g.logger.Info(ctx, "请求匹配到路由", "method", r.Method, "path", r.URL.Path, "service", service.Name)
 
-	// 执行插件链
-	continueChain, err := g.pluginManager.ExecuteChain(w, r, route.Plugins)
+	// 缓冲请求体，使插件链与代理都能重复读取
+	if cfg := route.RequestBuffering; cfg != nil && cfg.Enabled {
+		buf, err := bodybuffer.Wrap(r, bodybuffer.Options{MemoryLimit: int64(cfg.MemoryLimit), MaxSize: int64(cfg.MaxSize), TempDir: cfg.TempDir})
+		if err != nil {
+			if errors.Is(err, bodybuffer.ErrTooLarge) {
+				g.logger.Warn(ctx, "请求体超过缓冲上限", "path", r.URL.Path, "content_length", r.ContentLength)
+				response.WriteError(w, http.StatusRequestEntityTooLarge, "请求体过大")
+				return
+			}
+			g.logger.Error(ctx, "缓冲请求体失败", "path", r.URL.Path, "error", err)
+			response.WriteError(w, http.StatusBadRequest, "读取请求体失败")
+			return
+		}
+		defer func() {
+			if err := buf.Close(); err != nil {
+				g.logger.Warn(ctx, "释放请求体缓冲失败", "error", err)
+			}
+		}()
+	}
+
+	// 执行插件链 (全局 post_routing 插件与路由插件按优先级合并)
+	plugins := rs.plugins.forRoute(route)
+	block, err := g.pluginManager.ExecuteChain(w, r, plugins)
 	if err != nil {
 		g.logger.Error(ctx, "插件链执行因内部错误而中断", "error", err)
 		return
 	}
-	if !continueChain {
-		g.logger.Info(ctx, "插件链中断请求，处理结束")
+	if block != nil {
+		g.logger.Info(ctx, "插件链中断请求，处理结束", "route", route.PathPrefix, "plugin", block.Plugin, "reason", block.Reason, "status_code", block.Status)
+		return
+	}
+	ctx = r.Context()
+
+	// 插件 (如 A/B 实验) 可以把请求改投到其他服务
+	if target, ok := plugin.TargetService(r); ok && target != service.Name {
+		override, exists := rs.config.Services[target]
+		if !exists {
+			g.logger.Error(ctx, "插件指定的目标服务未在配置中定义", "route", route.PathPrefix, "service", target)
+			http.Error(w, "服务配置错误", http.StatusInternalServerError)
+			return
+		}
+		g.logger.Info(ctx, "插件将请求改投到其他服务", "route", route.PathPrefix, "from", service.Name, "to", override.Name)
+		service = override
+	}
+
+	// 请求体转换
+	if err := g.pluginManager.TransformRequestBody(r, plugins, int64(route.MaxTransformBodySize)); err != nil {
+		g.logger.Error(ctx, "请求体转换失败", "error", err)
+		http.Error(w, "请求体转换失败", http.StatusBadRequest)
 		return
 	}
 
 	// 反向代理转发请求
-	g.proxy.ServeHTTP(w, r, route, &service)
+	g.proxy.ServeHTTP(w, r, rs, route, &service)
 }
 
 // HealthCheckHandler 健康检查API端点
 // 返回所有服务的健康状态
 func (g *Gateway) HealthCheckHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := context.Background()
+	rs := g.proxy.currentRouting()
 
 	// 获取路由配置
-	route := g.router.FindRoute(r)
+	route := rs.router.FindRoute(r)
 	if route == nil {
 		http.Error(w, "路由未找到", http.StatusNotFound)
 		return
@@ -191,7 +468,7 @@ func (g *Gateway) HealthCheckHandler(w http.ResponseWriter, r *http.Request) {
 			// 处理单个服务检测
 			if route.ServiceName == "all-services" {
 				response = g.healthChecker.GetAllStatuses()
-			} else if _, exists := g.config.Services[route.ServiceName]; exists {
+			} else if _, exists := rs.config.Services[route.ServiceName]; exists {
 				response = map[string]interface{}{
 					route.ServiceName: g.healthChecker.GetServiceStatus(route.ServiceName),
 				}
@@ -203,7 +480,7 @@ func (g *Gateway) HealthCheckHandler(w http.ResponseWriter, r *http.Request) {
 		// 处理单个服务检测
 		if route.ServiceName == "all-services" {
 			response = g.healthChecker.GetAllStatuses()
-		} else if _, exists := g.config.Services[route.ServiceName]; exists {
+		} else if _, exists := rs.config.Services[route.ServiceName]; exists {
 			response = map[string]interface{}{
 				route.ServiceName: g.healthChecker.GetServiceStatus(route.ServiceName),
 			}
@@ -218,6 +495,18 @@ func (g *Gateway) HealthCheckHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// CloseStreams 结束所有进行中的流式响应（SSE 等）
+// 应在服务器开始优雅关闭时调用，否则长连接会阻塞关闭流程直到超时
+func (g *Gateway) CloseStreams() {
+	g.logger.Info(context.Background(), "正在结束进行中的流式响应...")
+	g.proxy.CloseStreams()
+}
+
+// ValidateConfig 按当前已注册的插件校验一份新配置，用于重新加载前检查配置文件
+func (g *Gateway) ValidateConfig(cfg *config.GatewayConfig) error {
+	return cfg.Validate(validateOptions(g.pluginManager))
+}
+
 // Shutdown 优雅关闭网关
 // 停止健康检查和所有服务
 func (g *Gateway) Shutdown() {
@@ -228,6 +517,7 @@ func (g *Gateway) Shutdown() {
 	g.healthChecker.Shutdown()
 
 	// 关闭限流服务
+	g.adaptiveLimits.Stop()
 	if err := g.rateLimitSvc.Close(); err != nil {
 		g.logger.Error(ctx, "关闭限流服务时出错", "error", err)
 	}
@@ -237,5 +527,10 @@ func (g *Gateway) Shutdown() {
 		g.logger.Error(ctx, "关闭熔断器服务时出错", "error", err)
 	}
 
+	// 关闭共享缓存
+	if err := g.cache.Close(); err != nil {
+		g.logger.Error(ctx, "关闭缓存时出错", "error", err)
+	}
+
 	g.logger.Info(ctx, "网关已成功关闭。")
 }
@@ -0,0 +1,63 @@
+package core
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"gateway.example/go-gateway/internal/config"
+)
+
+// gRPC 状态码中代表上游自身故障的部分 (参见 google.golang.org/grpc/codes)
+// 客户端错误 (如 InvalidArgument、NotFound) 不应计入熔断统计
+const (
+	grpcCodeUnknown           = 2
+	grpcCodeDeadlineExceeded  = 4
+	grpcCodeResourceExhausted = 8
+	grpcCodeInternal          = 13
+	grpcCodeUnavailable       = 14
+	grpcCodeDataLoss          = 15
+)
+
+// usesHTTP2 判断服务是否要求使用 HTTP/2 连接上游
+func usesHTTP2(service *config.ServiceConfig) bool {
+	switch strings.ToLower(service.Protocol) {
+	case "h2c", "grpc":
+		return true
+	default:
+		return false
+	}
+}
+
+// isGRPCRequest 根据 Content-Type 判断是否为 gRPC 请求
+func isGRPCRequest(r *http.Request) bool {
+	return strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc")
+}
+
+// grpcStatus 从响应头中读取 grpc-status
+// 反向代理会把上游 trailer 写回响应头，未预先声明的 trailer 带有 http.TrailerPrefix 前缀
+func grpcStatus(h http.Header) (int, bool) {
+	value := h.Get("Grpc-Status")
+	if value == "" {
+		value = h.Get(http.TrailerPrefix + "Grpc-Status")
+	}
+	if value == "" {
+		return 0, false
+	}
+	code, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, false
+	}
+	return code, true
+}
+
+// isGRPCServerFailure 判断 gRPC 状态码是否应视为上游故障
+func isGRPCServerFailure(code int) bool {
+	switch code {
+	case grpcCodeUnknown, grpcCodeDeadlineExceeded, grpcCodeResourceExhausted,
+		grpcCodeInternal, grpcCodeUnavailable, grpcCodeDataLoss:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,108 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gateway.example/go-gateway/internal/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// headerTemplatePattern 匹配头部值中的模板变量，例如 {client_ip}、{jwt.sub}
+var headerTemplatePattern = regexp.MustCompile(`\{([a-z_]+(?:\.[A-Za-z0-9_\-]+)?)\}`)
+
+// applyHeaderRules 按 remove → set → add 的顺序把规则应用到头部
+// 模板在删除之前展开，因此可以先移除 Authorization 再用 {jwt.sub} 设置其他头
+func applyHeaderRules(h http.Header, rules config.HeaderRules, r *http.Request, info *proxyRequestInfo) {
+	setValues := make(map[string]string, len(rules.Set))
+	for name, value := range rules.Set {
+		setValues[name] = expandHeaderTemplate(value, r, info)
+	}
+	addValues := make(map[string]string, len(rules.Add))
+	for name, value := range rules.Add {
+		addValues[name] = expandHeaderTemplate(value, r, info)
+	}
+
+	for _, name := range rules.Remove {
+		h.Del(name)
+	}
+	for name, value := range setValues {
+		h.Set(name, value)
+	}
+	for name, value := range addValues {
+		h.Add(name, value)
+	}
+}
+
+// expandHeaderTemplate 展开头部值中的模板变量，未知变量保持原样
+func expandHeaderTemplate(value string, r *http.Request, info *proxyRequestInfo) string {
+	if !strings.Contains(value, "{") {
+		return value
+	}
+
+	var claims jwt.MapClaims
+	return headerTemplatePattern.ReplaceAllStringFunc(value, func(match string) string {
+		name := match[1 : len(match)-1]
+		switch {
+		case name == "client_ip":
+			return info.clientIP
+		case name == "route":
+			return info.route.PathPrefix
+		case name == "service":
+			return info.service.Name
+		case name == "method":
+			return r.Method
+		case name == "host":
+			return r.Host
+		case name == "path":
+			return r.URL.Path
+		case strings.HasPrefix(name, "jwt."):
+			// Token 已由认证插件校验，这里只解析载荷，不重复验签
+			if claims == nil {
+				claims = unverifiedClaims(r)
+			}
+			if v, ok := claims[strings.TrimPrefix(name, "jwt.")]; ok {
+				return claimString(v)
+			}
+			return ""
+		default:
+			return match
+		}
+	})
+}
+
+// unverifiedClaims 解析 Authorization 头中 Bearer Token 的载荷（不校验签名）
+func unverifiedClaims(r *http.Request) jwt.MapClaims {
+	claims := jwt.MapClaims{}
+	parts := strings.Split(r.Header.Get("Authorization"), " ")
+	if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
+		return claims
+	}
+	if _, _, err := jwt.NewParser().ParseUnverified(parts[1], claims); err != nil {
+		return jwt.MapClaims{}
+	}
+	return claims
+}
+
+// claimString 将 claim 值转换为头部可用的字符串，数组以逗号连接
+func claimString(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case []interface{}:
+		items := make([]string, 0, len(val))
+		for _, item := range val {
+			items = append(items, claimString(item))
+		}
+		return strings.Join(items, ",")
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(val)
+	}
+}
@@ -17,6 +17,9 @@ type HealthChecker struct {
 	stopChan    chan struct{}
 	checkTicker *time.Ticker
 	log         logger.Logger
+
+	listenersMu sync.RWMutex
+	listeners   []func(serviceName string, healthy bool) // 服务整体健康状态变化的订阅者
 }
 
 // ServiceCheckInfo 存储单个服务的所有健康检查相关信息。
@@ -56,6 +59,12 @@ func (h *HealthChecker) RegisterService(serviceName string, instances []string,
 	h.log.Info(context.Background(), "[HealthChecker] 服务已注册", "service", serviceName, "instance_count", len(instances), "health_path", healthPath)
 }
 
+// UnregisterService 停止检查已从配置中移除的服务。
+func (h *HealthChecker) UnregisterService(serviceName string) {
+	h.services.Delete(serviceName)
+	h.log.Info(context.Background(), "[HealthChecker] 服务已移除", "service", serviceName)
+}
+
 // Start 在一个独立的 goroutine 中启动周期性健康检查。
 func (h *HealthChecker) Start() {
 	h.log.Info(context.Background(), "[HealthChecker] 开始周期性健康检查...")
@@ -112,8 +121,7 @@ func (h *HealthChecker) checkService(ctx context.Context, serviceName string, in
 
 func (h *HealthChecker) updateInstanceStatus(ctx context.Context, serviceName string, info *ServiceCheckInfo, url string, isHealthy bool) {
 	info.statusMutex.Lock()
-	defer info.statusMutex.Unlock()
-
+	serviceWasHealthy := info.anyHealthy()
 	wasHealthy, exists := info.Status[url]
 	if !exists || wasHealthy != isHealthy {
 		statusStr := "健康"
@@ -123,6 +131,46 @@ func (h *HealthChecker) updateInstanceStatus(ctx context.Context, serviceName st
 		h.log.Info(ctx, fmt.Sprintf("[HealthChecker] 状态变更 -> 服务: %s, 实例: %s, 当前状态: %s", serviceName, url, statusStr))
 		info.Status[url] = isHealthy
 	}
+	serviceHealthy := info.anyHealthy()
+	info.statusMutex.Unlock()
+
+	// 在锁外通知，订阅者可以再查询健康状态
+	if serviceHealthy != serviceWasHealthy {
+		h.log.Info(ctx, "[HealthChecker] 服务整体健康状态变更", "service", serviceName, "healthy", serviceHealthy)
+		h.listenersMu.RLock()
+		defer h.listenersMu.RUnlock()
+		for _, fn := range h.listeners {
+			fn(serviceName, serviceHealthy)
+		}
+	}
+}
+
+// anyHealthy 判断服务是否至少有一个健康实例，调用方需持有 statusMutex
+func (info *ServiceCheckInfo) anyHealthy() bool {
+	for _, isHealthy := range info.Status {
+		if isHealthy {
+			return true
+		}
+	}
+	return false
+}
+
+// OnServiceHealthChange 订阅服务整体健康状态的变化: 所有实例都变为不健康时以 healthy=false 回调，
+// 之后任一实例恢复健康时以 healthy=true 回调。回调在健康检查 (或被动标记) 的协程中同步调用，不应长时间阻塞。
+func (h *HealthChecker) OnServiceHealthChange(fn func(serviceName string, healthy bool)) {
+	h.listenersMu.Lock()
+	defer h.listenersMu.Unlock()
+	h.listeners = append(h.listeners, fn)
+}
+
+// MarkInstanceUnhealthy 被动健康检查: 代理转发失败时立即把实例标记为不健康，
+// 直到下一轮主动检查成功后才恢复。
+func (h *HealthChecker) MarkInstanceUnhealthy(ctx context.Context, serviceName, url string) {
+	val, ok := h.services.Load(serviceName)
+	if !ok {
+		return
+	}
+	h.updateInstanceStatus(ctx, serviceName, val.(*ServiceCheckInfo), url, false)
 }
 
 // IsInstanceHealthy 检查特定实例的当前健康状态。
@@ -0,0 +1,114 @@
+// file: internal/core/limiter/cluster_window.go
+package limiter
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"time"
+)
+
+// peerWindow 是其他副本在某个窗口内的计数
+type peerWindow struct {
+	start  time.Time
+	counts map[string]int64
+}
+
+// ClusterWindow 是不依赖 Redis 的近似全局固定窗口限流器。
+// 每个副本只在本地计数，并定期与其他副本交换计数 (见 Snapshot / Merge)；
+// 判断时把本地计数与其他副本最近一次上报的同一窗口计数相加。
+// 计数交换有延迟，突发流量下整个集群可能短暂超出上限，超出量约为 副本数 × 同步周期内的请求数。
+// 窗口按墙上时钟对齐，各副本需要保持时钟同步。
+type ClusterWindow struct {
+	name        string
+	window      time.Duration
+	maxRequests int64
+
+	mu    sync.Mutex
+	start time.Time        // 当前窗口的起始时间
+	local map[string]int64 // 标识符 -> 本副本在当前窗口内放行的请求数
+	peers map[string]*peerWindow
+}
+
+// NewClusterWindow 创建一个集群固定窗口限流器，maxRequests 为整个集群每个窗口允许的请求数。
+func NewClusterWindow(window time.Duration, maxRequests int, name string) *ClusterWindow {
+	return &ClusterWindow{
+		name:        name,
+		window:      window,
+		maxRequests: int64(maxRequests),
+		start:       time.Now().Truncate(window),
+		local:       make(map[string]int64),
+		peers:       make(map[string]*peerWindow),
+	}
+}
+
+// Allow 汇总集群在当前窗口内的计数，未达到上限时计入本次请求并放行
+func (l *ClusterWindow) Allow(ctx context.Context, identifier string) Decision {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	l.roll(now)
+
+	total := l.local[identifier]
+	for _, peer := range l.peers {
+		// 时钟略快的副本可能已经进入下一个窗口
+		if peer.start.Equal(l.start) {
+			total += peer.counts[identifier]
+		}
+	}
+	limit := int(l.maxRequests)
+	if total >= l.maxRequests {
+		return Decision{Limit: limit, RetryAfter: l.start.Add(l.window).Sub(now)}
+	}
+	l.local[identifier]++
+	return Decision{Allowed: true, Limit: limit, Remaining: int(l.maxRequests - total - 1)}
+}
+
+// SetMaxRequests 调整集群每个窗口允许的请求数，本副本与其他副本的计数保留
+func (l *ClusterWindow) SetMaxRequests(maxRequests int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.maxRequests = int64(maxRequests)
+}
+
+// Name 返回限流器的名称
+func (l *ClusterWindow) Name() string {
+	return l.name
+}
+
+// Snapshot 返回当前窗口的起始时间与本副本的计数副本，用于推送给其他副本
+func (l *ClusterWindow) Snapshot() (time.Time, map[string]int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.roll(time.Now())
+	return l.start, maps.Clone(l.local)
+}
+
+// Merge 保存其他副本上报的计数。计数是该副本在窗口内的累计值，重复或乱序上报不会重复计算；
+// 早于当前窗口的上报会被忽略
+func (l *ClusterWindow) Merge(node string, start time.Time, counts map[string]int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.roll(time.Now())
+	if start.Before(l.start) {
+		return
+	}
+	if prev, ok := l.peers[node]; ok && start.Before(prev.start) {
+		return
+	}
+	l.peers[node] = &peerWindow{start: start, counts: counts}
+}
+
+// roll 在窗口结束后清空计数，调用方需持有锁
+func (l *ClusterWindow) roll(now time.Time) {
+	if now.Sub(l.start) < l.window {
+		return
+	}
+	l.start = now.Truncate(l.window)
+	l.local = make(map[string]int64, len(l.local))
+	for node, peer := range l.peers {
+		if peer.start.Before(l.start) {
+			delete(l.peers, node)
+		}
+	}
+}
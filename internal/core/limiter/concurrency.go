@@ -0,0 +1,144 @@
+// file: internal/core/limiter/concurrency.go
+package limiter
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// bulkhead 是一个标识符的并发名额与等待队列，字段受 Concurrency.mu 保护
+type bulkhead struct {
+	inFlight int           // 已占用的名额
+	waiting  int           // 排队等待的请求数
+	users    int           // 持有或等待名额的请求数，归零后删除
+	wake     chan struct{} // 名额归还或上限提高时关闭并替换，唤醒全部等待者重新检查
+}
+
+// Concurrency 是并发限流器 (舱壁)，限制同一标识符同时在途的请求数。
+// 名额用尽时请求最多排队 queueSize 个、等待 queueTimeout，防止慢上游导致请求堆积。
+type Concurrency struct {
+	name         string
+	maxInFlight  int
+	queueSize    int
+	queueTimeout time.Duration
+	bulkheads    map[string]*bulkhead
+	mu           sync.Mutex
+}
+
+// NewConcurrency 创建一个并发限流器
+func NewConcurrency(maxInFlight, queueSize int, queueTimeout time.Duration, name string) *Concurrency {
+	return &Concurrency{
+		name:         name,
+		maxInFlight:  maxInFlight,
+		queueSize:    queueSize,
+		queueTimeout: queueTimeout,
+		bulkheads:    make(map[string]*bulkhead),
+	}
+}
+
+// Allow 占用一个名额，名额用尽时按队列配置等待；ctx 结束时放弃等待。
+// 放行时 Decision.Release 归还名额，调用方必须在请求结束后调用。
+func (l *Concurrency) Allow(ctx context.Context, identifier string) Decision {
+	l.mu.Lock()
+	b := l.get(identifier)
+	if b.inFlight < l.maxInFlight {
+		b.inFlight++
+		l.mu.Unlock()
+		return Decision{Allowed: true, Release: l.releaser(identifier, b)}
+	}
+
+	// 无法预估名额何时归还，建议客户端稍后重试
+	rejected := Decision{RetryAfter: time.Second}
+	if b.waiting >= l.queueSize {
+		l.put(identifier, b)
+		l.mu.Unlock()
+		return rejected
+	}
+	b.waiting++
+
+	timer := time.NewTimer(l.queueTimeout)
+	defer timer.Stop()
+	for {
+		wake := b.wake
+		l.mu.Unlock()
+		expired := false
+		select {
+		case <-wake:
+		case <-timer.C:
+			expired = true
+		case <-ctx.Done():
+			expired = true
+		}
+		l.mu.Lock()
+		if b.inFlight < l.maxInFlight {
+			b.waiting--
+			b.inFlight++
+			l.mu.Unlock()
+			return Decision{Allowed: true, Release: l.releaser(identifier, b)}
+		}
+		if expired {
+			b.waiting--
+			l.put(identifier, b)
+			l.mu.Unlock()
+			return rejected
+		}
+	}
+}
+
+// SetMaxInFlight 调整每个标识符的在途上限。已占用的名额保留并在请求结束时正常归还；
+// 上限降低时在途请求数降到新上限以下后才放行新请求
+func (l *Concurrency) SetMaxInFlight(maxInFlight int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	raised := maxInFlight > l.maxInFlight
+	l.maxInFlight = maxInFlight
+	if raised {
+		for _, b := range l.bulkheads {
+			b.broadcast()
+		}
+	}
+}
+
+// Name 返回限流器的名称
+func (l *Concurrency) Name() string {
+	return l.name
+}
+
+// releaser 返回归还名额的函数
+func (l *Concurrency) releaser(identifier string, b *bulkhead) func() {
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		b.inFlight--
+		b.broadcast()
+		l.put(identifier, b)
+	}
+}
+
+// get 返回标识符的 bulkhead 并登记一个使用者，调用方需持有锁
+func (l *Concurrency) get(identifier string) *bulkhead {
+	b, ok := l.bulkheads[identifier]
+	if !ok {
+		b = &bulkhead{wake: make(chan struct{})}
+		l.bulkheads[identifier] = b
+	}
+	b.users++
+	return b
+}
+
+// put 归还对 bulkhead 的引用，没有请求使用时删除，避免标识符过多时内存增长。调用方需持有锁
+func (l *Concurrency) put(identifier string, b *bulkhead) {
+	b.users--
+	if b.users == 0 {
+		delete(l.bulkheads, identifier)
+	}
+}
+
+// broadcast 唤醒全部等待者，调用方需持有锁
+func (b *bulkhead) broadcast() {
+	if b.waiting > 0 {
+		close(b.wake)
+		b.wake = make(chan struct{})
+	}
+}
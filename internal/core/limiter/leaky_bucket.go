@@ -0,0 +1,93 @@
+// file: internal/core/limiter/leaky_bucket.go
+package limiter
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// LeakyBucket 是基于内存的漏桶整形器。
+// 请求按固定间隔 (1/rate) 依次放行，超出速率的请求不会立即被拒绝，而是在 Allow 中等待轮到自己，
+// 预计等待时间超过 maxDelay 时才拒绝。适合平滑内部 API 客户端的突发流量。
+type LeakyBucket struct {
+	name     string
+	interval time.Duration
+	maxDelay time.Duration
+	// 标识符 -> 下一个请求可以放行的时间
+	next map[string]time.Time
+	mu   sync.Mutex
+}
+
+// NewLeakyBucket 创建一个漏桶整形器，rate 为每秒放行的请求数，ctx 结束时停止后台清理。
+func NewLeakyBucket(ctx context.Context, rate int, maxDelay time.Duration, name string) *LeakyBucket {
+	l := &LeakyBucket{
+		name:     name,
+		interval: time.Second / time.Duration(rate),
+		maxDelay: maxDelay,
+		next:     make(map[string]time.Time),
+	}
+	go l.cleanup(ctx)
+	return l
+}
+
+// Allow 为请求预约一个放行时刻并等待到该时刻；需要等待超过 maxDelay 或 ctx 结束时拒绝
+func (l *LeakyBucket) Allow(ctx context.Context, identifier string) Decision {
+	l.mu.Lock()
+	now := time.Now()
+	at := now
+	if next := l.next[identifier]; next.After(now) {
+		at = next
+	}
+	delay := at.Sub(now)
+	if delay > l.maxDelay {
+		l.mu.Unlock()
+		return Decision{RetryAfter: delay - l.maxDelay}
+	}
+	l.next[identifier] = at.Add(l.interval)
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return Decision{Allowed: true}
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return Decision{Allowed: true}
+	case <-ctx.Done():
+		return Decision{}
+	}
+}
+
+// SetRate 调整每秒放行的请求数，已预约的放行时刻不变，之后的请求按新的间隔排队
+func (l *LeakyBucket) SetRate(rate int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.interval = time.Second / time.Duration(rate)
+}
+
+// Name 返回限流器的名称
+func (l *LeakyBucket) Name() string {
+	return l.name
+}
+
+// cleanup 定期删除已经没有排队请求的标识符，它们与新的标识符等价
+func (l *LeakyBucket) cleanup(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			l.mu.Lock()
+			for identifier, next := range l.next {
+				if !next.After(now) {
+					delete(l.next, identifier)
+				}
+			}
+			l.mu.Unlock()
+		}
+	}
+}
@@ -3,60 +3,34 @@ package limiter
 
 import (
 	"context"
-	"fmt"
-	"net/http"
-	"strings"
+	"time"
 )
 
+// Decision 是一次限流判断的结果
+type Decision struct {
+	Allowed bool
+	// Limit 为规则的上限 (桶容量或窗口内的请求数)，限流器无法给出时为 0
+	Limit int
+	// Remaining 为本次判断后剩余的额度，Limit 为 0 时无意义
+	Remaining int
+	// RetryAfter 为被拒绝时建议客户端等待的时间，无法估算时为 0
+	RetryAfter time.Duration
+	// Release 由并发限流器在放行时设置，调用方必须在请求结束后调用以归还名额；其他限流器为 nil
+	Release func()
+}
+
 // Limiter 是所有限流算法必须实现的接口。
-// ★ 修改点: Allow 方法现在只接收 identifier，不再需要 settings 和 request。
-//
-//	因为限流器在创建时就已经知道了自己的配置。
+// 限流器在创建时就已经知道了自己的配置，因此 Allow 只接收 identifier。
 type Limiter interface {
-	Allow(ctx context.Context, identifier string) bool
+	Allow(ctx context.Context, identifier string) Decision
 	Name() string
 }
 
-// IdentifierFunc 是一个函数类型，用于从 HTTP 请求中提取唯一的标识符。
-type IdentifierFunc func(r *http.Request) string
-
 type NoOpLimiter struct{}
 
-// GetIdentifierFunc 根据策略名称返回对应的标识符提取函数。
-// 这个函数现在是插件层使用的工具，但定义在核心层是合适的。
-func GetIdentifierFunc(strategy string) (IdentifierFunc, error) {
-	switch strings.ToLower(strategy) {
-	case "ip":
-		return func(r *http.Request) string {
-			// 优先从 X-Forwarded-For 获取，适配代理场景
-			ip := r.Header.Get("X-Forwarded-For")
-			if ip == "" {
-				// X-Real-IP 是另一个常见Header
-				ip = r.Header.Get("X-Real-Ip")
-			}
-			if ip == "" {
-				// 最后回退到 RemoteAddr
-				// 注意：RemoteAddr 可能包含端口，需要处理
-				ip = strings.Split(r.RemoteAddr, ":")[0]
-			}
-			return ip
-		}, nil
-	case "path":
-		return func(r *http.Request) string {
-			return r.URL.Path
-		}, nil
-	case "global":
-		return func(r *http.Request) string {
-			return "global"
-		}, nil
-	default:
-		return nil, fmt.Errorf("不支持的限流策略: '%s'", strategy)
-	}
-}
-
-// Allow 总是返回 true。
-func (l *NoOpLimiter) Allow(ctx context.Context, identifier string) bool {
-	return true
+// Allow 总是放行。
+func (l *NoOpLimiter) Allow(ctx context.Context, identifier string) Decision {
+	return Decision{Allowed: true}
 }
 
 // Name 返回此限流器的名称。
@@ -0,0 +1,12 @@
+package limiter
+
+import "gateway.example/go-gateway/pkg/metrics"
+
+var (
+	bucketCount = metrics.NewGaugeVec("gateway_ratelimit_buckets",
+		"内存令牌桶当前保存的桶数量，按规则统计",
+		"rule")
+	bucketEvictions = metrics.NewCounterVec("gateway_ratelimit_bucket_evictions_total",
+		"内存令牌桶被淘汰的桶数量，reason 为 idle (空闲已补满) 或 capacity (超过 maxBuckets)",
+		"rule", "reason")
+)
@@ -0,0 +1,129 @@
+// file: internal/core/limiter/redis_token_bucket.go
+package limiter
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gateway.example/go-gateway/pkg/logger"
+	"github.com/redis/go-redis/v9"
+)
+
+// redisErrorLogInterval Redis 持续不可用时，两条错误日志之间的最小间隔
+const redisErrorLogInterval = 10 * time.Second
+
+// tokenBucketScript 在 Redis 中原子地补充并消耗令牌，返回 {是否放行, 剩余令牌数, 需要等待的毫秒数}。
+// 令牌数保存为小数，补充按毫秒计算；时间取自 Redis 服务器，避免各网关实例的时钟偏差。
+// 桶在闲置到可以补满之后自动过期。需要 Redis 5+ (脚本默认按效果复制，允许调用 TIME 后写入)。
+var tokenBucketScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+  tokens = capacity
+  ts = now
+end
+
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * rate / 1000)
+local allowed = 0
+local wait = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+else
+  wait = math.ceil((1 - tokens) * 1000 / rate)
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tokens, 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil(capacity / rate * 1000) + 1000)
+return {allowed, math.floor(tokens), wait}
+`)
+
+// RedisTokenBucket 是基于 Redis 的令牌桶限流器，多个网关实例共享同一组桶，
+// 水平扩容时限额不会随实例数成倍增加。
+type RedisTokenBucket struct {
+	name       string
+	mu         sync.RWMutex // 保护 capacity 与 refillRate
+	capacity   int
+	refillRate int
+	keyPrefix  string
+	failOpen   bool // Redis 不可用时是否放行
+	client     redis.Scripter
+	log        logger.Logger
+
+	lastErrorLog atomic.Int64 // 上次记录错误日志的时间 (UnixNano)
+}
+
+// NewRedisTokenBucket 创建一个 Redis 令牌桶，键名为 <keyPrefix>ratelimit:<name>:<identifier>
+func NewRedisTokenBucket(client redis.Scripter, capacity, refillRate int, name, keyPrefix string, failOpen bool, log logger.Logger) *RedisTokenBucket {
+	return &RedisTokenBucket{
+		name:       name,
+		capacity:   capacity,
+		refillRate: refillRate,
+		keyPrefix:  keyPrefix,
+		failOpen:   failOpen,
+		client:     client,
+		log:        log,
+	}
+}
+
+// Allow 在 Redis 中消耗一个令牌；Redis 出错时按 failOpen 决定放行或拒绝
+func (b *RedisTokenBucket) Allow(ctx context.Context, identifier string) Decision {
+	key := b.keyPrefix + "ratelimit:" + b.name + ":" + identifier
+	b.mu.RLock()
+	capacity, refillRate := b.capacity, b.refillRate
+	b.mu.RUnlock()
+	result, err := tokenBucketScript.Run(ctx, b.client, []string{key}, capacity, refillRate).Int64Slice()
+	if err == nil && len(result) != 3 {
+		err = fmt.Errorf("unexpected script result: %v", result)
+	}
+	if err != nil {
+		b.logError(ctx, err)
+		return Decision{Allowed: b.failOpen}
+	}
+	return Decision{
+		Allowed:    result[0] == 1,
+		Limit:      capacity,
+		Remaining:  int(result[1]),
+		RetryAfter: time.Duration(result[2]) * time.Millisecond,
+	}
+}
+
+// SetLimits 调整桶容量与补充速率，Redis 中各桶已有的令牌保留 (脚本按新容量截断)
+func (b *RedisTokenBucket) SetLimits(capacity, refillRate int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.capacity, b.refillRate = capacity, refillRate
+}
+
+// Name 返回限流器的名称
+func (b *RedisTokenBucket) Name() string {
+	return b.name
+}
+
+// logError 限制错误日志的频率，Redis 宕机时不会每个请求都打一条日志
+func (b *RedisTokenBucket) logError(ctx context.Context, err error) {
+	now := time.Now().UnixNano()
+	last := b.lastErrorLog.Load()
+	if now-last < int64(redisErrorLogInterval) || !b.lastErrorLog.CompareAndSwap(last, now) {
+		return
+	}
+	policy := "closed"
+	if b.failOpen {
+		policy = "open"
+	}
+	b.log.Error(ctx, "Redis rate limiter unavailable",
+		"rule_name", b.name,
+		"failure_policy", policy,
+		"error", err.Error(),
+		"service", "ratelimit",
+		"action", "backend_error")
+}
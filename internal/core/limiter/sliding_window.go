@@ -0,0 +1,103 @@
+// file: internal/core/limiter/sliding_window.go
+package limiter
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// windowCounter 记录一个标识符在当前与上一个固定窗口内的请求数
+type windowCounter struct {
+	start    time.Time // 当前窗口的起始时间
+	current  int
+	previous int
+}
+
+// SlidingWindow 是基于内存的滑动窗口计数限流器。
+// 用上一个窗口的计数按重叠比例加权，近似任意时刻往前一个窗口内的请求数，
+// 不会像令牌桶那样允许突发，适合 "每分钟最多 N 次" 这类严格的配额。
+type SlidingWindow struct {
+	name        string
+	window      time.Duration
+	maxRequests int
+	counters    map[string]*windowCounter
+	mu          sync.Mutex
+}
+
+// NewSlidingWindow 创建一个滑动窗口限流器，ctx 结束时停止后台清理。
+func NewSlidingWindow(ctx context.Context, window time.Duration, maxRequests int, name string) *SlidingWindow {
+	l := &SlidingWindow{
+		name:        name,
+		window:      window,
+		maxRequests: maxRequests,
+		counters:    make(map[string]*windowCounter),
+	}
+	go l.cleanup(ctx)
+	return l
+}
+
+// Allow 估算滑动窗口内的请求数，未达到上限时计入本次请求并放行
+func (l *SlidingWindow) Allow(ctx context.Context, identifier string) Decision {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	c, ok := l.counters[identifier]
+	if !ok {
+		c = &windowCounter{start: now.Truncate(l.window)}
+		l.counters[identifier] = c
+	}
+
+	// 滚动到当前窗口
+	switch elapsed := now.Sub(c.start); {
+	case elapsed >= 2*l.window:
+		c.start, c.previous, c.current = now.Truncate(l.window), 0, 0
+	case elapsed >= l.window:
+		c.start, c.previous, c.current = c.start.Add(l.window), c.current, 0
+	}
+
+	// 上一个窗口中仍落在滑动窗口内的比例
+	weight := 1 - float64(now.Sub(c.start))/float64(l.window)
+	estimate := float64(c.previous)*weight + float64(c.current)
+	if estimate >= float64(l.maxRequests) {
+		// 到当前窗口结束时上一个窗口的权重归零，此后一定有额度 (当前窗口已满时除外，仍是合理的估计)
+		return Decision{Limit: l.maxRequests, RetryAfter: c.start.Add(l.window).Sub(now)}
+	}
+	c.current++
+	remaining := max(l.maxRequests-int(math.Ceil(estimate+1)), 0)
+	return Decision{Allowed: true, Limit: l.maxRequests, Remaining: remaining}
+}
+
+// SetMaxRequests 调整窗口内允许的请求数，已有的计数保留
+func (l *SlidingWindow) SetMaxRequests(maxRequests int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.maxRequests = maxRequests
+}
+
+// Name 返回限流器的名称
+func (l *SlidingWindow) Name() string {
+	return l.name
+}
+
+// cleanup 定期删除两个窗口内没有请求的计数器
+func (l *SlidingWindow) cleanup(ctx context.Context) {
+	ticker := time.NewTicker(l.window)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			l.mu.Lock()
+			for id, c := range l.counters {
+				if now.Sub(c.start) >= 2*l.window {
+					delete(l.counters, id)
+				}
+			}
+			l.mu.Unlock()
+		}
+	}
+}
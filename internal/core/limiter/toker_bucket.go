@@ -2,59 +2,88 @@
 package limiter
 
 import (
+	"container/list"
 	"context"
 	"sync"
 	"time"
 )
 
+// 内存令牌桶默认参数
+const (
+	DefaultMaxBuckets          = 100000
+	tokenBucketCleanupInterval = time.Minute
+)
+
 // bucket 定义了每个标识符的状态
 type bucket struct {
-	tokens    int
-	lastCheck time.Time
+	identifier string
+	tokens     int
+	lastCheck  time.Time
+	lastSeen   time.Time // 最近一次请求的时间，用于淘汰空闲的桶
 }
 
 // MemoryTokenBucket 是一个基于内存的令牌桶限流器实现。
+// 桶的数量不超过 maxBuckets，超出时淘汰最久未访问的桶，防止扫描类流量 (大量不同 IP) 耗尽内存；
+// 被淘汰的标识符下次访问时会拿到一个满的桶。
 type MemoryTokenBucket struct {
 	name       string
 	capacity   int
 	refillRate int
+	maxBuckets int
 	// ★ 修改点: 现在的桶是 string -> *bucket，因为 identifier 是 string
-	buckets  map[string]*bucket
-	mu       sync.Mutex
-	stopChan chan struct{}
+	buckets map[string]*list.Element
+	// lru 按最近访问排序，队尾是最久未访问的桶
+	lru *list.List
+	mu  sync.Mutex
 }
 
-// NewMemoryTokenBucket 创建一个新的内存令牌桶。
-func NewMemoryTokenBucket(ctx context.Context, capacity, refillRate int, name string) *MemoryTokenBucket {
+// NewMemoryTokenBucket 创建一个新的内存令牌桶，ctx 结束时停止后台清理。
+// maxBuckets <= 0 时使用 DefaultMaxBuckets。
+func NewMemoryTokenBucket(ctx context.Context, capacity, refillRate, maxBuckets int, name string) *MemoryTokenBucket {
+	if maxBuckets <= 0 {
+		maxBuckets = DefaultMaxBuckets
+	}
 	b := &MemoryTokenBucket{
 		name:       name,
 		capacity:   capacity,
 		refillRate: refillRate,
-		buckets:    make(map[string]*bucket),
-		stopChan:   make(chan struct{}),
+		maxBuckets: maxBuckets,
+		buckets:    make(map[string]*list.Element),
+		lru:        list.New(),
 	}
-	// ... (清理 goroutine 的逻辑保持不变) ...
+	bucketCount.WithLabelValues(name).Set(0)
+	go b.cleanup(ctx)
 	return b
 }
 
-// ★ 修改点: Allow 方法签名更新，逻辑也相应简化
-func (b *MemoryTokenBucket) Allow(ctx context.Context, identifier string) bool {
+// Allow 补充令牌后尝试消耗一个令牌
+func (b *MemoryTokenBucket) Allow(ctx context.Context, identifier string) Decision {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
 	// 查找或创建标识符对应的桶
-	currentBucket, ok := b.buckets[identifier]
-	if !ok {
+	now := time.Now()
+	var currentBucket *bucket
+	if elem, ok := b.buckets[identifier]; ok {
+		currentBucket = elem.Value.(*bucket)
+		b.lru.MoveToFront(elem)
+	} else {
 		// 首次访问，创建一个满的桶
 		currentBucket = &bucket{
-			tokens:    b.capacity,
-			lastCheck: time.Now(),
+			identifier: identifier,
+			tokens:     b.capacity,
+			lastCheck:  now,
 		}
-		b.buckets[identifier] = currentBucket
+		b.buckets[identifier] = b.lru.PushFront(currentBucket)
+		for b.lru.Len() > b.maxBuckets {
+			b.remove(b.lru.Back())
+			bucketEvictions.WithLabelValues(b.name, "capacity").Inc()
+		}
+		bucketCount.WithLabelValues(b.name).Set(float64(b.lru.Len()))
 	}
+	currentBucket.lastSeen = now
 
 	// 补充令牌
-	now := time.Now()
 	elapsed := now.Sub(currentBucket.lastCheck)
 	// 注意: elapsed.Seconds() 返回的是 float64
 	refillCount := int(elapsed.Seconds() * float64(b.refillRate))
@@ -69,13 +98,75 @@ func (b *MemoryTokenBucket) Allow(ctx context.Context, identifier string) bool {
 	// 检查并消耗令牌
 	if currentBucket.tokens > 0 {
 		currentBucket.tokens--
-		return true
+		return Decision{Allowed: true, Limit: b.capacity, Remaining: currentBucket.tokens}
 	}
 
-	return false
+	// 距离补充下一个令牌的时间
+	var retryAfter time.Duration
+	if b.refillRate > 0 {
+		retryAfter = currentBucket.lastCheck.Add(time.Second / time.Duration(b.refillRate)).Sub(now)
+	}
+	return Decision{Limit: b.capacity, RetryAfter: retryAfter}
+}
+
+// SetLimits 调整桶容量与补充速率，各标识符已有的令牌保留 (超过新容量的部分在下次访问时截断)
+func (b *MemoryTokenBucket) SetLimits(capacity, refillRate int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.capacity, b.refillRate = capacity, refillRate
 }
 
 // Name 返回限流器的名称
 func (b *MemoryTokenBucket) Name() string {
 	return b.name
 }
+
+// Len 返回当前的桶数量
+func (b *MemoryTokenBucket) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.lru.Len()
+}
+
+// cleanup 定期淘汰空闲的桶
+func (b *MemoryTokenBucket) cleanup(ctx context.Context) {
+	ticker := time.NewTicker(tokenBucketCleanupInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			b.evictIdle(now)
+		}
+	}
+}
+
+// evictIdle 删除空闲时间足以补满令牌的桶，这样的桶与新建的满桶等价，删除不影响限流结果
+func (b *MemoryTokenBucket) evictIdle(now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	idle := time.Second
+	if b.refillRate > 0 {
+		idle = max(time.Duration(float64(b.capacity)/float64(b.refillRate)*float64(time.Second)), idle)
+	}
+	evicted := 0
+	// 从最久未访问的桶开始检查，遇到仍活跃的桶即可停止
+	for elem := b.lru.Back(); elem != nil; elem = b.lru.Back() {
+		if now.Sub(elem.Value.(*bucket).lastSeen) < idle {
+			break
+		}
+		b.remove(elem)
+		evicted++
+	}
+	if evicted > 0 {
+		bucketEvictions.WithLabelValues(b.name, "idle").Add(float64(evicted))
+		bucketCount.WithLabelValues(b.name).Set(float64(b.lru.Len()))
+	}
+}
+
+// remove 调用方需持有锁
+func (b *MemoryTokenBucket) remove(elem *list.Element) {
+	b.lru.Remove(elem)
+	delete(b.buckets, elem.Value.(*bucket).identifier)
+}
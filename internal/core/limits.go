@@ -0,0 +1,97 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// responseTooLargeError 上游响应体超过路由配置的 max_response_size
+type responseTooLargeError struct {
+	limit int64
+}
+
+func (e *responseTooLargeError) Error() string {
+	return fmt.Sprintf("上游响应体超过 %d 字节的限制", e.limit)
+}
+
+// limitResponseBody 为上游响应体施加大小上限
+// 声明了 Content-Length 的超限响应直接返回错误，由 ErrorHandler 返回 502；
+// 未声明长度的响应在读取超限时返回错误，反向代理会中断与客户端的连接
+func (p *Proxy) limitResponseBody(resp *http.Response, info *proxyRequestInfo) error {
+	limit := int64(info.route.MaxResponseSize)
+	if limit <= 0 {
+		return nil
+	}
+	if resp.ContentLength > limit {
+		return &responseTooLargeError{limit: limit}
+	}
+
+	ctx := resp.Request.Context()
+	resp.Body = &limitedBody{
+		ReadCloser: resp.Body,
+		remaining:  limit,
+		err:        &responseTooLargeError{limit: limit},
+		onExceed: func() {
+			p.logger.Error(ctx, "[Proxy] 上游响应体超过限制，已中断响应",
+				"service", info.service.Name,
+				"instance", info.instanceURL,
+				"path", resp.Request.URL.Path,
+				"limit", limit)
+			p.recordResult(context.Background(), info, false)
+		},
+	}
+	return nil
+}
+
+// limitedBody 读取超过 remaining 字节时返回 err
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+	err       error
+	exceeded  bool
+	onExceed  func()
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.exceeded {
+		return 0, b.err
+	}
+	// 多读 1 个字节用于判断是否超限
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+	n, err := b.ReadCloser.Read(p)
+	if int64(n) > b.remaining {
+		n = int(b.remaining)
+		b.remaining = 0
+		b.exceeded = true
+		b.onExceed()
+		return n, b.err
+	}
+	b.remaining -= int64(n)
+	return n, err
+}
+
+// bufferPool 为反向代理复制响应体提供固定大小的缓冲区，实现 httputil.BufferPool
+type bufferPool struct {
+	pool sync.Pool
+}
+
+func newBufferPool(size int) *bufferPool {
+	return &bufferPool{pool: sync.Pool{
+		New: func() any {
+			return make([]byte, size)
+		},
+	}}
+}
+
+func (b *bufferPool) Get() []byte {
+	return b.pool.Get().([]byte)
+}
+
+func (b *bufferPool) Put(buf []byte) {
+	b.pool.Put(buf)
+}
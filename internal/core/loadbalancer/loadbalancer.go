@@ -32,6 +32,27 @@ func NewLoadBalancerFactory() *LoadBalancerFactory {
 	}
 }
 
+// Replace 用新的算法与实例列表重建服务的负载均衡器，用于重新加载配置。
+// 已取得旧负载均衡器的请求继续使用它，之后的请求使用新的
+func (f *LoadBalancerFactory) Replace(serviceName, algorithm string, instances []*ServiceInstance) LoadBalancer {
+	lb := newLoadBalancer(serviceName, algorithm)
+	for _, inst := range instances {
+		lb.RegisterInstance(serviceName, inst)
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.balancers[serviceName] = lb
+	return lb
+}
+
+// Remove 删除已从配置中移除的服务的负载均衡器
+func (f *LoadBalancerFactory) Remove(serviceName string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	delete(f.balancers, serviceName)
+}
+
 // GetOrCreateLoadBalancer 获取或创建负载均衡器
 func (f *LoadBalancerFactory) GetOrCreateLoadBalancer(serviceName, algorithm string) LoadBalancer {
 	f.mutex.RLock()
@@ -50,18 +71,21 @@ func (f *LoadBalancerFactory) GetOrCreateLoadBalancer(serviceName, algorithm str
 	}
 
 	// 创建新的负载均衡器
-	var lb LoadBalancer
+	lb := newLoadBalancer(serviceName, algorithm)
+	f.balancers[serviceName] = lb
+	return lb
+}
+
+// newLoadBalancer 按算法名创建负载均衡器，未知算法使用轮询
+func newLoadBalancer(serviceName, algorithm string) LoadBalancer {
 	switch algorithm {
 	case "round_robin":
-		lb = NewRoundRobinBalancer(serviceName)
+		return NewRoundRobinBalancer(serviceName)
 	case "weighted_round_robin":
-		lb = NewWeightedRoundRobinBalancer(serviceName)
+		return NewWeightedRoundRobinBalancer(serviceName)
 	case "least_connections":
-		lb = NewLeastConnectionsBalancer(serviceName)
+		return NewLeastConnectionsBalancer(serviceName)
 	default:
-		lb = NewRoundRobinBalancer(serviceName)
+		return NewRoundRobinBalancer(serviceName)
 	}
-
-	f.balancers[serviceName] = lb
-	return lb
 }
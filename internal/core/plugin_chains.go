@@ -0,0 +1,87 @@
+package core
+
+import (
+	"fmt"
+
+	"gateway.example/go-gateway/internal/config"
+	"gateway.example/go-gateway/internal/plugin"
+	pl_ratelimit "gateway.example/go-gateway/internal/plugin/ratelimit"
+	pl_requestid "gateway.example/go-gateway/internal/plugin/requestid"
+)
+
+// pluginChains 启动时预先合并好的插件链
+type pluginChains struct {
+	preRouting []config.PluginSpec                         // 路由匹配之前执行
+	routes     map[*config.RouteConfig][]config.PluginSpec // 全局 post_routing 插件与路由插件合并排序后的结果
+	responses  map[*config.RouteConfig][]config.PluginSpec // 响应阶段按此列表逆序执行，覆盖请求阶段执行过的全部插件
+}
+
+func newPluginChains(cfg *config.GatewayConfig) *pluginChains {
+	// 请求 ID 插件不参与排序，始终最先执行，保证后续插件的日志都带有请求 ID
+	var preRouting []config.PluginSpec
+	if cfg.RequestID.Enabled {
+		preRouting = append(preRouting, config.PluginSpec{
+			"name":               pl_requestid.PluginName,
+			"header":             cfg.RequestID.Header,
+			"correlation_header": cfg.RequestID.CorrelationHeader,
+		})
+	}
+	preRouting = append(preRouting, plugin.MergeChains(cfg.Plugins.PreRouting)...)
+
+	c := &pluginChains{
+		preRouting: preRouting,
+		routes:     make(map[*config.RouteConfig][]config.PluginSpec, len(cfg.Routes)),
+		responses:  make(map[*config.RouteConfig][]config.PluginSpec, len(cfg.Routes)),
+	}
+	for _, route := range cfg.Routes {
+		chain := plugin.MergeChains(cfg.Plugins.PostRouting, route.Plugins)
+		c.routes[route] = chain
+		c.responses[route] = append(append([]config.PluginSpec(nil), preRouting...), chain...)
+	}
+	return c
+}
+
+// forRoute 返回路由匹配后要执行的插件链
+func (c *pluginChains) forRoute(route *config.RouteConfig) []config.PluginSpec {
+	if chain, ok := c.routes[route]; ok {
+		return chain
+	}
+	return route.Plugins
+}
+
+// forResponse 返回响应阶段的插件链
+func (c *pluginChains) forResponse(route *config.RouteConfig) []config.PluginSpec {
+	if chain, ok := c.responses[route]; ok {
+		return chain
+	}
+	return append(append([]config.PluginSpec(nil), c.preRouting...), route.Plugins...)
+}
+
+// validateOptions 让配置校验能够检查插件是否已注册、限流插件引用的规则是否已定义
+func validateOptions(pm *plugin.Manager) config.ValidateOptions {
+	return config.ValidateOptions{
+		PluginExists: func(name string) bool { return pm.GetPlugin(name) != nil },
+		RateLimitRules: func(spec config.PluginSpec) []string {
+			if name, _ := spec["name"].(string); name != pl_ratelimit.PluginName {
+				return nil
+			}
+			return pl_ratelimit.ReferencedRules(spec)
+		},
+	}
+}
+
+// validatePluginConfigs 在启动时校验全局插件链与每条路由的插件配置
+func validatePluginConfigs(pm *plugin.Manager, cfg *config.GatewayConfig) error {
+	if err := pm.ValidateChain(cfg.Plugins.PreRouting); err != nil {
+		return fmt.Errorf("全局 pre_routing 插件: %w", err)
+	}
+	if err := pm.ValidateChain(cfg.Plugins.PostRouting); err != nil {
+		return fmt.Errorf("全局 post_routing 插件: %w", err)
+	}
+	for _, route := range cfg.Routes {
+		if err := pm.ValidateChain(route.Plugins); err != nil {
+			return fmt.Errorf("路由 '%s': %w", route.PathPrefix, err)
+		}
+	}
+	return nil
+}
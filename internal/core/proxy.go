@@ -8,10 +8,17 @@ import (
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"gateway.example/go-gateway/internal/cache"
 	"gateway.example/go-gateway/internal/config"
 	"gateway.example/go-gateway/internal/core/health"
 	"gateway.example/go-gateway/internal/core/loadbalancer"
+	"gateway.example/go-gateway/internal/plugin"
+	"gateway.example/go-gateway/internal/response"
 	"gateway.example/go-gateway/internal/service/circuitbreaker"
 	"gateway.example/go-gateway/pkg/logger"
 )
@@ -20,8 +27,18 @@ import (
 type Proxy struct {
 	lbFactory         *loadbalancer.LoadBalancerFactory
 	healthChecker     *health.HealthChecker
-	circuitBreakerSvc circuitbreaker.Service // 添加熔断器服务依赖
-	logger            logger.Logger          // 添加日志器
+	circuitBreakerSvc circuitbreaker.Service       // 添加熔断器服务依赖
+	pluginManager     *plugin.Manager              // 插件管理器，用于响应阶段的处理
+	routing           atomic.Pointer[routingState] // 当前的路由表、插件链与按服务的传输层，重新加载时整体替换
+	trustedProxies    trustedProxies               // 可信代理网段，决定是否信任传入的转发头
+	transport         *http.Transport              // 所有上游共享的传输层（连接池）
+	responseCache     *responseCache               // 响应缓存，为 nil 时不缓存
+	streamCtx         context.Context              // 网关关闭时取消，用于结束进行中的流式响应
+	stopStreams       context.CancelFunc           // 取消 streamCtx
+	mu                sync.RWMutex
+	proxies           map[string]*httputil.ReverseProxy // "代数|服务名|实例URL" -> 反向代理，按实例复用
+	bufferPools       map[int]*bufferPool               // 缓冲区大小 -> 复制响应体使用的缓冲池
+	logger            logger.Logger                     // 添加日志器
 }
 
 type responseWriterWrapper struct {
@@ -29,27 +46,105 @@ type responseWriterWrapper struct {
 	statusCode int
 }
 
+// proxyContextKey 用于在请求 context 中传递本次转发的路由信息
+type proxyContextKey struct{}
+
+// proxyRequestInfo 是按实例复用的反向代理在处理单个请求时所需的上下文
+type proxyRequestInfo struct {
+	routing  *routingState // 请求入口处取得的路由状态
+	route    *config.RouteConfig
+	service  *config.ServiceConfig
+	clientIP string
+	// instanceURL 本次选中的上游实例
+	instanceURL string
+	// proxyErr 由 ErrorHandler 写入，非空表示失败已被记录
+	proxyErr error
+	// cacheKey 非空表示本次响应可写入响应缓存
+	cacheKey string
+}
+
 // NewProxy 创建一个新的 Proxy 实例。
-func NewProxy(lbFactory *loadbalancer.LoadBalancerFactory, hc *health.HealthChecker, cbSvc circuitbreaker.Service, log logger.Logger) *Proxy {
-	return &Proxy{
+func NewProxy(cfg *config.GatewayConfig, lbFactory *loadbalancer.LoadBalancerFactory, hc *health.HealthChecker, cbSvc circuitbreaker.Service, pm *plugin.Manager, store cache.Cache, log logger.Logger) (*Proxy, error) {
+	trusted, err := parseTrustedProxies(cfg.Server.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+	transport := newTransport(cfg.Transport)
+
+	routing, err := newRoutingState(cfg, transport, 1, log)
+	if err != nil {
+		return nil, err
+	}
+
+	streamCtx, stopStreams := context.WithCancel(context.Background())
+
+	p := &Proxy{
 		lbFactory:         lbFactory,
 		healthChecker:     hc,
 		circuitBreakerSvc: cbSvc,
+		pluginManager:     pm,
+		trustedProxies:    trusted,
+		transport:         transport,
+		responseCache:     newResponseCache(store, log),
+		streamCtx:         streamCtx,
+		stopStreams:       stopStreams,
+		proxies:           make(map[string]*httputil.ReverseProxy),
+		bufferPools:       make(map[int]*bufferPool),
 		logger:            log,
 	}
+	p.routing.Store(routing)
+	return p, nil
 }
 
-// ServeHTTP 执行反向代理的核心逻辑。
-func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request, route *config.RouteConfig, service *config.ServiceConfig) {
+// currentRouting 返回当前的路由状态，调用方应在一次请求中只取一次
+func (p *Proxy) currentRouting() *routingState {
+	return p.routing.Load()
+}
+
+// swapRouting 替换路由状态并清空按实例缓存的反向代理，使新请求使用新配置的传输层
+func (p *Proxy) swapRouting(rs *routingState) {
+	old := p.routing.Swap(rs)
+	p.mu.Lock()
+	p.proxies = make(map[string]*httputil.ReverseProxy)
+	p.mu.Unlock()
+	old.closeIdleConnections(p.transport)
+}
+
+// ServeHTTP 执行反向代理的核心逻辑。rs 是请求入口处取得的路由状态，route 与 service 都来自它
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request, rs *routingState, route *config.RouteConfig, service *config.ServiceConfig) {
 	ctx := r.Context()
 
 	// 推荐实践: 在使用指针前进行 nil 检查，增强代码健壮性。
 	if service == nil {
 		p.logger.Error(ctx, "[Proxy] 内部错误: 服务配置为 nil", "route", route.PathPrefix)
-		http.Error(w, "网关内部配置错误", http.StatusInternalServerError)
+		response.WriteError(w, http.StatusInternalServerError, "网关内部配置错误")
 		return
 	}
 
+	// 命中响应缓存时直接返回，不访问上游
+	var key string
+	if p.responseCache != nil && !route.Streaming {
+		key = cacheKey(r, route.Cache)
+	}
+	if key != "" {
+		if entry := p.responseCache.lookup(ctx, key); entry != nil {
+			p.logger.Info(ctx, "[Proxy] 命中响应缓存", "service", service.Name, "path", r.URL.Path)
+			p.responseCache.serve(w, r, entry, route)
+			return
+		}
+	}
+
+	// 舱壁: 服务在途请求数达到上限时快速失败，避免慢上游占满网关资源
+	if p.circuitBreakerSvc != nil {
+		release, ok := p.circuitBreakerSvc.AcquireBulkhead(ctx, service.Name)
+		if !ok {
+			p.logger.Warn(ctx, "[Proxy] 服务并发请求数已达上限，请求被拒绝", "service", service.Name)
+			response.WriteError(w, http.StatusServiceUnavailable, fmt.Sprintf("服务 '%s' 繁忙，请稍后重试", service.Name))
+			return
+		}
+		defer release()
+	}
+
 	// 1. 获取该服务对应的负载均衡器
 	lb := p.lbFactory.GetOrCreateLoadBalancer(
 		service.Name,
@@ -60,59 +155,235 @@ func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request, route *config.
 	instance, err := p.getHealthyInstance(ctx, lb, service.Name)
 	if err != nil {
 		p.logger.Error(ctx, "[Proxy] 错误: 服务无可用实例", "service", service.Name, "error", err)
-		http.Error(w, fmt.Sprintf("服务 '%s' 当前不可用", service.Name), http.StatusServiceUnavailable)
+		response.WriteError(w, http.StatusServiceUnavailable, fmt.Sprintf("服务 '%s' 当前不可用", service.Name))
 		return
 	}
 	p.logger.Info(ctx, "[Proxy] 信息: 为服务选择健康实例", "service", service.Name, "instance", instance.URL)
 
-	// 3. 创建反向代理
-	targetURL, err := url.Parse(instance.URL)
-	if err != nil {
-		p.logger.Error(ctx, "[Proxy] 内部错误: 解析实例URL失败", "instance_url", instance.URL, "error", err)
-		http.Error(w, "网关内部错误", http.StatusInternalServerError)
-		return
+	// 3. 获取该实例的反向代理（首次使用时创建，之后复用），转码路由直接调用上游 gRPC 方法
+	tc := rs.transcoders[route]
+	var proxy *httputil.ReverseProxy
+	if tc == nil {
+		proxy, err = p.getReverseProxy(rs, service, instance.URL, route)
+		if err != nil {
+			p.logger.Error(ctx, "[Proxy] 内部错误: 解析实例URL失败", "instance_url", instance.URL, "error", err)
+			response.WriteError(w, http.StatusInternalServerError, "网关内部错误")
+			return
+		}
 	}
-	proxy := httputil.NewSingleHostReverseProxy(targetURL)
 
-	// 4. 设置 director 来重写请求
-	originalDirector := proxy.Director
-	proxy.Director = func(req *http.Request) {
-		originalDirector(req) // 执行默认的 host, scheme 等重写
-
-		// 新增: 路径重写逻辑 - 移除路由前缀
-		originalPath := req.URL.Path
-		if len(route.PathPrefix) > 0 && len(originalPath) >= len(route.PathPrefix) {
-			// 移除路径前缀，保留剩余部分
-			newPath := originalPath[len(route.PathPrefix):]
-			if newPath == "" {
-				newPath = "/"
-			}
-			req.URL.Path = newPath
-			p.logger.Info(req.Context(), "[Proxy] 路径重写", "original_path", originalPath, "new_path", newPath)
+	// 流式路由与协议升级后的长连接不受服务器 WriteTimeout 限制
+	if route.Streaming || upgradeProtocol(r) != "" {
+		if err := disableWriteDeadline(w); err != nil {
+			p.logger.Warn(ctx, "[Proxy] 无法取消流式路由的写超时", "route", route.PathPrefix, "error", err)
 		}
+	}
 
-		req.Header.Set("X-Gateway-Proxy", "true")
-		// 可以在此处添加更多基于路由或服务配置的头操作
+	// 4. 设置上游整体超时，超时后由 ErrorHandler 返回 504
+	if timeout := upstreamTimeout(route, service); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
 	}
 
-	// 5. 使用 responseWriterWrapper 捕获响应状态码
+	// 5. 通过 context 把路由信息交给共享的 director
+	info := &proxyRequestInfo{
+		routing:     rs,
+		route:       route,
+		service:     service,
+		clientIP:    p.trustedProxies.clientIP(r),
+		instanceURL: instance.URL,
+		cacheKey:    key,
+	}
+	r = r.WithContext(context.WithValue(ctx, proxyContextKey{}, info))
+
+	if tc != nil {
+		p.serveTranscoded(w, r, tc, info)
+		return
+	}
+
+	// 6. 使用 responseWriterWrapper 捕获响应状态码
 	wrapper := &responseWriterWrapper{
 		ResponseWriter: w,
 		statusCode:     0,
 	}
 
-	// 6. 执行代理
+	// 7. 执行代理
 	proxy.ServeHTTP(wrapper, r)
 
-	// 7. 根据响应状态码更新熔断器状态
-	// 判断请求是否成功（2xx 状态码视为成功，其他视为失败）
+	// 转发失败已由 ErrorHandler 记录到熔断器与被动健康检查
+	if info.proxyErr != nil {
+		return
+	}
+
+	// 8. 根据响应状态码更新熔断器状态
+	if p.circuitBreakerSvc == nil {
+		return
+	}
+	// 判断请求是否成功（按服务的 failure_statuses 分类，默认只有 5xx 视为失败，客户端错误不影响熔断）
 	statusCode := wrapper.GetStatusCode()
-	success := statusCode >= 200 && statusCode < 300
+	success := !p.circuitBreakerSvc.IsFailure(service.Name, statusCode)
 
-	if p.circuitBreakerSvc != nil {
-		p.logger.Info(ctx, "[Proxy] 服务请求完成", "service", service.Name, "status_code", statusCode, "success", success)
-		p.circuitBreakerSvc.RecordResult(ctx, service.Name, success)
+	// gRPC 请求的 HTTP 状态码通常为 200，真实结果在 grpc-status trailer 中
+	if success && isGRPCRequest(r) {
+		if code, ok := grpcStatus(wrapper.Header()); ok {
+			success = !isGRPCServerFailure(code)
+			p.logger.Info(ctx, "[Proxy] gRPC 请求完成", "service", service.Name, "grpc_status", code)
+		}
+	}
+
+	p.logger.Info(ctx, "[Proxy] 服务请求完成", "service", service.Name, "status_code", statusCode, "success", success)
+	p.recordResult(ctx, info, success)
+}
+
+// recordResult 把请求结果记录到服务级熔断器与所选实例的熔断器
+func (p *Proxy) recordResult(ctx context.Context, info *proxyRequestInfo, success bool) {
+	if p.circuitBreakerSvc == nil {
+		return
+	}
+	p.circuitBreakerSvc.RecordResult(ctx, info.service.Name, success)
+	p.circuitBreakerSvc.RecordInstanceResult(ctx, info.service.Name, info.instanceURL, success)
+}
+
+// upstreamTimeout 返回本次请求的上游整体超时，路由配置优先于服务配置
+// 流式路由只使用路由上显式配置的超时
+func upstreamTimeout(route *config.RouteConfig, service *config.ServiceConfig) time.Duration {
+	if route.Timeout > 0 || route.Streaming {
+		return route.Timeout
+	}
+	return service.Timeouts.Request
+}
+
+// getReverseProxy 返回指定实例的反向代理，不存在时创建并缓存
+// 流式路由和自定义缓冲区大小的路由使用独立的代理实例
+func (p *Proxy) getReverseProxy(rs *routingState, service *config.ServiceConfig, instanceURL string, route *config.RouteConfig) (*httputil.ReverseProxy, error) {
+	streaming := route.Streaming
+	key := strconv.FormatUint(rs.generation, 10) + "|" + service.Name + "|" + instanceURL
+	if streaming {
+		key += "|stream"
+	}
+	if route.BufferSize > 0 {
+		key += "|buf=" + route.BufferSize.String()
+	}
+
+	p.mu.RLock()
+	proxy, exists := p.proxies[key]
+	p.mu.RUnlock()
+	if exists {
+		return proxy, nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// 再次检查，防止其他协程已经创建了
+	if proxy, exists := p.proxies[key]; exists {
+		return proxy, nil
+	}
+
+	targetURL, err := url.Parse(instanceURL)
+	if err != nil {
+		return nil, err
+	}
+
+	proxy = httputil.NewSingleHostReverseProxy(targetURL)
+	proxy.Transport = rs.transportFor(service.Name, p.transport)
+	if usesHTTP2(service) || streaming {
+		// gRPC 与流式响应需要立即刷新，不能在网关中缓冲
+		proxy.FlushInterval = -1
+	}
+	if size := int(route.BufferSize); size > 0 {
+		pool, ok := p.bufferPools[size]
+		if !ok {
+			pool = newBufferPool(size)
+			p.bufferPools[size] = pool
+		}
+		proxy.BufferPool = pool
+	}
+
+	originalDirector := proxy.Director
+	proxy.Director = func(req *http.Request) {
+		originalDirector(req) // 执行默认的 host, scheme 等重写
+		p.rewriteRequest(req)
+	}
+	proxy.ModifyResponse = p.modifyResponse
+	proxy.ErrorHandler = p.handleProxyError
+
+	p.proxies[key] = proxy
+	p.logger.Info(context.Background(), "[Proxy] 已为实例创建反向代理", "service", service.Name, "instance", instanceURL)
+	return proxy, nil
+}
+
+// rewriteRequest 根据请求 context 中的路由信息重写出站请求
+func (p *Proxy) rewriteRequest(req *http.Request) {
+	info, _ := req.Context().Value(proxyContextKey{}).(*proxyRequestInfo)
+	if info == nil {
+		return
 	}
+	route := info.route
+
+	// 路径重写逻辑 - 移除路由前缀
+	originalPath := req.URL.Path
+	if len(route.PathPrefix) > 0 && len(originalPath) >= len(route.PathPrefix) {
+		// 移除路径前缀，保留剩余部分
+		newPath := originalPath[len(route.PathPrefix):]
+		if newPath == "" {
+			newPath = "/"
+		}
+		req.URL.Path = newPath
+		req.URL.RawPath = ""
+		p.logger.Info(req.Context(), "[Proxy] 路径重写", "original_path", originalPath, "new_path", newPath)
+	}
+
+	req.Header.Set("X-Gateway-Proxy", "true")
+	p.trustedProxies.setForwardedHeaders(req, info.clientIP)
+	applyHeaderRules(req.Header, route.RequestHeaders, req, info)
+	p.signRequest(req, info.service)
+}
+
+// modifyResponse 在上游响应返回给客户端之前对其进行改写
+func (p *Proxy) modifyResponse(resp *http.Response) error {
+	info, _ := resp.Request.Context().Value(proxyContextKey{}).(*proxyRequestInfo)
+	if info == nil {
+		return nil
+	}
+	// 协议升级响应的 Body 是双向连接，不能被包装
+	if resp.StatusCode == http.StatusSwitchingProtocols {
+		applyHeaderRules(resp.Header, info.route.ResponseHeaders, resp.Request, info)
+		return nil
+	}
+	if err := p.limitResponseBody(resp, info); err != nil {
+		return err
+	}
+	if p.pluginManager != nil {
+		if err := p.pluginManager.TransformResponseBody(resp, info.routing.plugins.forRoute(info.route), int64(info.route.MaxTransformBodySize)); err != nil {
+			return &modifyResponseError{err: err}
+		}
+		// 响应阶段插件链
+		if err := p.pluginManager.ExecuteResponseChain(resp, info.route, info.routing.plugins.forResponse(info.route)); err != nil {
+			return &modifyResponseError{err: err}
+		}
+	}
+	applyHeaderRules(resp.Header, info.route.ResponseHeaders, resp.Request, info)
+	// 在压缩之前缓存原始响应，命中时再按客户端的 Accept-Encoding 压缩
+	if info.cacheKey != "" {
+		p.responseCache.capture(resp, info.cacheKey, info.route.Cache)
+	}
+	compressResponse(resp, info.route.Compression)
+
+	// 流式响应在网关关闭时需要被主动结束，否则优雅关闭会一直等待长连接
+	if info.route.Streaming || isEventStream(resp.Header) {
+		resp.Body = newStoppableBody(p.streamCtx, resp.Body)
+	}
+	return nil
+}
+
+// PurgeCache 清除路径以 pathPrefix 开头的缓存响应，返回清除的条目数
+func (p *Proxy) PurgeCache(ctx context.Context, pathPrefix string) (int, error) {
+	if p.responseCache == nil {
+		return 0, nil
+	}
+	return p.responseCache.purge(ctx, pathPrefix)
 }
 
 // getHealthyInstance 封装了"获取下一个健康实例"的逻辑
@@ -130,11 +401,16 @@ func (p *Proxy) getHealthyInstance(ctx context.Context, lb loadbalancer.LoadBala
 			return nil, err // 负载均衡器内部错误
 		}
 
-		if p.healthChecker.IsInstanceHealthy(serviceName, instance.URL) {
-			return instance, nil // 找到健康的实例，立即返回
+		if !p.healthChecker.IsInstanceHealthy(serviceName, instance.URL) {
+			p.logger.Warn(ctx, "[Proxy] 警告: 跳过不健康的实例", "instance", instance.URL, "service", serviceName)
+			continue
 		}
-
-		p.logger.Warn(ctx, "[Proxy] 警告: 跳过不健康的实例", "instance", instance.URL, "service", serviceName)
+		// 实例级熔断器打开时同样跳过该实例
+		if p.circuitBreakerSvc != nil && !p.circuitBreakerSvc.AllowInstance(ctx, serviceName, instance.URL) {
+			p.logger.Warn(ctx, "[Proxy] 警告: 跳过已熔断的实例", "instance", instance.URL, "service", serviceName)
+			continue
+		}
+		return instance, nil // 找到可用的实例，立即返回
 	}
 
 	return nil, fmt.Errorf("在所有实例中未找到健康的实例")
@@ -142,9 +418,18 @@ func (p *Proxy) getHealthyInstance(ctx context.Context, lb loadbalancer.LoadBala
 
 func (w *responseWriterWrapper) WriteHeader(statusCode int) {
 	w.statusCode = statusCode
+	// 未标记为流式的路由返回了 SSE，也需要解除写超时
+	if isEventStream(w.Header()) {
+		_ = disableWriteDeadline(w.ResponseWriter)
+	}
 	w.ResponseWriter.WriteHeader(statusCode)
 }
 
+// Unwrap 暴露底层 ResponseWriter，使 http.ResponseController 能够调用 Flush 等能力
+func (w *responseWriterWrapper) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
 func (w *responseWriterWrapper) GetStatusCode() int {
 	if w.statusCode == 0 {
 		// 如果没有显式设置状态码，默认认为是 200 OK
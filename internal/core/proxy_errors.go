@@ -0,0 +1,98 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+
+	"gateway.example/go-gateway/internal/response"
+)
+
+// statusClientClosedRequest 客户端在上游响应前断开连接 (沿用 nginx 的 499 约定)
+const statusClientClosedRequest = 499
+
+// modifyResponseError 标记由网关自身在改写响应时产生的错误，这类错误不应归咎于上游实例
+type modifyResponseError struct {
+	err error
+}
+
+func (e *modifyResponseError) Error() string {
+	return e.err.Error()
+}
+
+func (e *modifyResponseError) Unwrap() error {
+	return e.err
+}
+
+// handleProxyError 是反向代理的 ErrorHandler
+// 记录结构化错误日志，以统一 JSON 格式返回错误，并把失败反馈给被动健康检查和熔断器
+func (p *Proxy) handleProxyError(w http.ResponseWriter, r *http.Request, err error) {
+	ctx := r.Context()
+	info, _ := ctx.Value(proxyContextKey{}).(*proxyRequestInfo)
+	if info != nil {
+		info.proxyErr = err
+	}
+
+	var serviceName, instanceURL string
+	if info != nil {
+		serviceName, instanceURL = info.service.Name, info.instanceURL
+	}
+
+	// 客户端主动取消，不属于上游故障
+	if errors.Is(err, context.Canceled) {
+		p.logger.Info(ctx, "[Proxy] 客户端已断开连接", "service", serviceName, "instance", instanceURL, "path", r.URL.Path)
+		w.WriteHeader(statusClientClosedRequest)
+		return
+	}
+
+	// 响应体超限属于上游行为异常，计入熔断统计但不摘除实例
+	var tooLarge *responseTooLargeError
+	if errors.As(err, &tooLarge) {
+		p.logger.Error(ctx, "[Proxy] 上游响应体超过限制", "service", serviceName, "instance", instanceURL, "path", r.URL.Path, "limit", tooLarge.limit)
+		if info != nil {
+			p.recordResult(ctx, info, false)
+		}
+		response.WriteError(w, http.StatusBadGateway, tooLarge.Error())
+		return
+	}
+
+	// 客户端请求体在转发过程中超过插件设置的上限
+	var tooLargeBody *http.MaxBytesError
+	if errors.As(err, &tooLargeBody) {
+		p.logger.Info(ctx, "[Proxy] 请求体超过限制", "service", serviceName, "path", r.URL.Path, "limit", tooLargeBody.Limit)
+		response.WriteError(w, http.StatusRequestEntityTooLarge, "请求体过大")
+		return
+	}
+
+	var mre *modifyResponseError
+	if errors.As(err, &mre) {
+		p.logger.Error(ctx, "[Proxy] 改写上游响应失败", "service", serviceName, "instance", instanceURL, "path", r.URL.Path, "error", mre.err)
+		response.WriteError(w, http.StatusBadGateway, "网关处理上游响应失败")
+		return
+	}
+
+	status := http.StatusBadGateway
+	message := "上游服务不可达"
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		status = http.StatusGatewayTimeout
+		message = "上游服务响应超时"
+	}
+
+	p.logger.Error(ctx, "[Proxy] 转发请求到上游失败",
+		"service", serviceName,
+		"instance", instanceURL,
+		"method", r.Method,
+		"path", r.URL.Path,
+		"status_code", status,
+		"error", err)
+
+	if info != nil {
+		// 被动健康检查: 立即把实例摘除，等待下一轮主动检查恢复
+		p.healthChecker.MarkInstanceUnhealthy(ctx, serviceName, instanceURL)
+		p.recordResult(ctx, info, false)
+	}
+
+	response.WriteError(w, status, message)
+}
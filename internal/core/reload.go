@@ -0,0 +1,186 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"gateway.example/go-gateway/internal/config"
+	"gateway.example/go-gateway/internal/core/loadbalancer"
+)
+
+// serviceAwarePlugin 持有服务列表用于校验配置的插件 (如 ab_test、circuitbreaker)，重新加载时同步新的服务列表
+type serviceAwarePlugin interface {
+	SetServices(services map[string]config.ServiceConfig)
+}
+
+// Reload 在不重启的情况下应用新配置中的 routes、services、插件链与限流规则。
+// 新配置先经过整体校验与插件校验，任一步失败都回滚到原有配置并返回错误；
+// 通过后原子替换路由状态，进行中的请求继续使用旧配置完成。
+// server、transport、cache、admin 等其余部分在启动时生效，修改后只记录警告，需要重启网关
+func (g *Gateway) Reload(cfg *config.GatewayConfig) error {
+	g.reloadMu.Lock()
+	defer g.reloadMu.Unlock()
+
+	ctx := context.Background()
+	current := g.proxy.currentRouting()
+
+	if err := g.ValidateConfig(cfg); err != nil {
+		return err
+	}
+	g.setPluginServices(cfg.Services)
+	rollback := func() { g.setPluginServices(current.config.Services) }
+	if err := validatePluginConfigs(g.pluginManager, cfg); err != nil {
+		rollback()
+		return fmt.Errorf("插件配置校验失败: %w", err)
+	}
+	next, err := newRoutingState(cfg, g.proxy.transport, current.generation+1, g.logger)
+	if err != nil {
+		rollback()
+		return fmt.Errorf("构建路由失败: %w", err)
+	}
+	// 限流器重建后从空状态开始计数，规则没有变化时保留原有限流器
+	if !reflect.DeepEqual(cfg.RateLimiting.Rules, current.config.RateLimiting.Rules) {
+		if err := g.rateLimitSvc.Reload(cfg.RateLimiting); err != nil {
+			rollback()
+			return fmt.Errorf("重新加载限流规则失败: %w", err)
+		}
+	}
+
+	g.applyServices(current.config.Services, cfg.Services)
+	g.proxy.swapRouting(next)
+
+	diff := diffConfig(current.config, cfg)
+	g.logger.Info(ctx, "配置已重新加载",
+		"generation", next.generation,
+		"routes_added", diff.routesAdded,
+		"routes_removed", diff.routesRemoved,
+		"routes_changed", diff.routesChanged,
+		"services_added", diff.servicesAdded,
+		"services_removed", diff.servicesRemoved,
+		"services_changed", diff.servicesChanged,
+		"global_plugins_changed", diff.globalPluginsChanged,
+		"rate_limit_rules_changed", diff.rulesChanged)
+	if restart := restartRequired(g.config, cfg); len(restart) > 0 {
+		g.logger.Warn(ctx, "以下配置的修改需要重启网关才能生效", "sections", restart)
+	}
+	return nil
+}
+
+func (g *Gateway) setPluginServices(services map[string]config.ServiceConfig) {
+	for _, p := range g.serviceAware {
+		p.SetServices(services)
+	}
+}
+
+// applyServices 按新的服务列表更新负载均衡器与健康检查，实例、算法与健康检查路径都没变的服务保持原状
+func (g *Gateway) applyServices(old, next map[string]config.ServiceConfig) {
+	for key, svc := range old {
+		if _, ok := next[key]; !ok {
+			g.lbFactory.Remove(svc.Name)
+			g.healthChecker.UnregisterService(svc.Name)
+		}
+	}
+	for key, svc := range next {
+		prev, ok := old[key]
+		if ok && prev.Name == svc.Name && prev.LoadBalancer == svc.LoadBalancer &&
+			prev.HealthCheckPath == svc.HealthCheckPath && reflect.DeepEqual(prev.Instances, svc.Instances) {
+			continue
+		}
+		urls := make([]string, 0, len(svc.Instances))
+		instances := make([]*loadbalancer.ServiceInstance, 0, len(svc.Instances))
+		for _, inst := range svc.Instances {
+			urls = append(urls, inst.URL)
+			instances = append(instances, &loadbalancer.ServiceInstance{URL: inst.URL, Weight: inst.Weight, Alive: true})
+		}
+		g.healthChecker.RegisterService(svc.Name, urls, svc.HealthCheckPath)
+		g.lbFactory.Replace(svc.Name, svc.LoadBalancer, instances)
+	}
+}
+
+// configDiff 两份配置在可重新加载部分的差异，用于重新加载后的日志
+type configDiff struct {
+	routesAdded, routesRemoved, routesChanged       []string // 按 path_prefix
+	servicesAdded, servicesRemoved, servicesChanged []string
+	globalPluginsChanged                            bool
+	rulesChanged                                    []string // 新增、删除或修改的限流规则
+}
+
+func diffConfig(old, next *config.GatewayConfig) configDiff {
+	var d configDiff
+
+	oldRoutes := make(map[string]*config.RouteConfig, len(old.Routes))
+	for _, r := range old.Routes {
+		oldRoutes[r.PathPrefix] = r
+	}
+	nextRoutes := make(map[string]*config.RouteConfig, len(next.Routes))
+	for _, r := range next.Routes {
+		nextRoutes[r.PathPrefix] = r
+	}
+	d.routesAdded, d.routesRemoved, d.routesChanged = diffKeys(oldRoutes, nextRoutes)
+	d.servicesAdded, d.servicesRemoved, d.servicesChanged = diffKeys(old.Services, next.Services)
+	d.globalPluginsChanged = !reflect.DeepEqual(old.Plugins, next.Plugins)
+
+	oldRules := make(map[string]config.RateLimiterRule, len(old.RateLimiting.Rules))
+	for _, r := range old.RateLimiting.Rules {
+		oldRules[r.Name] = r
+	}
+	nextRules := make(map[string]config.RateLimiterRule, len(next.RateLimiting.Rules))
+	for _, r := range next.RateLimiting.Rules {
+		nextRules[r.Name] = r
+	}
+	added, removed, changed := diffKeys(oldRules, nextRules)
+	d.rulesChanged = append(append(added, removed...), changed...)
+	sort.Strings(d.rulesChanged)
+	return d
+}
+
+// diffKeys 比较两个 map，返回排序后的新增、删除与值发生变化的键
+func diffKeys[V any](old, next map[string]V) (added, removed, changed []string) {
+	for k, v := range next {
+		prev, ok := old[k]
+		switch {
+		case !ok:
+			added = append(added, k)
+		case !reflect.DeepEqual(prev, v):
+			changed = append(changed, k)
+		}
+	}
+	for k := range old {
+		if _, ok := next[k]; !ok {
+			removed = append(removed, k)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	sort.Strings(changed)
+	return added, removed, changed
+}
+
+// restartRequired 返回只在启动时生效、且与启动配置不同的配置段
+func restartRequired(started, next *config.GatewayConfig) []string {
+	sections := []struct {
+		name      string
+		old, next interface{}
+	}{
+		{"server", started.Server, next.Server},
+		{"health_check", started.HealthCheck, next.HealthCheck},
+		{"transport", started.Transport, next.Transport},
+		{"cache", started.Cache, next.Cache},
+		{"admin", started.Admin, next.Admin},
+		{"jwt", started.JWT, next.JWT},
+		{"auth_service", started.AuthService, next.AuthService},
+		{"circuit_breaker", started.CircuitBreaker, next.CircuitBreaker},
+		{"quota", started.Quota, next.Quota},
+		{"external_plugins", started.ExternalPlugins, next.ExternalPlugins},
+		{"config", started.ConfigSource, next.ConfigSource},
+	}
+	var changed []string
+	for _, s := range sections {
+		if !reflect.DeepEqual(s.old, s.next) {
+			changed = append(changed, s.name)
+		}
+	}
+	return changed
+}
@@ -0,0 +1,294 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gateway.example/go-gateway/internal/cache"
+	"gateway.example/go-gateway/internal/config"
+	"gateway.example/go-gateway/pkg/logger"
+)
+
+// 响应缓存相关常量
+const (
+	responseCacheKeyPrefix        = "resp:"
+	defaultResponseCacheBodyLimit = 1 << 20
+	cacheStatusHeader             = "X-Cache"
+)
+
+// cacheableStatusCodes 默认可缓存的响应状态码 (RFC 9111 中可启发式缓存的状态码子集)
+var cacheableStatusCodes = []int{
+	http.StatusOK,
+	http.StatusNonAuthoritativeInfo,
+	http.StatusNoContent,
+	http.StatusMovedPermanently,
+	http.StatusNotFound,
+	http.StatusGone,
+}
+
+// cachedResponse 缓存中保存的一条上游响应
+type cachedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+	StoredAt   time.Time   `json:"stored_at"`
+}
+
+// responseCache 在代理层为只读接口缓存上游响应
+type responseCache struct {
+	store  cache.Cache
+	logger logger.Logger
+}
+
+func newResponseCache(store cache.Cache, log logger.Logger) *responseCache {
+	if store == nil {
+		return nil
+	}
+	return &responseCache{store: store, logger: log}
+}
+
+// cacheKey 计算请求的缓存键，不可缓存时返回空串
+// 键以原始请求路径开头，便于按路径前缀清除
+func cacheKey(r *http.Request, cfg *config.RouteCacheConfig) string {
+	if cfg == nil || !cfg.Enabled {
+		return ""
+	}
+	methods := cfg.Methods
+	if len(methods) == 0 {
+		methods = []string{http.MethodGet, http.MethodHead}
+	}
+	if !slices.ContainsFunc(methods, func(m string) bool { return strings.EqualFold(m, r.Method) }) {
+		return ""
+	}
+	if upgradeProtocol(r) != "" {
+		return ""
+	}
+	if directives := parseCacheControl(r.Header.Get("Cache-Control")); hasDirective(directives, "no-store") {
+		return ""
+	}
+	// 带凭证的请求默认不缓存，避免把用户私有数据返回给其他人
+	if r.Header.Get("Authorization") != "" && !slices.ContainsFunc(cfg.Vary, func(h string) bool { return strings.EqualFold(h, "Authorization") }) {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(responseCacheKeyPrefix)
+	b.WriteString(r.URL.Path)
+	if r.URL.RawQuery != "" {
+		b.WriteString("?")
+		b.WriteString(r.URL.RawQuery)
+	}
+	b.WriteString("|")
+	b.WriteString(r.Method)
+	b.WriteString("|")
+	b.WriteString(r.Host)
+	for _, h := range cfg.Vary {
+		b.WriteString("|")
+		b.WriteString(r.Header.Get(h))
+	}
+	return b.String()
+}
+
+// lookup 读取缓存的响应，未命中或读取失败时返回 nil
+func (c *responseCache) lookup(ctx context.Context, key string) *cachedResponse {
+	data, err := c.store.Get(ctx, key)
+	if err != nil {
+		if !errors.Is(err, cache.ErrNotFound) {
+			c.logger.Warn(ctx, "[Proxy] 读取响应缓存失败", "key", key, "error", err)
+		}
+		return nil
+	}
+	var entry cachedResponse
+	if err := json.Unmarshal(data, &entry); err != nil {
+		c.logger.Warn(ctx, "[Proxy] 解析响应缓存失败，已丢弃", "key", key, "error", err)
+		_ = c.store.Delete(ctx, key)
+		return nil
+	}
+	return &entry
+}
+
+// serve 把缓存的响应写回客户端，客户端携带匹配的 If-None-Match 时返回 304
+func (c *responseCache) serve(w http.ResponseWriter, r *http.Request, entry *cachedResponse, route *config.RouteConfig) {
+	header := entry.Header.Clone()
+	header.Set("Age", strconv.Itoa(int(time.Since(entry.StoredAt).Seconds())))
+	header.Set(cacheStatusHeader, "HIT")
+
+	if etag := header.Get("ETag"); etag != "" && etagMatches(r.Header.Get("If-None-Match"), etag) {
+		for k, v := range header {
+			w.Header()[k] = v
+		}
+		w.Header().Del("Content-Length")
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	// 构造响应后复用压缩逻辑，使命中的响应同样按客户端能力压缩
+	resp := &http.Response{
+		StatusCode:    entry.StatusCode,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(entry.Body)),
+		ContentLength: int64(len(entry.Body)),
+		Request:       r,
+	}
+	compressResponse(resp, route.Compression)
+
+	for k, v := range resp.Header {
+		w.Header()[k] = v
+	}
+	w.WriteHeader(resp.StatusCode)
+	if r.Method != http.MethodHead {
+		_, _ = io.Copy(w, resp.Body)
+	}
+	resp.Body.Close()
+}
+
+// capture 在响应可缓存时包装响应体，响应体完整读出后写入缓存
+func (c *responseCache) capture(resp *http.Response, key string, cfg *config.RouteCacheConfig) {
+	ttl, ok := responseTTL(resp, cfg)
+	if !ok {
+		resp.Header.Set(cacheStatusHeader, "BYPASS")
+		return
+	}
+
+	limit := int64(cfg.MaxBodySize)
+	if limit <= 0 {
+		limit = defaultResponseCacheBodyLimit
+	}
+	if resp.ContentLength > limit {
+		resp.Header.Set(cacheStatusHeader, "BYPASS")
+		return
+	}
+
+	entry := &cachedResponse{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header.Clone(),
+	}
+	entry.Header.Del(cacheStatusHeader)
+	resp.Header.Set(cacheStatusHeader, "MISS")
+
+	resp.Body = &cachingBody{
+		ReadCloser: resp.Body,
+		limit:      limit,
+		onComplete: func(body []byte) {
+			ctx := context.Background()
+			entry.Body = body
+			entry.StoredAt = time.Now()
+			data, err := json.Marshal(entry)
+			if err != nil {
+				return
+			}
+			if err := c.store.Set(ctx, key, data, ttl); err != nil {
+				c.logger.Warn(ctx, "[Proxy] 写入响应缓存失败", "key", key, "error", err)
+			}
+		},
+	}
+}
+
+// purge 清除路径以 pathPrefix 开头的缓存响应，pathPrefix 为空时清除全部
+func (c *responseCache) purge(ctx context.Context, pathPrefix string) (int, error) {
+	return c.store.DeletePrefix(ctx, responseCacheKeyPrefix+pathPrefix)
+}
+
+// responseTTL 根据上游响应头和路由配置计算缓存时长
+func responseTTL(resp *http.Response, cfg *config.RouteCacheConfig) (time.Duration, bool) {
+	if !slices.Contains(cacheableStatusCodes, resp.StatusCode) {
+		return 0, false
+	}
+	if resp.Header.Get("Set-Cookie") != "" || isEventStream(resp.Header) || resp.Header.Get("Vary") == "*" {
+		return 0, false
+	}
+	if cfg.IgnoreCacheControl {
+		return cfg.TTL, cfg.TTL > 0
+	}
+
+	directives := parseCacheControl(resp.Header.Get("Cache-Control"))
+	if hasDirective(directives, "no-store") || hasDirective(directives, "private") || hasDirective(directives, "no-cache") {
+		return 0, false
+	}
+	for _, name := range []string{"s-maxage", "max-age"} {
+		if v, ok := directives[name]; ok {
+			seconds, err := strconv.Atoi(v)
+			if err != nil || seconds <= 0 {
+				return 0, false
+			}
+			return time.Duration(seconds) * time.Second, true
+		}
+	}
+	if expires := resp.Header.Get("Expires"); expires != "" {
+		t, err := http.ParseTime(expires)
+		if err != nil || !t.After(time.Now()) {
+			return 0, false
+		}
+		return time.Until(t), true
+	}
+	return cfg.TTL, cfg.TTL > 0
+}
+
+// parseCacheControl 把 Cache-Control 头解析为 指令 -> 参数 的映射
+func parseCacheControl(value string) map[string]string {
+	directives := make(map[string]string)
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, arg, _ := strings.Cut(part, "=")
+		directives[strings.ToLower(strings.TrimSpace(name))] = strings.Trim(strings.TrimSpace(arg), `"`)
+	}
+	return directives
+}
+
+func hasDirective(directives map[string]string, name string) bool {
+	_, ok := directives[name]
+	return ok
+}
+
+// etagMatches 按弱比较规则判断 If-None-Match 是否命中
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// cachingBody 在转发响应体的同时复制一份，读到 EOF 后回调；超过上限则放弃缓存
+type cachingBody struct {
+	io.ReadCloser
+	buf        bytes.Buffer
+	limit      int64
+	overflow   bool
+	done       bool
+	onComplete func(body []byte)
+}
+
+func (b *cachingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 && !b.overflow {
+		if int64(b.buf.Len()+n) > b.limit {
+			b.overflow = true
+			b.buf = bytes.Buffer{}
+		} else {
+			b.buf.Write(p[:n])
+		}
+	}
+	if err == io.EOF && !b.overflow && !b.done {
+		b.done = true
+		b.onComplete(b.buf.Bytes())
+	}
+	return n, err
+}
@@ -0,0 +1,64 @@
+package core
+
+import (
+	"net/http"
+
+	"gateway.example/go-gateway/internal/config"
+	"gateway.example/go-gateway/internal/core/transcoder"
+	"gateway.example/go-gateway/pkg/logger"
+)
+
+// routingState 路由表以及由 routes、services、插件链派生出的全部数据。
+// 重新加载配置时整体构建新的状态再原子替换；每个请求在入口处取一次状态并一直使用它，
+// 进行中的请求不会看到新旧混合的配置
+type routingState struct {
+	generation        uint64                // 每次重新加载加一，区分按实例缓存的反向代理
+	config            *config.GatewayConfig // 只读: routes、services 与全局插件链以此为准
+	router            *Router
+	plugins           *pluginChains
+	transcoders       map[*config.RouteConfig]*transcoder.Transcoder // 路由 -> HTTP/JSON 到 gRPC 的转码器
+	serviceTransports map[string]*http.Transport                     // 服务名 -> 按服务配置构建的传输层
+}
+
+// newRoutingState 按配置构建路由状态，shared 为所有上游共享的传输层
+func newRoutingState(cfg *config.GatewayConfig, shared *http.Transport, generation uint64, log logger.Logger) (*routingState, error) {
+	serviceTransports := make(map[string]*http.Transport, len(cfg.Services))
+	for _, serviceCfg := range cfg.Services {
+		t, err := newServiceTransport(shared, cfg.Transport, serviceCfg)
+		if err != nil {
+			return nil, err
+		}
+		serviceTransports[serviceCfg.Name] = t
+	}
+
+	transcoders, err := newTranscoders(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &routingState{
+		generation:        generation,
+		config:            cfg,
+		router:            NewRouter(cfg.Routes, log),
+		plugins:           newPluginChains(cfg),
+		transcoders:       transcoders,
+		serviceTransports: serviceTransports,
+	}, nil
+}
+
+// transportFor 返回访问服务使用的传输层，服务没有独立配置时使用共享传输层
+func (rs *routingState) transportFor(service string, shared *http.Transport) *http.Transport {
+	if t, ok := rs.serviceTransports[service]; ok {
+		return t
+	}
+	return shared
+}
+
+// closeIdleConnections 关闭被替换的状态持有的空闲连接，进行中的请求不受影响
+func (rs *routingState) closeIdleConnections(shared *http.Transport) {
+	for _, t := range rs.serviceTransports {
+		if t != shared {
+			t.CloseIdleConnections()
+		}
+	}
+}
@@ -2,18 +2,24 @@ package core
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"gateway.example/go-gateway/internal/config"
 	"gateway.example/go-gateway/pkg/logger"
 )
 
 // Server 封装了 http.Server
 type Server struct {
 	httpServer *http.Server
+	certFile   string
+	keyFile    string
 	logger     logger.Logger
 }
 
@@ -31,23 +37,104 @@ func (s *Server) Shutdown(ctx context.Context) error {
 	return nil
 }
 
-func NewServer(port string, handler http.Handler, log logger.Logger) (*Server, error) {
+// 客户端证书要求
+const (
+	clientAuthNone     = "none"
+	clientAuthOptional = "optional"
+	clientAuthRequire  = "require"
+)
+
+// NewServer 根据服务器配置创建 HTTP 服务器
+// 配置了证书时使用 TLS (HTTP/1.1 + HTTP/2)，开启 enable_h2c 时额外接受明文 HTTP/2
+func NewServer(cfg config.ServerConfig, handler http.Handler, log logger.Logger) (*Server, error) {
+	if (cfg.TLSCertFile == "") != (cfg.TLSKeyFile == "") {
+		return nil, fmt.Errorf("tls_cert_file 与 tls_key_file 必须同时配置")
+	}
+	tlsCfg, err := newServerTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	if cfg.TLSCertFile != "" {
+		protocols.SetHTTP2(true)
+	}
+	if cfg.EnableH2C {
+		protocols.SetUnencryptedHTTP2(true)
+	}
+
 	srv := &http.Server{
-		Addr:         port,
+		Addr:         cfg.Port,
 		Handler:      handler,
+		Protocols:    protocols,
 		ReadTimeout:  5 * time.Second,
 		WriteTimeout: 10 * time.Second,
 		IdleTimeout:  120 * time.Second,
+		TLSConfig:    tlsCfg,
 	}
 	return &Server{
 		httpServer: srv,
+		certFile:   cfg.TLSCertFile,
+		keyFile:    cfg.TLSKeyFile,
 		logger:     log,
 	}, nil
 }
 
+// newServerTLSConfig 按 client_auth 构建校验客户端证书 (mTLS) 的 TLS 配置，不要求客户端证书时返回 nil。
+// 证书中的 SAN/CN 由 mtls 插件按路由映射为调用方身份
+func newServerTLSConfig(cfg config.ServerConfig) (*tls.Config, error) {
+	var clientAuth tls.ClientAuthType
+	switch cfg.ClientAuth {
+	case "", clientAuthNone:
+		if cfg.ClientCAFile != "" {
+			return nil, fmt.Errorf("配置了 client_ca_file 但 client_auth 为 none")
+		}
+		return nil, nil
+	case clientAuthOptional:
+		clientAuth = tls.VerifyClientCertIfGiven
+	case clientAuthRequire:
+		clientAuth = tls.RequireAndVerifyClientCert
+	default:
+		return nil, fmt.Errorf("不支持的 client_auth '%s' (可选: %s, %s, %s)", cfg.ClientAuth, clientAuthNone, clientAuthOptional, clientAuthRequire)
+	}
+	if cfg.TLSCertFile == "" {
+		return nil, fmt.Errorf("client_auth 为 %s 时必须配置 tls_cert_file 与 tls_key_file", cfg.ClientAuth)
+	}
+	if cfg.ClientCAFile == "" {
+		return nil, fmt.Errorf("client_auth 为 %s 时必须配置 client_ca_file", cfg.ClientAuth)
+	}
+	pem, err := os.ReadFile(cfg.ClientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("读取 client_ca_file 失败: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("client_ca_file '%s' 中没有有效的 PEM 证书", cfg.ClientCAFile)
+	}
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ClientAuth: clientAuth,
+		ClientCAs:  pool,
+	}, nil
+}
+
+// RegisterOnShutdown 注册在服务器开始优雅关闭时调用的函数
+func (s *Server) RegisterOnShutdown(f func()) {
+	s.httpServer.RegisterOnShutdown(f)
+}
+
 // Start 启动服务器
 func (s *Server) Start() error {
-	s.logger.Info(context.Background(), "服务器启动中...", "addr", s.httpServer.Addr)
+	if s.certFile != "" {
+		clientAuth := clientAuthNone
+		if s.httpServer.TLSConfig != nil {
+			clientAuth = s.httpServer.TLSConfig.ClientAuth.String()
+		}
+		s.logger.Info(context.Background(), "服务器启动中 (TLS)...", "addr", s.httpServer.Addr, "client_auth", clientAuth)
+		return s.httpServer.ListenAndServeTLS(s.certFile, s.keyFile)
+	}
+	s.logger.Info(context.Background(), "服务器启动中...", "addr", s.httpServer.Addr, "h2c", s.httpServer.Protocols.UnencryptedHTTP2())
 	return s.httpServer.ListenAndServe()
 }
 
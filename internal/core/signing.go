@@ -0,0 +1,73 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gateway.example/go-gateway/internal/bodybuffer"
+	"gateway.example/go-gateway/internal/config"
+	"gateway.example/go-gateway/pkg/signing"
+)
+
+// signRequest 按服务的签名配置为出站请求添加 HMAC 签名头
+// 需在所有请求改写完成之后调用，签名覆盖的是上游实际收到的方法与路径
+func (p *Proxy) signRequest(req *http.Request, service *config.ServiceConfig) {
+	cfg := service.Signing
+	if cfg == nil || cfg.Secret == "" {
+		return
+	}
+	ctx := req.Context()
+
+	bodyHash, err := requestBodyHash(req, int64(cfg.MaxBodySize))
+	if err != nil {
+		p.logger.Warn(ctx, "[Proxy] 计算请求体哈希失败，请求体不参与签名", "service", service.Name, "error", err)
+		bodyHash = signing.UnsignedPayload
+	}
+
+	header := cfg.Header
+	if header == "" {
+		header = signing.DefaultSignatureHeader
+	}
+	timestampHeader := cfg.TimestampHeader
+	if timestampHeader == "" {
+		timestampHeader = signing.DefaultTimestampHeader
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	signature := signing.Sign([]byte(cfg.Secret), signing.StringToSign(req.Method, req.URL.RequestURI(), timestamp, bodyHash))
+
+	req.Header.Set(timestampHeader, timestamp)
+	req.Header.Set(header, bodyHash+":"+signature)
+	if cfg.KeyID != "" {
+		req.Header.Set(signing.DefaultKeyIDHeader, cfg.KeyID)
+	} else {
+		req.Header.Del(signing.DefaultKeyIDHeader)
+	}
+}
+
+// requestBodyHash 计算出站请求体的哈希，不消耗请求体
+// 请求体已被缓冲时流式读取副本；否则最多缓冲 limit 字节，超过时返回 UNSIGNED-PAYLOAD
+func requestBodyHash(req *http.Request, limit int64) (string, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return signing.BodyHash(nil)
+	}
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return "", err
+		}
+		defer body.Close()
+		return signing.BodyHash(body)
+	}
+	data, err := bodybuffer.ReadAll(req, limit)
+	if errors.Is(err, bodybuffer.ErrTooLarge) {
+		return signing.UnsignedPayload, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return signing.BodyHash(bytes.NewReader(data))
+}
@@ -0,0 +1,57 @@
+package core
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// isEventStream 判断响应是否为 Server-Sent Events 流
+func isEventStream(h http.Header) bool {
+	return strings.HasPrefix(strings.ToLower(h.Get("Content-Type")), "text/event-stream")
+}
+
+// disableWriteDeadline 取消服务器 WriteTimeout 对当前连接的限制，使长连接流式响应不会被强制中断
+func disableWriteDeadline(w http.ResponseWriter) error {
+	return http.NewResponseController(w).SetWriteDeadline(time.Time{})
+}
+
+// stoppableBody 包装流式上游响应体
+// 网关关闭时主动关闭上游连接，并把随之产生的读错误转换为 io.EOF，
+// 让反向代理正常结束响应（写出分块结束标记），客户端看到的是一次干净的流结束而不是连接重置
+type stoppableBody struct {
+	io.ReadCloser
+	stopped atomic.Bool
+	stop    func() bool
+}
+
+// newStoppableBody 返回一个在 ctx 结束时自动终止的响应体
+func newStoppableBody(ctx context.Context, body io.ReadCloser) *stoppableBody {
+	b := &stoppableBody{ReadCloser: body}
+	b.stop = context.AfterFunc(ctx, func() {
+		b.stopped.Store(true)
+		b.ReadCloser.Close()
+	})
+	return b
+}
+
+func (b *stoppableBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && b.stopped.Load() {
+		return n, io.EOF
+	}
+	return n, err
+}
+
+func (b *stoppableBody) Close() error {
+	b.stop()
+	return b.ReadCloser.Close()
+}
+
+// CloseStreams 通知所有进行中的流式响应结束，在服务器开始优雅关闭时调用
+func (p *Proxy) CloseStreams() {
+	p.stopStreams()
+}
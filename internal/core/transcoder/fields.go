@@ -0,0 +1,111 @@
+package transcoder
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// resolveField 按点分路径 (如 user.id) 查找字段，字段名可以是 proto 名或 JSON 名
+func resolveField(md protoreflect.MessageDescriptor, path string) (protoreflect.FieldDescriptor, error) {
+	names := strings.Split(path, ".")
+	for i, name := range names {
+		fd := md.Fields().ByName(protoreflect.Name(name))
+		if fd == nil {
+			fd = md.Fields().ByJSONName(name)
+		}
+		if fd == nil {
+			return nil, fmt.Errorf("消息 '%s' 中不存在字段 '%s'", md.FullName(), name)
+		}
+		if i == len(names)-1 {
+			return fd, nil
+		}
+		if fd.Message() == nil || fd.IsList() || fd.IsMap() {
+			return nil, fmt.Errorf("字段 '%s' 不是消息类型，无法访问子字段", name)
+		}
+		md = fd.Message()
+	}
+	return nil, fmt.Errorf("字段路径 '%s' 为空", path)
+}
+
+// mutableMessage 返回路径中最后一个字段所在的消息，沿途创建缺失的子消息
+func mutableMessage(msg protoreflect.Message, path string) protoreflect.Message {
+	names := strings.Split(path, ".")
+	for _, name := range names[:len(names)-1] {
+		fd, _ := resolveField(msg.Descriptor(), name)
+		msg = msg.Mutable(fd).Message()
+	}
+	return msg
+}
+
+// setField 把字符串形式的参数值写入标量字段，重复字段追加元素
+func setField(msg protoreflect.Message, path, raw string) error {
+	fd, err := resolveField(msg.Descriptor(), path)
+	if err != nil {
+		return err
+	}
+	if fd.IsMap() || fd.Message() != nil {
+		return fmt.Errorf("字段 '%s' 不是标量类型", path)
+	}
+	value, err := parseScalar(fd, raw)
+	if err != nil {
+		return err
+	}
+
+	parent := mutableMessage(msg, path)
+	if fd.IsList() {
+		parent.Mutable(fd).List().Append(value)
+		return nil
+	}
+	parent.Set(fd, value)
+	return nil
+}
+
+// parseScalar 按字段类型解析字符串
+func parseScalar(fd protoreflect.FieldDescriptor, raw string) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(raw), nil
+	case protoreflect.BoolKind:
+		v, err := strconv.ParseBool(raw)
+		return protoreflect.ValueOfBool(v), err
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		v, err := strconv.ParseInt(raw, 10, 32)
+		return protoreflect.ValueOfInt32(int32(v)), err
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		v, err := strconv.ParseInt(raw, 10, 64)
+		return protoreflect.ValueOfInt64(v), err
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		v, err := strconv.ParseUint(raw, 10, 32)
+		return protoreflect.ValueOfUint32(uint32(v)), err
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		v, err := strconv.ParseUint(raw, 10, 64)
+		return protoreflect.ValueOfUint64(v), err
+	case protoreflect.FloatKind:
+		v, err := strconv.ParseFloat(raw, 32)
+		return protoreflect.ValueOfFloat32(float32(v)), err
+	case protoreflect.DoubleKind:
+		v, err := strconv.ParseFloat(raw, 64)
+		return protoreflect.ValueOfFloat64(v), err
+	case protoreflect.BytesKind:
+		v, err := base64.StdEncoding.DecodeString(raw)
+		if err != nil {
+			v, err = base64.URLEncoding.DecodeString(raw)
+		}
+		return protoreflect.ValueOfBytes(v), err
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByName(protoreflect.Name(raw)); ev != nil {
+			return protoreflect.ValueOfEnum(ev.Number()), nil
+		}
+		n, err := strconv.ParseInt(raw, 10, 32)
+		if err != nil {
+			return protoreflect.Value{}, fmt.Errorf("'%s' 不是枚举 '%s' 的合法取值", raw, fd.Enum().FullName())
+		}
+		return protoreflect.ValueOfEnum(protoreflect.EnumNumber(n)), nil
+	default:
+		return protoreflect.Value{}, fmt.Errorf("不支持的字段类型 '%s'", fd.Kind())
+	}
+}
@@ -0,0 +1,32 @@
+package transcoder
+
+import "net/http"
+
+// grpcToHTTPStatus gRPC 状态码到 HTTP 状态码的映射 (与 grpc-gateway 保持一致)
+var grpcToHTTPStatus = map[int]int{
+	0:  http.StatusOK,
+	1:  499, // Canceled
+	2:  http.StatusInternalServerError,
+	3:  http.StatusBadRequest,
+	4:  http.StatusGatewayTimeout,
+	5:  http.StatusNotFound,
+	6:  http.StatusConflict,
+	7:  http.StatusForbidden,
+	8:  http.StatusTooManyRequests,
+	9:  http.StatusBadRequest,
+	10: http.StatusConflict,
+	11: http.StatusBadRequest,
+	12: http.StatusNotImplemented,
+	13: http.StatusInternalServerError,
+	14: http.StatusServiceUnavailable,
+	15: http.StatusInternalServerError,
+	16: http.StatusUnauthorized,
+}
+
+// HTTPStatus 返回 gRPC 状态码对应的 HTTP 状态码，未知状态码视为 500
+func HTTPStatus(code int) int {
+	if status, ok := grpcToHTTPStatus[code]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,310 @@
+// package transcoder 实现 HTTP/JSON 与 gRPC 之间的转码，
+// 依据 proto 描述文件把 REST 请求映射为上游 gRPC 一元调用。
+package transcoder
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"gateway.example/go-gateway/internal/config"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// grpcFrameHeaderSize gRPC 消息帧头长度: 1 字节压缩标志 + 4 字节大端长度
+const grpcFrameHeaderSize = 5
+
+// ErrInvalidRequest 表示客户端请求无法转换为 gRPC 请求消息
+var ErrInvalidRequest = errors.New("请求无法转码")
+
+// Transcoder 保存一条路由上所有已编译的转码绑定
+type Transcoder struct {
+	bindings  []*Binding
+	marshal   protojson.MarshalOptions
+	unmarshal protojson.UnmarshalOptions
+}
+
+// Binding 是一条已解析的 HTTP -> gRPC 映射
+type Binding struct {
+	httpMethod string
+	segments   []segment
+	method     protoreflect.MethodDescriptor
+	fullMethod string // 形如 /package.Service/Method，作为上游请求路径
+	body       string
+}
+
+// segment 路径模板中的一段: 字面量、单段变量 {field} 或剩余路径变量 {field=**}
+type segment struct {
+	literal string
+	field   string
+	rest    bool
+}
+
+// New 加载描述文件并编译路由的转码规则
+func New(cfg *config.TranscodingConfig) (*Transcoder, error) {
+	files, err := loadDescriptors(cfg.DescriptorFiles)
+	if err != nil {
+		return nil, err
+	}
+	types := dynamicpb.NewTypes(files)
+
+	t := &Transcoder{
+		marshal:   protojson.MarshalOptions{EmitUnpopulated: true, Resolver: types},
+		unmarshal: protojson.UnmarshalOptions{Resolver: types},
+	}
+	for _, b := range cfg.Bindings {
+		binding, err := compileBinding(files, b)
+		if err != nil {
+			return nil, fmt.Errorf("转码规则 '%s %s' 无效: %w", b.Method, b.Path, err)
+		}
+		t.bindings = append(t.bindings, binding)
+	}
+	return t, nil
+}
+
+// loadDescriptors 读取 FileDescriptorSet 文件并构建描述符注册表
+func loadDescriptors(paths []string) (*protoregistry.Files, error) {
+	if len(paths) == 0 {
+		return nil, errors.New("未配置 proto 描述文件")
+	}
+	set := &descriptorpb.FileDescriptorSet{}
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("读取 proto 描述文件 '%s' 失败: %w", path, err)
+		}
+		var part descriptorpb.FileDescriptorSet
+		if err := proto.Unmarshal(data, &part); err != nil {
+			return nil, fmt.Errorf("解析 proto 描述文件 '%s' 失败: %w", path, err)
+		}
+		for _, fd := range part.File {
+			if !seen[fd.GetName()] {
+				seen[fd.GetName()] = true
+				set.File = append(set.File, fd)
+			}
+		}
+	}
+	files, err := protodesc.NewFiles(set)
+	if err != nil {
+		return nil, fmt.Errorf("构建 proto 描述符失败 (描述文件需使用 --include_imports 生成): %w", err)
+	}
+	return files, nil
+}
+
+func compileBinding(files *protoregistry.Files, cfg config.TranscodingBinding) (*Binding, error) {
+	serviceName, methodName, ok := strings.Cut(strings.TrimPrefix(cfg.GRPCMethod, "/"), "/")
+	if !ok {
+		return nil, fmt.Errorf("grpc_method '%s' 应为 package.Service/Method 格式", cfg.GRPCMethod)
+	}
+	desc, err := files.FindDescriptorByName(protoreflect.FullName(serviceName))
+	if err != nil {
+		return nil, fmt.Errorf("未找到服务 '%s': %w", serviceName, err)
+	}
+	service, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("'%s' 不是 gRPC 服务", serviceName)
+	}
+	method := service.Methods().ByName(protoreflect.Name(methodName))
+	if method == nil {
+		return nil, fmt.Errorf("服务 '%s' 中未找到方法 '%s'", serviceName, methodName)
+	}
+	if method.IsStreamingClient() || method.IsStreamingServer() {
+		return nil, fmt.Errorf("方法 '%s' 为流式方法，仅支持一元调用", cfg.GRPCMethod)
+	}
+
+	segments, err := parseTemplate(cfg.Path)
+	if err != nil {
+		return nil, err
+	}
+	for _, seg := range segments {
+		if seg.field != "" {
+			if _, err := resolveField(method.Input(), seg.field); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if cfg.Body != "" && cfg.Body != "*" {
+		fd, err := resolveField(method.Input(), cfg.Body)
+		if err != nil {
+			return nil, err
+		}
+		if fd.Message() == nil || fd.IsList() || fd.IsMap() {
+			return nil, fmt.Errorf("body 字段 '%s' 必须是消息类型", cfg.Body)
+		}
+	}
+
+	httpMethod := strings.ToUpper(cfg.Method)
+	if httpMethod == "" {
+		httpMethod = http.MethodPost
+	}
+	return &Binding{
+		httpMethod: httpMethod,
+		segments:   segments,
+		method:     method,
+		fullMethod: "/" + string(service.FullName()) + "/" + string(method.Name()),
+		body:       cfg.Body,
+	}, nil
+}
+
+// parseTemplate 解析路径模板，{field=**} 只能作为最后一段
+func parseTemplate(path string) ([]segment, error) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	segments := make([]segment, 0, len(parts))
+	for i, part := range parts {
+		if !strings.HasPrefix(part, "{") {
+			segments = append(segments, segment{literal: part})
+			continue
+		}
+		if !strings.HasSuffix(part, "}") {
+			return nil, fmt.Errorf("路径模板 '%s' 中的变量 '%s' 格式错误", path, part)
+		}
+		field, pattern, _ := strings.Cut(part[1:len(part)-1], "=")
+		switch pattern {
+		case "", "*":
+			segments = append(segments, segment{field: field})
+		case "**":
+			if i != len(parts)-1 {
+				return nil, fmt.Errorf("路径模板 '%s' 中的 '**' 只能出现在最后一段", path)
+			}
+			segments = append(segments, segment{field: field, rest: true})
+		default:
+			return nil, fmt.Errorf("路径模板 '%s' 不支持模式 '%s'", path, pattern)
+		}
+	}
+	return segments, nil
+}
+
+// Match 查找与请求方法和路径匹配的绑定，返回绑定和路径变量
+// path 为去掉路由前缀后的路径
+func (t *Transcoder) Match(method, path string) (*Binding, map[string]string) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	for _, b := range t.bindings {
+		if b.httpMethod != method {
+			continue
+		}
+		if params, ok := b.match(parts); ok {
+			return b, params
+		}
+	}
+	return nil, nil
+}
+
+func (b *Binding) match(parts []string) (map[string]string, bool) {
+	params := make(map[string]string)
+	for i, seg := range b.segments {
+		if seg.rest {
+			if i >= len(parts) {
+				return nil, false
+			}
+			params[seg.field] = strings.Join(parts[i:], "/")
+			return params, true
+		}
+		if i >= len(parts) {
+			return nil, false
+		}
+		if seg.field == "" {
+			if seg.literal != parts[i] {
+				return nil, false
+			}
+			continue
+		}
+		value, err := url.PathUnescape(parts[i])
+		if err != nil || value == "" {
+			return nil, false
+		}
+		params[seg.field] = value
+	}
+	return params, len(parts) == len(b.segments)
+}
+
+// FullMethod 返回上游 gRPC 请求路径，如 /package.Service/Method
+func (b *Binding) FullMethod() string {
+	return b.fullMethod
+}
+
+// EncodeRequest 把 HTTP 请求 (请求体、路径变量、查询参数) 转换为 gRPC 请求帧
+func (t *Transcoder) EncodeRequest(b *Binding, r *http.Request, params map[string]string) ([]byte, error) {
+	msg := dynamicpb.NewMessage(b.method.Input())
+
+	if b.body != "" {
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			return nil, fmt.Errorf("%w: 读取请求体失败: %v", ErrInvalidRequest, err)
+		}
+		if len(data) > 0 {
+			target := msg.Interface()
+			if b.body != "*" {
+				fd, _ := resolveField(b.method.Input(), b.body)
+				target = mutableMessage(msg, b.body).Mutable(fd).Message().Interface()
+			}
+			if err := t.unmarshal.Unmarshal(data, target); err != nil {
+				return nil, fmt.Errorf("%w: 请求体不是合法的 JSON 消息: %v", ErrInvalidRequest, err)
+			}
+		}
+	}
+
+	// 请求体映射整个消息时，查询参数不再参与映射
+	if b.body != "*" {
+		for name, values := range r.URL.Query() {
+			if _, err := resolveField(b.method.Input(), name); err != nil {
+				continue // 未知的查询参数直接忽略
+			}
+			for _, v := range values {
+				if err := setField(msg, name, v); err != nil {
+					return nil, fmt.Errorf("%w: 查询参数 '%s': %v", ErrInvalidRequest, name, err)
+				}
+			}
+		}
+	}
+	for name, v := range params {
+		if err := setField(msg, name, v); err != nil {
+			return nil, fmt.Errorf("%w: 路径参数 '%s': %v", ErrInvalidRequest, name, err)
+		}
+	}
+
+	payload, err := proto.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	frame := make([]byte, grpcFrameHeaderSize+len(payload))
+	binary.BigEndian.PutUint32(frame[1:grpcFrameHeaderSize], uint32(len(payload)))
+	copy(frame[grpcFrameHeaderSize:], payload)
+	return frame, nil
+}
+
+// DecodeResponse 读取上游返回的 gRPC 响应帧并转换为 JSON
+// 一元调用只有一个消息帧；trailers-only 响应没有消息帧，返回 nil
+func (t *Transcoder) DecodeResponse(b *Binding, body io.Reader) ([]byte, error) {
+	header := make([]byte, grpcFrameHeaderSize)
+	if _, err := io.ReadFull(body, header); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("读取 gRPC 响应帧失败: %w", err)
+	}
+	if header[0] != 0 {
+		return nil, errors.New("不支持压缩的 gRPC 响应")
+	}
+	payload := make([]byte, binary.BigEndian.Uint32(header[1:]))
+	if _, err := io.ReadFull(body, payload); err != nil {
+		return nil, fmt.Errorf("读取 gRPC 响应消息失败: %w", err)
+	}
+
+	msg := dynamicpb.NewMessage(b.method.Output())
+	if err := proto.Unmarshal(payload, msg); err != nil {
+		return nil, fmt.Errorf("解析 gRPC 响应消息失败: %w", err)
+	}
+	return t.marshal.Marshal(msg)
+}
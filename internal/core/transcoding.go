@@ -0,0 +1,158 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"gateway.example/go-gateway/internal/config"
+	"gateway.example/go-gateway/internal/core/transcoder"
+	"gateway.example/go-gateway/internal/response"
+)
+
+// transcodeErrorBody 转码路由返回的错误响应，附带上游 gRPC 状态码
+type transcodeErrorBody struct {
+	response.ErrorBody
+	GRPCCode int `json:"grpc_code"`
+}
+
+// transcodingHopHeaders 不转发给 gRPC 上游的请求头
+var transcodingHopHeaders = []string{
+	"Connection", "Keep-Alive", "Proxy-Connection", "Transfer-Encoding", "Upgrade",
+	"Te", "Trailer", "Content-Type", "Content-Length", "Accept-Encoding", "Host",
+}
+
+// newTranscoders 为配置了转码的路由加载描述文件并编译规则
+func newTranscoders(cfg *config.GatewayConfig) (map[*config.RouteConfig]*transcoder.Transcoder, error) {
+	transcoders := make(map[*config.RouteConfig]*transcoder.Transcoder)
+	for _, route := range cfg.Routes {
+		if route.Transcoding == nil {
+			continue
+		}
+		service, ok := cfg.Services[route.ServiceName]
+		if !ok || !usesHTTP2(&service) {
+			return nil, fmt.Errorf("转码路由 '%s' 的上游服务 '%s' 必须配置 protocol: grpc", route.PathPrefix, route.ServiceName)
+		}
+		t, err := transcoder.New(route.Transcoding)
+		if err != nil {
+			return nil, fmt.Errorf("路由 '%s' 转码配置错误: %w", route.PathPrefix, err)
+		}
+		transcoders[route] = t
+	}
+	return transcoders, nil
+}
+
+// serveTranscoded 把 HTTP/JSON 请求转码为 gRPC 一元调用，并把结果转回 JSON
+// 上游调用的结果按 gRPC 状态码反馈给熔断器
+func (p *Proxy) serveTranscoded(w http.ResponseWriter, r *http.Request, t *transcoder.Transcoder, info *proxyRequestInfo) {
+	ctx := r.Context()
+	path := strings.TrimPrefix(r.URL.Path, info.route.PathPrefix)
+
+	binding, params := t.Match(r.Method, path)
+	if binding == nil {
+		response.WriteError(w, http.StatusNotFound, "没有与请求匹配的 gRPC 方法")
+		return
+	}
+
+	frame, err := t.EncodeRequest(binding, r, params)
+	if err != nil {
+		p.logger.Warn(ctx, "[Proxy] 转码请求失败", "service", info.service.Name, "method", binding.FullMethod(), "error", err)
+		status := http.StatusInternalServerError
+		if errors.Is(err, transcoder.ErrInvalidRequest) {
+			status = http.StatusBadRequest
+		}
+		response.WriteError(w, status, err.Error())
+		return
+	}
+
+	target, err := url.Parse(strings.TrimSuffix(info.instanceURL, "/") + binding.FullMethod())
+	if err != nil {
+		response.WriteError(w, http.StatusInternalServerError, "网关内部错误")
+		return
+	}
+
+	outReq := r.Clone(ctx)
+	outReq.Method = http.MethodPost
+	outReq.URL = target
+	outReq.Host = target.Host
+	outReq.RequestURI = ""
+	outReq.Body = io.NopCloser(bytes.NewReader(frame))
+	outReq.ContentLength = int64(len(frame))
+	for _, h := range transcodingHopHeaders {
+		outReq.Header.Del(h)
+	}
+	outReq.Header.Set("Content-Type", "application/grpc+proto")
+	outReq.Header.Set("Te", "trailers")
+	if deadline, ok := ctx.Deadline(); ok {
+		outReq.Header.Set("Grpc-Timeout", strconv.FormatInt(max(time.Until(deadline).Milliseconds(), 1), 10)+"m")
+	}
+	p.trustedProxies.setForwardedHeaders(outReq, info.clientIP)
+	// 与 httputil.ReverseProxy 一致，把对端地址追加到 X-Forwarded-For
+	xff := remoteIP(r)
+	if prior := outReq.Header.Values("X-Forwarded-For"); len(prior) > 0 {
+		xff = strings.Join(prior, ", ") + ", " + xff
+	}
+	outReq.Header.Set("X-Forwarded-For", xff)
+	outReq.Header.Set("X-Gateway-Proxy", "true")
+	applyHeaderRules(outReq.Header, info.route.RequestHeaders, outReq, info)
+	p.signRequest(outReq, info.service)
+
+	resp, err := info.routing.transportFor(info.service.Name, p.transport).RoundTrip(outReq)
+	if err != nil {
+		p.handleProxyError(w, r, err)
+		return
+	}
+	defer resp.Body.Close()
+
+	body, decodeErr := t.DecodeResponse(binding, resp.Body)
+	// 读完剩余数据后 trailer 才可用
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	// trailers-only 响应的状态位于响应头
+	code, ok := grpcStatus(resp.Trailer)
+	if !ok {
+		code, ok = grpcStatus(resp.Header)
+	}
+	if !ok && resp.StatusCode != http.StatusOK {
+		code = grpcCodeUnavailable
+	}
+	if code == 0 && decodeErr != nil {
+		p.logger.Error(ctx, "[Proxy] 解析上游 gRPC 响应失败", "service", info.service.Name, "method", binding.FullMethod(), "error", decodeErr)
+		code = grpcCodeInternal
+	}
+	p.recordResult(ctx, info, !isGRPCServerFailure(code))
+
+	applyHeaderRules(w.Header(), info.route.ResponseHeaders, r, info)
+	if code != 0 {
+		message := resp.Trailer.Get("Grpc-Message")
+		if message == "" {
+			message = resp.Header.Get("Grpc-Message")
+		}
+		if decoded, err := url.PathUnescape(message); err == nil {
+			message = decoded
+		}
+		if message == "" && decodeErr != nil {
+			message = "上游 gRPC 响应无效"
+		}
+		status := transcoder.HTTPStatus(code)
+		p.logger.Info(ctx, "[Proxy] gRPC 转码调用返回错误", "service", info.service.Name, "method", binding.FullMethod(), "grpc_status", code, "status_code", status)
+		response.WriteErrorBody(w, status, transcodeErrorBody{
+			ErrorBody: response.ErrorBody{Error: http.StatusText(status), Status: status, Message: message},
+			GRPCCode:  code,
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if body == nil {
+		body = []byte("{}")
+	}
+	_, _ = w.Write(body)
+}
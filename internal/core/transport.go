@@ -0,0 +1,134 @@
+package core
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"gateway.example/go-gateway/internal/config"
+)
+
+// 传输层默认参数，配置缺省时使用
+const (
+	defaultMaxIdleConns        = 512
+	defaultMaxIdleConnsPerHost = 64
+	defaultIdleConnTimeout     = 90 * time.Second
+	defaultDialTimeout         = 5 * time.Second
+	defaultKeepAlive           = 30 * time.Second
+	defaultTLSHandshakeTimeout = 5 * time.Second
+)
+
+// newTransport 根据配置创建供所有上游共享的 HTTP 传输层
+// 所有反向代理复用同一个连接池，避免每个请求重新建立连接
+func newTransport(cfg config.TransportConfig) *http.Transport {
+	return &http.Transport{
+		Proxy:                  http.ProxyFromEnvironment,
+		DialContext:            newDialer(cfg, 0).DialContext,
+		ForceAttemptHTTP2:      true,
+		MaxIdleConns:           orDefault(cfg.MaxIdleConns, defaultMaxIdleConns),
+		MaxIdleConnsPerHost:    orDefault(cfg.MaxIdleConnsPerHost, defaultMaxIdleConnsPerHost),
+		MaxConnsPerHost:        cfg.MaxConnsPerHost,
+		IdleConnTimeout:        orDefault(cfg.IdleConnTimeout, defaultIdleConnTimeout),
+		TLSHandshakeTimeout:    orDefault(cfg.TLSHandshakeTimeout, defaultTLSHandshakeTimeout),
+		ExpectContinueTimeout:  1 * time.Second,
+		MaxResponseHeaderBytes: int64(cfg.MaxResponseHeaderBytes),
+		ReadBufferSize:         int(cfg.ReadBufferSize),
+		WriteBufferSize:        int(cfg.WriteBufferSize),
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: cfg.InsecureSkipVerify,
+		},
+	}
+}
+
+// newDialer 创建拨号器，dialTimeout 大于 0 时覆盖全局配置
+func newDialer(cfg config.TransportConfig, dialTimeout time.Duration) *net.Dialer {
+	if dialTimeout <= 0 {
+		dialTimeout = orDefault(cfg.DialTimeout, defaultDialTimeout)
+	}
+	return &net.Dialer{
+		Timeout:   dialTimeout,
+		KeepAlive: orDefault(cfg.KeepAlive, defaultKeepAlive),
+	}
+}
+
+// orDefault 在配置值未设置（非正数）时返回默认值
+func orDefault[T int | time.Duration](v, def T) T {
+	if v <= 0 {
+		return def
+	}
+	return v
+}
+
+// newH2CTransport 基于共享传输层派生出仅使用 HTTP/2 的传输层
+// http:// 上游使用明文 HTTP/2 (prior knowledge)，https:// 上游通过 ALPN 协商 h2
+func newH2CTransport(base *http.Transport) *http.Transport {
+	t := base.Clone()
+	t.Protocols = new(http.Protocols)
+	t.Protocols.SetUnencryptedHTTP2(true)
+	t.Protocols.SetHTTP2(true)
+	return t
+}
+
+// newServiceTransport 为单个服务构建传输层
+// 配置了 TLS 的服务使用独立连接池，避免不同证书配置的连接被错误复用
+func newServiceTransport(base *http.Transport, cfg config.TransportConfig, service config.ServiceConfig) (*http.Transport, error) {
+	t := base
+	if service.TLS != nil || service.Timeouts.Dial > 0 || service.Timeouts.ResponseHeader > 0 {
+		t = base.Clone()
+	}
+	if service.TLS != nil {
+		tlsCfg, err := newUpstreamTLSConfig(service.TLS)
+		if err != nil {
+			return nil, fmt.Errorf("服务 '%s' 的 TLS 配置无效: %w", service.Name, err)
+		}
+		t.TLSClientConfig = tlsCfg
+	}
+	if service.Timeouts.Dial > 0 {
+		t.DialContext = newDialer(cfg, service.Timeouts.Dial).DialContext
+	}
+	if service.Timeouts.ResponseHeader > 0 {
+		t.ResponseHeaderTimeout = service.Timeouts.ResponseHeader
+	}
+	if usesHTTP2(&service) {
+		t = newH2CTransport(t)
+	}
+	return t, nil
+}
+
+// newUpstreamTLSConfig 根据服务配置构建 tls.Config
+func newUpstreamTLSConfig(cfg *config.UpstreamTLSConfig) (*tls.Config, error) {
+	tlsCfg := &tls.Config{
+		ServerName:         cfg.ServerName,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+		MinVersion:         tls.VersionTLS12,
+	}
+
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("读取 CA 证书 '%s' 失败: %w", cfg.CAFile, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("CA 证书 '%s' 中没有有效的 PEM 证书", cfg.CAFile)
+		}
+		tlsCfg.RootCAs = pool
+	}
+
+	if (cfg.CertFile == "") != (cfg.KeyFile == "") {
+		return nil, fmt.Errorf("cert_file 与 key_file 必须同时配置")
+	}
+	if cfg.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("加载客户端证书失败: %w", err)
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsCfg, nil
+}
@@ -0,0 +1,51 @@
+package core
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"gateway.example/go-gateway/internal/config"
+)
+
+// rejectNonOriginRequest 拒绝把网关当作正向代理使用的请求
+// CONNECT 隧道返回 405，absolute-form 请求目标 (GET http://host/path) 返回 400
+func rejectNonOriginRequest(r *http.Request) (int, string, bool) {
+	if r.Method == http.MethodConnect {
+		return http.StatusMethodNotAllowed, "网关不支持 CONNECT 隧道", true
+	}
+	if r.ProtoMajor == 1 && r.RequestURI != "" && !strings.HasPrefix(r.RequestURI, "/") && r.RequestURI != "*" {
+		return http.StatusBadRequest, "网关不接受代理形式的请求目标", true
+	}
+	return 0, "", false
+}
+
+// upgradeProtocol 返回请求希望升级到的协议 (小写)，不是升级请求时返回空串
+func upgradeProtocol(r *http.Request) string {
+	for _, v := range r.Header.Values("Connection") {
+		for _, token := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(token), "upgrade") {
+				return strings.ToLower(strings.TrimSpace(r.Header.Get("Upgrade")))
+			}
+		}
+	}
+	return ""
+}
+
+// checkUpgrade 按路由的 allowed_upgrades 策略处理协议升级请求
+// h2c 升级由服务器按 prior knowledge 方式支持，这里直接去掉升级头按 HTTP/1.1 处理
+func checkUpgrade(r *http.Request, route *config.RouteConfig) (string, bool) {
+	protocol := upgradeProtocol(r)
+	if protocol == "" {
+		return "", true
+	}
+	if protocol == "h2c" {
+		r.Header.Del("Upgrade")
+		r.Header.Del("Http2-Settings")
+		return "", true
+	}
+	if !slices.ContainsFunc(route.AllowedUpgrades, func(p string) bool { return strings.EqualFold(p, protocol) }) {
+		return protocol, false
+	}
+	return protocol, true
+}
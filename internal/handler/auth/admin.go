@@ -0,0 +1,173 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"gateway.example/go-gateway/internal/models"
+	"gateway.example/go-gateway/internal/service/auth"
+)
+
+// userResponse 用户管理接口返回的用户信息，不包含密码哈希
+type userResponse struct {
+	ID       string   `json:"id"`
+	Username string   `json:"username"`
+	Roles    []string `json:"roles"`
+	TenantID string   `json:"tenant_id,omitempty"`
+	Scopes   []string `json:"scopes"`
+	Disabled bool     `json:"disabled"`
+	Provider string   `json:"provider,omitempty"` // 外部身份源创建的账户
+}
+
+func newUserResponse(user *models.User) userResponse {
+	resp := userResponse{
+		ID:       user.ID,
+		Username: user.Username,
+		Roles:    user.Roles,
+		TenantID: user.TenantID,
+		Scopes:   user.Scopes,
+		Disabled: user.Disabled,
+		Provider: user.Provider,
+	}
+	if resp.Roles == nil {
+		resp.Roles = []string{}
+	}
+	if resp.Scopes == nil {
+		resp.Scopes = []string{}
+	}
+	return resp
+}
+
+type resetPasswordRequest struct {
+	Password string `json:"password"`
+}
+
+// requireAdmin 校验请求携带的访问令牌具有 admin 角色，失败时写入 401/403 响应
+func (h *AuthHandler) requireAdmin(w http.ResponseWriter, r *http.Request) (*auth.Claims, bool) {
+	claims, ok := h.authenticate(w, r)
+	if !ok {
+		return nil, false
+	}
+	if !claims.HasRole(auth.RoleAdmin) {
+		http.Error(w, "admin role required", http.StatusForbidden)
+		return nil, false
+	}
+	return claims, true
+}
+
+// ListUsersHandler 列出全部用户 (GET /admin/users)
+func (h *AuthHandler) ListUsersHandler(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.requireAdmin(w, r); !ok {
+		return
+	}
+	users, err := h.authService.ListUsers(r.Context())
+	if err != nil {
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	resp := make([]userResponse, 0, len(users))
+	for _, user := range users {
+		resp = append(resp, newUserResponse(user))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"users": resp})
+}
+
+// GetUserHandler 查询单个用户 (GET /admin/users/{username})
+func (h *AuthHandler) GetUserHandler(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.requireAdmin(w, r); !ok {
+		return
+	}
+	user, err := h.authService.GetUser(r.Context(), r.PathValue("username"))
+	if err != nil {
+		writeUserError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(newUserResponse(user))
+}
+
+// DisableUserHandler 停用账户 (POST /admin/users/{username}/disable)
+func (h *AuthHandler) DisableUserHandler(w http.ResponseWriter, r *http.Request) {
+	h.setUserDisabled(w, r, true)
+}
+
+// EnableUserHandler 启用账户 (POST /admin/users/{username}/enable)
+func (h *AuthHandler) EnableUserHandler(w http.ResponseWriter, r *http.Request) {
+	h.setUserDisabled(w, r, false)
+}
+
+func (h *AuthHandler) setUserDisabled(w http.ResponseWriter, r *http.Request, disabled bool) {
+	claims, ok := h.requireAdmin(w, r)
+	if !ok {
+		return
+	}
+	username := r.PathValue("username")
+	if disabled && h.isSelf(r, claims, username) {
+		http.Error(w, "cannot disable your own account", http.StatusConflict)
+		return
+	}
+	if err := h.authService.SetUserDisabled(r.Context(), username, disabled); err != nil {
+		writeUserError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ResetPasswordHandler 为用户设置新密码并作废其全部刷新令牌 (POST /admin/users/{username}/reset-password)
+func (h *AuthHandler) ResetPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.requireAdmin(w, r); !ok {
+		return
+	}
+	var req resetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := h.authService.ResetPassword(r.Context(), r.PathValue("username"), req.Password); err != nil {
+		writeUserError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeleteUserHandler 删除用户 (DELETE /admin/users/{username})
+func (h *AuthHandler) DeleteUserHandler(w http.ResponseWriter, r *http.Request) {
+	claims, ok := h.requireAdmin(w, r)
+	if !ok {
+		return
+	}
+	username := r.PathValue("username")
+	if h.isSelf(r, claims, username) {
+		http.Error(w, "cannot delete your own account", http.StatusConflict)
+		return
+	}
+	if err := h.authService.DeleteUser(r.Context(), username); err != nil {
+		writeUserError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// isSelf 判断操作对象是否为管理员本人，防止管理员把自己锁在系统之外
+func (h *AuthHandler) isSelf(r *http.Request, claims *auth.Claims, username string) bool {
+	user, err := h.authService.GetUser(r.Context(), username)
+	return err == nil && user.ID == claims.Subject
+}
+
+// writeUserError 把用户管理错误映射为 HTTP 状态码
+func writeUserError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, auth.ErrWeakPassword):
+		writePolicyError(w, err)
+	case errors.Is(err, auth.ErrUserNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, auth.ErrExternalAccount):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, auth.ErrInvalidInput):
+		http.Error(w, "password is required", http.StatusBadRequest)
+	default:
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+	}
+}
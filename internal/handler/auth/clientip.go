@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// trustedProxies 认证服务前面的可信代理网段，只有直连对端属于其中时才信任 X-Forwarded-For
+type trustedProxies []netip.Prefix
+
+// parseTrustedProxies 解析 CIDR 列表，单个 IP 视为 /32 或 /128
+func parseTrustedProxies(cidrs []string) (trustedProxies, error) {
+	prefixes := make(trustedProxies, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		if !strings.Contains(cidr, "/") {
+			addr, err := netip.ParseAddr(cidr)
+			if err != nil {
+				return nil, fmt.Errorf("无效的可信代理地址 '%s'", cidr)
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("无效的可信代理网段 '%s': %w", cidr, err)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+func (t trustedProxies) contains(ip string) bool {
+	addr, err := netip.ParseAddr(strings.TrimSpace(ip))
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range t {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientIP 计算真实客户端 IP: 对端不可信时直接使用对端地址；
+// 可信时从 X-Forwarded-For 右侧向左跳过可信代理，第一个不可信地址即为客户端
+func (t trustedProxies) clientIP(r *http.Request) string {
+	peer, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		peer = r.RemoteAddr
+	}
+	if !t.contains(peer) {
+		return peer
+	}
+	var hops []string
+	for _, v := range r.Header.Values("X-Forwarded-For") {
+		for _, hop := range strings.Split(v, ",") {
+			if hop = strings.TrimSpace(hop); hop != "" {
+				hops = append(hops, hop)
+			}
+		}
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		if !t.contains(hops[i]) {
+			return hops[i]
+		}
+	}
+	return peer
+}
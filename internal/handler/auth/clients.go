@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"gateway.example/go-gateway/internal/service/auth"
+)
+
+// grantClientCredentials 是 /token 目前支持的唯一授权类型
+const grantClientCredentials = "client_credentials"
+
+// TokenHandler 按 OAuth 2.0 client_credentials 为服务客户端签发机器令牌 (POST /token)。
+// 请求体为 application/x-www-form-urlencoded: grant_type=client_credentials&scope=...，
+// 客户端凭证通过 HTTP Basic 认证或表单字段 client_id / client_secret 提供
+func (h *AuthHandler) TokenHandler(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "invalid request body")
+		return
+	}
+	if grant := r.PostForm.Get("grant_type"); grant != grantClientCredentials {
+		writeOAuthError(w, http.StatusBadRequest, "unsupported_grant_type", "only client_credentials is supported")
+		return
+	}
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if clientID == "" || clientSecret == "" {
+		w.Header().Set("WWW-Authenticate", `Basic realm="auth-service"`)
+		writeOAuthError(w, http.StatusUnauthorized, "invalid_client", "client credentials are required")
+		return
+	}
+
+	token, err := h.authService.ClientCredentials(r.Context(), clientID, clientSecret, r.PostForm.Get("scope"))
+	switch {
+	case errors.Is(err, auth.ErrInvalidClient):
+		w.Header().Set("WWW-Authenticate", `Basic realm="auth-service"`)
+		writeOAuthError(w, http.StatusUnauthorized, "invalid_client", err.Error())
+		return
+	case errors.Is(err, auth.ErrInvalidScope):
+		writeOAuthError(w, http.StatusBadRequest, "invalid_scope", err.Error())
+		return
+	case err != nil:
+		writeOAuthError(w, http.StatusInternalServerError, "server_error", "internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(token)
+}
+
+// writeOAuthError 按 OAuth 2.0 (RFC 6749 5.2) 的格式返回错误
+func writeOAuthError(w http.ResponseWriter, status int, code, description string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{
+		"error":             code,
+		"error_description": description,
+	})
+}
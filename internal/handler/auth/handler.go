@@ -2,7 +2,10 @@ package auth
 
 import (
 	"encoding/json"
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"gateway.example/go-gateway/internal/service/auth"
@@ -10,10 +13,17 @@ import (
 
 type AuthHandler struct {
 	authService auth.AuthService
+	proxies     trustedProxies
 }
 
-func NewAuthHandler(service auth.AuthService) *AuthHandler {
-	return &AuthHandler{authService: service}
+// NewAuthHandler 创建认证处理器，trustedProxies 为认证服务前面的可信代理 (通常是网关)，
+// 用于从 X-Forwarded-For 中解析客户端 IP (见 RequestContext)
+func NewAuthHandler(service auth.AuthService, trustedProxies []string) (*AuthHandler, error) {
+	proxies, err := parseTrustedProxies(trustedProxies)
+	if err != nil {
+		return nil, err
+	}
+	return &AuthHandler{authService: service, proxies: proxies}, nil
 }
 
 type loginRequest struct {
@@ -28,14 +38,192 @@ func (h *AuthHandler) LoginHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	token, err := h.authService.Login(r.Context(), req.Username, req.Password)
+	tokens, err := h.authService.Login(r.Context(), req.Username, req.Password)
+	if errors.Is(err, auth.ErrLoginThrottled) {
+		retryAfter := int(math.Ceil(auth.RetryAfter(err).Seconds()))
+		w.Header().Set("Retry-After", strconv.Itoa(max(retryAfter, 1)))
+		http.Error(w, auth.ErrLoginThrottled.Error(), http.StatusTooManyRequests)
+		return
+	}
+	if errors.Is(err, auth.ErrAccountDisabled) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	if errors.Is(err, auth.ErrIdentityProviderUnavailable) {
+		http.Error(w, auth.ErrIdentityProviderUnavailable.Error(), http.StatusServiceUnavailable)
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusUnauthorized)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"token": token})
+	json.NewEncoder(w).Encode(tokenResponse{Token: tokens.AccessToken, TokenPair: tokens})
+}
+
+// tokenResponse 登录与刷新的响应，token 与 access_token 相同，保留给旧客户端
+type tokenResponse struct {
+	Token string `json:"token"`
+	*auth.TokenPair
+}
+
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+func (h *AuthHandler) RefreshHandler(w http.ResponseWriter, r *http.Request) {
+	var req refreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	tokens, err := h.authService.Refresh(r.Context(), req.RefreshToken)
+	if errors.Is(err, auth.ErrInvalidRefreshToken) {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tokenResponse{Token: tokens.AccessToken, TokenPair: tokens})
+}
+
+type registerResponse struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+}
+
+func (h *AuthHandler) RegisterHandler(w http.ResponseWriter, r *http.Request) {
+	var req loginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.authService.Register(r.Context(), req.Username, req.Password)
+	switch {
+	case errors.Is(err, auth.ErrWeakPassword):
+		writePolicyError(w, err)
+		return
+	case errors.Is(err, auth.ErrInvalidInput):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, auth.ErrUserExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(registerResponse{ID: user.ID, Username: user.Username})
+}
+
+type changePasswordRequest struct {
+	OldPassword string `json:"old_password"`
+	NewPassword string `json:"new_password"`
+}
+
+// ChangePasswordHandler 修改当前用户的密码 (POST /password)，需要访问令牌与当前密码。
+// 成功后其他设备的会话全部结束
+func (h *AuthHandler) ChangePasswordHandler(w http.ResponseWriter, r *http.Request) {
+	claims, ok := h.authenticate(w, r)
+	if !ok {
+		return
+	}
+	var req changePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	err := h.authService.ChangePassword(r.Context(), claims.Subject, claims.SessionID, req.OldPassword, req.NewPassword)
+	switch {
+	case errors.Is(err, auth.ErrWeakPassword):
+		writePolicyError(w, err)
+	case errors.Is(err, auth.ErrInvalidCredentials):
+		http.Error(w, "current password is incorrect", http.StatusForbidden)
+	case errors.Is(err, auth.ErrExternalAccount):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, auth.ErrInvalidInput):
+		http.Error(w, "old_password and new_password are required", http.StatusBadRequest)
+	case errors.Is(err, auth.ErrUserNotFound):
+		http.Error(w, "Invalid token", http.StatusUnauthorized)
+	case err != nil:
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// writePolicyError 返回密码不符合策略的全部原因 (400)，客户端按 code 显示对应提示
+func writePolicyError(w http.ResponseWriter, err error) {
+	var pe *auth.PolicyError
+	violations := []auth.PolicyViolation{}
+	if errors.As(err, &pe) {
+		violations = pe.Violations
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":      "weak_password",
+		"message":    auth.ErrWeakPassword.Error(),
+		"violations": violations,
+	})
+}
+
+// LogoutHandler 吊销 Authorization 中的访问令牌，请求体可选 {"refresh_token": "..."} 同时作废刷新令牌。
+// 重复注销同一令牌同样返回 204
+func (h *AuthHandler) LogoutHandler(w http.ResponseWriter, r *http.Request) {
+	tokenString, ok := bearerToken(r)
+	if !ok {
+		http.Error(w, "Authorization header required", http.StatusUnauthorized)
+		return
+	}
+	var req refreshRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	err := h.authService.Logout(r.Context(), tokenString, req.RefreshToken)
+	if err != nil && !errors.Is(err, auth.ErrTokenRevoked) {
+		http.Error(w, "Invalid token", http.StatusUnauthorized)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// JWKSHandler 发布校验令牌所需的公钥 (GET /.well-known/jwks.json)
+func (h *AuthHandler) JWKSHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	json.NewEncoder(w).Encode(h.authService.JWKS())
+}
+
+// RevocationsHandler 返回尚未过期的已吊销令牌 (GET /revocations)，供网关本地校验模式同步
+func (h *AuthHandler) RevocationsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(map[string]interface{}{"revocations": h.authService.Revocations(r.Context())})
+}
+
+// bearerToken 从 Authorization 请求头中提取 Bearer 令牌
+func bearerToken(r *http.Request) (string, bool) {
+	parts := strings.Split(r.Header.Get("Authorization"), " ")
+	if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" || parts[1] == "" {
+		return "", false
+	}
+	return parts[1], true
 }
 
 func (h *AuthHandler) ValidateHandler(w http.ResponseWriter, r *http.Request) {
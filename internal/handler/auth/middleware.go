@@ -0,0 +1,35 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+
+	"gateway.example/go-gateway/internal/service/auth"
+	"gateway.example/go-gateway/pkg/logger"
+	"github.com/google/uuid"
+)
+
+const (
+	requestIDHeader = "X-Request-ID"
+	// maxRequestIDLength 超过该长度或包含控制字符的请求 ID 不被沿用，避免污染审计日志
+	maxRequestIDLength = 128
+)
+
+// RequestContext 为每个请求在 context 中记录客户端 IP、User-Agent 与请求 ID，供登录限制、会话与审计日志使用。
+// 请求 ID 沿用网关 requestid 插件传入的 X-Request-ID，没有时生成，并在响应头中返回
+func (h *AuthHandler) RequestContext(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(requestIDHeader)
+		if requestID == "" || len(requestID) > maxRequestIDLength || strings.ContainsFunc(requestID, isControl) {
+			requestID = uuid.NewString()
+		}
+		w.Header().Set(requestIDHeader, requestID)
+
+		ctx := logger.WithRequestID(r.Context(), requestID)
+		ctx = auth.WithClientIP(ctx, h.proxies.clientIP(r))
+		ctx = auth.WithUserAgent(ctx, r.UserAgent())
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func isControl(r rune) bool { return r < 0x20 || r == 0x7f }
@@ -0,0 +1,101 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"gateway.example/go-gateway/internal/service/auth"
+)
+
+// sessionResponse 会话列表中的一项
+type sessionResponse struct {
+	ID         string    `json:"id"`
+	UserAgent  string    `json:"user_agent,omitempty"`
+	IP         string    `json:"ip,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"` // 是否为发起本次请求的会话
+}
+
+// authenticate 校验请求携带的用户访问令牌，失败时写入 401 响应；机器令牌不能调用面向用户的接口，返回 403
+func (h *AuthHandler) authenticate(w http.ResponseWriter, r *http.Request) (*auth.Claims, bool) {
+	token, ok := bearerToken(r)
+	if !ok {
+		http.Error(w, "Authorization header required", http.StatusUnauthorized)
+		return nil, false
+	}
+	claims, err := h.authService.ValidateTokenWithClaims(r.Context(), token)
+	if err != nil {
+		http.Error(w, "Invalid token", http.StatusUnauthorized)
+		return nil, false
+	}
+	if claims.IsMachine() {
+		http.Error(w, "user token required", http.StatusForbidden)
+		return nil, false
+	}
+	// 之后的操作以该用户为操作者记录审计日志
+	*r = *r.WithContext(auth.WithActor(r.Context(), claims.Subject))
+	return claims, true
+}
+
+// ListSessionsHandler 列出当前用户的登录会话 (GET /sessions)
+func (h *AuthHandler) ListSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	claims, ok := h.authenticate(w, r)
+	if !ok {
+		return
+	}
+	sessions, err := h.authService.Sessions(r.Context(), claims.Subject)
+	if err != nil {
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	resp := make([]sessionResponse, 0, len(sessions))
+	for _, s := range sessions {
+		resp = append(resp, sessionResponse{
+			ID:         s.ID,
+			UserAgent:  s.UserAgent,
+			IP:         s.IP,
+			CreatedAt:  s.CreatedAt,
+			LastUsedAt: s.LastUsedAt,
+			ExpiresAt:  s.ExpiresAt,
+			Current:    s.ID == claims.SessionID,
+		})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"sessions": resp})
+}
+
+// RevokeSessionHandler 结束当前用户的一个会话 (DELETE /sessions/{id})，该会话的访问令牌与刷新令牌立即失效
+func (h *AuthHandler) RevokeSessionHandler(w http.ResponseWriter, r *http.Request) {
+	claims, ok := h.authenticate(w, r)
+	if !ok {
+		return
+	}
+	err := h.authService.RevokeSession(r.Context(), claims.Subject, r.PathValue("id"))
+	switch {
+	case errors.Is(err, auth.ErrSessionNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case err != nil:
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// RevokeAllSessionsHandler 结束当前用户的全部会话，包括发起请求的会话 (DELETE /sessions)
+func (h *AuthHandler) RevokeAllSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	claims, ok := h.authenticate(w, r)
+	if !ok {
+		return
+	}
+	n, err := h.authService.RevokeAllSessions(r.Context(), claims.Subject)
+	if err != nil {
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int{"revoked": n})
+}
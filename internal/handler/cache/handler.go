@@ -0,0 +1,51 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"gateway.example/go-gateway/internal/response"
+	"gateway.example/go-gateway/pkg/logger"
+)
+
+// Purger 能够按路径前缀清除响应缓存
+type Purger interface {
+	PurgeCache(ctx context.Context, pathPrefix string) (int, error)
+}
+
+type CacheHandler struct {
+	purger Purger
+	log    logger.Logger
+}
+
+func NewCacheHandler(purger Purger, log logger.Logger) *CacheHandler {
+	return &CacheHandler{
+		purger: purger,
+		log:    log,
+	}
+}
+
+// Purge 清除响应缓存
+// 查询参数 prefix 指定请求路径前缀，省略时清除全部缓存
+func (h *CacheHandler) Purge(w http.ResponseWriter, r *http.Request) {
+	prefix := r.URL.Query().Get("prefix")
+	count, err := h.purger.PurgeCache(r.Context(), prefix)
+	if err != nil {
+		h.log.Error(r.Context(), "[Handler] 清除响应缓存时出错", "prefix", prefix, "error", err)
+		response.WriteError(w, http.StatusInternalServerError, "清除响应缓存失败")
+		return
+	}
+	h.log.Info(r.Context(), "[Handler] 响应缓存已清除", "prefix", prefix, "count", count)
+
+	resp := map[string]interface{}{
+		"status": "ok",
+		"prefix": prefix,
+		"purged": count,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.log.Error(r.Context(), "[Handler] 编码响应时出错", "error", err)
+	}
+}
@@ -1,11 +1,14 @@
 package circuitbreaker
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"time"
 
 	"gateway.example/go-gateway/internal/config"
+	"gateway.example/go-gateway/internal/response"
 	"gateway.example/go-gateway/internal/service/circuitbreaker"
 	"gateway.example/go-gateway/pkg/logger"
 )
@@ -41,13 +44,21 @@ func (h *CircuitBreakerHandler) Status(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// Reset 重置熔断器，服务名来自路径或 ?service= 参数
 func (h *CircuitBreakerHandler) Reset(w http.ResponseWriter, r *http.Request) {
-	serviceName := r.URL.Query().Get("service")
+	serviceName := r.PathValue("service")
+	if serviceName == "" {
+		serviceName = r.URL.Query().Get("service")
+	}
 	if serviceName == "" {
 		h.log.Error(r.Context(), "[Handler] 重置服务时未提供服务名称")
 		http.Error(w, "缺少服务名称参数", http.StatusBadRequest)
 		return
 	}
+	// 指定 instance 时重置该实例的熔断器 (per_instance)
+	if instance := r.URL.Query().Get("instance"); instance != "" {
+		serviceName = circuitbreaker.InstanceKey(serviceName, instance)
+	}
 	err := h.svc.Reset(r.Context(), serviceName)
 	if err != nil {
 		h.log.Error(r.Context(), fmt.Sprintf("[Handler] 重置服务 %s 时出错", serviceName), "service", serviceName, "error", err)
@@ -67,3 +78,49 @@ func (h *CircuitBreakerHandler) Reset(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+// ForceOpen 强制打开服务的熔断器，用于维护期间主动切走流量。
+// ?duration=30m 指定持续时间 (默认直到 close 或 reset)，?instance= 指定实例 (per_instance)
+func (h *CircuitBreakerHandler) ForceOpen(w http.ResponseWriter, r *http.Request) {
+	h.force(w, r, "open", h.svc.ForceOpen)
+}
+
+// ForceClose 强制关闭服务的熔断器，期间放行所有请求，参数同 ForceOpen
+func (h *CircuitBreakerHandler) ForceClose(w http.ResponseWriter, r *http.Request) {
+	h.force(w, r, "closed", h.svc.ForceClose)
+}
+
+func (h *CircuitBreakerHandler) force(w http.ResponseWriter, r *http.Request, state string,
+	fn func(ctx context.Context, serviceName, instanceURL string, d time.Duration) error) {
+	serviceName := r.PathValue("service")
+	instance := r.URL.Query().Get("instance")
+	var d time.Duration
+	if v := r.URL.Query().Get("duration"); v != "" {
+		var err error
+		if d, err = time.ParseDuration(v); err != nil || d <= 0 {
+			response.WriteError(w, http.StatusBadRequest, "duration 不是有效的时长")
+			return
+		}
+	}
+	if err := fn(r.Context(), serviceName, instance, d); err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	h.log.Warn(r.Context(), "[Handler] 熔断器状态已被强制设置", "service", serviceName, "instance", instance, "state", state, "duration", d.String(), "remote_addr", r.RemoteAddr)
+
+	result := map[string]string{
+		"status":  "ok",
+		"service": serviceName,
+		"state":   state,
+	}
+	if instance != "" {
+		result["instance"] = instance
+	}
+	if d > 0 {
+		result["until"] = time.Now().Add(d).Format(time.RFC3339)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		h.log.Error(r.Context(), "[Handler] 编码响应时出错", "error", err)
+	}
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"gateway.example/go-gateway/internal/response"
+	"gateway.example/go-gateway/pkg/logger"
+)
+
+// Source 提供网关当前生效的配置
+type Source interface {
+	// RedactedConfig 返回隐藏了敏感值的配置，以及路由状态的版本号 (每次重新加载配置加一)
+	RedactedConfig() (generation uint64, cfg map[string]interface{}, err error)
+}
+
+type ConfigHandler struct {
+	source Source
+	log    logger.Logger
+}
+
+func NewConfigHandler(source Source, log logger.Logger) *ConfigHandler {
+	return &ConfigHandler{
+		source: source,
+		log:    log,
+	}
+}
+
+// configResponse GET /admin/config 的响应体
+type configResponse struct {
+	Generation uint64                 `json:"generation"`
+	Config     map[string]interface{} `json:"config"`
+}
+
+// Get 返回网关实际加载的配置 (合并 include、环境配置与环境变量之后)，密钥、密码等敏感值已隐藏
+func (h *ConfigHandler) Get(w http.ResponseWriter, r *http.Request) {
+	generation, cfg, err := h.source.RedactedConfig()
+	if err != nil {
+		h.log.Error(r.Context(), "[Handler] 导出配置时出错", "error", err)
+		response.WriteError(w, http.StatusInternalServerError, "导出配置失败")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(configResponse{Generation: generation, Config: cfg}); err != nil {
+		h.log.Error(r.Context(), "[Handler] 编码响应时出错", "error", err)
+	}
+}
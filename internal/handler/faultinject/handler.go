@@ -0,0 +1,68 @@
+package faultinject
+
+import (
+	"encoding/json"
+	"net/http"
+
+	pl_faultinject "gateway.example/go-gateway/internal/plugin/faultinject"
+	"gateway.example/go-gateway/internal/response"
+	"gateway.example/go-gateway/pkg/logger"
+)
+
+// Switch 能够在运行时开关故障注入
+type Switch interface {
+	State() pl_faultinject.State
+	SetEnabled(id string, enabled bool)
+	Reset()
+}
+
+type FaultHandler struct {
+	faults Switch
+	log    logger.Logger
+}
+
+func NewFaultHandler(faults Switch, log logger.Logger) *FaultHandler {
+	return &FaultHandler{
+		faults: faults,
+		log:    log,
+	}
+}
+
+// toggleRequest 切换开关的请求体，id 省略时切换全局开关
+type toggleRequest struct {
+	ID      string `json:"id"`
+	Enabled *bool  `json:"enabled"`
+}
+
+// Get 返回故障注入的开关状态
+func (h *FaultHandler) Get(w http.ResponseWriter, r *http.Request) {
+	h.writeState(w, r)
+}
+
+// Toggle 打开或关闭故障注入
+// 请求体 {"id": "checkout-chaos", "enabled": true}，省略 id 时切换全局开关
+func (h *FaultHandler) Toggle(w http.ResponseWriter, r *http.Request) {
+	var req toggleRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4096)).Decode(&req); err != nil || req.Enabled == nil {
+		response.WriteError(w, http.StatusBadRequest, `请求体应为 {"id": "...", "enabled": true|false}`)
+		return
+	}
+	h.faults.SetEnabled(req.ID, *req.Enabled)
+	h.log.Warn(r.Context(), "[Handler] 故障注入开关已切换", "id", req.ID, "enabled", *req.Enabled, "remote_addr", r.RemoteAddr)
+	h.writeState(w, r)
+}
+
+// Reset 清除运行时设置的开关，恢复为配置中的初始状态
+func (h *FaultHandler) Reset(w http.ResponseWriter, r *http.Request) {
+	h.faults.Reset()
+	h.log.Warn(r.Context(), "[Handler] 故障注入开关已重置", "remote_addr", r.RemoteAddr)
+	h.writeState(w, r)
+}
+
+func (h *FaultHandler) writeState(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(h.faults.State()); err != nil {
+		h.log.Error(r.Context(), "[Handler] 编码响应时出错", "error", err)
+	}
+}
@@ -0,0 +1,90 @@
+package quota
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"gateway.example/go-gateway/internal/response"
+	svc_quota "gateway.example/go-gateway/internal/service/quota"
+	"gateway.example/go-gateway/pkg/logger"
+)
+
+type QuotaHandler struct {
+	quotaSvc svc_quota.Service
+	log      logger.Logger
+}
+
+func NewQuotaHandler(quotaSvc svc_quota.Service, log logger.Logger) *QuotaHandler {
+	return &QuotaHandler{
+		quotaSvc: quotaSvc,
+		log:      log,
+	}
+}
+
+// Get 查询配额使用情况
+// 查询参数 rule 指定配额规则；identifier 指定标识 (如 user:alice、ip:10.0.0.1)，
+// 也可以用 api_key 直接传入 API Key
+func (h *QuotaHandler) Get(w http.ResponseWriter, r *http.Request) {
+	rule, identifier, ok := h.target(w, r)
+	if !ok {
+		return
+	}
+	usage, err := h.quotaSvc.Usage(r.Context(), rule, identifier)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	h.writeUsage(w, r, usage)
+}
+
+// Reset 清零配额在当前周期的使用量，查询参数与 Get 相同
+func (h *QuotaHandler) Reset(w http.ResponseWriter, r *http.Request) {
+	rule, identifier, ok := h.target(w, r)
+	if !ok {
+		return
+	}
+	if err := h.quotaSvc.Reset(r.Context(), rule, identifier); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	h.log.Warn(r.Context(), "[Handler] 配额已重置", "rule", rule, "identifier", identifier, "remote_addr", r.RemoteAddr)
+
+	usage, err := h.quotaSvc.Usage(r.Context(), rule, identifier)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	h.writeUsage(w, r, usage)
+}
+
+// target 解析查询参数中的规则与标识
+func (h *QuotaHandler) target(w http.ResponseWriter, r *http.Request) (string, string, bool) {
+	query := r.URL.Query()
+	rule, identifier := query.Get("rule"), query.Get("identifier")
+	if apiKey := query.Get("api_key"); apiKey != "" {
+		identifier = svc_quota.APIKeyIdentifier(apiKey)
+	}
+	if rule == "" || identifier == "" {
+		response.WriteError(w, http.StatusBadRequest, "需要查询参数 rule 以及 identifier 或 api_key")
+		return "", "", false
+	}
+	return rule, identifier, true
+}
+
+func (h *QuotaHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, svc_quota.ErrRuleNotFound) {
+		response.WriteError(w, http.StatusNotFound, "配额规则不存在")
+		return
+	}
+	h.log.Error(r.Context(), "[Handler] 读取配额时出错", "error", err)
+	response.WriteError(w, http.StatusInternalServerError, "读取配额失败")
+}
+
+func (h *QuotaHandler) writeUsage(w http.ResponseWriter, r *http.Request, usage svc_quota.Usage) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(usage); err != nil {
+		h.log.Error(r.Context(), "[Handler] 编码响应时出错", "error", err)
+	}
+}
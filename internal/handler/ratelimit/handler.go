@@ -0,0 +1,149 @@
+package ratelimit
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gateway.example/go-gateway/internal/response"
+	svc_ratelimit "gateway.example/go-gateway/internal/service/ratelimit"
+	"gateway.example/go-gateway/pkg/logger"
+)
+
+// maxSyncBodySize 其他副本推送的计数请求体上限
+const maxSyncBodySize = 8 << 20
+
+// defaultOverrideTTL 未指定 ttl 时临时调整的有效期
+const defaultOverrideTTL = time.Hour
+
+// 标识符排行默认与最多返回的条数
+const (
+	defaultTopN = 10
+	maxTopN     = 100
+)
+
+type RateLimitHandler struct {
+	rateLimitSvc svc_ratelimit.Service
+	log          logger.Logger
+}
+
+func NewRateLimitHandler(rateLimitSvc svc_ratelimit.Service, log logger.Logger) *RateLimitHandler {
+	return &RateLimitHandler{
+		rateLimitSvc: rateLimitSvc,
+		log:          log,
+	}
+}
+
+// overrideRequest 临时调整规则的请求体
+type overrideRequest struct {
+	Factor float64 `json:"factor"` // 限额缩放比例，<1 收紧，>1 放宽
+	TTL    string  `json:"ttl"`    // 有效期，如 "30m"，默认 1h
+}
+
+// ListRules 列出所有限流规则当前生效的限额与临时调整
+func (h *RateLimitHandler) ListRules(w http.ResponseWriter, r *http.Request) {
+	h.writeJSON(w, r, h.rateLimitSvc.Rules())
+}
+
+// SetOverride 临时收紧或放宽规则，例如故障期间 {"factor": 0.5, "ttl": "30m"}
+func (h *RateLimitHandler) SetOverride(w http.ResponseWriter, r *http.Request) {
+	var req overrideRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4096)).Decode(&req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, `请求体应为 {"factor": 0.5, "ttl": "30m"}`)
+		return
+	}
+	ttl := defaultOverrideTTL
+	if req.TTL != "" {
+		var err error
+		if ttl, err = time.ParseDuration(req.TTL); err != nil {
+			response.WriteError(w, http.StatusBadRequest, "ttl 不是有效的时长")
+			return
+		}
+	}
+
+	name := r.PathValue("name")
+	state, err := h.rateLimitSvc.SetOverride(name, req.Factor, ttl, svc_ratelimit.OverrideAdmin)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	h.log.Warn(r.Context(), "[Handler] 限流规则已临时调整", "rule", name, "factor", req.Factor, "ttl", ttl.String(), "remote_addr", r.RemoteAddr)
+	h.writeJSON(w, r, state)
+}
+
+// ClearOverride 撤销规则的临时调整，恢复配置中的限额
+func (h *RateLimitHandler) ClearOverride(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	state, err := h.rateLimitSvc.ClearOverride(name)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	h.log.Warn(r.Context(), "[Handler] 限流规则的临时调整已撤销", "rule", name, "remote_addr", r.RemoteAddr)
+	h.writeJSON(w, r, state)
+}
+
+// Top 返回规则最近 1~2 分钟内放行与拒绝次数最多的标识符，?n= 指定条数 (默认 10，最多 100)。
+// 路径中没有规则名时返回所有规则的排行。
+func (h *RateLimitHandler) Top(w http.ResponseWriter, r *http.Request) {
+	n := defaultTopN
+	if v := r.URL.Query().Get("n"); v != "" {
+		var err error
+		if n, err = strconv.Atoi(v); err != nil || n <= 0 || n > maxTopN {
+			response.WriteError(w, http.StatusBadRequest, "n 应为 1 到 100 之间的整数")
+			return
+		}
+	}
+
+	if name := r.PathValue("name"); name != "" {
+		report, err := h.rateLimitSvc.TopIdentifiers(name, n)
+		if err != nil {
+			h.writeError(w, err)
+			return
+		}
+		h.writeJSON(w, r, report)
+		return
+	}
+
+	rules := h.rateLimitSvc.Rules()
+	reports := make([]svc_ratelimit.TopReport, 0, len(rules))
+	for _, rule := range rules {
+		// 规则可能在遍历期间被重新加载移除
+		if report, err := h.rateLimitSvc.TopIdentifiers(rule.Name, n); err == nil {
+			reports = append(reports, report)
+		}
+	}
+	h.writeJSON(w, r, reports)
+}
+
+// Sync 接收其他副本推送的 cluster_window 规则计数
+func (h *RateLimitHandler) Sync(w http.ResponseWriter, r *http.Request) {
+	var counts svc_ratelimit.ClusterCounts
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxSyncBodySize)).Decode(&counts); err != nil {
+		response.WriteError(w, http.StatusBadRequest, "计数格式无效")
+		return
+	}
+	if err := h.rateLimitSvc.MergeClusterCounts(counts); err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *RateLimitHandler) writeError(w http.ResponseWriter, err error) {
+	if errors.Is(err, svc_ratelimit.ErrRuleNotFound) {
+		response.WriteError(w, http.StatusNotFound, "限流规则不存在")
+		return
+	}
+	response.WriteError(w, http.StatusBadRequest, err.Error())
+}
+
+func (h *RateLimitHandler) writeJSON(w http.ResponseWriter, r *http.Request, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		h.log.Error(r.Context(), "[Handler] 编码响应时出错", "error", err)
+	}
+}
@@ -0,0 +1,16 @@
+// file: internal/models/refresh_token.go
+package models
+
+import "time"
+
+// RefreshToken 是一个已签发的刷新令牌。令牌本身只返回给客户端一次，这里只保存其 SHA-256 摘要。
+// 同一次登录轮换出的令牌属于同一个家族 (FamilyID)，检测到已使用的令牌被重放时整个家族作废
+type RefreshToken struct {
+	TokenHash string
+	FamilyID  string
+	UserID    string
+	Username  string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+	Used      bool // 已经换取过新令牌
+}
@@ -0,0 +1,16 @@
+// file: internal/models/session.go
+package models
+
+import "time"
+
+// Session 一次登录会话。会话 ID 与该次登录的刷新令牌家族 ID 相同，访问令牌通过 sid claim 关联会话
+type Session struct {
+	ID         string
+	UserID     string
+	Username   string
+	UserAgent  string // 登录时的 User-Agent，用于识别设备
+	IP         string // 登录时的客户端 IP
+	CreatedAt  time.Time
+	LastUsedAt time.Time // 最近一次刷新令牌的时间
+	ExpiresAt  time.Time // 刷新令牌过期后会话结束
+}
@@ -6,5 +6,14 @@ package models
 type User struct {
 	ID       string
 	Username string
-	Password string // 在真实项目中，这里应该是密码的哈希值
+	// PasswordHash 密码的 bcrypt 哈希。早期数据可能仍是明文，用户下次登录成功后自动替换为哈希
+	PasswordHash string
+	// 以下字段写入访问令牌，供网关按角色、租户与 scope 做授权
+	Roles    []string
+	TenantID string
+	Scopes   []string
+	// Disabled 被管理员停用的账户无法登录，也无法刷新令牌
+	Disabled bool
+	// Provider 外部身份源 (如 ldap、oidc) 的账户在首次登录时自动创建，密码由身份源管理，本地为空
+	Provider string
 }
@@ -0,0 +1,253 @@
+// file: internal/plugin/abtest/plugin.go
+package abtest
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"time"
+
+	"gateway.example/go-gateway/internal/config"
+	"gateway.example/go-gateway/internal/plugin"
+	"gateway.example/go-gateway/pkg/logger"
+)
+
+const PluginName = "ab_test"
+
+// 默认参数
+const (
+	DefaultBucketHeader = "X-Experiment-Bucket"
+	defaultCookieMaxAge = 30 * 24 * time.Hour
+)
+
+// Plugin 把请求确定性地分配到实验分桶，并通过请求头告知上游所在分桶；
+// 分桶可以指定 service，把该分桶的流量改投到另一个服务 (如新版本)。
+// 分桶依据 hash_by 指定的用户标识计算，同一用户总是落在同一个分桶；
+// 没有标识时随机分配，并通过 Cookie 保持粘性。
+// 配置示例:
+//
+//	plugins:
+//	  - name: "ab_test"
+//	    experiment: "checkout-v2"
+//	    hash_by: "header:X-User-ID"      # header:<名称> / cookie:<名称> / ip
+//	    buckets:
+//	      - { name: "control", weight: 90 }
+//	      - { name: "v2", weight: 10, service: "service-a-v2" }
+//	    cookie: "ab_checkout-v2"         # 粘性 Cookie，默认 ab_<experiment>
+//	    sticky: true
+//
+// 路由开启响应缓存时，应把 X-Experiment-Bucket 加入 cache.vary，避免不同分桶共用缓存。
+type Plugin struct {
+	services map[string]config.ServiceConfig
+	log      logger.Logger
+}
+
+// bucket 是一个实验分桶
+type bucket struct {
+	name    string
+	weight  int
+	service string
+}
+
+// experiment 是插件配置解析后的实验定义
+type experiment struct {
+	name         string
+	hashSource   string // header / cookie / ip
+	hashKey      string
+	buckets      []bucket
+	totalWeight  int
+	header       string
+	cookie       string
+	sticky       bool
+	cookieMaxAge time.Duration
+}
+
+// NewPlugin 创建 A/B 实验插件，services 用于校验分桶引用的服务是否存在
+func NewPlugin(services map[string]config.ServiceConfig, log logger.Logger) *Plugin {
+	return &Plugin{services: services, log: log}
+}
+
+// SetServices 在网关重新加载配置时更新用于校验的服务列表
+func (p *Plugin) SetServices(services map[string]config.ServiceConfig) {
+	p.services = services
+}
+
+func (p *Plugin) Name() string {
+	return PluginName
+}
+
+func (p *Plugin) ValidateConfig(params config.PluginSpec) error {
+	exp, err := parseExperiment(params)
+	if err != nil {
+		return err
+	}
+	for _, b := range exp.buckets {
+		if b.service == "" {
+			continue
+		}
+		if _, ok := p.services[b.service]; !ok {
+			return fmt.Errorf("分桶 '%s' 引用的服务 '%s' 未在 services 中定义", b.name, b.service)
+		}
+	}
+	return nil
+}
+
+func (p *Plugin) Execute(w http.ResponseWriter, r *http.Request, params config.PluginSpec) (bool, error) {
+	ctx := r.Context()
+
+	exp, err := parseExperiment(params)
+	if err != nil {
+		p.log.Error(ctx, fmt.Sprintf("[插件: %s] 配置错误: %v", p.Name(), err))
+		http.Error(w, "内部服务器错误: 插件配置错误", http.StatusInternalServerError)
+		return false, fmt.Errorf("插件 '%s' 配置错误: %w", p.Name(), err)
+	}
+
+	b, fromCookie := exp.assign(r)
+	if exp.sticky && !fromCookie {
+		http.SetCookie(w, &http.Cookie{
+			Name:     exp.cookie,
+			Value:    b.name,
+			Path:     "/",
+			MaxAge:   int(exp.cookieMaxAge.Seconds()),
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		})
+	}
+
+	r.Header.Set(exp.header, b.name)
+	if b.service != "" {
+		plugin.SetTargetService(r, b.service)
+	}
+	p.log.Debug(ctx, "[插件] 请求分配到实验分桶", "plugin", p.Name(), "experiment", exp.name, "bucket", b.name, "service", b.service)
+	return true, nil
+}
+
+// assign 为请求选择分桶: 有效的粘性 Cookie 优先，其次按用户标识哈希，最后随机
+func (e *experiment) assign(r *http.Request) (*bucket, bool) {
+	if e.sticky {
+		if c, err := r.Cookie(e.cookie); err == nil {
+			for i := range e.buckets {
+				if e.buckets[i].name == c.Value {
+					return &e.buckets[i], true
+				}
+			}
+		}
+	}
+
+	var n int
+	if key := e.identity(r); key != "" {
+		sum := sha256.Sum256([]byte(e.name + ":" + key))
+		n = int(binary.BigEndian.Uint64(sum[:8]) % uint64(e.totalWeight))
+	} else {
+		n = rand.IntN(e.totalWeight)
+	}
+	for i := range e.buckets {
+		if n < e.buckets[i].weight {
+			return &e.buckets[i], false
+		}
+		n -= e.buckets[i].weight
+	}
+	return &e.buckets[len(e.buckets)-1], false
+}
+
+// identity 返回用于哈希分桶的用户标识
+func (e *experiment) identity(r *http.Request) string {
+	switch e.hashSource {
+	case "header":
+		return r.Header.Get(e.hashKey)
+	case "cookie":
+		if c, err := r.Cookie(e.hashKey); err == nil {
+			return c.Value
+		}
+	case "ip":
+		return plugin.ClientIP(r)
+	}
+	return ""
+}
+
+func parseExperiment(params config.PluginSpec) (*experiment, error) {
+	e := &experiment{header: DefaultBucketHeader, sticky: true, cookieMaxAge: defaultCookieMaxAge}
+
+	e.name, _ = params["experiment"].(string)
+	if e.name == "" {
+		return nil, fmt.Errorf("缺少 'experiment' 配置")
+	}
+	e.cookie = "ab_" + e.name
+
+	if v, ok := params["hash_by"]; ok && v != nil {
+		s, _ := v.(string)
+		source, key, _ := strings.Cut(s, ":")
+		switch {
+		case source == "ip" && key == "":
+		case (source == "header" || source == "cookie") && key != "":
+		default:
+			return nil, fmt.Errorf("配置 'hash_by' 格式应为 header:<名称>、cookie:<名称> 或 ip")
+		}
+		e.hashSource, e.hashKey = source, key
+	}
+
+	list, ok := params["buckets"].([]interface{})
+	if !ok || len(list) == 0 {
+		return nil, fmt.Errorf("缺少 'buckets' 配置")
+	}
+	seen := make(map[string]bool, len(list))
+	for i, item := range list {
+		m, isMap := item.(map[interface{}]interface{})
+		if !isMap {
+			return nil, fmt.Errorf("buckets[%d] 应为映射", i)
+		}
+		b := bucket{weight: 1}
+		b.name, _ = m["name"].(string)
+		if b.name == "" || seen[b.name] {
+			return nil, fmt.Errorf("buckets[%d] 的 name 为空或重复", i)
+		}
+		seen[b.name] = true
+		if w, exists := m["weight"]; exists {
+			weight, isInt := w.(int)
+			if !isInt || weight < 0 {
+				return nil, fmt.Errorf("分桶 '%s' 的 weight 应为非负整数", b.name)
+			}
+			b.weight = weight
+		}
+		if s, exists := m["service"]; exists && s != nil {
+			if b.service, ok = s.(string); !ok {
+				return nil, fmt.Errorf("分桶 '%s' 的 service 应为字符串", b.name)
+			}
+		}
+		e.totalWeight += b.weight
+		e.buckets = append(e.buckets, b)
+	}
+	if e.totalWeight == 0 {
+		return nil, fmt.Errorf("所有分桶的 weight 之和必须大于 0")
+	}
+
+	stringFields := map[string]*string{"header": &e.header, "cookie": &e.cookie}
+	for key, target := range stringFields {
+		if v, ok := params[key]; ok && v != nil {
+			s, isString := v.(string)
+			if !isString || s == "" {
+				return nil, fmt.Errorf("配置 '%s' 应为非空字符串", key)
+			}
+			*target = s
+		}
+	}
+	if v, ok := params["sticky"]; ok && v != nil {
+		b, isBool := v.(bool)
+		if !isBool {
+			return nil, fmt.Errorf("配置 'sticky' 应为布尔值")
+		}
+		e.sticky = b
+	}
+	maxAge, err := plugin.Duration(params, "cookie_max_age", e.cookieMaxAge)
+	if err != nil {
+		return nil, err
+	}
+	if maxAge <= 0 {
+		return nil, fmt.Errorf("配置 'cookie_max_age' 应为正的时长")
+	}
+	e.cookieMaxAge = maxAge
+	return e, nil
+}
@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"gateway.example/go-gateway/internal/config"
+	"gateway.example/go-gateway/internal/plugin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// validatedKeyPrefix 校验结果在缓存中的键前缀，键中只保存 Token 的摘要
+const validatedKeyPrefix = "auth:validated:"
+
+// parseValidationCacheTTL 解析 validation_cache_ttl，未配置时不缓存校验结果
+func parseValidationCacheTTL(params config.PluginSpec) (time.Duration, error) {
+	return plugin.Duration(params, "validation_cache_ttl", 0)
+}
+
+func validatedKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return validatedKeyPrefix + hex.EncodeToString(sum[:])
+}
+
+// cachedValid 查询 Token 最近是否已通过认证服务校验，缓存不可用时视为未命中
+func (p *Plugin) cachedValid(ctx context.Context, token string) bool {
+	_, err := p.store.Get(ctx, validatedKey(token))
+	return err == nil
+}
+
+// cacheValid 缓存一次成功的校验结果，有效期不超过 Token 的剩余有效期
+func (p *Plugin) cacheValid(ctx context.Context, token string, ttl time.Duration) {
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(token, claims); err != nil {
+		return
+	}
+	if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+		ttl = min(ttl, time.Until(exp.Time))
+	}
+	if ttl <= 0 {
+		return
+	}
+	if err := p.store.Set(ctx, validatedKey(token), []byte("1"), ttl); err != nil {
+		p.log.Warn(ctx, fmt.Sprintf("[插件: %s] 缓存校验结果失败: %v", p.Name(), err))
+	}
+}
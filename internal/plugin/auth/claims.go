@@ -0,0 +1,206 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gateway.example/go-gateway/internal/config"
+	"gateway.example/go-gateway/internal/plugin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// defaultClaimHeaders 开启 forward_claims 但未配置 claims_to_headers 时使用的映射
+var defaultClaimHeaders = map[string]string{
+	"sub":       "X-User-ID",
+	"roles":     "X-User-Roles",
+	"tenant_id": "X-Tenant-ID",
+	"client_id": "X-Client-ID",
+}
+
+// claimOptions 是插件配置中与 claim 透传相关的选项
+type claimOptions struct {
+	headers            map[string]string // claim 名 -> 请求头名
+	stripAuthorization bool
+	tierClaim          string   // 记录为套餐等级的 claim，供限流插件按等级选择规则
+	requiredRoles      []string // 满足其一即可
+	requiredScopes     []string // 必须全部具备
+	principal          string   // 允许的调用方类型: any / user / service
+}
+
+// 调用方类型，机器令牌 (client_credentials) 携带 client_id 声明
+const (
+	principalAny     = "any"
+	principalUser    = "user"
+	principalService = "service"
+)
+
+// parseClaimOptions 解析插件配置:
+//
+//	forward_claims: true                 # 使用默认映射 sub -> X-User-ID, roles -> X-User-Roles, tenant_id -> X-Tenant-ID, client_id -> X-Client-ID
+//	claims_to_headers: { tenant: "X-Tenant-ID" }  # 自定义映射，配置后覆盖默认映射
+//	strip_authorization: true            # 校验通过后不再把原始 Token 转发给上游
+//	tier_claim: "plan"                   # 把该 claim 记录为套餐等级，供限流插件的 tiers 使用
+//	required_roles: [ "admin" ]          # Token 的 roles 至少包含其中一个，否则返回 403
+//	required_scopes: [ "orders:read" ]   # Token 的 scope 必须全部包含，否则返回 403
+//	principal: "service"                 # 只接受机器令牌 (service) 或用户令牌 (user)，默认 any
+func parseClaimOptions(params config.PluginSpec) (*claimOptions, error) {
+	opts := &claimOptions{}
+	forward, err := boolParam(params, "forward_claims")
+	if err != nil {
+		return nil, err
+	}
+	if opts.stripAuthorization, err = boolParam(params, "strip_authorization"); err != nil {
+		return nil, err
+	}
+	if v, ok := params["tier_claim"]; ok && v != nil {
+		if opts.tierClaim, ok = v.(string); !ok || opts.tierClaim == "" {
+			return nil, fmt.Errorf("配置 'tier_claim' 应为非空字符串")
+		}
+	}
+
+	if opts.requiredRoles, err = plugin.StringList(params, "required_roles"); err != nil {
+		return nil, err
+	}
+	if opts.requiredScopes, err = plugin.StringList(params, "required_scopes"); err != nil {
+		return nil, err
+	}
+	opts.principal = principalAny
+	if v, ok := params["principal"]; ok && v != nil {
+		opts.principal, _ = v.(string)
+		switch opts.principal {
+		case principalAny, principalUser, principalService:
+		default:
+			return nil, fmt.Errorf("配置 'principal' 应为 %s、%s 或 %s", principalAny, principalUser, principalService)
+		}
+	}
+
+	if raw, ok := params["claims_to_headers"]; ok && raw != nil {
+		var m map[string]string
+		switch mapping := raw.(type) {
+		case map[string]interface{}:
+			m = make(map[string]string, len(mapping))
+			for claim, header := range mapping {
+				m[claim] = fmt.Sprint(header)
+			}
+		case map[interface{}]interface{}:
+			m = make(map[string]string, len(mapping))
+			for claim, header := range mapping {
+				m[fmt.Sprint(claim)] = fmt.Sprint(header)
+			}
+		default:
+			return nil, fmt.Errorf("配置 'claims_to_headers' 类型不正确")
+		}
+		opts.headers = m
+		return opts, nil
+	}
+
+	if forward {
+		opts.headers = defaultClaimHeaders
+	}
+	return opts, nil
+}
+
+func boolParam(params config.PluginSpec, key string) (bool, error) {
+	v, ok := params[key]
+	if !ok || v == nil {
+		return false, nil
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("配置 '%s' 必须是布尔值", key)
+	}
+	return b, nil
+}
+
+// authorize 检查 Token 的调用方类型、角色与 scope，返回拒绝原因；满足要求时返回空串
+func (o *claimOptions) authorize(token string) (reason string) {
+	if o.principal == principalAny && len(o.requiredRoles) == 0 && len(o.requiredScopes) == 0 {
+		return ""
+	}
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(token, claims); err != nil {
+		return "insufficient_role"
+	}
+	machine := claimString(claims["client_id"]) != ""
+	switch {
+	case o.principal == principalService && !machine:
+		return "service_token_required"
+	case o.principal == principalUser && machine:
+		return "user_token_required"
+	}
+	if len(o.requiredRoles) > 0 {
+		roles := strings.Split(claimString(claims["roles"]), ",")
+		if !slices.ContainsFunc(o.requiredRoles, func(r string) bool { return slices.Contains(roles, r) }) {
+			return "insufficient_role"
+		}
+	}
+	scopes := strings.Fields(claimString(claims["scope"]))
+	for _, s := range o.requiredScopes {
+		if !slices.Contains(scopes, s) {
+			return "insufficient_scope"
+		}
+	}
+	return ""
+}
+
+// removeClaimHeaders 删除客户端自带的同名请求头，防止伪造用户身份
+func (o *claimOptions) removeClaimHeaders(r *http.Request) {
+	for _, header := range o.headers {
+		r.Header.Del(header)
+	}
+}
+
+// forwardClaims 解析已通过认证服务校验的 Token 载荷，把配置的 claim 写入请求头
+// 签名已由认证服务验证，这里只解析载荷，不重复验签
+func (o *claimOptions) forwardClaims(r *http.Request, token string) error {
+	if len(o.headers) == 0 {
+		return nil
+	}
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(token, claims); err != nil {
+		return fmt.Errorf("解析 Token 载荷失败: %w", err)
+	}
+	for claim, header := range o.headers {
+		if v, ok := claims[claim]; ok {
+			// 含控制字符的值无法作为请求头转发，直接跳过
+			if s := claimString(v); s != "" && !strings.ContainsAny(s, "\r\n\x00") {
+				r.Header.Set(header, s)
+			}
+		}
+	}
+	return nil
+}
+
+// tokenClaim 返回已通过认证服务校验的 Token 中指定 claim 的字符串值，解析失败时返回空串
+func tokenClaim(token, claim string) string {
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(token, claims); err != nil {
+		return ""
+	}
+	return claimString(claims[claim])
+}
+
+// claimString 将 claim 值转换为头部可用的字符串，数组以逗号连接
+func claimString(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case []interface{}:
+		items := make([]string, 0, len(val))
+		for _, item := range val {
+			items = append(items, claimString(item))
+		}
+		return strings.Join(items, ",")
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(val)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(val)
+	}
+}
@@ -0,0 +1,261 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"gateway.example/go-gateway/internal/config"
+	"gateway.example/go-gateway/internal/plugin"
+	svc_auth "gateway.example/go-gateway/internal/service/auth"
+	"gateway.example/go-gateway/pkg/jwks"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// 校验模式
+const (
+	modeRemote = "remote" // 每个请求调用认证服务的 /validate
+	modeLocal  = "local"  // 插件自行验签，不再逐个请求调用认证服务
+)
+
+// 本地校验的默认参数
+const (
+	defaultIssuer       = "auth-service"
+	defaultJWKSCacheTTL = 5 * time.Minute
+	// minRefreshInterval 遇到未知 kid 时强制刷新 JWKS 的最小间隔，防止伪造 kid 的请求打爆认证服务
+	minRefreshInterval = 30 * time.Second
+	maxDocumentSize    = 1 << 20
+)
+
+// errKeyUnavailable 无法从认证服务获取校验公钥
+var errKeyUnavailable = errors.New("无法获取认证服务的校验公钥")
+
+// localOptions 是 mode: local 时的配置:
+//
+//	mode: "local"
+//	secret_key: "..."                # 可选，HS256 共享密钥；不配置时从认证服务的 /.well-known/jwks.json 获取公钥
+//	issuer: "auth-service"           # 校验 iss，默认 auth-service
+//	jwks_cache_ttl: "5m"             # 公钥缓存时间，遇到未知 kid 时提前刷新
+//	leeway: "30s"                    # 允许的时钟偏差
+//	fallback_remote: true            # 无法获取公钥时改为调用认证服务校验
+//	revocation_sync_interval: "30s"  # 定期从认证服务同步吊销列表，与认证服务共用缓存时无需开启
+type localOptions struct {
+	secret         []byte
+	issuer         string
+	cacheTTL       time.Duration
+	leeway         time.Duration
+	fallbackRemote bool
+	syncInterval   time.Duration
+}
+
+// parseLocalOptions 解析校验模式，mode 为 remote (默认) 时返回 nil
+func parseLocalOptions(params config.PluginSpec) (*localOptions, error) {
+	mode := modeRemote
+	if v, ok := params["mode"]; ok && v != nil {
+		if mode, ok = v.(string); !ok {
+			return nil, fmt.Errorf("配置 'mode' 应为字符串")
+		}
+	}
+	switch mode {
+	case modeRemote:
+		return nil, nil
+	case modeLocal:
+	default:
+		return nil, fmt.Errorf("不支持的校验模式 '%s' (可选: %s, %s)", mode, modeRemote, modeLocal)
+	}
+
+	o := &localOptions{issuer: defaultIssuer, cacheTTL: defaultJWKSCacheTTL}
+	if v, ok := params["secret_key"]; ok && v != nil {
+		s, isString := v.(string)
+		if !isString || s == "" {
+			return nil, fmt.Errorf("配置 'secret_key' 应为非空字符串")
+		}
+		o.secret = []byte(s)
+	}
+	if v, ok := params["issuer"]; ok && v != nil {
+		if o.issuer, ok = v.(string); !ok {
+			return nil, fmt.Errorf("配置 'issuer' 应为字符串")
+		}
+	}
+	var err error
+	if o.fallbackRemote, err = boolParam(params, "fallback_remote"); err != nil {
+		return nil, err
+	}
+
+	durations := map[string]*time.Duration{
+		"jwks_cache_ttl":           &o.cacheTTL,
+		"leeway":                   &o.leeway,
+		"revocation_sync_interval": &o.syncInterval,
+	}
+	for key, target := range durations {
+		d, err := plugin.Duration(params, key, *target)
+		if err != nil {
+			return nil, err
+		}
+		*target = d
+	}
+	if o.syncInterval > 0 && o.syncInterval < time.Second {
+		return nil, fmt.Errorf("配置 'revocation_sync_interval' 不能小于 1s")
+	}
+	return o, nil
+}
+
+// verifyLocal 在网关内校验 Token 的签名、过期时间与签发方。
+// 吊销状态在调用前已通过共享缓存检查；无法获取公钥时返回 errKeyUnavailable
+func (p *Plugin) verifyLocal(ctx context.Context, token string, opts *localOptions) error {
+	parserOpts := []jwt.ParserOption{jwt.WithExpirationRequired(), jwt.WithLeeway(opts.leeway)}
+	if opts.issuer != "" {
+		parserOpts = append(parserOpts, jwt.WithIssuer(opts.issuer))
+	}
+
+	var keyFunc jwt.Keyfunc
+	if opts.secret != nil {
+		parserOpts = append(parserOpts, jwt.WithValidMethods([]string{svc_auth.AlgHS256}))
+		keyFunc = func(*jwt.Token) (interface{}, error) { return opts.secret, nil }
+	} else {
+		parserOpts = append(parserOpts, jwt.WithValidMethods([]string{svc_auth.AlgRS256, svc_auth.AlgES256}))
+		// 拉取公钥不应随客户端断开而中止，否则失败结果会影响后续请求
+		fetchCtx := context.WithoutCancel(ctx)
+		keyFunc = func(t *jwt.Token) (interface{}, error) {
+			kid, _ := t.Header["kid"].(string)
+			return p.keys.key(fetchCtx, p, kid, opts.cacheTTL)
+		}
+	}
+
+	_, err := jwt.NewParser(parserOpts...).Parse(token, keyFunc)
+	return err
+}
+
+// keySet 缓存认证服务通过 JWKS 发布的公钥
+type keySet struct {
+	mu          sync.Mutex
+	keys        map[string]crypto.PublicKey // kid -> 公钥
+	fetchedAt   time.Time
+	lastAttempt time.Time
+	lastErr     error
+}
+
+// key 返回 kid 对应的公钥。缓存过期或 kid 未知时刷新 JWKS (认证服务轮换密钥后即可识别新 kid)；
+// 刷新失败但缓存中仍有该 kid 时继续使用旧公钥
+func (s *keySet) key(ctx context.Context, p *Plugin, kid string, ttl time.Duration) (crypto.PublicKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	k, known := s.lookup(kid)
+	if known && now.Sub(s.fetchedAt) < ttl {
+		return k, nil
+	}
+	if now.Sub(s.lastAttempt) < minRefreshInterval {
+		switch {
+		case known:
+			return k, nil
+		case s.lastErr != nil:
+			return nil, s.lastErr
+		default:
+			return nil, fmt.Errorf("未知的签名密钥 kid '%s'", kid)
+		}
+	}
+
+	s.lastAttempt = now
+	var set jwks.Set
+	if err := p.getJSON(ctx, "/.well-known/jwks.json", &set); err != nil {
+		s.lastErr = fmt.Errorf("%w: %v", errKeyUnavailable, err)
+		if known {
+			return k, nil
+		}
+		return nil, s.lastErr
+	}
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		if pub, err := jwk.PublicKey(); err == nil {
+			keys[jwk.Kid] = pub
+		}
+	}
+	s.keys, s.lastErr, s.fetchedAt = keys, nil, now
+
+	if k, known = s.lookup(kid); !known {
+		return nil, fmt.Errorf("未知的签名密钥 kid '%s'", kid)
+	}
+	return k, nil
+}
+
+// lookup 按 kid 查找公钥；Token 未携带 kid 且 JWKS 只有一个密钥时使用该密钥
+func (s *keySet) lookup(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(s.keys) == 1 {
+		for _, k := range s.keys {
+			return k, true
+		}
+	}
+	k, ok := s.keys[kid]
+	return k, ok
+}
+
+// startRevocationSync 启动吊销列表同步，整个插件只启动一次，间隔取第一次遇到的配置
+func (p *Plugin) startRevocationSync(interval time.Duration) {
+	p.syncOnce.Do(func() {
+		p.log.Info(context.Background(), fmt.Sprintf("[插件: %s] 开始同步吊销列表，间隔 %s", p.Name(), interval))
+		go func() {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				if err := p.syncRevocations(context.Background()); err != nil {
+					p.log.Warn(context.Background(), fmt.Sprintf("[插件: %s] 同步吊销列表失败: %v", p.Name(), err))
+				}
+				<-ticker.C
+			}
+		}()
+	})
+}
+
+// syncRevocations 把认证服务的吊销列表写入网关的缓存，记录保留到令牌过期为止
+func (p *Plugin) syncRevocations(ctx context.Context) error {
+	var body struct {
+		Revocations []svc_auth.Revocation `json:"revocations"`
+	}
+	if err := p.getJSON(ctx, "/revocations", &body); err != nil {
+		return err
+	}
+	for _, rev := range body.Revocations {
+		ttl := time.Until(time.Unix(rev.ExpiresAt, 0))
+		if (rev.JTI == "" && rev.SessionID == "") || ttl <= 0 {
+			continue
+		}
+		if err := p.store.Set(ctx, rev.Key(), []byte("synced"), ttl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getJSON 向一个健康的认证服务实例发起 GET 请求并解析 JSON 响应
+func (p *Plugin) getJSON(ctx context.Context, path string, v interface{}) error {
+	lb := p.lbFactory.GetOrCreateLoadBalancer(p.serviceName, "round_robin")
+	instance, err := p.getHealthyInstance(lb)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, instance.URL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s 返回状态码 %d", path, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, maxDocumentSize)).Decode(v)
+}
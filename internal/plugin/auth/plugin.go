@@ -2,14 +2,19 @@
 package auth
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
+	"gateway.example/go-gateway/internal/cache"
 	"gateway.example/go-gateway/internal/config" // ★ 引入 config 包
 	"gateway.example/go-gateway/internal/core/health"
 	"gateway.example/go-gateway/internal/core/loadbalancer"
+	"gateway.example/go-gateway/internal/plugin"
+	svc_auth "gateway.example/go-gateway/internal/service/auth"
 	"gateway.example/go-gateway/pkg/logger"
 )
 
@@ -23,13 +28,18 @@ type Plugin struct {
 	lbFactory     *loadbalancer.LoadBalancerFactory
 	healthChecker *health.HealthChecker
 	serviceName   string
+	store         cache.Cache // 与认证服务共享的吊销列表
 	log           logger.Logger
+
+	keys     keySet    // mode: local 时从认证服务获取的校验公钥
+	syncOnce sync.Once // 吊销列表同步只启动一次
 }
 
 // NewPlugin 创建一个新的认证插件实例
-func NewPlugin(lbFactory *loadbalancer.LoadBalancerFactory, hc *health.HealthChecker, serviceName string, log logger.Logger) (*Plugin, error) {
-	if lbFactory == nil || hc == nil || serviceName == "" || log == nil {
-		return nil, fmt.Errorf("插件初始化参数缺失: lbFactory, hc, serviceName, log 不能为空")
+// store 为网关的共享缓存，认证服务使用同一个缓存后端 (如 Redis) 时插件可以不经过认证服务直接拒绝已吊销的令牌
+func NewPlugin(lbFactory *loadbalancer.LoadBalancerFactory, hc *health.HealthChecker, serviceName string, store cache.Cache, log logger.Logger) (*Plugin, error) {
+	if lbFactory == nil || hc == nil || serviceName == "" || store == nil || log == nil {
+		return nil, fmt.Errorf("插件初始化参数缺失: lbFactory, hc, serviceName, store, log 不能为空")
 	}
 	return &Plugin{
 		client: &http.Client{
@@ -38,22 +48,43 @@ func NewPlugin(lbFactory *loadbalancer.LoadBalancerFactory, hc *health.HealthChe
 		lbFactory:     lbFactory,
 		healthChecker: hc,
 		serviceName:   serviceName,
+		store:         store,
 		log:           log,
 	}, nil
 }
 
 // Execute 方法中修改验证请求的URL获取方式
 func (p *Plugin) Execute(w http.ResponseWriter, r *http.Request, pluginCfg config.PluginSpec) (bool, error) {
-	// (未使用 pluginCfg 参数，但签名必须匹配)
-	_ = pluginCfg
-
 	p.log.Info(r.Context(), fmt.Sprintf("[插件: %s] 开始执行...", p.Name()))
 
+	claimOpts, err := parseClaimOptions(pluginCfg)
+	if err != nil {
+		p.log.Error(r.Context(), fmt.Sprintf("[插件: %s] 配置错误: %v", p.Name(), err))
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return false, err
+	}
+	localOpts, err := parseLocalOptions(pluginCfg)
+	if err != nil {
+		p.log.Error(r.Context(), fmt.Sprintf("[插件: %s] 配置错误: %v", p.Name(), err))
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return false, err
+	}
+	cacheTTL, err := parseValidationCacheTTL(pluginCfg)
+	if err != nil {
+		p.log.Error(r.Context(), fmt.Sprintf("[插件: %s] 配置错误: %v", p.Name(), err))
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return false, err
+	}
+	if localOpts != nil && localOpts.syncInterval > 0 {
+		p.startRevocationSync(localOpts.syncInterval)
+	}
+	claimOpts.removeClaimHeaders(r)
+
 	// 1. --- 从 Header 中获取 Authorization ---
 	authHeader := r.Header.Get("Authorization")
 	if authHeader == "" {
 		p.log.Info(r.Context(), fmt.Sprintf("[插件: %s] 未授权: 缺少 Authorization 请求头", p.Name()))
-		http.Error(w, "Unauthorized: Authorization header required", http.StatusUnauthorized)
+		plugin.Block(w, http.StatusUnauthorized, "missing_token", "缺少 Authorization 请求头")
 		return false, nil // 中断执行链
 	}
 
@@ -61,26 +92,115 @@ func (p *Plugin) Execute(w http.ResponseWriter, r *http.Request, pluginCfg confi
 	parts := strings.Split(authHeader, " ")
 	if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
 		p.log.Info(r.Context(), fmt.Sprintf("[插件: %s] 未授权: Authorization 请求头格式无效", p.Name()))
-		http.Error(w, `Unauthorized: Invalid Authorization header format (expected "Bearer <token>")`, http.StatusUnauthorized)
+		plugin.Block(w, http.StatusUnauthorized, "invalid_authorization_header", `Authorization 请求头格式无效 (应为 "Bearer <token>")`)
+		return false, nil
+	}
+
+	// 已吊销的令牌或已注销的会话直接拒绝；查询失败时交给认证服务判断
+	revoked, err := svc_auth.IsRevoked(r.Context(), p.store, tokenClaim(parts[1], "jti"))
+	if err == nil && !revoked {
+		revoked, err = svc_auth.IsSessionRevoked(r.Context(), p.store, tokenClaim(parts[1], "sid"))
+	}
+	if err != nil {
+		p.log.Warn(r.Context(), fmt.Sprintf("[插件: %s] 查询令牌吊销列表失败: %v", p.Name(), err))
+	}
+	if revoked {
+		p.log.Info(r.Context(), fmt.Sprintf("[插件: %s] 未授权: Token 已被吊销", p.Name()))
+		plugin.Block(w, http.StatusUnauthorized, "token_revoked", "Token 已被吊销")
+		return false, nil
+	}
+
+	// 3. --- 本地验签或调用认证服务校验 Token ---
+	valid, err := p.validate(r, parts[1], authHeader, localOpts, cacheTTL)
+	if err != nil {
+		http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+		return false, err
+	}
+
+	// 4. --- 根据校验结果决定是否放行 ---
+	if valid {
+		if reason := claimOpts.authorize(parts[1]); reason != "" {
+			p.log.Info(r.Context(), fmt.Sprintf("[插件: %s] 禁止访问: Token 类型、角色或 scope 不满足要求 (%s)", p.Name(), reason))
+			plugin.Block(w, http.StatusForbidden, reason, "Token 类型、角色或 scope 不满足要求")
+			return false, nil
+		}
+		p.log.Info(r.Context(), fmt.Sprintf("[插件: %s] 授权成功: Token 有效", p.Name()))
+		if sub := tokenClaim(parts[1], "sub"); sub != "" {
+			plugin.SetSubject(r, sub)
+		}
+		if claimOpts.tierClaim != "" {
+			if tier := tokenClaim(parts[1], claimOpts.tierClaim); tier != "" {
+				plugin.SetTier(r, tier)
+			}
+		}
+		// 5. --- 把 claim 透传给上游，按需移除原始 Token ---
+		if err := claimOpts.forwardClaims(r, parts[1]); err != nil {
+			p.log.Warn(r.Context(), fmt.Sprintf("[插件: %s] 透传 claim 失败: %v", p.Name(), err))
+		}
+		if claimOpts.stripAuthorization {
+			r.Header.Del("Authorization")
+		}
+		return true, nil // 成功，继续执行
+	}
+
+	plugin.Block(w, http.StatusUnauthorized, "invalid_token", "Token 无效或已过期")
+	return false, nil
+}
+
+// validate 校验 Token。mode: local 时在本地验签，无法获取公钥且开启 fallback_remote 时改为调用认证服务；
+// 调用认证服务时，cacheTTL > 0 则在该时间内复用同一 Token 的成功结果。返回的 error 表示校验无法完成 (认证服务不可用)
+func (p *Plugin) validate(r *http.Request, token, authHeader string, local *localOptions, cacheTTL time.Duration) (bool, error) {
+	if local == nil {
+		return p.validateRemoteCached(r, token, authHeader, cacheTTL)
+	}
+	err := p.verifyLocal(r.Context(), token, local)
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, errKeyUnavailable) && local.fallbackRemote:
+		p.log.Warn(r.Context(), fmt.Sprintf("[插件: %s] 本地校验不可用，改为调用认证服务: %v", p.Name(), err))
+		return p.validateRemoteCached(r, token, authHeader, cacheTTL)
+	case errors.Is(err, errKeyUnavailable):
+		p.log.Info(r.Context(), fmt.Sprintf("[插件: %s] 服务不可用: %v", p.Name(), err))
+		return false, err
+	default:
+		p.log.Info(r.Context(), fmt.Sprintf("[插件: %s] 未授权: Token 本地校验失败: %v", p.Name(), err))
 		return false, nil
 	}
+}
+
+// validateRemoteCached 先查询校验结果缓存，未命中时调用认证服务并缓存成功的结果。
+// 只缓存成功结果，失败的 Token 每次都交给认证服务判断
+func (p *Plugin) validateRemoteCached(r *http.Request, token, authHeader string, cacheTTL time.Duration) (bool, error) {
+	if cacheTTL <= 0 {
+		return p.validateRemote(r, authHeader)
+	}
+	if p.cachedValid(r.Context(), token) {
+		p.log.Debug(r.Context(), fmt.Sprintf("[插件: %s] 命中校验结果缓存", p.Name()))
+		return true, nil
+	}
+	valid, err := p.validateRemote(r, authHeader)
+	if err == nil && valid {
+		p.cacheValid(r.Context(), token, cacheTTL)
+	}
+	return valid, err
+}
 
-	// 3. --- 使用负载均衡器获取健康的auth-service实例 ---
+// validateRemote 调用认证服务的 /validate 接口校验 Token
+func (p *Plugin) validateRemote(r *http.Request, authHeader string) (bool, error) {
+	// 使用负载均衡器获取健康的auth-service实例
 	lb := p.lbFactory.GetOrCreateLoadBalancer(p.serviceName, "round_robin")
 	instance, err := p.getHealthyInstance(lb)
 	if err != nil {
 		p.log.Info(r.Context(), fmt.Sprintf("[插件: %s] 服务不可用: 无法获取健康实例: %v", p.Name(), err))
-		http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
 		return false, err
 	}
 
-	// 4. --- 创建并发送 HTTP 请求到认证服务 ---
-	// 实例URL已经包含协议前缀，直接拼接路径即可
+	// 创建并发送 HTTP 请求到认证服务，实例URL已经包含协议前缀，直接拼接路径即可
 	validateURL := instance.URL + "/validate"
 	req, err := http.NewRequestWithContext(r.Context(), "POST", validateURL, nil)
 	if err != nil {
 		p.log.Info(r.Context(), fmt.Sprintf("[插件: %s] 内部错误: 创建 HTTP 请求失败: %v", p.Name(), err))
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		return false, fmt.Errorf("创建认证 HTTP 请求失败: %w", err)
 	}
 	req.Header.Set("Authorization", authHeader)
@@ -88,20 +208,27 @@ func (p *Plugin) Execute(w http.ResponseWriter, r *http.Request, pluginCfg confi
 	resp, err := p.client.Do(req)
 	if err != nil {
 		p.log.Info(r.Context(), fmt.Sprintf("[插件: %s] 服务不可用: 调用认证服务失败: %v", p.Name(), err))
-		http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
 		return false, err
 	}
 	defer resp.Body.Close()
 
-	// 5. --- 根据 auth-service 的响应决定是否放行 ---
-	if resp.StatusCode == http.StatusOK {
-		p.log.Info(r.Context(), fmt.Sprintf("[插件: %s] 授权成功: Token 有效", p.Name()))
-		return true, nil // 成功，继续执行
+	if resp.StatusCode != http.StatusOK {
+		p.log.Info(r.Context(), fmt.Sprintf("[插件: %s] 未授权: Token 无效 (认证服务返回状态码 %d)", p.Name(), resp.StatusCode))
+		return false, nil
 	}
+	return true, nil
+}
 
-	p.log.Info(r.Context(), fmt.Sprintf("[插件: %s] 未授权: Token 无效 (认证服务返回状态码 %d)", p.Name(), resp.StatusCode))
-	http.Error(w, "Unauthorized", http.StatusUnauthorized)
-	return false, nil
+// ValidateConfig 校验 claim 透传与校验模式相关配置
+func (p *Plugin) ValidateConfig(pluginCfg config.PluginSpec) error {
+	if _, err := parseClaimOptions(pluginCfg); err != nil {
+		return err
+	}
+	if _, err := parseValidationCacheTTL(pluginCfg); err != nil {
+		return err
+	}
+	_, err := parseLocalOptions(pluginCfg)
+	return err
 }
 
 func (p *Plugin) Name() string {
@@ -0,0 +1,175 @@
+// file: internal/plugin/basicauth/plugin.go
+package basicauth
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gateway.example/go-gateway/internal/config"
+	"gateway.example/go-gateway/internal/plugin"
+	"gateway.example/go-gateway/pkg/logger"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const PluginName = "basicauth"
+
+const (
+	defaultRealm = "Restricted"
+	// maxCacheEntries 校验结果缓存的上限，超过后整体清空
+	maxCacheEntries = 1024
+)
+
+// dummyHash 用户不存在时同样执行一次 bcrypt 比较，避免通过响应时间枚举用户名
+var dummyHash = sync.OnceValue(func() []byte {
+	h, _ := bcrypt.GenerateFromPassword([]byte("dummy"), bcrypt.DefaultCost)
+	return h
+})
+
+// Plugin 使用 HTTP Basic 认证保护路由，适合 /admin 或预发环境等内部入口。
+// 密码只以 bcrypt 哈希形式出现在配置中，可以用 `htpasswd -nbB user password` 生成。
+// 配置示例:
+//
+//	plugins:
+//	  - name: "basicauth"
+//	    realm: "staging"
+//	    users:
+//	      admin: "$2y$10$..."
+//	    user_header: "X-User-ID"       # 可选，把认证通过的用户名转发给上游
+//	    strip_authorization: true      # 默认不把凭据转发给上游
+type Plugin struct {
+	log logger.Logger
+
+	mu       sync.Mutex
+	verified map[[sha256.Size]byte]struct{} // 已校验通过的 (哈希, 用户名, 密码) 摘要，避免每个请求都计算 bcrypt
+}
+
+// options 是插件配置解析后的参数
+type options struct {
+	realm              string
+	users              map[string][]byte
+	userHeader         string
+	stripAuthorization bool
+}
+
+func NewPlugin(log logger.Logger) *Plugin {
+	return &Plugin{log: log, verified: make(map[[sha256.Size]byte]struct{})}
+}
+
+func (p *Plugin) Name() string {
+	return PluginName
+}
+
+func (p *Plugin) ValidateConfig(params config.PluginSpec) error {
+	_, err := parseOptions(params)
+	return err
+}
+
+func (p *Plugin) Execute(w http.ResponseWriter, r *http.Request, params config.PluginSpec) (bool, error) {
+	ctx := r.Context()
+
+	opts, err := parseOptions(params)
+	if err != nil {
+		p.log.Error(ctx, fmt.Sprintf("[插件: %s] 配置错误: %v", p.Name(), err))
+		http.Error(w, "内部服务器错误: 插件配置错误", http.StatusInternalServerError)
+		return false, fmt.Errorf("插件 '%s' 配置错误: %w", p.Name(), err)
+	}
+	if opts.userHeader != "" {
+		r.Header.Del(opts.userHeader)
+	}
+
+	user, pass, ok := r.BasicAuth()
+	if !ok || !p.check(opts, user, pass) {
+		if ok {
+			p.log.Info(ctx, fmt.Sprintf("[插件: %s] 未授权: 用户名或密码错误", p.Name()), "user", user, "remote_addr", r.RemoteAddr)
+		}
+		w.Header().Set("WWW-Authenticate", `Basic realm=`+strconv.Quote(opts.realm)+`, charset="UTF-8"`)
+		plugin.Block(w, http.StatusUnauthorized, "invalid_credentials", "需要有效的用户名和密码")
+		return false, nil
+	}
+
+	plugin.SetSubject(r, user)
+	if opts.userHeader != "" {
+		r.Header.Set(opts.userHeader, user)
+	}
+	if opts.stripAuthorization {
+		r.Header.Del("Authorization")
+	}
+	return true, nil
+}
+
+// check 校验用户名与密码，校验通过的组合会被缓存
+func (p *Plugin) check(opts *options, user, pass string) bool {
+	hash, exists := opts.users[user]
+	if !exists {
+		_ = bcrypt.CompareHashAndPassword(dummyHash(), []byte(pass))
+		return false
+	}
+
+	digest := sha256.Sum256([]byte(string(hash) + "\x00" + user + "\x00" + pass))
+	p.mu.Lock()
+	_, cached := p.verified[digest]
+	p.mu.Unlock()
+	if cached {
+		return true
+	}
+
+	if bcrypt.CompareHashAndPassword(hash, []byte(pass)) != nil {
+		return false
+	}
+	p.mu.Lock()
+	if len(p.verified) >= maxCacheEntries {
+		clear(p.verified)
+	}
+	p.verified[digest] = struct{}{}
+	p.mu.Unlock()
+	return true
+}
+
+func parseOptions(params config.PluginSpec) (*options, error) {
+	o := &options{realm: defaultRealm, stripAuthorization: true}
+
+	if v, ok := params["realm"]; ok && v != nil {
+		s, isString := v.(string)
+		if !isString || s == "" {
+			return nil, fmt.Errorf("配置 'realm' 应为非空字符串")
+		}
+		o.realm = s
+	}
+	if v, ok := params["user_header"]; ok && v != nil {
+		if o.userHeader, ok = v.(string); !ok {
+			return nil, fmt.Errorf("配置 'user_header' 应为字符串")
+		}
+	}
+	if v, ok := params["strip_authorization"]; ok && v != nil {
+		b, isBool := v.(bool)
+		if !isBool {
+			return nil, fmt.Errorf("配置 'strip_authorization' 应为布尔值")
+		}
+		o.stripAuthorization = b
+	}
+
+	users, ok := params["users"].(map[interface{}]interface{})
+	if !ok || len(users) == 0 {
+		return nil, fmt.Errorf("缺少 'users' 配置")
+	}
+	o.users = make(map[string][]byte, len(users))
+	for name, hash := range users {
+		user := fmt.Sprint(name)
+		h, isString := hash.(string)
+		if user == "" || strings.Contains(user, ":") {
+			return nil, fmt.Errorf("用户名 '%s' 无效", user)
+		}
+		if !isString {
+			return nil, fmt.Errorf("用户 '%s' 的密码哈希应为字符串", user)
+		}
+		if _, err := bcrypt.Cost([]byte(h)); err != nil {
+			return nil, fmt.Errorf("用户 '%s' 的密码不是有效的 bcrypt 哈希: %w", user, err)
+		}
+		o.users[user] = []byte(h)
+	}
+	return o, nil
+}
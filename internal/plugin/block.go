@@ -0,0 +1,102 @@
+package plugin
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gateway.example/go-gateway/internal/response"
+)
+
+// ReasonBlocked 插件未通过 Block 说明原因时使用的默认原因代码
+const ReasonBlocked = "blocked"
+
+// BlockResult 描述插件中断请求链的原因，由 ExecuteChain 返回，用于日志、指标与错误响应
+type BlockResult struct {
+	Plugin string `json:"plugin"` // 中断请求的插件
+	Reason string `json:"reason"` // 机器可读的原因代码，如 unauthorized、rate_limited
+	Status int    `json:"status"` // 返回给客户端的状态码
+}
+
+// blockErrorBody 插件中断请求时的 JSON 错误响应，在统一格式上附加插件名称与原因代码
+type blockErrorBody struct {
+	response.ErrorBody
+	Plugin            string `json:"plugin,omitempty"`
+	Reason            string `json:"reason"`
+	RetryAfterSeconds int    `json:"retry_after_seconds,omitempty"`
+}
+
+// Block 供插件在中断请求时调用: 以统一的 JSON 格式写出错误响应，并把原因代码交给插件管理器。
+// 调用后插件应返回 (false, nil)。
+func Block(w http.ResponseWriter, status int, reason, message string) {
+	block(w, blockErrorBody{
+		ErrorBody: response.ErrorBody{Error: http.StatusText(status), Status: status, Message: message},
+		Reason:    reason,
+	})
+}
+
+// BlockRetryAfter 与 Block 相同，并通过 Retry-After 响应头与 retry_after_seconds 字段告知客户端多久后可以重试。
+// retryAfter 向上取整到秒，不大于 0 时等同于 Block
+func BlockRetryAfter(w http.ResponseWriter, status int, reason, message string, retryAfter time.Duration) {
+	body := blockErrorBody{
+		ErrorBody: response.ErrorBody{Error: http.StatusText(status), Status: status, Message: message},
+		Reason:    reason,
+	}
+	if retryAfter > 0 {
+		body.RetryAfterSeconds = int(math.Ceil(retryAfter.Seconds()))
+		w.Header().Set("Retry-After", strconv.Itoa(body.RetryAfterSeconds))
+	}
+	block(w, body)
+}
+
+func block(w http.ResponseWriter, body blockErrorBody) {
+	if rec, ok := w.(*blockRecorder); ok {
+		rec.reason = body.Reason
+		body.Plugin = rec.plugin
+	}
+	response.WriteErrorBody(w, body.Status, body)
+}
+
+// SetBlockReason 供自行写出响应 (如模拟响应、外部插件返回的响应) 的插件记录中断原因
+func SetBlockReason(w http.ResponseWriter, reason string) {
+	if rec, ok := w.(*blockRecorder); ok {
+		rec.reason = reason
+	}
+}
+
+// blockRecorder 在插件执行期间包装 ResponseWriter，记录插件写出的状态码与中断原因
+type blockRecorder struct {
+	http.ResponseWriter
+	plugin string
+	status int
+	reason string
+}
+
+func (b *blockRecorder) WriteHeader(code int) {
+	if b.status == 0 {
+		b.status = code
+	}
+	b.ResponseWriter.WriteHeader(code)
+}
+
+func (b *blockRecorder) Write(p []byte) (int, error) {
+	if b.status == 0 {
+		b.status = http.StatusOK
+	}
+	return b.ResponseWriter.Write(p)
+}
+
+// Unwrap 使 http.ResponseController 可以访问底层连接 (Flush、Hijack 等)
+func (b *blockRecorder) Unwrap() http.ResponseWriter {
+	return b.ResponseWriter
+}
+
+// result 生成中断结果；插件直接写出响应而未调用 Block 时使用默认原因代码
+func (b *blockRecorder) result() *BlockResult {
+	reason := b.reason
+	if reason == "" {
+		reason = ReasonBlocked
+	}
+	return &BlockResult{Plugin: b.plugin, Reason: reason, Status: b.status}
+}
@@ -0,0 +1,341 @@
+// file: internal/plugin/bodytransform/plugin.go
+package bodytransform
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"gateway.example/go-gateway/internal/config"
+	"gateway.example/go-gateway/pkg/logger"
+)
+
+const PluginName = "body_transform"
+
+// Plugin 对 JSON 请求体/响应体执行字段重命名、注入、删除，并支持把 XML 响应转换为 JSON。
+// 配置示例:
+//
+//	plugins:
+//	  - name: "body_transform"
+//	    request:
+//	      rename: { userName: "username" }
+//	      set: { "meta.source": "gateway" }
+//	      remove: [ "debug" ]
+//	    response:
+//	      xml_to_json: true
+//	      remove: [ "internal_id" ]
+type Plugin struct {
+	log logger.Logger
+}
+
+// rules 是 request / response 段解析后的转换规则
+type rules struct {
+	Rename    map[string]string
+	Set       map[string]interface{}
+	Remove    []string
+	XMLToJSON bool
+}
+
+func NewPlugin(log logger.Logger) *Plugin {
+	return &Plugin{log: log}
+}
+
+func (p *Plugin) Name() string {
+	return PluginName
+}
+
+// Execute 在请求阶段不做任何处理，实际转换由插件管理器在转发前后调用
+func (p *Plugin) Execute(w http.ResponseWriter, r *http.Request, pluginCfg config.PluginSpec) (bool, error) {
+	return true, nil
+}
+
+// ValidateConfig 校验 request / response 段的转换规则
+func (p *Plugin) ValidateConfig(pluginCfg config.PluginSpec) error {
+	for _, section := range []string{"request", "response"} {
+		if _, err := parseRules(pluginCfg[section]); err != nil {
+			return fmt.Errorf("'%s' 段: %w", section, err)
+		}
+	}
+	return nil
+}
+
+// TransformRequestBody 实现 plugin.RequestBodyTransformer
+func (p *Plugin) TransformRequestBody(r *http.Request, body []byte, params config.PluginSpec) ([]byte, error) {
+	rl, err := parseRules(params["request"])
+	if err != nil || rl == nil {
+		return body, err
+	}
+	if !isJSON(r.Header.Get("Content-Type")) || len(body) == 0 {
+		return body, nil
+	}
+	return p.transformJSON(body, rl)
+}
+
+// TransformResponseBody 实现 plugin.ResponseBodyTransformer
+func (p *Plugin) TransformResponseBody(resp *http.Response, body []byte, params config.PluginSpec) ([]byte, error) {
+	rl, err := parseRules(params["response"])
+	if err != nil || rl == nil {
+		return body, err
+	}
+	if len(body) == 0 {
+		return body, nil
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if rl.XMLToJSON && isXML(contentType) {
+		converted, err := xmlToJSON(body)
+		if err != nil {
+			return nil, fmt.Errorf("XML 转 JSON 失败: %w", err)
+		}
+		body = converted
+		contentType = "application/json"
+		resp.Header.Set("Content-Type", "application/json; charset=utf-8")
+	}
+	if !isJSON(contentType) {
+		return body, nil
+	}
+	return p.transformJSON(body, rl)
+}
+
+// transformJSON 按 rename → set → remove 顺序改写 JSON 对象
+func (p *Plugin) transformJSON(body []byte, rl *rules) ([]byte, error) {
+	if len(rl.Rename) == 0 && len(rl.Set) == 0 && len(rl.Remove) == 0 {
+		return body, nil
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		// 非 JSON 对象（如数组）不做处理
+		return body, nil
+	}
+
+	for from, to := range rl.Rename {
+		if v, ok := getPath(doc, from); ok {
+			deletePath(doc, from)
+			setPath(doc, to, v)
+		}
+	}
+	for path, v := range rl.Set {
+		setPath(doc, path, v)
+	}
+	for _, path := range rl.Remove {
+		deletePath(doc, path)
+	}
+	return json.Marshal(doc)
+}
+
+// parseRules 把 PluginSpec 中的 request/response 段解析为转换规则
+func parseRules(raw interface{}) (*rules, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	section, ok := toStringMap(raw)
+	if !ok {
+		return nil, fmt.Errorf("转换规则格式不正确")
+	}
+
+	rl := &rules{
+		Rename: make(map[string]string),
+		Set:    make(map[string]interface{}),
+	}
+	if v, ok := section["rename"]; ok {
+		m, ok := toStringMap(v)
+		if !ok {
+			return nil, fmt.Errorf("配置 'rename' 类型不正确")
+		}
+		for from, to := range m {
+			toStr, ok := to.(string)
+			if !ok {
+				return nil, fmt.Errorf("配置 'rename.%s' 必须为字符串", from)
+			}
+			rl.Rename[from] = toStr
+		}
+	}
+	if v, ok := section["set"]; ok {
+		m, ok := toStringMap(v)
+		if !ok {
+			return nil, fmt.Errorf("配置 'set' 类型不正确")
+		}
+		for k, val := range m {
+			rl.Set[k] = normalize(val)
+		}
+	}
+	if v, ok := section["remove"]; ok {
+		list, ok := v.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("配置 'remove' 必须为列表")
+		}
+		for _, item := range list {
+			if s, ok := item.(string); ok {
+				rl.Remove = append(rl.Remove, s)
+			}
+		}
+	}
+	if v, ok := section["xml_to_json"].(bool); ok {
+		rl.XMLToJSON = v
+	}
+	return rl, nil
+}
+
+// toStringMap 将 YAML 解析出的 map[interface{}]interface{} 转换为 map[string]interface{}
+func toStringMap(v interface{}) (map[string]interface{}, bool) {
+	switch m := v.(type) {
+	case map[string]interface{}:
+		return m, true
+	case map[interface{}]interface{}:
+		out := make(map[string]interface{}, len(m))
+		for k, val := range m {
+			out[fmt.Sprint(k)] = val
+		}
+		return out, true
+	default:
+		return nil, false
+	}
+}
+
+// normalize 递归转换 YAML 值，使其可以被 encoding/json 序列化
+func normalize(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[interface{}]interface{}:
+		m, _ := toStringMap(val)
+		for k, item := range m {
+			m[k] = normalize(item)
+		}
+		return m
+	case []interface{}:
+		for i, item := range val {
+			val[i] = normalize(item)
+		}
+		return val
+	default:
+		return v
+	}
+}
+
+// getPath 读取以点分隔的字段路径
+func getPath(doc map[string]interface{}, path string) (interface{}, bool) {
+	keys := strings.Split(path, ".")
+	cur := doc
+	for i, key := range keys {
+		v, ok := cur[key]
+		if !ok {
+			return nil, false
+		}
+		if i == len(keys)-1 {
+			return v, true
+		}
+		if cur, ok = v.(map[string]interface{}); !ok {
+			return nil, false
+		}
+	}
+	return nil, false
+}
+
+// setPath 写入以点分隔的字段路径，中间层不存在时自动创建
+func setPath(doc map[string]interface{}, path string, value interface{}) {
+	keys := strings.Split(path, ".")
+	cur := doc
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := cur[key].(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			cur[key] = next
+		}
+		cur = next
+	}
+	cur[keys[len(keys)-1]] = value
+}
+
+// deletePath 删除以点分隔的字段路径
+func deletePath(doc map[string]interface{}, path string) {
+	keys := strings.Split(path, ".")
+	cur := doc
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := cur[key].(map[string]interface{})
+		if !ok {
+			return
+		}
+		cur = next
+	}
+	delete(cur, keys[len(keys)-1])
+}
+
+func isJSON(contentType string) bool {
+	ct := strings.ToLower(contentType)
+	return strings.HasPrefix(ct, "application/json") || strings.Contains(ct, "+json")
+}
+
+func isXML(contentType string) bool {
+	ct := strings.ToLower(contentType)
+	return strings.HasPrefix(ct, "application/xml") || strings.HasPrefix(ct, "text/xml") || strings.Contains(ct, "+xml")
+}
+
+// xmlToJSON 将 XML 文档转换为 JSON
+// 元素转换为对象，属性以 "@" 前缀保存，重复的子元素合并为数组，纯文本元素转换为字符串
+func xmlToJSON(data []byte) ([]byte, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			return []byte("{}"), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			value, err := decodeElement(decoder, start)
+			if err != nil {
+				return nil, err
+			}
+			return json.Marshal(map[string]interface{}{start.Name.Local: value})
+		}
+	}
+}
+
+// decodeElement 递归解析一个 XML 元素
+func decodeElement(decoder *xml.Decoder, start xml.StartElement) (interface{}, error) {
+	node := make(map[string]interface{})
+	for _, attr := range start.Attr {
+		node["@"+attr.Name.Local] = attr.Value
+	}
+
+	var text strings.Builder
+	for {
+		tok, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			child, err := decodeElement(decoder, t)
+			if err != nil {
+				return nil, err
+			}
+			name := t.Name.Local
+			if existing, ok := node[name]; ok {
+				if list, ok := existing.([]interface{}); ok {
+					node[name] = append(list, child)
+				} else {
+					node[name] = []interface{}{existing, child}
+				}
+			} else {
+				node[name] = child
+			}
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			content := strings.TrimSpace(text.String())
+			if len(node) == 0 {
+				return content, nil
+			}
+			if content != "" {
+				node["#text"] = content
+			}
+			return node, nil
+		}
+	}
+}
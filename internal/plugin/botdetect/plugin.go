@@ -0,0 +1,199 @@
+// file: internal/plugin/botdetect/plugin.go
+package botdetect
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"gateway.example/go-gateway/internal/config"
+	"gateway.example/go-gateway/internal/plugin"
+	"gateway.example/go-gateway/pkg/logger"
+)
+
+const PluginName = "bot_detection"
+
+// 处置方式
+const (
+	ActionBlock  = "block"  // 立即返回 403
+	ActionTarpit = "tarpit" // 延迟一段时间后返回 429，拖慢扫描器
+)
+
+const defaultTarpitDelay = 2 * time.Second
+
+// Plugin 根据 User-Agent、缺失的请求头和可疑路径识别爬虫与扫描器。
+// 配置示例:
+//
+//	plugins:
+//	  - name: "bot_detection"
+//	    allow_user_agents: [ "(?i)googlebot", "(?i)bingbot" ]   # 白名单优先，命中后直接放行
+//	    deny_user_agents: [ "(?i)sqlmap", "(?i)nikto", "(?i)python-requests" ]
+//	    deny_paths: [ "^/wp-admin", "\\.php$", "/\\.env$" ]
+//	    block_empty_user_agent: true
+//	    require_headers: [ "Accept" ]
+//	    action: "tarpit"        # block (默认，403) 或 tarpit (延迟后 429)
+//	    tarpit_delay: "2s"
+//
+// 所有模式均为正则表达式。User-Agent 可以被伪造，白名单只适合放行对业务无害的爬虫。
+type Plugin struct {
+	log      logger.Logger
+	patterns sync.Map // 正则表达式 -> *regexp.Regexp，避免每个请求重复编译
+}
+
+// rules 是插件配置解析后的检测规则
+type rules struct {
+	allowUserAgents []*regexp.Regexp
+	denyUserAgents  []*regexp.Regexp
+	denyPaths       []*regexp.Regexp
+	blockEmptyUA    bool
+	requireHeaders  []string
+	action          string
+	tarpitDelay     time.Duration
+}
+
+func NewPlugin(log logger.Logger) *Plugin {
+	return &Plugin{log: log}
+}
+
+func (p *Plugin) Name() string {
+	return PluginName
+}
+
+func (p *Plugin) ValidateConfig(params config.PluginSpec) error {
+	_, err := p.parseRules(params)
+	return err
+}
+
+func (p *Plugin) Execute(w http.ResponseWriter, r *http.Request, params config.PluginSpec) (bool, error) {
+	ctx := r.Context()
+
+	rl, err := p.parseRules(params)
+	if err != nil {
+		p.log.Error(ctx, fmt.Sprintf("[插件: %s] 配置错误: %v", p.Name(), err))
+		http.Error(w, "内部服务器错误: 插件配置错误", http.StatusInternalServerError)
+		return false, fmt.Errorf("插件 '%s' 配置错误: %w", p.Name(), err)
+	}
+
+	reason := rl.detect(r)
+	if reason == "" {
+		return true, nil
+	}
+
+	p.log.Warn(ctx, "[插件] 疑似爬虫请求", "plugin", p.Name(), "reason", reason, "action", rl.action,
+		"path", r.URL.Path, "user_agent", r.UserAgent(), "remote_addr", r.RemoteAddr)
+
+	if rl.action == ActionTarpit {
+		timer := time.NewTimer(rl.tarpitDelay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			plugin.SetBlockReason(w, "client_closed")
+			return false, nil
+		}
+		w.Header().Set("Retry-After", strconv.Itoa(int(rl.tarpitDelay.Seconds())+1))
+		plugin.Block(w, http.StatusTooManyRequests, "bot_tarpit", "请求过于频繁")
+		return false, nil
+	}
+
+	plugin.Block(w, http.StatusForbidden, "bot_detected", "疑似自动化请求")
+	return false, nil
+}
+
+// detect 返回命中的规则描述，未命中返回空串
+func (rl *rules) detect(r *http.Request) string {
+	ua := r.UserAgent()
+	if ua != "" && matchAny(rl.allowUserAgents, ua) {
+		return ""
+	}
+	if ua == "" && rl.blockEmptyUA {
+		return "empty_user_agent"
+	}
+	if matchAny(rl.denyUserAgents, ua) {
+		return "denied_user_agent"
+	}
+	for _, h := range rl.requireHeaders {
+		if r.Header.Get(h) == "" {
+			return "missing_header:" + h
+		}
+	}
+	if matchAny(rl.denyPaths, r.URL.Path) {
+		return "denied_path"
+	}
+	return ""
+}
+
+func matchAny(patterns []*regexp.Regexp, s string) bool {
+	for _, re := range patterns {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Plugin) parseRules(params config.PluginSpec) (*rules, error) {
+	rl := &rules{action: ActionBlock, tarpitDelay: defaultTarpitDelay}
+	var err error
+
+	if rl.allowUserAgents, err = p.compileList(params, "allow_user_agents"); err != nil {
+		return nil, err
+	}
+	if rl.denyUserAgents, err = p.compileList(params, "deny_user_agents"); err != nil {
+		return nil, err
+	}
+	if rl.denyPaths, err = p.compileList(params, "deny_paths"); err != nil {
+		return nil, err
+	}
+	if rl.requireHeaders, err = plugin.StringList(params, "require_headers"); err != nil {
+		return nil, err
+	}
+	if v, ok := params["block_empty_user_agent"]; ok && v != nil {
+		b, isBool := v.(bool)
+		if !isBool {
+			return nil, fmt.Errorf("配置 'block_empty_user_agent' 应为布尔值")
+		}
+		rl.blockEmptyUA = b
+	}
+	if v, ok := params["action"]; ok && v != nil {
+		action, _ := v.(string)
+		if action != ActionBlock && action != ActionTarpit {
+			return nil, fmt.Errorf("配置 'action' 只能是 %s 或 %s", ActionBlock, ActionTarpit)
+		}
+		rl.action = action
+	}
+	tarpitDelay, err := plugin.Duration(params, "tarpit_delay", rl.tarpitDelay)
+	if err != nil {
+		return nil, err
+	}
+	if tarpitDelay <= 0 {
+		return nil, fmt.Errorf("配置 'tarpit_delay' 应为正的时长")
+	}
+	rl.tarpitDelay = tarpitDelay
+	return rl, nil
+}
+
+// compileList 编译配置中的正则表达式列表，编译结果按表达式缓存
+func (p *Plugin) compileList(params config.PluginSpec, key string) ([]*regexp.Regexp, error) {
+	patterns, err := plugin.StringList(params, key)
+	if err != nil {
+		return nil, err
+	}
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		if re, ok := p.patterns.Load(pattern); ok {
+			compiled = append(compiled, re.(*regexp.Regexp))
+			continue
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("配置 '%s' 中的正则表达式 '%s' 无效: %w", key, pattern, err)
+		}
+		p.patterns.Store(pattern, re)
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
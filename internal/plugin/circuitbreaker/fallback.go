@@ -0,0 +1,277 @@
+package circuitbreaker
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"gateway.example/go-gateway/internal/cache"
+	"gateway.example/go-gateway/internal/config"
+	"gateway.example/go-gateway/internal/plugin"
+)
+
+// HeaderFallback 返回降级响应时带上的响应头，值为降级方式
+const HeaderFallback = "X-Circuit-Fallback"
+
+// 降级方式
+const (
+	FallbackStatic  = "static"  // 返回配置的固定响应
+	FallbackCache   = "cache"   // 返回该请求最近一次成功的响应
+	FallbackService = "service" // 改投到降级服务
+)
+
+// 默认参数
+const (
+	cacheKeyPrefix     = "cbfb:"
+	defaultFallbackTTL = time.Hour
+	defaultMaxRespSize = 1 << 20
+)
+
+// fallback 是熔断时的降级配置
+type fallback struct {
+	kind string
+
+	// static
+	status      int
+	contentType string
+	headers     map[string]string
+	body        string
+
+	// cache
+	ttl          time.Duration
+	maxRespSize  int64
+	scopeHeaders []string
+
+	// service
+	service string
+}
+
+// cachedResponse 是缓存中保存的最近一次成功响应
+type cachedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       []byte      `json:"body,omitempty"`
+}
+
+// serveFallback 按降级配置处理被熔断的请求，返回值与 Execute 相同；retryAfter 用于没有可用降级响应时的 503
+func (p *Plugin) serveFallback(w http.ResponseWriter, r *http.Request, opts *options, retryAfter time.Duration) bool {
+	ctx := r.Context()
+	fb := opts.fallback
+
+	switch fb.kind {
+	case FallbackService:
+		p.log.Warn(ctx, "[插件] 请求被熔断，改投到降级服务", "plugin", p.Name(), "service", opts.service, "fallback_service", fb.service)
+		plugin.SetTargetService(r, fb.service)
+		return true
+
+	case FallbackStatic:
+		p.log.Warn(ctx, "[插件] 请求被熔断，返回固定降级响应", "plugin", p.Name(), "service", opts.service)
+		for k, v := range fb.headers {
+			w.Header().Set(k, v)
+		}
+		w.Header().Set("Content-Type", fb.contentType)
+		w.Header().Set(HeaderFallback, FallbackStatic)
+		plugin.SetBlockReason(w, "circuit_fallback")
+		w.WriteHeader(fb.status)
+		_, _ = io.WriteString(w, fb.body)
+		return false
+
+	case FallbackCache:
+		if r.Method == http.MethodGet {
+			data, err := p.store.Get(ctx, fallbackKey(r, opts))
+			var cached cachedResponse
+			if err == nil && json.Unmarshal(data, &cached) == nil {
+				p.log.Warn(ctx, "[插件] 请求被熔断，返回最近一次成功的响应", "plugin", p.Name(), "service", opts.service, "path", r.URL.Path)
+				for k, v := range cached.Header {
+					w.Header()[k] = v
+				}
+				w.Header().Set(HeaderFallback, FallbackCache)
+				plugin.SetBlockReason(w, "circuit_fallback")
+				w.WriteHeader(cached.StatusCode)
+				_, _ = w.Write(cached.Body)
+				return false
+			}
+			if err != nil && !errors.Is(err, cache.ErrNotFound) {
+				p.log.Warn(ctx, "[插件] 读取熔断降级缓存失败", "plugin", p.Name(), "error", err)
+			}
+		}
+	}
+
+	p.log.Warn(ctx, "[插件] 请求被熔断", "plugin", p.Name(), "service", opts.service)
+	plugin.BlockRetryAfter(w, http.StatusServiceUnavailable, "circuit_open", "服务暂时不可用", retryAfter)
+	return false
+}
+
+// OnResponse 实现 plugin.ResponseHandler，fallback 为 cache 时保存 GET 请求最近一次成功的响应
+func (p *Plugin) OnResponse(resp *http.Response, route *config.RouteConfig, params config.PluginSpec) error {
+	opts, err := parseConfig(params)
+	if err != nil || opts.fallback == nil || opts.fallback.kind != FallbackCache {
+		return nil
+	}
+	if resp.Request.Method != http.MethodGet || resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil
+	}
+
+	key := fallbackKey(resp.Request, opts)
+	header := resp.Header.Clone()
+	header.Del("Set-Cookie") // 降级响应可能返回给其他客户端
+	ttl := opts.fallback.ttl
+	resp.Body = &capturingBody{
+		ReadCloser: resp.Body,
+		limit:      opts.fallback.maxRespSize,
+		onComplete: func(body []byte) {
+			ctx := context.Background()
+			data, err := json.Marshal(&cachedResponse{StatusCode: resp.StatusCode, Header: header, Body: body})
+			if err == nil {
+				err = p.store.Set(ctx, key, data, ttl)
+			}
+			if err != nil {
+				p.log.Warn(ctx, "[插件] 写入熔断降级缓存失败", "plugin", p.Name(), "error", err)
+			}
+		},
+	}
+	return nil
+}
+
+// fallbackKey 由服务名、请求 URI、Accept-Encoding 与作用域请求头计算缓存键
+func fallbackKey(r *http.Request, opts *options) string {
+	h := sha256.New()
+	h.Write([]byte(r.Header.Get("Accept-Encoding")))
+	for _, name := range opts.fallback.scopeHeaders {
+		h.Write([]byte{0})
+		h.Write([]byte(r.Header.Get(name)))
+	}
+	return cacheKeyPrefix + opts.service + "|" + r.URL.RequestURI() + "|" + hex.EncodeToString(h.Sum(nil))
+}
+
+// capturingBody 在转发响应体的同时复制一份，读到 EOF 后回调；超过上限则放弃缓存
+type capturingBody struct {
+	io.ReadCloser
+	buf        bytes.Buffer
+	limit      int64
+	overflow   bool
+	onComplete func(body []byte)
+}
+
+func (b *capturingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 && !b.overflow {
+		if int64(b.buf.Len()+n) > b.limit {
+			b.overflow = true
+			b.buf = bytes.Buffer{}
+		} else {
+			b.buf.Write(p[:n])
+		}
+	}
+	if err == io.EOF && !b.overflow && b.onComplete != nil {
+		b.onComplete(b.buf.Bytes())
+		b.onComplete = nil
+	}
+	return n, err
+}
+
+// parseFallback 解析 fallback 配置块
+func parseFallback(v interface{}) (*fallback, error) {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		raw, isMap := v.(map[interface{}]interface{})
+		if !isMap {
+			return nil, fmt.Errorf("配置 'fallback' 应为对象")
+		}
+		m = make(map[string]interface{}, len(raw))
+		for k, val := range raw {
+			m[fmt.Sprint(k)] = val
+		}
+	}
+
+	fb := &fallback{
+		status:      http.StatusOK,
+		contentType: "application/json",
+		ttl:         defaultFallbackTTL,
+		maxRespSize: defaultMaxRespSize,
+	}
+	fb.kind, _ = m["type"].(string)
+	switch fb.kind {
+	case FallbackStatic:
+		if v, ok := m["status"]; ok {
+			status, isInt := v.(int)
+			if !isInt || status < 100 || status > 599 {
+				return nil, fmt.Errorf("配置 'fallback.status' 应为有效的 HTTP 状态码")
+			}
+			fb.status = status
+		}
+		if v, ok := m["content_type"]; ok {
+			s, isString := v.(string)
+			if !isString || s == "" {
+				return nil, fmt.Errorf("配置 'fallback.content_type' 应为非空字符串")
+			}
+			fb.contentType = s
+		}
+		if v, ok := m["body"]; ok {
+			s, isString := v.(string)
+			if !isString {
+				return nil, fmt.Errorf("配置 'fallback.body' 应为字符串")
+			}
+			fb.body = s
+		}
+		if v, ok := m["headers"]; ok {
+			raw, isMap := v.(map[interface{}]interface{})
+			if !isMap {
+				return nil, fmt.Errorf("配置 'fallback.headers' 应为对象")
+			}
+			fb.headers = make(map[string]string, len(raw))
+			for k, val := range raw {
+				fb.headers[fmt.Sprint(k)] = fmt.Sprint(val)
+			}
+		}
+
+	case FallbackCache:
+		if v, ok := m["ttl"]; ok {
+			d, err := plugin.DurationValue("fallback.ttl", v)
+			if err != nil {
+				return nil, err
+			}
+			if d <= 0 {
+				return nil, fmt.Errorf("配置 'fallback.ttl' 应为正的时长")
+			}
+			fb.ttl = d
+		}
+		if v, ok := m["max_response_size"]; ok {
+			size, err := config.ParseByteSizeValue(v)
+			if err != nil || size <= 0 {
+				return nil, fmt.Errorf("配置 'fallback.max_response_size' 应为正的字节数或带单位的大小 (如 1MB)")
+			}
+			fb.maxRespSize = int64(size)
+		}
+		if v, ok := m["scope_headers"]; ok {
+			list, isList := v.([]interface{})
+			if !isList {
+				return nil, fmt.Errorf("配置 'fallback.scope_headers' 应为字符串列表")
+			}
+			for _, item := range list {
+				s, isString := item.(string)
+				if !isString || s == "" {
+					return nil, fmt.Errorf("配置 'fallback.scope_headers' 应为字符串列表")
+				}
+				fb.scopeHeaders = append(fb.scopeHeaders, s)
+			}
+		}
+
+	case FallbackService:
+		fb.service, _ = m["service"].(string)
+		if fb.service == "" {
+			return nil, fmt.Errorf("配置 'fallback.service' 缺失或类型不正确")
+		}
+
+	default:
+		return nil, fmt.Errorf("配置 'fallback.type' 应为 %s / %s / %s", FallbackStatic, FallbackCache, FallbackService)
+	}
+	return fb, nil
+}
@@ -2,31 +2,65 @@ package circuitbreaker
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
+	"gateway.example/go-gateway/internal/cache"
 	"gateway.example/go-gateway/internal/config"
+	"gateway.example/go-gateway/internal/plugin"
 	pl_circuitbreaker "gateway.example/go-gateway/internal/service/circuitbreaker"
 	"gateway.example/go-gateway/pkg/logger"
 )
 
 const PluginName = "circuitbreaker"
 
+// Plugin 在服务熔断时拒绝请求，或按 fallback 配置返回降级响应。
+// 配置示例:
+//
+//	plugins:
+//	  - name: "circuitbreaker"
+//	    service: "service-b"
+//	    fallback:                      # 可选，不配置时返回 503
+//	      type: "static"               # static / cache / service
+//	      status: 200
+//	      content_type: "application/json"
+//	      body: '{"items":[],"degraded":true}'
+//
+// type 为 cache 时返回该 GET 请求最近一次成功的响应 (ttl、max_response_size、scope_headers)，
+// 没有缓存时仍返回 503；type 为 service 时把请求改投到 fallback.service 指定的降级服务。
 type Plugin struct {
 	circuitBreakerSvc pl_circuitbreaker.Service
+	services          map[string]config.ServiceConfig
+	store             cache.Cache
 	log               logger.Logger
 }
 
-func NewPlugin(svc pl_circuitbreaker.Service, log logger.Logger) *Plugin {
+// options 是插件配置解析后的参数
+type options struct {
+	service  string
+	fallback *fallback
+}
+
+// NewPlugin 创建熔断插件，services 用于校验降级服务是否存在，store 保存 cache 降级使用的响应
+func NewPlugin(svc pl_circuitbreaker.Service, services map[string]config.ServiceConfig, store cache.Cache, log logger.Logger) *Plugin {
 	if svc == nil {
 		log.Fatal(context.Background(), fmt.Sprintf("[插件 %s] 致命错误: circuitbreaker.Service 依赖注入失败，为 nil", PluginName))
 	}
 	return &Plugin{
 		circuitBreakerSvc: svc,
+		services:          services,
+		store:             store,
 		log:               log,
 	}
 }
 
+// SetServices 在网关重新加载配置时更新用于校验降级服务的服务列表
+func (p *Plugin) SetServices(services map[string]config.ServiceConfig) {
+	p.services = services
+}
+
 func (p *Plugin) Name() string {
 	return PluginName
 }
@@ -35,7 +69,7 @@ func (p *Plugin) Execute(w http.ResponseWriter, r *http.Request, pluginCfg confi
 	ctx := r.Context()
 
 	// 1. 解析插件配置
-	serviceName, err := p.parseConfig(pluginCfg)
+	opts, err := parseConfig(pluginCfg)
 	if err != nil {
 		p.log.Error(ctx, "[插件] 熔断插件配置错误", "plugin", p.Name(), "error", err)
 		http.Error(w, "熔断插件配置错误", http.StatusInternalServerError)
@@ -43,7 +77,13 @@ func (p *Plugin) Execute(w http.ResponseWriter, r *http.Request, pluginCfg confi
 	}
 
 	// 2. 检查熔断状态
+	serviceName := opts.service
 	allowed, err := p.circuitBreakerSvc.CheckCircuit(ctx, serviceName)
+	var retryAfter time.Duration
+	if errors.Is(err, pl_circuitbreaker.ErrOpenState) {
+		// 熔断器打开或半开状态下试探请求已满，按熔断处理
+		allowed, err, retryAfter = false, nil, pl_circuitbreaker.RetryAfter(err)
+	}
 	if err != nil {
 		p.log.Error(ctx, "[插件] 调用熔断服务失败", "plugin", p.Name(), "service", serviceName, "error", err)
 		http.Error(w, "熔断服务内部错误", http.StatusInternalServerError)
@@ -51,8 +91,11 @@ func (p *Plugin) Execute(w http.ResponseWriter, r *http.Request, pluginCfg confi
 	}
 
 	if !allowed {
+		if opts.fallback != nil {
+			return p.serveFallback(w, r, opts, retryAfter), nil
+		}
 		p.log.Warn(ctx, "[插件] 请求被熔断", "plugin", p.Name(), "service", serviceName)
-		http.Error(w, "服务暂时不可用", http.StatusServiceUnavailable)
+		plugin.BlockRetryAfter(w, http.StatusServiceUnavailable, "circuit_open", "服务暂时不可用", retryAfter)
 		return false, nil // 中断插件链
 	}
 
@@ -60,10 +103,32 @@ func (p *Plugin) Execute(w http.ResponseWriter, r *http.Request, pluginCfg confi
 	return true, nil // 继续下一个插件
 }
 
-func (p *Plugin) parseConfig(cfg config.PluginSpec) (string, error) {
+// ValidateConfig 校验 service 字段已配置，以及降级服务已在 services 中定义
+func (p *Plugin) ValidateConfig(pluginCfg config.PluginSpec) error {
+	opts, err := parseConfig(pluginCfg)
+	if err != nil {
+		return err
+	}
+	if fb := opts.fallback; fb != nil && fb.kind == FallbackService {
+		if _, ok := p.services[fb.service]; !ok {
+			return fmt.Errorf("降级服务 '%s' 未在 services 中定义", fb.service)
+		}
+	}
+	return nil
+}
+
+func parseConfig(cfg config.PluginSpec) (*options, error) {
 	service, ok := cfg["service"].(string)
 	if !ok || service == "" {
-		return "", fmt.Errorf("配置 'service' 缺失或类型不正确")
+		return nil, fmt.Errorf("配置 'service' 缺失或类型不正确")
+	}
+	opts := &options{service: service}
+	if v, ok := cfg["fallback"]; ok && v != nil {
+		fb, err := parseFallback(v)
+		if err != nil {
+			return nil, err
+		}
+		opts.fallback = fb
 	}
-	return service, nil
+	return opts, nil
 }
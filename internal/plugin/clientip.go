@@ -0,0 +1,27 @@
+package plugin
+
+import (
+	"context"
+	"net"
+	"net/http"
+)
+
+type clientIPKey struct{}
+
+// WithClientIP 把网关按 trusted_proxies 规则解析出的客户端 IP 放入 context，供插件使用
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey{}, ip)
+}
+
+// ClientIP 返回网关解析出的客户端 IP。插件不应自行信任 X-Forwarded-For，
+// 否则客户端可以伪造来源地址；请求未经网关处理时退回对端地址。
+func ClientIP(r *http.Request) string {
+	if ip, ok := r.Context().Value(clientIPKey{}).(string); ok && ip != "" {
+		return ip
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
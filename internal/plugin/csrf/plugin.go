@@ -0,0 +1,252 @@
+// file: internal/plugin/csrf/plugin.go
+package csrf
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"gateway.example/go-gateway/internal/config"
+	"gateway.example/go-gateway/internal/plugin"
+	"gateway.example/go-gateway/pkg/logger"
+)
+
+const PluginName = "csrf"
+
+// 默认参数
+const (
+	DefaultCookieName = "csrf_token"
+	DefaultHeaderName = "X-CSRF-Token"
+	defaultMaxAge     = 12 * time.Hour
+	tokenBytes        = 32
+)
+
+// safeMethods 不改变服务端状态的方法，不做校验，只在缺少 Cookie 时下发令牌
+var safeMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodOptions: true,
+	http.MethodTrace:   true,
+}
+
+// Plugin 以 double-submit cookie 方式防御 CSRF: 安全方法的请求下发令牌 Cookie，
+// 改变状态的请求必须在请求头中回传与 Cookie 相同的令牌。跨站页面无法读取 Cookie，
+// 因此无法构造出正确的请求头。配置 secret 后令牌带有 HMAC 签名，可防止子域注入伪造的 Cookie。
+// 配置示例:
+//
+//	plugins:
+//	  - name: "csrf"
+//	    secret: "change-me"
+//	    cookie_name: "csrf_token"
+//	    header_name: "X-CSRF-Token"
+//	    same_site: "lax"          # strict / lax / none
+//	    secure: true
+//	    max_age: "12h"
+//	    exempt_paths: [ "/service-a/webhooks/*" ]
+type Plugin struct {
+	log logger.Logger
+}
+
+// options 是插件配置解析后的参数
+type options struct {
+	secret      []byte
+	cookieName  string
+	headerName  string
+	cookiePath  string
+	domain      string
+	sameSite    http.SameSite
+	secure      bool
+	maxAge      time.Duration
+	exemptPaths []string
+}
+
+func NewPlugin(log logger.Logger) *Plugin {
+	return &Plugin{log: log}
+}
+
+func (p *Plugin) Name() string {
+	return PluginName
+}
+
+func (p *Plugin) ValidateConfig(params config.PluginSpec) error {
+	_, err := parseOptions(params)
+	return err
+}
+
+func (p *Plugin) Execute(w http.ResponseWriter, r *http.Request, params config.PluginSpec) (bool, error) {
+	ctx := r.Context()
+
+	opts, err := parseOptions(params)
+	if err != nil {
+		p.log.Error(ctx, fmt.Sprintf("[插件: %s] 配置错误: %v", p.Name(), err))
+		http.Error(w, "内部服务器错误: 插件配置错误", http.StatusInternalServerError)
+		return false, fmt.Errorf("插件 '%s' 配置错误: %w", p.Name(), err)
+	}
+
+	cookie, _ := r.Cookie(opts.cookieName)
+	hasValidCookie := cookie != nil && opts.validToken(cookie.Value)
+
+	if safeMethods[r.Method] {
+		if !hasValidCookie {
+			token, err := opts.newToken()
+			if err != nil {
+				return false, fmt.Errorf("生成 CSRF 令牌失败: %w", err)
+			}
+			http.SetCookie(w, opts.cookie(token))
+		}
+		return true, nil
+	}
+
+	if opts.exempt(r.URL.Path) {
+		return true, nil
+	}
+
+	provided := r.Header.Get(opts.headerName)
+	if !hasValidCookie || provided == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(cookie.Value)) != 1 {
+		p.log.Warn(ctx, "[插件] CSRF 校验失败", "plugin", p.Name(), "method", r.Method, "path", r.URL.Path,
+			"has_cookie", cookie != nil, "has_header", provided != "")
+		plugin.Block(w, http.StatusForbidden, "csrf_failed", "CSRF 令牌缺失或无效")
+		return false, nil
+	}
+	return true, nil
+}
+
+// newToken 生成随机令牌，配置了 secret 时追加 HMAC 签名
+func (o *options) newToken() (string, error) {
+	b := make([]byte, tokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+	if len(o.secret) > 0 {
+		token += "." + o.sign(token)
+	}
+	return token, nil
+}
+
+// validToken 校验令牌格式，配置了 secret 时同时校验签名
+func (o *options) validToken(token string) bool {
+	if token == "" {
+		return false
+	}
+	if len(o.secret) == 0 {
+		return true
+	}
+	value, sig, ok := strings.Cut(token, ".")
+	return ok && hmac.Equal([]byte(sig), []byte(o.sign(value)))
+}
+
+func (o *options) sign(value string) string {
+	mac := hmac.New(sha256.New, o.secret)
+	mac.Write([]byte(value))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// cookie 构造令牌 Cookie；前端脚本需要读取它，因此不能设置 HttpOnly
+func (o *options) cookie(token string) *http.Cookie {
+	return &http.Cookie{
+		Name:     o.cookieName,
+		Value:    token,
+		Path:     o.cookiePath,
+		Domain:   o.domain,
+		MaxAge:   int(o.maxAge.Seconds()),
+		Secure:   o.secure,
+		SameSite: o.sameSite,
+	}
+}
+
+// exempt 判断路径是否免于校验，支持 path.Match 通配符和以 /* 结尾的前缀匹配
+func (o *options) exempt(p string) bool {
+	for _, pattern := range o.exemptPaths {
+		if prefix, ok := strings.CutSuffix(pattern, "/*"); ok && strings.HasPrefix(p, prefix+"/") {
+			return true
+		}
+		if matched, _ := path.Match(pattern, p); matched {
+			return true
+		}
+	}
+	return false
+}
+
+func parseOptions(params config.PluginSpec) (*options, error) {
+	o := &options{
+		cookieName: DefaultCookieName,
+		headerName: DefaultHeaderName,
+		cookiePath: "/",
+		sameSite:   http.SameSiteLaxMode,
+		secure:     true,
+		maxAge:     defaultMaxAge,
+	}
+
+	stringFields := map[string]*string{
+		"cookie_name": &o.cookieName,
+		"header_name": &o.headerName,
+		"cookie_path": &o.cookiePath,
+		"domain":      &o.domain,
+	}
+	for key, target := range stringFields {
+		if v, ok := params[key]; ok && v != nil {
+			s, isString := v.(string)
+			if !isString || s == "" {
+				return nil, fmt.Errorf("配置 '%s' 应为非空字符串", key)
+			}
+			*target = s
+		}
+	}
+	if v, ok := params["secret"]; ok && v != nil {
+		s, isString := v.(string)
+		if !isString {
+			return nil, fmt.Errorf("配置 'secret' 应为字符串")
+		}
+		o.secret = []byte(s)
+	}
+	if v, ok := params["secure"]; ok && v != nil {
+		b, isBool := v.(bool)
+		if !isBool {
+			return nil, fmt.Errorf("配置 'secure' 应为布尔值")
+		}
+		o.secure = b
+	}
+	if v, ok := params["same_site"]; ok && v != nil {
+		s, _ := v.(string)
+		switch s {
+		case "strict", "Strict":
+			o.sameSite = http.SameSiteStrictMode
+		case "lax", "Lax":
+			o.sameSite = http.SameSiteLaxMode
+		case "none", "None":
+			o.sameSite = http.SameSiteNoneMode
+		default:
+			return nil, fmt.Errorf("配置 'same_site' 只能是 strict、lax 或 none")
+		}
+	}
+	if o.sameSite == http.SameSiteNoneMode && !o.secure {
+		return nil, fmt.Errorf("same_site 为 none 时必须开启 secure")
+	}
+	maxAge, err := plugin.Duration(params, "max_age", o.maxAge)
+	if err != nil {
+		return nil, err
+	}
+	if maxAge <= 0 {
+		return nil, fmt.Errorf("配置 'max_age' 应为正的时长")
+	}
+	o.maxAge = maxAge
+	exemptPaths, err := plugin.StringList(params, "exempt_paths")
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range exemptPaths {
+		if _, err := path.Match(s, ""); err != nil {
+			return nil, fmt.Errorf("配置 'exempt_paths' 中的模式 '%s' 无效", s)
+		}
+	}
+	o.exemptPaths = exemptPaths
+	return o, nil
+}
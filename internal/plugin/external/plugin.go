@@ -0,0 +1,250 @@
+// file: internal/plugin/external/plugin.go
+package external
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"time"
+
+	"gateway.example/go-gateway/internal/bodybuffer"
+	"gateway.example/go-gateway/internal/config"
+	"gateway.example/go-gateway/internal/plugin"
+	"gateway.example/go-gateway/pkg/logger"
+)
+
+// 外部插件默认参数
+const (
+	DefaultTimeout     = time.Second
+	DefaultMaxBodySize = 64 << 10
+
+	maxCallResponseSize = 1 << 20
+)
+
+// reasonPattern 外部插件返回的中断原因代码格式
+var reasonPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+
+// 外部插件可以返回的动作
+const (
+	ActionContinue = "continue"
+	ActionBlock    = "block"
+)
+
+// CallRequest 是网关 POST 给外部插件的 JSON 请求
+type CallRequest struct {
+	Plugin  string                 `json:"plugin"`
+	Config  map[string]interface{} `json:"config,omitempty"` // 路由上该插件的配置 (去掉 name、priority)
+	Request RequestInfo            `json:"request"`
+}
+
+// RequestInfo 是客户端请求的元数据
+type RequestInfo struct {
+	Method     string              `json:"method"`
+	Host       string              `json:"host"`
+	Path       string              `json:"path"`
+	Query      string              `json:"query,omitempty"`
+	Headers    map[string][]string `json:"headers"`
+	RemoteAddr string              `json:"remote_addr"`
+	Body       []byte              `json:"body,omitempty"` // 仅在 forward_body 开启且未超过上限时携带，JSON 中为 base64
+}
+
+// CallResponse 是外部插件返回的决定，外部插件必须以 200 返回
+type CallResponse struct {
+	Action string `json:"action"` // continue (默认) 或 block
+
+	// block 时返回给客户端的响应
+	Reason  string            `json:"reason,omitempty"` // 中断原因代码，用于日志与指标，默认 external_block
+	Status  int               `json:"status,omitempty"` // 默认 403
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+
+	// continue 时对请求头的改写，先删除后设置
+	SetRequestHeaders    map[string]string `json:"set_request_headers,omitempty"`
+	RemoveRequestHeaders []string          `json:"remove_request_headers,omitempty"`
+}
+
+// Plugin 把插件逻辑委托给外部进程，使第三方插件无需重新编译网关即可接入。
+// 每个 external_plugins 条目注册为一个独立的插件，名称即配置中的 name。
+type Plugin struct {
+	cfg    config.ExternalPluginConfig
+	client *http.Client
+	log    logger.Logger
+}
+
+// NewPlugin 根据 external_plugins 中的一项配置创建插件
+func NewPlugin(cfg config.ExternalPluginConfig, log logger.Logger) (*Plugin, error) {
+	if cfg.Name == "" || cfg.URL == "" {
+		return nil, fmt.Errorf("外部插件配置缺失: name 与 url 不能为空")
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	if cfg.MaxBodySize <= 0 {
+		cfg.MaxBodySize = DefaultMaxBodySize
+	}
+	return &Plugin{
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.Timeout},
+		log:    log,
+	}, nil
+}
+
+func (p *Plugin) Name() string {
+	return p.cfg.Name
+}
+
+// ValidateConfig 路由上的配置原样转交外部插件，这里只检查能否序列化为 JSON
+func (p *Plugin) ValidateConfig(params config.PluginSpec) error {
+	if _, err := json.Marshal(pluginConfig(params)); err != nil {
+		return fmt.Errorf("配置无法序列化为 JSON: %w", err)
+	}
+	return nil
+}
+
+func (p *Plugin) Execute(w http.ResponseWriter, r *http.Request, params config.PluginSpec) (bool, error) {
+	ctx := r.Context()
+
+	decision, err := p.call(ctx, r, params)
+	if err != nil {
+		if p.cfg.FailOpen {
+			p.log.Warn(ctx, fmt.Sprintf("[插件: %s] 调用外部插件失败，按 fail_open 放行: %v", p.Name(), err),
+				"plugin", p.Name(), "url", p.cfg.URL)
+			return true, nil
+		}
+		p.log.Error(ctx, fmt.Sprintf("[插件: %s] 调用外部插件失败: %v", p.Name(), err),
+			"plugin", p.Name(), "url", p.cfg.URL)
+		http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+		return false, fmt.Errorf("调用外部插件 '%s' 失败: %w", p.Name(), err)
+	}
+
+	if decision.Action == ActionBlock {
+		status := decision.Status
+		if status == 0 {
+			status = http.StatusForbidden
+		}
+		// 原因代码会成为指标标签，只接受简短的 snake_case，避免外部输入造成标签爆炸
+		reason := decision.Reason
+		if !reasonPattern.MatchString(reason) {
+			reason = "external_block"
+		}
+		plugin.SetBlockReason(w, reason)
+		for name, value := range decision.Headers {
+			w.Header().Set(name, value)
+		}
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		}
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, decision.Body)
+		p.log.Info(ctx, fmt.Sprintf("[插件: %s] 外部插件拦截了请求", p.Name()),
+			"plugin", p.Name(), "reason", reason, "status_code", status)
+		return false, nil
+	}
+
+	for _, name := range decision.RemoveRequestHeaders {
+		r.Header.Del(name)
+	}
+	for name, value := range decision.SetRequestHeaders {
+		r.Header.Set(name, value)
+	}
+	return true, nil
+}
+
+// call 把请求元数据发送给外部插件并解析其决定
+func (p *Plugin) call(ctx context.Context, r *http.Request, params config.PluginSpec) (*CallResponse, error) {
+	payload := CallRequest{
+		Plugin: p.Name(),
+		Config: pluginConfig(params),
+		Request: RequestInfo{
+			Method:     r.Method,
+			Host:       r.Host,
+			Path:       r.URL.Path,
+			Query:      r.URL.RawQuery,
+			Headers:    r.Header,
+			RemoteAddr: r.RemoteAddr,
+		},
+	}
+	if p.cfg.ForwardBody {
+		body, err := bodybuffer.ReadAll(r, int64(p.cfg.MaxBodySize))
+		switch {
+		case errors.Is(err, bodybuffer.ErrTooLarge):
+			p.log.Debug(ctx, "[插件] 请求体超过外部插件上限，不携带请求体", "plugin", p.Name(), "limit", p.cfg.MaxBodySize)
+		case err != nil:
+			return nil, fmt.Errorf("读取请求体失败: %w", err)
+		default:
+			payload.Request.Body = body
+		}
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("序列化请求失败: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.cfg.URL, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("外部插件返回状态码 %d", resp.StatusCode)
+	}
+	var decision CallResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxCallResponseSize)).Decode(&decision); err != nil {
+		return nil, fmt.Errorf("解析外部插件响应失败: %w", err)
+	}
+	switch decision.Action {
+	case "", ActionContinue, ActionBlock:
+	default:
+		return nil, fmt.Errorf("外部插件返回了未知的动作 '%s'", decision.Action)
+	}
+	return &decision, nil
+}
+
+// pluginConfig 去掉网关自身使用的字段，并把 YAML 解析出的映射转换为可 JSON 序列化的形式
+func pluginConfig(params config.PluginSpec) map[string]interface{} {
+	out := make(map[string]interface{}, len(params))
+	for k, v := range params {
+		if k == "name" || k == "priority" {
+			continue
+		}
+		out[k] = normalize(v)
+	}
+	return out
+}
+
+func normalize(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			m[fmt.Sprint(k)] = normalize(item)
+		}
+		return m
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			m[k] = normalize(item)
+		}
+		return m
+	case []interface{}:
+		items := make([]interface{}, len(val))
+		for i, item := range val {
+			items[i] = normalize(item)
+		}
+		return items
+	default:
+		return v
+	}
+}
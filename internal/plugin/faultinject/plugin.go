@@ -0,0 +1,230 @@
+// file: internal/plugin/faultinject/plugin.go
+package faultinject
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"time"
+
+	"gateway.example/go-gateway/internal/config"
+	"gateway.example/go-gateway/internal/plugin"
+	"gateway.example/go-gateway/pkg/logger"
+)
+
+const PluginName = "fault_injection"
+
+// DefaultID 未配置 id 时故障规则所属的开关
+const DefaultID = "default"
+
+// HeaderFaultInjected 注入了故障的响应会带上该头，值为 delay 或 abort，便于客户端区分真实故障
+const HeaderFaultInjected = "X-Fault-Injected"
+
+// Plugin 按比例为请求注入延迟或错误响应，用于混沌测试、验证客户端的重试与超时策略。
+// 每条规则归属一个 id，可以通过管理接口在运行时开关，无需修改配置或重启。
+// 配置示例:
+//
+//	plugins:
+//	  - name: "fault_injection"
+//	    id: "checkout-chaos"
+//	    enabled: false                 # 初始状态，之后由管理接口切换
+//	    delay: { percentage: 5, duration: "2s" }
+//	    abort: { percentage: 1, status: 503 }
+type Plugin struct {
+	log logger.Logger
+
+	mu        sync.RWMutex
+	disabled  bool            // 全局开关，关闭后所有规则都不生效
+	overrides map[string]bool // id -> 管理接口设置的开关状态，优先于配置中的 enabled
+}
+
+// State 是故障注入开关的当前状态
+type State struct {
+	Enabled   bool            `json:"enabled"`   // 全局开关
+	Overrides map[string]bool `json:"overrides"` // 管理接口按 id 设置的开关
+}
+
+// rule 是插件配置解析后的故障规则
+type rule struct {
+	id              string
+	enabled         bool
+	delayPercentage float64
+	delay           time.Duration
+	abortPercentage float64
+	abortStatus     int
+}
+
+func NewPlugin(log logger.Logger) *Plugin {
+	return &Plugin{log: log, overrides: make(map[string]bool)}
+}
+
+func (p *Plugin) Name() string {
+	return PluginName
+}
+
+func (p *Plugin) ValidateConfig(params config.PluginSpec) error {
+	_, err := parseRule(params)
+	return err
+}
+
+func (p *Plugin) Execute(w http.ResponseWriter, r *http.Request, params config.PluginSpec) (bool, error) {
+	ctx := r.Context()
+
+	rl, err := parseRule(params)
+	if err != nil {
+		p.log.Error(ctx, fmt.Sprintf("[插件: %s] 配置错误: %v", p.Name(), err))
+		http.Error(w, "内部服务器错误: 插件配置错误", http.StatusInternalServerError)
+		return false, fmt.Errorf("插件 '%s' 配置错误: %w", p.Name(), err)
+	}
+	if !p.active(rl) {
+		return true, nil
+	}
+
+	if hit(rl.abortPercentage) {
+		p.log.Info(ctx, "[插件] 注入错误响应", "plugin", p.Name(), "id", rl.id, "status_code", rl.abortStatus, "path", r.URL.Path)
+		w.Header().Set(HeaderFaultInjected, "abort")
+		plugin.Block(w, rl.abortStatus, "fault_injected", "故障注入")
+		return false, nil
+	}
+
+	if hit(rl.delayPercentage) {
+		p.log.Info(ctx, "[插件] 注入延迟", "plugin", p.Name(), "id", rl.id, "delay", rl.delay.String(), "path", r.URL.Path)
+		w.Header().Set(HeaderFaultInjected, "delay")
+		timer := time.NewTimer(rl.delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			plugin.SetBlockReason(w, "client_closed")
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// State 返回当前的开关状态
+func (p *Plugin) State() State {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	overrides := make(map[string]bool, len(p.overrides))
+	for id, enabled := range p.overrides {
+		overrides[id] = enabled
+	}
+	return State{Enabled: !p.disabled, Overrides: overrides}
+}
+
+// SetEnabled 在运行时打开或关闭故障注入；id 为空时切换全局开关
+func (p *Plugin) SetEnabled(id string, enabled bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if id == "" {
+		p.disabled = !enabled
+		return
+	}
+	p.overrides[id] = enabled
+}
+
+// Reset 清除管理接口设置的所有开关，恢复为配置中的初始状态
+func (p *Plugin) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.disabled = false
+	clear(p.overrides)
+}
+
+// active 判断规则当前是否生效: 全局开关优先，其次是按 id 的覆盖，最后是配置中的 enabled
+func (p *Plugin) active(rl *rule) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.disabled {
+		return false
+	}
+	if enabled, ok := p.overrides[rl.id]; ok {
+		return enabled
+	}
+	return rl.enabled
+}
+
+func hit(percentage float64) bool {
+	return percentage > 0 && rand.Float64()*100 < percentage
+}
+
+func parseRule(params config.PluginSpec) (*rule, error) {
+	rl := &rule{id: DefaultID, enabled: true, abortStatus: http.StatusServiceUnavailable}
+
+	if v, ok := params["id"]; ok && v != nil {
+		s, isString := v.(string)
+		if !isString || s == "" {
+			return nil, fmt.Errorf("配置 'id' 应为非空字符串")
+		}
+		rl.id = s
+	}
+	if v, ok := params["enabled"]; ok && v != nil {
+		b, isBool := v.(bool)
+		if !isBool {
+			return nil, fmt.Errorf("配置 'enabled' 应为布尔值")
+		}
+		rl.enabled = b
+	}
+
+	if raw, ok := params["delay"]; ok && raw != nil {
+		m, isMap := raw.(map[interface{}]interface{})
+		if !isMap {
+			return nil, fmt.Errorf("配置 'delay' 应为映射")
+		}
+		pct, err := percentage(m, "delay")
+		if err != nil {
+			return nil, err
+		}
+		d, err := plugin.DurationValue("delay.duration", m["duration"])
+		if err != nil {
+			return nil, err
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("配置 'delay.duration' 应为正的时长")
+		}
+		rl.delayPercentage, rl.delay = pct, d
+	}
+
+	if raw, ok := params["abort"]; ok && raw != nil {
+		m, isMap := raw.(map[interface{}]interface{})
+		if !isMap {
+			return nil, fmt.Errorf("配置 'abort' 应为映射")
+		}
+		pct, err := percentage(m, "abort")
+		if err != nil {
+			return nil, err
+		}
+		if v, exists := m["status"]; exists {
+			status, isInt := v.(int)
+			if !isInt || status < 400 || status > 599 {
+				return nil, fmt.Errorf("配置 'abort.status' 应为 4xx 或 5xx 状态码")
+			}
+			rl.abortStatus = status
+		}
+		rl.abortPercentage = pct
+	}
+
+	if rl.delayPercentage == 0 && rl.abortPercentage == 0 {
+		return nil, fmt.Errorf("至少需要配置 'delay' 或 'abort' 中的一项")
+	}
+	return rl, nil
+}
+
+// percentage 解析 0-100 的百分比，YAML 中可以是整数或小数
+func percentage(m map[interface{}]interface{}, section string) (float64, error) {
+	var pct float64
+	switch v := m["percentage"].(type) {
+	case int:
+		pct = float64(v)
+	case float64:
+		pct = v
+	default:
+		return 0, fmt.Errorf("配置 '%s.percentage' 应为数字", section)
+	}
+	if pct < 0 || pct > 100 {
+		return 0, fmt.Errorf("配置 '%s.percentage' 应在 0 到 100 之间", section)
+	}
+	return pct, nil
+}
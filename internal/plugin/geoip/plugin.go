@@ -0,0 +1,231 @@
+// file: internal/plugin/geoip/plugin.go
+package geoip
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"gateway.example/go-gateway/internal/config"
+	"gateway.example/go-gateway/internal/plugin"
+	"gateway.example/go-gateway/pkg/logger"
+	"github.com/oschwald/maxminddb-golang"
+)
+
+const PluginName = "geoip"
+
+// 默认请求头
+const (
+	DefaultCountryHeader = "X-Geo-Country"
+	DefaultCityHeader    = "X-Geo-City"
+)
+
+// Plugin 根据 MaxMind 格式 (mmdb) 的 GeoIP 数据库解析客户端 IP 所在地，
+// 可以按国家放行或拦截，并把国家、城市写入请求头供上游统计分析。
+// 客户端 IP 按 server.trusted_proxies 规则解析，客户端无法通过 X-Forwarded-For 伪造。
+// 配置示例:
+//
+//	plugins:
+//	  - name: "geoip"
+//	    database: "/var/lib/geoip/GeoLite2-City.mmdb"
+//	    block_countries: [ "KP", "IR" ]      # 与 allow_countries 二选一
+//	    block_unknown: false                 # 无法解析国家 (如内网地址) 时是否拦截
+//	    inject_headers: true                 # 写入 X-Geo-Country / X-Geo-City
+//	    language: "en"                       # 城市名称的语言
+type Plugin struct {
+	log logger.Logger
+
+	mu      sync.Mutex
+	readers map[string]*maxminddb.Reader // 数据库路径 -> 读取器，多条路由共用同一个文件时只打开一次
+}
+
+// options 是插件配置解析后的参数
+type options struct {
+	database       string
+	allowCountries []string
+	blockCountries []string
+	blockUnknown   bool
+	injectHeaders  bool
+	countryHeader  string
+	cityHeader     string
+	language       string
+}
+
+// record 是从数据库中读取的字段，兼容 GeoLite2/GeoIP2 的 Country 与 City 库
+type record struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	RegisteredCountry struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"registered_country"`
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+}
+
+func NewPlugin(log logger.Logger) *Plugin {
+	return &Plugin{log: log, readers: make(map[string]*maxminddb.Reader)}
+}
+
+func (p *Plugin) Name() string {
+	return PluginName
+}
+
+// ValidateConfig 除检查配置外还会打开数据库，数据库缺失或损坏时网关启动失败
+func (p *Plugin) ValidateConfig(params config.PluginSpec) error {
+	opts, err := parseOptions(params)
+	if err != nil {
+		return err
+	}
+	_, err = p.reader(opts.database)
+	return err
+}
+
+func (p *Plugin) Execute(w http.ResponseWriter, r *http.Request, params config.PluginSpec) (bool, error) {
+	ctx := r.Context()
+
+	opts, err := parseOptions(params)
+	if err != nil {
+		p.log.Error(ctx, fmt.Sprintf("[插件: %s] 配置错误: %v", p.Name(), err))
+		http.Error(w, "内部服务器错误: 插件配置错误", http.StatusInternalServerError)
+		return false, fmt.Errorf("插件 '%s' 配置错误: %w", p.Name(), err)
+	}
+	db, err := p.reader(opts.database)
+	if err != nil {
+		p.log.Error(ctx, fmt.Sprintf("[插件: %s] 打开 GeoIP 数据库失败: %v", p.Name(), err))
+		http.Error(w, "内部服务器错误: 插件配置错误", http.StatusInternalServerError)
+		return false, err
+	}
+
+	// 删除客户端自带的地理位置头，防止伪造
+	r.Header.Del(opts.countryHeader)
+	r.Header.Del(opts.cityHeader)
+
+	clientIP := plugin.ClientIP(r)
+	var rec record
+	if ip := net.ParseIP(clientIP); ip != nil {
+		if err := db.Lookup(ip, &rec); err != nil {
+			p.log.Warn(ctx, fmt.Sprintf("[插件: %s] 查询 GeoIP 数据库失败: %v", p.Name(), err), "client_ip", clientIP)
+		}
+	}
+	country := rec.Country.ISOCode
+	if country == "" {
+		country = rec.RegisteredCountry.ISOCode
+	}
+
+	if !opts.allowed(country) {
+		p.log.Info(ctx, "[插件] 按地理位置拦截请求", "plugin", p.Name(), "client_ip", clientIP, "country", country, "path", r.URL.Path)
+		plugin.Block(w, http.StatusForbidden, "geo_blocked", "所在地区不允许访问")
+		return false, nil
+	}
+
+	if opts.injectHeaders {
+		if country != "" {
+			r.Header.Set(opts.countryHeader, country)
+		}
+		if city := rec.City.Names[opts.language]; city != "" && !strings.ContainsAny(city, "\r\n\x00") {
+			r.Header.Set(opts.cityHeader, city)
+		}
+	}
+	return true, nil
+}
+
+// allowed 判断国家是否允许访问，国家未知时由 block_unknown 决定
+func (o *options) allowed(country string) bool {
+	if country == "" {
+		return !o.blockUnknown
+	}
+	if len(o.allowCountries) > 0 {
+		return slices.Contains(o.allowCountries, country)
+	}
+	return !slices.Contains(o.blockCountries, country)
+}
+
+// reader 返回已打开的数据库，首次使用时打开
+func (p *Plugin) reader(path string) (*maxminddb.Reader, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if db, ok := p.readers[path]; ok {
+		return db, nil
+	}
+	db, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开 GeoIP 数据库 '%s' 失败: %w", path, err)
+	}
+	p.readers[path] = db
+	p.log.Info(context.Background(), fmt.Sprintf("[插件: %s] 已加载 GeoIP 数据库", p.Name()), "path", path, "type", db.Metadata.DatabaseType)
+	return db, nil
+}
+
+func parseOptions(params config.PluginSpec) (*options, error) {
+	o := &options{
+		injectHeaders: true,
+		countryHeader: DefaultCountryHeader,
+		cityHeader:    DefaultCityHeader,
+		language:      "en",
+	}
+
+	o.database, _ = params["database"].(string)
+	if o.database == "" {
+		return nil, fmt.Errorf("缺少 'database' 配置")
+	}
+
+	var err error
+	if o.allowCountries, err = countryList(params, "allow_countries"); err != nil {
+		return nil, err
+	}
+	if o.blockCountries, err = countryList(params, "block_countries"); err != nil {
+		return nil, err
+	}
+	if len(o.allowCountries) > 0 && len(o.blockCountries) > 0 {
+		return nil, fmt.Errorf("'allow_countries' 与 'block_countries' 不能同时配置")
+	}
+
+	bools := map[string]*bool{"block_unknown": &o.blockUnknown, "inject_headers": &o.injectHeaders}
+	for key, target := range bools {
+		if v, ok := params[key]; ok && v != nil {
+			b, isBool := v.(bool)
+			if !isBool {
+				return nil, fmt.Errorf("配置 '%s' 应为布尔值", key)
+			}
+			*target = b
+		}
+	}
+	stringFields := map[string]*string{"country_header": &o.countryHeader, "city_header": &o.cityHeader, "language": &o.language}
+	for key, target := range stringFields {
+		if v, ok := params[key]; ok && v != nil {
+			s, isString := v.(string)
+			if !isString || s == "" {
+				return nil, fmt.Errorf("配置 '%s' 应为非空字符串", key)
+			}
+			*target = s
+		}
+	}
+	return o, nil
+}
+
+// countryList 解析 ISO 3166-1 两位国家代码列表，统一为大写
+func countryList(params config.PluginSpec, key string) ([]string, error) {
+	raw, ok := params[key]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	list, isList := raw.([]interface{})
+	if !isList {
+		return nil, fmt.Errorf("配置 '%s' 应为国家代码列表", key)
+	}
+	out := make([]string, 0, len(list))
+	for _, item := range list {
+		code, isString := item.(string)
+		if !isString || len(code) != 2 {
+			return nil, fmt.Errorf("配置 '%s' 中的 '%v' 不是两位国家代码", key, item)
+		}
+		out = append(out, strings.ToUpper(code))
+	}
+	return out, nil
+}
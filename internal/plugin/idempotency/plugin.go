@@ -0,0 +1,413 @@
+// file: internal/plugin/idempotency/plugin.go
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gateway.example/go-gateway/internal/bodybuffer"
+	"gateway.example/go-gateway/internal/cache"
+	"gateway.example/go-gateway/internal/config"
+	"gateway.example/go-gateway/internal/plugin"
+	"gateway.example/go-gateway/pkg/logger"
+)
+
+const PluginName = "idempotency"
+
+// HeaderReplayed 重放缓存响应时带上的响应头
+const HeaderReplayed = "Idempotent-Replayed"
+
+// 默认参数
+const (
+	cacheKeyPrefix      = "idem:"
+	defaultHeader       = "Idempotency-Key"
+	defaultTTL          = 24 * time.Hour
+	defaultLockTimeout  = time.Minute
+	defaultMaxBodySize  = 1 << 20
+	defaultMaxRespSize  = 1 << 20
+	defaultMaxKeyLength = 255
+)
+
+// 缓存条目的状态
+const (
+	stateProcessing = "processing"
+	stateCompleted  = "completed"
+)
+
+// defaultMethods 默认需要去重的请求方法
+var defaultMethods = []string{http.MethodPost, http.MethodPatch}
+
+// Plugin 按 Idempotency-Key 请求头对非幂等请求去重。
+// 首个请求正常转发，上游响应缓存 ttl 时长；之后携带相同 key 的重试直接返回原响应，
+// 避免客户端重试导致重复扣款。原请求尚未完成时重试返回 409，key 被用于不同请求体时返回 422。
+// 上游 5xx 响应不会被缓存，客户端可以使用同一个 key 重试。
+// 配置示例:
+//
+//	plugins:
+//	  - name: "idempotency"
+//	    header: "Idempotency-Key"
+//	    required: false            # true 时缺少 key 的请求返回 400
+//	    methods: [ "POST", "PATCH" ]
+//	    ttl: "24h"                 # 响应保留时长
+//	    lock_timeout: "1m"         # 处理中状态的最长保留时间，应大于上游超时
+//	    scope_headers: [ "Authorization" ]   # 参与计算缓存键的请求头，避免不同用户的 key 冲突
+//	    max_body_size: 1048576     # 请求体上限，用于识别 key 被用于不同请求
+//	    max_response_size: 1048576 # 超过该大小的响应不缓存
+type Plugin struct {
+	store cache.Cache
+	log   logger.Logger
+}
+
+// options 是插件配置解析后的参数
+type options struct {
+	header       string
+	required     bool
+	methods      []string
+	ttl          time.Duration
+	lockTimeout  time.Duration
+	scopeHeaders []string
+	maxBodySize  int64
+	maxRespSize  int64
+}
+
+// entry 是缓存中保存的一条记录
+type entry struct {
+	State       string      `json:"state"`
+	Fingerprint string      `json:"fingerprint"`
+	StatusCode  int         `json:"status_code,omitempty"`
+	Header      http.Header `json:"header,omitempty"`
+	Body        []byte      `json:"body,omitempty"`
+}
+
+// pending 是已占位、等待上游响应的请求
+type pending struct {
+	key         string
+	fingerprint string
+	ttl         time.Duration
+	maxRespSize int64
+
+	mu   sync.Mutex
+	done bool
+}
+
+type pendingKey struct{}
+
+func NewPlugin(store cache.Cache, log logger.Logger) *Plugin {
+	return &Plugin{store: store, log: log}
+}
+
+func (p *Plugin) Name() string {
+	return PluginName
+}
+
+func (p *Plugin) ValidateConfig(params config.PluginSpec) error {
+	_, err := parseOptions(params)
+	return err
+}
+
+func (p *Plugin) Execute(w http.ResponseWriter, r *http.Request, params config.PluginSpec) (bool, error) {
+	ctx := r.Context()
+
+	opts, err := parseOptions(params)
+	if err != nil {
+		p.log.Error(ctx, fmt.Sprintf("[插件: %s] 配置错误: %v", p.Name(), err))
+		http.Error(w, "内部服务器错误: 插件配置错误", http.StatusInternalServerError)
+		return false, fmt.Errorf("插件 '%s' 配置错误: %w", p.Name(), err)
+	}
+	if !slices.Contains(opts.methods, r.Method) {
+		return true, nil
+	}
+
+	idemKey := r.Header.Get(opts.header)
+	if idemKey == "" {
+		if opts.required {
+			plugin.Block(w, http.StatusBadRequest, "missing_idempotency_key", fmt.Sprintf("缺少 %s 请求头", opts.header))
+			return false, nil
+		}
+		return true, nil
+	}
+	if len(idemKey) > defaultMaxKeyLength {
+		plugin.Block(w, http.StatusBadRequest, "invalid_idempotency_key", fmt.Sprintf("%s 长度不能超过 %d", opts.header, defaultMaxKeyLength))
+		return false, nil
+	}
+
+	body, err := bodybuffer.ReadAll(r, opts.maxBodySize)
+	if errors.Is(err, bodybuffer.ErrTooLarge) {
+		plugin.Block(w, http.StatusRequestEntityTooLarge, "body_too_large", "请求体过大")
+		return false, nil
+	}
+	if err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return false, fmt.Errorf("读取请求体失败: %w", err)
+	}
+
+	key := cacheKey(r, idemKey, opts.scopeHeaders)
+	fingerprint := requestFingerprint(r, body)
+
+	existing, err := p.reserve(ctx, key, fingerprint, opts.lockTimeout)
+	if err != nil {
+		// 缓存不可用时放行，去重失效好过拒绝所有写请求
+		p.log.Error(ctx, "[插件] 读写幂等缓存失败，跳过去重", "plugin", p.Name(), "error", err)
+		return true, nil
+	}
+
+	switch {
+	case existing == nil:
+		pd := &pending{key: key, fingerprint: fingerprint, ttl: opts.ttl, maxRespSize: opts.maxRespSize}
+		*r = *r.WithContext(context.WithValue(ctx, pendingKey{}, pd))
+		// 请求结束时仍未缓存响应 (上游出错、响应过大等)，释放占位以便客户端重试
+		context.AfterFunc(r.Context(), func() {
+			if pd.finish() {
+				p.release(pd.key)
+			}
+		})
+		return true, nil
+
+	case existing.Fingerprint != fingerprint:
+		p.log.Warn(ctx, "[插件] Idempotency-Key 被用于不同的请求", "plugin", p.Name(), "path", r.URL.Path)
+		plugin.Block(w, http.StatusUnprocessableEntity, "idempotency_key_reused", fmt.Sprintf("%s 已被用于不同的请求", opts.header))
+		return false, nil
+
+	case existing.State == stateProcessing:
+		w.Header().Set("Retry-After", "1")
+		plugin.Block(w, http.StatusConflict, "request_in_progress", "相同 Idempotency-Key 的请求正在处理中")
+		return false, nil
+	}
+
+	p.log.Info(ctx, "[插件] 重放幂等请求的响应", "plugin", p.Name(), "path", r.URL.Path, "status_code", existing.StatusCode)
+	for k, v := range existing.Header {
+		w.Header()[k] = v
+	}
+	w.Header().Set(HeaderReplayed, "true")
+	plugin.SetBlockReason(w, "idempotent_replay")
+	w.WriteHeader(existing.StatusCode)
+	_, _ = w.Write(existing.Body)
+	return false, nil
+}
+
+// OnResponse 实现 plugin.ResponseHandler，在响应体完整转发后写入缓存
+func (p *Plugin) OnResponse(resp *http.Response, route *config.RouteConfig, params config.PluginSpec) error {
+	pd, _ := resp.Request.Context().Value(pendingKey{}).(*pending)
+	if pd == nil {
+		return nil
+	}
+	// 5xx 表示请求没有被可靠地处理，不缓存，允许客户端重试
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return nil
+	}
+
+	e := &entry{
+		State:       stateCompleted,
+		Fingerprint: pd.fingerprint,
+		StatusCode:  resp.StatusCode,
+		Header:      resp.Header.Clone(),
+	}
+	resp.Body = &capturingBody{
+		ReadCloser: resp.Body,
+		limit:      pd.maxRespSize,
+		onComplete: func(body []byte) {
+			if !pd.finish() {
+				return
+			}
+			e.Body = body
+			ctx := context.Background()
+			data, err := json.Marshal(e)
+			if err == nil {
+				err = p.store.Set(ctx, pd.key, data, pd.ttl)
+			}
+			if err != nil {
+				p.log.Warn(ctx, "[插件] 写入幂等缓存失败", "plugin", p.Name(), "error", err)
+				p.release(pd.key)
+			}
+		},
+	}
+	return nil
+}
+
+// reserve 原子地写入处理中占位并返回 nil；key 已有记录时返回该记录。
+// 缓存实现 cache.Adder 时占位使用 SET NX，共用 Redis 的多个副本之间同样互斥
+func (p *Plugin) reserve(ctx context.Context, key, fingerprint string, lockTimeout time.Duration) (*entry, error) {
+	placeholder, err := json.Marshal(&entry{State: stateProcessing, Fingerprint: fingerprint})
+	if err != nil {
+		return nil, err
+	}
+	adder, ok := p.store.(cache.Adder)
+	if !ok {
+		// 不支持原子写入的缓存只能先读后写，并发的重试可能同时通过
+		existing, err := p.lookup(ctx, key)
+		if existing != nil || err != nil {
+			return existing, err
+		}
+		return nil, p.store.Set(ctx, key, placeholder, lockTimeout)
+	}
+
+	// 占位失败后记录可能恰好过期或被释放，重试几次
+	for attempt := 0; attempt < 3; attempt++ {
+		added, err := adder.SetNX(ctx, key, placeholder, lockTimeout)
+		if err != nil || added {
+			return nil, err
+		}
+		existing, err := p.lookup(ctx, key)
+		if existing != nil || err != nil {
+			return existing, err
+		}
+	}
+	return nil, errors.New("幂等记录反复变化，无法占位")
+}
+
+// lookup 读取 key 对应的记录，不存在时返回 nil；无法解析的记录被删除并视为不存在
+func (p *Plugin) lookup(ctx context.Context, key string) (*entry, error) {
+	data, err := p.store.Get(ctx, key)
+	if errors.Is(err, cache.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, p.store.Delete(ctx, key)
+	}
+	return &e, nil
+}
+
+func (p *Plugin) release(key string) {
+	ctx := context.Background()
+	if err := p.store.Delete(ctx, key); err != nil {
+		p.log.Warn(ctx, "[插件] 释放幂等占位失败", "plugin", p.Name(), "error", err)
+	}
+}
+
+// finish 标记请求已结束，只有第一次调用返回 true
+func (pd *pending) finish() bool {
+	pd.mu.Lock()
+	defer pd.mu.Unlock()
+	if pd.done {
+		return false
+	}
+	pd.done = true
+	return true
+}
+
+// cacheKey 由方法、路径、作用域请求头与 Idempotency-Key 计算缓存键
+func cacheKey(r *http.Request, idemKey string, scopeHeaders []string) string {
+	h := sha256.New()
+	for _, name := range scopeHeaders {
+		h.Write([]byte(r.Header.Get(name)))
+		h.Write([]byte{0})
+	}
+	h.Write([]byte(idemKey))
+	return cacheKeyPrefix + r.Method + " " + r.URL.Path + "|" + hex.EncodeToString(h.Sum(nil))
+}
+
+// requestFingerprint 标识请求内容，用于发现同一个 key 被用于不同的请求
+func requestFingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.URL.RawQuery))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// capturingBody 在转发响应体的同时复制一份，读到 EOF 后回调；超过上限则放弃缓存
+type capturingBody struct {
+	io.ReadCloser
+	buf        bytes.Buffer
+	limit      int64
+	overflow   bool
+	onComplete func(body []byte)
+}
+
+func (b *capturingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 && !b.overflow {
+		if int64(b.buf.Len()+n) > b.limit {
+			b.overflow = true
+			b.buf = bytes.Buffer{}
+		} else {
+			b.buf.Write(p[:n])
+		}
+	}
+	if err == io.EOF && !b.overflow && b.onComplete != nil {
+		b.onComplete(b.buf.Bytes())
+		b.onComplete = nil
+	}
+	return n, err
+}
+
+func parseOptions(params config.PluginSpec) (*options, error) {
+	o := &options{
+		header:       defaultHeader,
+		methods:      defaultMethods,
+		ttl:          defaultTTL,
+		lockTimeout:  defaultLockTimeout,
+		scopeHeaders: []string{"Authorization"},
+		maxBodySize:  defaultMaxBodySize,
+		maxRespSize:  defaultMaxRespSize,
+	}
+
+	if v, ok := params["header"]; ok && v != nil {
+		s, isString := v.(string)
+		if !isString || s == "" {
+			return nil, fmt.Errorf("配置 'header' 应为非空字符串")
+		}
+		o.header = s
+	}
+	if v, ok := params["required"]; ok && v != nil {
+		b, isBool := v.(bool)
+		if !isBool {
+			return nil, fmt.Errorf("配置 'required' 应为布尔值")
+		}
+		o.required = b
+	}
+
+	methods, err := plugin.StringList(params, "methods")
+	if err != nil {
+		return nil, err
+	}
+	if len(methods) > 0 {
+		o.methods = make([]string, 0, len(methods))
+		for _, m := range methods {
+			o.methods = append(o.methods, strings.ToUpper(m))
+		}
+	}
+	if _, ok := params["scope_headers"]; ok {
+		if o.scopeHeaders, err = plugin.StringList(params, "scope_headers"); err != nil {
+			return nil, err
+		}
+	}
+
+	durations := map[string]*time.Duration{"ttl": &o.ttl, "lock_timeout": &o.lockTimeout}
+	for key, target := range durations {
+		d, err := plugin.Duration(params, key, *target)
+		if err != nil {
+			return nil, err
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("配置 '%s' 应为正的时长", key)
+		}
+		*target = d
+	}
+	sizes := map[string]*int64{"max_body_size": &o.maxBodySize, "max_response_size": &o.maxRespSize}
+	for key, target := range sizes {
+		if v, ok := params[key]; ok && v != nil {
+			size, err := config.ParseByteSizeValue(v)
+			if err != nil || size <= 0 {
+				return nil, fmt.Errorf("配置 '%s' 应为正的字节数或带单位的大小 (如 1MB)", key)
+			}
+			*target = int64(size)
+		}
+	}
+	return o, nil
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"time"
 
 	"gateway.example/go-gateway/internal/config"
 	"gateway.example/go-gateway/pkg/logger"
@@ -14,6 +15,8 @@ import (
 type Interface interface {
 	Name() string
 	Execute(w http.ResponseWriter, r *http.Request, params config.PluginSpec) (continueChain bool, err error)
+	// ValidateConfig 在网关启动时校验插件配置，使配置错误尽早暴露而不是在请求时返回 500
+	ValidateConfig(params config.PluginSpec) error
 }
 
 // Manager 负责管理和执行插件
@@ -22,13 +25,8 @@ type Manager struct {
 	log     logger.Logger
 }
 
-func NewManager() *Manager {
-	log, err := logger.DefaultNew()
-	if err != nil {
-		// 如果无法初始化日志记录器，使用标准输出输出错误信息并退出
-		panic(fmt.Sprintf("[插件管理器] 致命错误: 无法初始化日志记录器: %v", err))
-	}
-
+// NewManager 创建插件管理器，log 通常为网关的日志器
+func NewManager(log logger.Logger) *Manager {
 	return &Manager{
 		plugins: make(map[string]Interface),
 		log:     log,
@@ -60,8 +58,33 @@ func (m *Manager) Register(p Interface) {
 	m.plugins[name] = p
 }
 
-// ExecuteChain 执行插件链
-func (m *Manager) ExecuteChain(w http.ResponseWriter, r *http.Request, pluginSpecs []config.PluginSpec) (bool, error) {
+// ValidateChain 校验插件链中每个插件的配置: name 必须指向已注册的插件，
+// priority 必须为整数，其余字段交由插件自身的 ValidateConfig 校验
+func (m *Manager) ValidateChain(pluginSpecs []config.PluginSpec) error {
+	for i, spec := range pluginSpecs {
+		pluginName, ok := spec["name"].(string)
+		if !ok || pluginName == "" {
+			return fmt.Errorf("第 %d 个插件缺少 'name' 字段或类型不正确", i+1)
+		}
+		plugin := m.GetPlugin(pluginName)
+		if plugin == nil {
+			return fmt.Errorf("插件 '%s' 未注册", pluginName)
+		}
+		if v, ok := spec["priority"]; ok {
+			if _, isInt := v.(int); !isInt {
+				return fmt.Errorf("插件 '%s' 的 'priority' 必须是整数", pluginName)
+			}
+		}
+		if err := plugin.ValidateConfig(spec); err != nil {
+			return fmt.Errorf("插件 '%s' 配置错误: %w", pluginName, err)
+		}
+	}
+	return nil
+}
+
+// ExecuteChain 执行插件链。全部插件放行时返回 nil；
+// 某个插件中断请求时返回 BlockResult，说明由哪个插件、以什么原因和状态码中断
+func (m *Manager) ExecuteChain(w http.ResponseWriter, r *http.Request, pluginSpecs []config.PluginSpec) (*BlockResult, error) {
 	ctx := r.Context()
 
 	for _, spec := range pluginSpecs {
@@ -71,7 +94,7 @@ func (m *Manager) ExecuteChain(w http.ResponseWriter, r *http.Request, pluginSpe
 				"spec", spec,
 				"action", "config_error")
 			http.Error(w, "内部服务器错误: 插件配置错误", http.StatusInternalServerError)
-			return false, fmt.Errorf("无效的插件配置: %v", spec)
+			return nil, fmt.Errorf("无效的插件配置: %v", spec)
 		}
 
 		plugin := m.GetPlugin(pluginName)
@@ -80,29 +103,72 @@ func (m *Manager) ExecuteChain(w http.ResponseWriter, r *http.Request, pluginSpe
 				"plugin_name", pluginName,
 				"action", "plugin_not_found")
 			http.Error(w, "内部服务器错误: 插件未找到", http.StatusInternalServerError)
-			return false, fmt.Errorf("插件 '%s' 未注册", pluginName)
+			return nil, fmt.Errorf("插件 '%s' 未注册", pluginName)
 		}
 
 		m.log.Info(ctx, fmt.Sprintf("[插件管理器] 执行插件: %s", pluginName),
 			"plugin_name", pluginName,
 			"action", "execute")
 
-		continueChain, err := plugin.Execute(w, r, spec)
+		start := time.Now()
+		rec := &blockRecorder{ResponseWriter: w, plugin: pluginName}
+		continueChain, err := plugin.Execute(rec, r, spec)
 		if err != nil {
+			observePlugin(pluginName, phaseRequest, resultError, start)
 			m.log.Error(ctx, fmt.Sprintf("[插件管理器] 错误: 插件 '%s' 执行时返回内部错误: %v", pluginName, err),
 				"plugin_name", pluginName,
 				"error", err.Error(),
 				"action", "execute_error")
-			return false, err
+			return nil, err
 		}
 
 		if !continueChain {
+			block := rec.result()
+			observePlugin(pluginName, phaseRequest, resultBlock, start)
+			observeBlock(block)
 			m.log.Info(ctx, fmt.Sprintf("[插件管理器] 信息: 插件 '%s' 中断了请求链。", pluginName),
 				"plugin_name", pluginName,
+				"reason", block.Reason,
+				"status_code", block.Status,
 				"action", "chain_interrupted")
-			return false, nil
+			return block, nil
 		}
+		observePlugin(pluginName, phaseRequest, resultPass, start)
 	}
 
-	return true, nil
+	return nil, nil
+}
+
+// ResponseHandler 是插件可选实现的接口，在上游响应返回之后、写回客户端之前执行
+// 可用于改写响应头、记录指标或清理响应中的敏感字段
+type ResponseHandler interface {
+	OnResponse(resp *http.Response, route *config.RouteConfig, params config.PluginSpec) error
+}
+
+// ExecuteResponseChain 执行响应阶段的插件链
+// 按与请求阶段相反的顺序调用 pluginSpecs 中实现了 ResponseHandler 的插件，任一插件返回错误即中止
+func (m *Manager) ExecuteResponseChain(resp *http.Response, route *config.RouteConfig, pluginSpecs []config.PluginSpec) error {
+	ctx := resp.Request.Context()
+
+	for i := len(pluginSpecs) - 1; i >= 0; i-- {
+		spec := pluginSpecs[i]
+		pluginName, _ := spec["name"].(string)
+		handler, ok := m.GetPlugin(pluginName).(ResponseHandler)
+		if !ok {
+			continue
+		}
+
+		start := time.Now()
+		if err := handler.OnResponse(resp, route, spec); err != nil {
+			observePlugin(pluginName, phaseResponse, resultError, start)
+			m.log.Error(ctx, fmt.Sprintf("[插件管理器] 错误: 插件 '%s' 处理响应时返回错误: %v", pluginName, err),
+				"plugin_name", pluginName,
+				"error", err.Error(),
+				"action", "on_response_error")
+			return fmt.Errorf("插件 '%s' 处理响应失败: %w", pluginName, err)
+		}
+		observePlugin(pluginName, phaseResponse, resultPass, start)
+	}
+
+	return nil
 }
@@ -0,0 +1,40 @@
+package plugin
+
+import (
+	"time"
+
+	"gateway.example/go-gateway/pkg/metrics"
+)
+
+// 插件执行的阶段与结果，用作指标标签
+const (
+	phaseRequest  = "request"
+	phaseResponse = "response"
+
+	resultPass  = "pass"
+	resultBlock = "block"
+	resultError = "error"
+)
+
+var (
+	pluginExecutions = metrics.NewCounterVec("gateway_plugin_executions_total",
+		"插件执行次数，result 为 pass (放行)、block (中断请求链) 或 error (内部错误)",
+		"plugin", "phase", "result")
+	pluginDuration = metrics.NewHistogramVec("gateway_plugin_duration_seconds",
+		"插件单次执行耗时 (秒)", nil,
+		"plugin", "phase")
+	pluginBlocks = metrics.NewCounterVec("gateway_plugin_blocks_total",
+		"插件中断请求的次数，按插件与原因代码统计",
+		"plugin", "reason")
+)
+
+// observePlugin 记录一次插件执行的耗时和结果
+func observePlugin(pluginName, phase, result string, start time.Time) {
+	pluginDuration.WithLabelValues(pluginName, phase).Observe(time.Since(start).Seconds())
+	pluginExecutions.WithLabelValues(pluginName, phase, result).Inc()
+}
+
+// observeBlock 记录一次插件中断请求的原因
+func observeBlock(block *BlockResult) {
+	pluginBlocks.WithLabelValues(block.Plugin, block.Reason).Inc()
+}
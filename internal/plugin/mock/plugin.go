@@ -0,0 +1,186 @@
+// file: internal/plugin/mock/plugin.go
+package mock
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gateway.example/go-gateway/internal/config"
+	"gateway.example/go-gateway/internal/plugin"
+	"gateway.example/go-gateway/pkg/logger"
+)
+
+const PluginName = "mock"
+
+// templatePattern 匹配响应模板变量，例如 {path}、{query.id}、{header.X-Request-ID}
+var templatePattern = regexp.MustCompile(`\{([a-z_]+(?:\.[A-Za-z0-9_\-]+)?)\}`)
+
+// Plugin 直接返回配置好的响应并中断插件链，请求不会被转发给上游。
+// 用于前端联调或故障期间临时旁路上游。配置示例:
+//
+//	plugins:
+//	  - name: "mock"
+//	    status: 200
+//	    headers: { Content-Type: "application/json" }
+//	    body: '{"id": "{query.id}", "path": "{path}"}'
+//	    template: true        # 展开 body 和 headers 中的模板变量
+//	    delay: "200ms"        # 模拟上游延迟
+//	    jitter: "50ms"        # 在 delay 基础上增加 [0, jitter) 的随机延迟
+//
+// 模板变量: {method} {path} {host} {query.<name>} {header.<name>} {timestamp}
+type Plugin struct {
+	log logger.Logger
+}
+
+func NewPlugin(log logger.Logger) *Plugin {
+	return &Plugin{log: log}
+}
+
+func (p *Plugin) Name() string {
+	return PluginName
+}
+
+// response 是插件配置解析后的模拟响应
+type response struct {
+	status    int
+	headers   map[string]string
+	body      string
+	templated bool
+	delay     time.Duration
+	jitter    time.Duration
+}
+
+func (p *Plugin) Execute(w http.ResponseWriter, r *http.Request, params config.PluginSpec) (bool, error) {
+	ctx := r.Context()
+
+	resp, err := parseResponse(params)
+	if err != nil {
+		p.log.Error(ctx, fmt.Sprintf("[插件: %s] 配置错误: %v", p.Name(), err))
+		http.Error(w, "内部服务器错误: 插件配置错误", http.StatusInternalServerError)
+		return false, fmt.Errorf("插件 '%s' 配置错误: %w", p.Name(), err)
+	}
+
+	delay := resp.delay
+	if resp.jitter > 0 {
+		delay += time.Duration(rand.Int63n(int64(resp.jitter)))
+	}
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			p.log.Info(ctx, "[插件] 客户端在模拟响应返回前断开连接", "plugin", p.Name(), "path", r.URL.Path)
+			plugin.SetBlockReason(w, "client_closed")
+			return false, nil
+		}
+	}
+
+	body := resp.body
+	for name, value := range resp.headers {
+		if resp.templated {
+			value = expandTemplate(value, r)
+		}
+		w.Header().Set(name, value)
+	}
+	if resp.templated {
+		body = expandTemplate(body, r)
+	}
+	if w.Header().Get("Content-Type") == "" && body != "" {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	plugin.SetBlockReason(w, "mocked")
+	w.WriteHeader(resp.status)
+	if r.Method != http.MethodHead {
+		_, _ = w.Write([]byte(body))
+	}
+
+	p.log.Info(ctx, "[插件] 已返回模拟响应", "plugin", p.Name(), "path", r.URL.Path, "status_code", resp.status, "delay", delay.String())
+	return false, nil
+}
+
+// ValidateConfig 校验状态码、时长等配置
+func (p *Plugin) ValidateConfig(params config.PluginSpec) error {
+	_, err := parseResponse(params)
+	return err
+}
+
+func parseResponse(params config.PluginSpec) (*response, error) {
+	resp := &response{}
+	var err error
+	resp.status, err = intParam(params["status"], http.StatusOK)
+	if err != nil || resp.status < 100 || resp.status > 999 {
+		return nil, fmt.Errorf("配置 'status' 无效: %v", params["status"])
+	}
+	if resp.delay, err = plugin.Duration(params, "delay", 0); err != nil {
+		return nil, err
+	}
+	if resp.jitter, err = plugin.Duration(params, "jitter", 0); err != nil {
+		return nil, err
+	}
+	if resp.headers, err = plugin.StringMap(params, "headers"); err != nil {
+		return nil, err
+	}
+	if v, ok := params["body"]; ok && v != nil {
+		body, isString := v.(string)
+		if !isString {
+			return nil, fmt.Errorf("配置 'body' 应为字符串")
+		}
+		resp.body = body
+	}
+	if v, ok := params["template"]; ok && v != nil {
+		templated, isBool := v.(bool)
+		if !isBool {
+			return nil, fmt.Errorf("配置 'template' 应为布尔值")
+		}
+		resp.templated = templated
+	}
+	return resp, nil
+}
+
+// expandTemplate 展开模板变量，未知变量保持原样
+func expandTemplate(value string, r *http.Request) string {
+	if !strings.Contains(value, "{") {
+		return value
+	}
+	return templatePattern.ReplaceAllStringFunc(value, func(match string) string {
+		name := match[1 : len(match)-1]
+		switch {
+		case name == "method":
+			return r.Method
+		case name == "path":
+			return r.URL.Path
+		case name == "host":
+			return r.Host
+		case name == "timestamp":
+			return time.Now().UTC().Format(time.RFC3339)
+		case strings.HasPrefix(name, "query."):
+			return r.URL.Query().Get(strings.TrimPrefix(name, "query."))
+		case strings.HasPrefix(name, "header."):
+			return r.Header.Get(strings.TrimPrefix(name, "header."))
+		default:
+			return match
+		}
+	})
+}
+
+func intParam(raw interface{}, def int) (int, error) {
+	switch v := raw.(type) {
+	case nil:
+		return def, nil
+	case int:
+		return v, nil
+	case float64:
+		return int(v), nil
+	case string:
+		return strconv.Atoi(v)
+	default:
+		return 0, fmt.Errorf("应为整数")
+	}
+}
@@ -0,0 +1,158 @@
+// file: internal/plugin/mtls/plugin.go
+package mtls
+
+import (
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"gateway.example/go-gateway/internal/config"
+	"gateway.example/go-gateway/internal/plugin"
+	"gateway.example/go-gateway/pkg/logger"
+)
+
+const PluginName = "mtls"
+
+// Plugin 按客户端证书识别调用方，作为内部调用方在 Bearer Token 之外的另一种认证方式。
+// 网关需以 server.client_auth: optional 或 require 监听，证书链已在 TLS 握手时按 client_ca_file 校验，
+// 插件只负责把证书的 SAN/CN 映射为调用方身份并写入请求上下文 (供限流、配额等插件按调用方区分)。
+// 配置示例:
+//
+//	plugins:
+//	  - name: "mtls"
+//	    identities:                              # 证书名称 -> 调用方身份，未配置时直接使用证书名称
+//	      "spiffe://cluster.local/ns/orders/sa/default": "order-service"
+//	      "*.billing.internal": "billing"        # 通配符匹配一级 DNS 子域名
+//	    identity_header: "X-Client-Identity"     # 可选，把身份转发给上游
+//
+// 证书名称按 URI SAN、DNS SAN、Email SAN、CN 的顺序匹配，取第一个匹配的名称。
+type Plugin struct {
+	log logger.Logger
+}
+
+// options 是插件配置解析后的参数
+type options struct {
+	identities     map[string]string
+	identityHeader string
+}
+
+func NewPlugin(log logger.Logger) *Plugin {
+	return &Plugin{log: log}
+}
+
+func (p *Plugin) Name() string {
+	return PluginName
+}
+
+func (p *Plugin) ValidateConfig(params config.PluginSpec) error {
+	_, err := parseOptions(params)
+	return err
+}
+
+func (p *Plugin) Execute(w http.ResponseWriter, r *http.Request, params config.PluginSpec) (bool, error) {
+	ctx := r.Context()
+
+	opts, err := parseOptions(params)
+	if err != nil {
+		p.log.Error(ctx, fmt.Sprintf("[插件: %s] 配置错误: %v", p.Name(), err))
+		http.Error(w, "内部服务器错误: 插件配置错误", http.StatusInternalServerError)
+		return false, fmt.Errorf("插件 '%s' 配置错误: %w", p.Name(), err)
+	}
+	if opts.identityHeader != "" {
+		r.Header.Del(opts.identityHeader)
+	}
+
+	// 只接受握手时已通过 CA 校验的证书
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		p.log.Info(ctx, fmt.Sprintf("[插件: %s] 未授权: 缺少已校验的客户端证书", p.Name()), "remote_addr", r.RemoteAddr)
+		plugin.Block(w, http.StatusUnauthorized, "client_certificate_required", "需要有效的客户端证书")
+		return false, nil
+	}
+	cert := r.TLS.VerifiedChains[0][0]
+
+	identity, ok := opts.identify(cert)
+	if !ok {
+		p.log.Info(ctx, fmt.Sprintf("[插件: %s] 禁止访问: 客户端证书未映射到调用方身份", p.Name()),
+			"names", strings.Join(certNames(cert), ","), "remote_addr", r.RemoteAddr)
+		plugin.Block(w, http.StatusForbidden, "unknown_client_certificate", "客户端证书不允许访问该路由")
+		return false, nil
+	}
+
+	p.log.Debug(ctx, fmt.Sprintf("[插件: %s] 客户端证书认证通过", p.Name()), "identity", identity)
+	plugin.SetSubject(r, identity)
+	if opts.identityHeader != "" {
+		r.Header.Set(opts.identityHeader, identity)
+	}
+	return true, nil
+}
+
+// identify 返回证书对应的调用方身份。未配置 identities 时使用证书的第一个名称
+func (o *options) identify(cert *x509.Certificate) (string, bool) {
+	names := certNames(cert)
+	if len(o.identities) == 0 {
+		if len(names) == 0 {
+			return "", false
+		}
+		return names[0], true
+	}
+	for _, name := range names {
+		if identity, ok := o.identities[name]; ok {
+			return identity, true
+		}
+		// 通配符只用于主机名形式的名称，不匹配 URI 与 Email
+		if _, parent, found := strings.Cut(name, "."); found && !strings.ContainsAny(name, ":/@") {
+			if identity, ok := o.identities["*."+parent]; ok {
+				return identity, true
+			}
+		}
+	}
+	return "", false
+}
+
+// certNames 按 URI SAN、DNS SAN、Email SAN、CN 的顺序返回证书中的名称
+func certNames(cert *x509.Certificate) []string {
+	var names []string
+	for _, u := range cert.URIs {
+		names = append(names, u.String())
+	}
+	names = append(names, cert.DNSNames...)
+	names = append(names, cert.EmailAddresses...)
+	if cert.Subject.CommonName != "" {
+		names = append(names, cert.Subject.CommonName)
+	}
+	return names
+}
+
+func parseOptions(params config.PluginSpec) (*options, error) {
+	o := &options{}
+	if v, ok := params["identity_header"]; ok && v != nil {
+		if o.identityHeader, ok = v.(string); !ok {
+			return nil, fmt.Errorf("配置 'identity_header' 应为字符串")
+		}
+	}
+
+	var mapping map[string]interface{}
+	switch m := params["identities"].(type) {
+	case nil:
+		return o, nil
+	case map[string]interface{}:
+		mapping = m
+	case map[interface{}]interface{}:
+		mapping = make(map[string]interface{}, len(m))
+		for k, v := range m {
+			mapping[fmt.Sprint(k)] = v
+		}
+	default:
+		return nil, fmt.Errorf("配置 'identities' 应为证书名称到身份的映射")
+	}
+	o.identities = make(map[string]string, len(mapping))
+	for name, v := range mapping {
+		identity, isString := v.(string)
+		if name == "" || !isString || identity == "" {
+			return nil, fmt.Errorf("配置 'identities' 中 '%s' 的身份应为非空字符串", name)
+		}
+		o.identities[name] = identity
+	}
+	return o, nil
+}
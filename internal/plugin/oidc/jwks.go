@@ -0,0 +1,225 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// minRefreshInterval 遇到未知 kid 时强制刷新 JWKS 的最小间隔，防止伪造 kid 的请求打爆身份提供方
+const minRefreshInterval = 30 * time.Second
+
+const maxDocumentSize = 1 << 20
+
+// errProviderUnavailable 无法获取发现文档或 JWKS
+var errProviderUnavailable = errors.New("身份提供方不可用")
+
+// provider 缓存一个 issuer 的 JWKS 地址与公钥
+type provider struct {
+	issuer  string
+	jwksURI string // 为空时通过发现文档获取
+	client  *http.Client
+
+	mu          sync.Mutex
+	keys        map[string]crypto.PublicKey // kid -> 公钥
+	fetchedAt   time.Time
+	lastAttempt time.Time
+	lastErr     error
+}
+
+// key 返回 kid 对应的公钥。缓存过期或 kid 未知时刷新 JWKS (身份提供方轮换密钥后即可识别新 kid)；
+// 刷新失败但缓存中仍有该 kid 时继续使用旧公钥。
+func (p *provider) key(ctx context.Context, kid string, ttl time.Duration) (crypto.PublicKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	k, known := p.lookup(kid)
+	if known && now.Sub(p.fetchedAt) < ttl {
+		return k, nil
+	}
+	// 距上次刷新不久时不再请求身份提供方，沿用上次的结果
+	if now.Sub(p.lastAttempt) < minRefreshInterval {
+		switch {
+		case known:
+			return k, nil
+		case p.lastErr != nil:
+			return nil, p.lastErr
+		default:
+			return nil, fmt.Errorf("未知的签名密钥 kid '%s'", kid)
+		}
+	}
+
+	p.lastAttempt = now
+	if err := p.refresh(ctx); err != nil {
+		p.lastErr = fmt.Errorf("%w: %v", errProviderUnavailable, err)
+		if known {
+			return k, nil
+		}
+		return nil, p.lastErr
+	}
+	p.lastErr = nil
+	p.fetchedAt = now
+
+	if k, known = p.lookup(kid); !known {
+		return nil, fmt.Errorf("未知的签名密钥 kid '%s'", kid)
+	}
+	return k, nil
+}
+
+// lookup 按 kid 查找公钥；Token 未携带 kid 且 JWKS 只有一个密钥时使用该密钥
+func (p *provider) lookup(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, k := range p.keys {
+			return k, true
+		}
+	}
+	k, ok := p.keys[kid]
+	return k, ok
+}
+
+func (p *provider) refresh(ctx context.Context) error {
+	if p.jwksURI == "" {
+		var doc struct {
+			Issuer  string `json:"issuer"`
+			JWKSURI string `json:"jwks_uri"`
+		}
+		if err := p.getJSON(ctx, strings.TrimSuffix(p.issuer, "/")+"/.well-known/openid-configuration", &doc); err != nil {
+			return fmt.Errorf("获取发现文档失败: %w", err)
+		}
+		if doc.Issuer != p.issuer {
+			return fmt.Errorf("发现文档中的 issuer '%s' 与配置不一致", doc.Issuer)
+		}
+		if doc.JWKSURI == "" {
+			return fmt.Errorf("发现文档缺少 jwks_uri")
+		}
+		p.jwksURI = doc.JWKSURI
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := p.getJSON(ctx, p.jwksURI, &set); err != nil {
+		return fmt.Errorf("获取 JWKS 失败: %w", err)
+	}
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		pub, err := k.publicKey()
+		if err != nil {
+			// 不支持的密钥类型不影响其余密钥
+			continue
+		}
+		keys[k.Kid] = pub
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("JWKS 中没有可用的签名密钥")
+	}
+	p.keys = keys
+	return nil
+}
+
+func (p *provider) getJSON(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s 返回状态码 %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, maxDocumentSize)).Decode(v)
+}
+
+// jwk 是 RFC 7517 中的单个公钥，只解析验签需要的字段
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k *jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("RSA 指数无效")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("不支持的曲线 '%s'", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("EC 公钥不在曲线上")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("不支持的曲线 '%s'", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("Ed25519 公钥无效")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("不支持的密钥类型 '%s'", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, fmt.Errorf("无效的 base64url 整数")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
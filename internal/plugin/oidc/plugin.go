@@ -0,0 +1,308 @@
+// file: internal/plugin/oidc/plugin.go
+package oidc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gateway.example/go-gateway/internal/config"
+	"gateway.example/go-gateway/internal/plugin"
+	"gateway.example/go-gateway/pkg/logger"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const PluginName = "oidc"
+
+// 默认参数
+const (
+	defaultJWKSCacheTTL = 10 * time.Minute
+	fetchTimeout        = 5 * time.Second
+)
+
+// defaultAlgorithms 默认接受的非对称签名算法；身份提供方不会使用 HMAC 签发供第三方校验的 Token
+var defaultAlgorithms = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// defaultClaimHeaders 未配置 claims_to_headers 时透传给上游的 claim
+var defaultClaimHeaders = map[string]string{"sub": "X-User-ID"}
+
+// Plugin 直接校验外部身份提供方 (OIDC) 签发的 Token，作为内部认证服务之外的另一种认证方式。
+// 公钥通过 issuer 的发现文档找到 JWKS 地址后获取并缓存，遇到未知 kid 时自动刷新。
+// 配置示例:
+//
+//	plugins:
+//	  - name: "oidc"
+//	    issuer: "https://accounts.example.com"
+//	    audience: [ "gateway", "service-a" ]   # 任意一个匹配即可
+//	    jwks_uri: ""                # 可选，跳过发现文档直接使用
+//	    required_scopes: [ "orders:read" ]
+//	    claims_to_headers: { sub: "X-User-ID", email: "X-User-Email" }
+//	    tier_claim: "plan"          # 可选，把该 claim 记录为套餐等级，供限流插件的 tiers 使用
+//	    strip_authorization: false
+//	    jwks_cache_ttl: "10m"
+//	    leeway: "30s"
+type Plugin struct {
+	client *http.Client
+	log    logger.Logger
+
+	mu        sync.Mutex
+	providers map[string]*provider // issuer + jwks_uri -> provider
+}
+
+// options 是插件配置解析后的参数
+type options struct {
+	issuer             string
+	jwksURI            string
+	audiences          []string
+	algorithms         []string
+	requiredScopes     []string
+	claimHeaders       map[string]string
+	tierClaim          string
+	stripAuthorization bool
+	cacheTTL           time.Duration
+	leeway             time.Duration
+}
+
+func NewPlugin(log logger.Logger) *Plugin {
+	return &Plugin{
+		client:    &http.Client{Timeout: fetchTimeout},
+		log:       log,
+		providers: make(map[string]*provider),
+	}
+}
+
+func (p *Plugin) Name() string {
+	return PluginName
+}
+
+func (p *Plugin) ValidateConfig(params config.PluginSpec) error {
+	_, err := parseOptions(params)
+	return err
+}
+
+func (p *Plugin) Execute(w http.ResponseWriter, r *http.Request, params config.PluginSpec) (bool, error) {
+	ctx := r.Context()
+
+	opts, err := parseOptions(params)
+	if err != nil {
+		p.log.Error(ctx, fmt.Sprintf("[插件: %s] 配置错误: %v", p.Name(), err))
+		http.Error(w, "内部服务器错误: 插件配置错误", http.StatusInternalServerError)
+		return false, fmt.Errorf("插件 '%s' 配置错误: %w", p.Name(), err)
+	}
+
+	// 先删除客户端自带的同名请求头，防止伪造用户身份
+	for _, header := range opts.claimHeaders {
+		r.Header.Del(header)
+	}
+
+	tokenString, ok := bearerToken(r)
+	if !ok {
+		p.log.Info(ctx, fmt.Sprintf("[插件: %s] 未授权: 缺少 Bearer Token", p.Name()))
+		w.Header().Set("WWW-Authenticate", `Bearer realm="gateway"`)
+		plugin.Block(w, http.StatusUnauthorized, "missing_token", "缺少 Bearer Token")
+		return false, nil
+	}
+
+	prov := p.provider(opts)
+	// 拉取公钥不应随客户端断开而中止，否则失败结果会影响后续请求
+	fetchCtx := context.WithoutCancel(ctx)
+	claims := jwt.MapClaims{}
+	parserOpts := []jwt.ParserOption{
+		jwt.WithValidMethods(opts.algorithms),
+		jwt.WithIssuer(opts.issuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(opts.leeway),
+	}
+	if len(opts.audiences) > 0 {
+		parserOpts = append(parserOpts, jwt.WithAudience(opts.audiences...))
+	}
+	_, err = jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return prov.key(fetchCtx, kid, opts.cacheTTL)
+	}, parserOpts...)
+	if errors.Is(err, errProviderUnavailable) {
+		p.log.Error(ctx, fmt.Sprintf("[插件: %s] 无法获取身份提供方公钥: %v", p.Name(), err), "issuer", opts.issuer)
+		http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+		return false, err
+	}
+	if err != nil {
+		p.log.Info(ctx, fmt.Sprintf("[插件: %s] 未授权: Token 校验失败: %v", p.Name(), err), "issuer", opts.issuer)
+		w.Header().Set("WWW-Authenticate", `Bearer realm="gateway", error="invalid_token"`)
+		plugin.Block(w, http.StatusUnauthorized, "invalid_token", "Token 无效或已过期")
+		return false, nil
+	}
+
+	if missing := missingScopes(claims, opts.requiredScopes); len(missing) > 0 {
+		p.log.Info(ctx, fmt.Sprintf("[插件: %s] 禁止访问: 缺少 scope %v", p.Name(), missing))
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="gateway", error="insufficient_scope", scope="%s"`, strings.Join(opts.requiredScopes, " ")))
+		plugin.Block(w, http.StatusForbidden, "insufficient_scope", "Token 缺少所需的 scope")
+		return false, nil
+	}
+
+	if sub, _ := claims["sub"].(string); sub != "" {
+		plugin.SetSubject(r, sub)
+	}
+	if opts.tierClaim != "" {
+		if tier := claimString(claims[opts.tierClaim]); tier != "" {
+			plugin.SetTier(r, tier)
+		}
+	}
+	for claim, header := range opts.claimHeaders {
+		// 含控制字符的值无法作为请求头转发，直接跳过
+		if s := claimString(claims[claim]); s != "" && !strings.ContainsAny(s, "\r\n\x00") {
+			r.Header.Set(header, s)
+		}
+	}
+	if opts.stripAuthorization {
+		r.Header.Del("Authorization")
+	}
+	return true, nil
+}
+
+// provider 返回 issuer 对应的缓存，不同路由共用同一个 issuer 时共享公钥
+func (p *Plugin) provider(opts *options) *provider {
+	key := opts.issuer + "|" + opts.jwksURI
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	prov, ok := p.providers[key]
+	if !ok {
+		prov = &provider{issuer: opts.issuer, jwksURI: opts.jwksURI, client: p.client}
+		p.providers[key] = prov
+	}
+	return prov
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "bearer") || token == "" {
+		return "", false
+	}
+	return token, true
+}
+
+// missingScopes 返回 Token 中缺少的 scope，兼容空格分隔的 scope 与数组形式的 scp
+func missingScopes(claims jwt.MapClaims, required []string) []string {
+	if len(required) == 0 {
+		return nil
+	}
+	var granted []string
+	if s, ok := claims["scope"].(string); ok {
+		granted = strings.Fields(s)
+	}
+	switch scp := claims["scp"].(type) {
+	case string:
+		granted = append(granted, strings.Fields(scp)...)
+	case []interface{}:
+		for _, item := range scp {
+			if s, ok := item.(string); ok {
+				granted = append(granted, s)
+			}
+		}
+	}
+	var missing []string
+	for _, s := range required {
+		if !slices.Contains(granted, s) {
+			missing = append(missing, s)
+		}
+	}
+	return missing
+}
+
+// claimString 将 claim 值转换为头部可用的字符串，数组以逗号连接
+func claimString(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case []interface{}:
+		items := make([]string, 0, len(val))
+		for _, item := range val {
+			items = append(items, claimString(item))
+		}
+		return strings.Join(items, ",")
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(val)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+func parseOptions(params config.PluginSpec) (*options, error) {
+	o := &options{
+		algorithms:   defaultAlgorithms,
+		claimHeaders: defaultClaimHeaders,
+		cacheTTL:     defaultJWKSCacheTTL,
+	}
+
+	o.issuer, _ = params["issuer"].(string)
+	if o.issuer == "" {
+		return nil, fmt.Errorf("缺少 'issuer' 配置")
+	}
+	if v, ok := params["jwks_uri"]; ok && v != nil {
+		if o.jwksURI, ok = v.(string); !ok {
+			return nil, fmt.Errorf("配置 'jwks_uri' 应为字符串")
+		}
+	}
+
+	var err error
+	if aud, ok := params["audience"].(string); ok {
+		o.audiences = []string{aud}
+	} else if o.audiences, err = plugin.StringList(params, "audience"); err != nil {
+		return nil, err
+	}
+	if algs, err := plugin.StringList(params, "algorithms"); err != nil {
+		return nil, err
+	} else if len(algs) > 0 {
+		for _, alg := range algs {
+			if !slices.Contains(defaultAlgorithms, alg) {
+				return nil, fmt.Errorf("不支持的签名算法 '%s'", alg)
+			}
+		}
+		o.algorithms = algs
+	}
+	if o.requiredScopes, err = plugin.StringList(params, "required_scopes"); err != nil {
+		return nil, err
+	}
+
+	if raw, ok := params["claims_to_headers"]; ok && raw != nil {
+		mapping, isMap := raw.(map[interface{}]interface{})
+		if !isMap {
+			return nil, fmt.Errorf("配置 'claims_to_headers' 类型不正确")
+		}
+		o.claimHeaders = make(map[string]string, len(mapping))
+		for claim, header := range mapping {
+			o.claimHeaders[fmt.Sprint(claim)] = fmt.Sprint(header)
+		}
+	}
+	if v, ok := params["tier_claim"]; ok && v != nil {
+		if o.tierClaim, ok = v.(string); !ok || o.tierClaim == "" {
+			return nil, fmt.Errorf("配置 'tier_claim' 应为非空字符串")
+		}
+	}
+	if v, ok := params["strip_authorization"]; ok && v != nil {
+		b, isBool := v.(bool)
+		if !isBool {
+			return nil, fmt.Errorf("配置 'strip_authorization' 应为布尔值")
+		}
+		o.stripAuthorization = b
+	}
+
+	durations := map[string]*time.Duration{"jwks_cache_ttl": &o.cacheTTL, "leeway": &o.leeway}
+	for key, target := range durations {
+		d, err := plugin.Duration(params, key, *target)
+		if err != nil {
+			return nil, err
+		}
+		*target = d
+	}
+	return o, nil
+}
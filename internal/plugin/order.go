@@ -0,0 +1,32 @@
+package plugin
+
+import (
+	"sort"
+
+	"gateway.example/go-gateway/internal/config"
+)
+
+// Priority 返回插件配置中的 priority 字段，未配置或类型不正确时为 0
+func Priority(spec config.PluginSpec) int {
+	switch v := spec["priority"].(type) {
+	case int:
+		return v
+	case float64:
+		return int(v)
+	default:
+		return 0
+	}
+}
+
+// MergeChains 按顺序拼接多条插件链，再按 priority 从高到低稳定排序
+// 优先级相同的插件保持拼接后的相对顺序，因此先传入的链 (如全局插件) 先执行
+func MergeChains(chains ...[]config.PluginSpec) []config.PluginSpec {
+	var merged []config.PluginSpec
+	for _, chain := range chains {
+		merged = append(merged, chain...)
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		return Priority(merged[i]) > Priority(merged[j])
+	})
+	return merged
+}
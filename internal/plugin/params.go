@@ -0,0 +1,81 @@
+package plugin
+
+import (
+	"fmt"
+	"time"
+
+	"gateway.example/go-gateway/internal/config"
+)
+
+// StringList 读取插件配置中的字符串列表参数，未配置时返回 nil
+func StringList(params config.PluginSpec, key string) ([]string, error) {
+	switch l := params[key].(type) {
+	case nil:
+		return nil, nil
+	case []string:
+		return append([]string(nil), l...), nil
+	case []interface{}:
+		out := make([]string, 0, len(l))
+		for _, v := range l {
+			s, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("配置 '%s' 应为字符串列表", key)
+			}
+			out = append(out, s)
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("配置 '%s' 应为字符串列表", key)
+	}
+}
+
+// StringMap 读取插件配置中的映射参数，值按字符串处理 (如 YAML 中的数字 2 读作 "2")，未配置时返回 nil
+func StringMap(params config.PluginSpec, key string) (map[string]string, error) {
+	switch m := params[key].(type) {
+	case nil:
+		return nil, nil
+	case map[string]string:
+		out := make(map[string]string, len(m))
+		for k, v := range m {
+			out[k] = v
+		}
+		return out, nil
+	case map[string]interface{}:
+		out := make(map[string]string, len(m))
+		for k, v := range m {
+			out[k] = fmt.Sprint(v)
+		}
+		return out, nil
+	case map[interface{}]interface{}:
+		out := make(map[string]string, len(m))
+		for k, v := range m {
+			out[fmt.Sprint(k)] = fmt.Sprint(v)
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("配置 '%s' 应为映射类型", key)
+	}
+}
+
+// Duration 读取插件配置中的时长参数，未配置时返回 def。
+// 取值必须是带单位的字符串 (如 500ms、30s、5m)，不接受不带单位的数字与负数
+func Duration(params config.PluginSpec, key string, def time.Duration) (time.Duration, error) {
+	v, ok := params[key]
+	if !ok || v == nil {
+		return def, nil
+	}
+	return DurationValue(key, v)
+}
+
+// DurationValue 按 Duration 的规则解析嵌套配置中的时长 (如 delay.duration)，key 只用于错误信息
+func DurationValue(key string, v interface{}) (time.Duration, error) {
+	s, ok := v.(string)
+	if !ok {
+		return 0, fmt.Errorf("配置 '%s' 应为带单位的时长，如 30s、5m: %v", key, v)
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("配置 '%s' 不是有效的时长: %v", key, v)
+	}
+	return d, nil
+}
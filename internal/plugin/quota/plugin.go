@@ -0,0 +1,149 @@
+// file: internal/plugin/quota/plugin.go
+package quota
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gateway.example/go-gateway/internal/config"
+	"gateway.example/go-gateway/internal/plugin"
+	svc_quota "gateway.example/go-gateway/internal/service/quota"
+	"gateway.example/go-gateway/pkg/logger"
+)
+
+const (
+	PluginName          = "quota"
+	DefaultAPIKeyHeader = "X-API-Key"
+)
+
+// 响应中返回给客户端的配额信息
+const (
+	HeaderQuotaLimit     = "X-Quota-Limit"
+	HeaderQuotaRemaining = "X-Quota-Remaining"
+	HeaderQuotaReset     = "X-Quota-Reset" // 周期结束的 Unix 时间戳 (秒)
+)
+
+// bySources 支持的配额计数对象
+//   - user:    认证插件 (auth / oidc / basicauth) 校验通过的主体，需排在认证插件之后
+//   - api_key: api_key_header 请求头中的 API Key
+//   - ip:      客户端 IP
+var bySources = []string{"user", "api_key", "ip"}
+
+// Plugin 按 quota.rules 中定义的规则累计每个用户或 API Key 在当天/当月的请求数，超出后拒绝请求。
+// 无法识别用户或 API Key 的请求按客户端 IP 计数。配置示例:
+//
+//	plugins:
+//	  - name: "quota"
+//	    rule: "free-tier-daily"
+//	    by: "api_key"                  # user / api_key / ip，默认 user
+//	    api_key_header: "X-API-Key"
+type Plugin struct {
+	quotaSvc svc_quota.Service
+	log      logger.Logger
+}
+
+// options 是插件配置解析后的参数
+type options struct {
+	rule         string
+	by           string
+	apiKeyHeader string
+}
+
+// NewPlugin 创建一个新的配额插件实例
+func NewPlugin(svc svc_quota.Service, log logger.Logger) *Plugin {
+	if svc == nil {
+		log.Fatal(context.Background(), fmt.Sprintf("[插件 %s] 致命错误: quota.Service 依赖注入失败，为 nil", PluginName))
+	}
+	return &Plugin{quotaSvc: svc, log: log}
+}
+
+func (p *Plugin) Name() string {
+	return PluginName
+}
+
+func (p *Plugin) Execute(w http.ResponseWriter, r *http.Request, params config.PluginSpec) (bool, error) {
+	ctx := r.Context()
+
+	opts, err := parseOptions(params)
+	if err != nil {
+		p.log.Error(ctx, fmt.Sprintf("[插件: %s] 配置错误: %v", p.Name(), err))
+		http.Error(w, "内部服务器错误: 插件配置错误", http.StatusInternalServerError)
+		return false, fmt.Errorf("插件 '%s' 配置错误: %w", p.Name(), err)
+	}
+
+	identifier := identify(r, opts)
+	usage, allowed, err := p.quotaSvc.Consume(ctx, opts.rule, identifier)
+	if err != nil {
+		// 配额属于计费类限制，计数后端故障时放行，避免影响正常业务
+		p.log.Error(ctx, "[插件] 配额计数失败，放行请求", "plugin", p.Name(), "rule", opts.rule, "error", err)
+		return true, nil
+	}
+
+	w.Header().Set(HeaderQuotaLimit, strconv.FormatInt(usage.Limit, 10))
+	w.Header().Set(HeaderQuotaRemaining, strconv.FormatInt(usage.Remaining, 10))
+	w.Header().Set(HeaderQuotaReset, strconv.FormatInt(usage.ResetAt.Unix(), 10))
+
+	if !allowed {
+		p.log.Info(ctx, "[插件] 配额已用尽", "plugin", p.Name(), "rule", opts.rule, "identifier", identifier, "period", usage.Period)
+		retryAfter := int(time.Until(usage.ResetAt).Seconds()) + 1
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+		plugin.Block(w, http.StatusTooManyRequests, "quota_exceeded", "已超出配额")
+		return false, nil
+	}
+	return true, nil
+}
+
+// ValidateConfig 校验 rule 已在 quota.rules 中定义且 by 受支持
+func (p *Plugin) ValidateConfig(params config.PluginSpec) error {
+	opts, err := parseOptions(params)
+	if err != nil {
+		return err
+	}
+	if !p.quotaSvc.HasRule(opts.rule) {
+		return fmt.Errorf("配额规则 '%s' 未定义", opts.rule)
+	}
+	return nil
+}
+
+func parseOptions(params config.PluginSpec) (*options, error) {
+	opts := &options{by: "user", apiKeyHeader: DefaultAPIKeyHeader}
+
+	var ok bool
+	opts.rule, ok = params["rule"].(string)
+	if !ok || opts.rule == "" {
+		return nil, fmt.Errorf("配置 'rule' 缺失或类型不正确")
+	}
+	if v, exists := params["by"]; exists && v != nil {
+		opts.by, ok = v.(string)
+		if !ok || !slices.Contains(bySources, opts.by) {
+			return nil, fmt.Errorf("配置 'by' 应为 %s 之一", strings.Join(bySources, ", "))
+		}
+	}
+	if v, exists := params["api_key_header"]; exists && v != nil {
+		opts.apiKeyHeader, ok = v.(string)
+		if !ok || opts.apiKeyHeader == "" {
+			return nil, fmt.Errorf("配置 'api_key_header' 应为非空字符串")
+		}
+	}
+	return opts, nil
+}
+
+// identify 返回配额计数的标识，带有 "user:" / "key:" / "ip:" 前缀，可直接用于管理接口查询
+func identify(r *http.Request, opts *options) string {
+	switch opts.by {
+	case "user":
+		if sub, ok := plugin.Subject(r); ok {
+			return "user:" + sub
+		}
+	case "api_key":
+		if key := r.Header.Get(opts.apiKeyHeader); key != "" {
+			return svc_quota.APIKeyIdentifier(key)
+		}
+	}
+	return "ip:" + plugin.ClientIP(r)
+}
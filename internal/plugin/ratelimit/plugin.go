@@ -3,22 +3,68 @@ package ratelimit
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
-	"net"
+	"math"
 	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 
 	"gateway.example/go-gateway/internal/config"
+	"gateway.example/go-gateway/internal/plugin"
 	svc_ratelimit "gateway.example/go-gateway/internal/service/ratelimit"
 	"gateway.example/go-gateway/pkg/logger"
 )
 
 const (
 	PluginName          = "ratelimit"
-	HeaderXForwardedFor = "X-Forwarded-For"
-	HeaderXRealIP       = "X-Real-IP"
+	DefaultAPIKeyHeader = "X-API-Key"
 )
 
+// 响应中返回给客户端的限流信息，限流器无法给出上限 (如并发、漏桶规则) 时不设置
+const (
+	HeaderRateLimitLimit     = "X-RateLimit-Limit"
+	HeaderRateLimitRemaining = "X-RateLimit-Remaining"
+)
+
+// strategies 支持的限流策略
+//   - ip:      按客户端 IP
+//   - path:    按请求路径
+//   - jwt_sub: 按认证插件 (auth / oidc / basicauth) 校验通过的主体，需排在认证插件之后；未认证的请求按 IP
+//   - api_key: 按 api_key_header 请求头中的 API Key (默认 X-API-Key)；未携带时按 IP
+//   - global:  所有请求共用一个标识，常与 concurrency 规则搭配，限制路由或服务的总并发
+var strategies = []string{"ip", "path", "jwt_sub", "api_key", "global"}
+
+// keyPattern 匹配组合标识模板中的变量，例如 {ip}、{header.X-Tenant}
+var keyPattern = regexp.MustCompile(`\{([a-z_]+(?:\.[A-Za-z0-9_\-]+)?)\}`)
+
+// keyVars 组合标识模板支持的变量；{user} 为认证主体，未认证时为客户端 IP，{api_key} 为 API Key 的摘要
+var keyVars = []string{"ip", "path", "method", "host", "user", "api_key"}
+
+// compositeParts 组合策略 (如 "ip+path"、"user+method") 中各部分对应的模板变量
+var compositeParts = map[string]string{
+	"ip":      "{ip}",
+	"path":    "{path}",
+	"method":  "{method}",
+	"host":    "{host}",
+	"user":    "{user}",
+	"jwt_sub": "{user}",
+	"api_key": "{api_key}",
+}
+
+// options 是插件配置解析后的参数
+type options struct {
+	rule         string
+	strategy     string
+	key          string // 组合标识模板，非空时优先于 strategy
+	apiKeyHeader string
+	// 套餐等级 -> 规则名。认证插件记录了等级 (tier_claim) 且等级在此列出时使用对应规则，否则使用 rule
+	tiers map[string]string
+}
+
 // Plugin 实现了 plugin.Interface 接口
 type Plugin struct {
 	rateLimitSvc svc_ratelimit.Service
@@ -46,91 +92,243 @@ func (p *Plugin) Execute(w http.ResponseWriter, r *http.Request, pluginCfg confi
 	ctx := r.Context()
 
 	// 1. 解析插件配置
-	ruleName, strategy, err := p.parseConfig(pluginCfg)
+	opts, err := parseConfig(pluginCfg)
 	if err != nil {
 		http.Error(w, "限流插件配置错误", http.StatusInternalServerError)
 		return false, fmt.Errorf("[插件 %s] %w", p.Name(), err)
 	}
 
-	// 2. 根据策略提取标识符
-	identifier := p.getIdentifier(r, strategy)
+	// 2. 按认证插件记录的套餐等级选择规则
+	ruleName := opts.rule
+	if tier, ok := plugin.Tier(r); ok {
+		if rule, listed := opts.tiers[tier]; listed {
+			ruleName = rule
+		}
+	}
+
+	// 3. 免限流名单中的请求 (健康探针、内部服务等) 不经过限流器
+	if bypass := p.rateLimitSvc.Bypass(ruleName); bypass != nil && bypass.Match(plugin.ClientIP(r), r.Header, opts.apiKeyHeader) {
+		p.log.Debug(ctx, fmt.Sprintf("[插件 %s] 请求命中免限流名单", p.Name()),
+			"plugin", p.Name(),
+			"rule", ruleName,
+			"action", "bypassed")
+		return true, nil
+	}
+
+	// 4. 根据策略提取标识符
+	identifier := p.getIdentifier(r, opts)
 	if identifier == "" {
 		p.log.Warn(ctx, "[插件 %s] 警告: 未能根据策略 '%s' 找到有效的请求标识符",
-			p.Name(), strategy,
+			p.Name(), opts.strategy,
 			"plugin", p.Name(),
-			"strategy", strategy)
+			"strategy", opts.strategy)
 		// 如果无法识别，可以选择放行或拒绝，这里选择放行并记录日志
 		return true, nil
 	}
 
-	// 3. 使用新的 Service 接口进行限流检查
-	allowed, err := p.rateLimitSvc.CheckLimit(ctx, ruleName, identifier)
+	// 5. 使用新的 Service 接口进行限流检查，并发限流规则会占用名额直到请求结束
+	decision, err := p.rateLimitSvc.Allow(ctx, ruleName, identifier)
 	if err != nil {
 		http.Error(w, "限流服务内部错误", http.StatusInternalServerError)
 		return false, fmt.Errorf("[插件 %s] 调用限流服务失败: %w", p.Name(), err)
 	}
+	if decision.Limit > 0 {
+		w.Header().Set(HeaderRateLimitLimit, strconv.Itoa(decision.Limit))
+		w.Header().Set(HeaderRateLimitRemaining, strconv.Itoa(decision.Remaining))
+	}
 
-	if !allowed {
+	if !decision.Allowed {
 		p.log.Info(ctx, "[插件 %s] 请求被拒绝: [规则: %s, 标识: %s]",
 			p.Name(), ruleName, identifier,
 			"plugin", p.Name(),
 			"rule", ruleName,
 			"identifier", identifier,
 			"action", "rejected")
-		http.Error(w, "请求过于频繁", http.StatusTooManyRequests)
+		if decision.RetryAfter > 0 {
+			// 向上取整到秒，避免客户端在额度恢复前重试
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(decision.RetryAfter.Seconds()))))
+		}
+		if ruleType, _ := p.rateLimitSvc.RuleType(ruleName); ruleType == "concurrency" {
+			// 并发名额用尽说明上游处理不过来，与客户端请求频率无关
+			plugin.Block(w, http.StatusServiceUnavailable, "concurrency_limited", "同时处理的请求过多，请稍后重试")
+			return false, nil
+		}
+		plugin.Block(w, http.StatusTooManyRequests, "rate_limited", "请求过于频繁")
 		return false, nil // 中断插件链
 	}
+	// 网关处理完请求 (含转发上游与写回响应) 后 context 结束，归还并发名额
+	if decision.Release != nil {
+		context.AfterFunc(ctx, decision.Release)
+	}
 
 	// 请求被允许，不打印日志，避免日志泛滥
 	return true, nil // 继续下一个插件
 }
 
-// parseConfig 从配置中解析出规则名称和策略
-func (p *Plugin) parseConfig(cfg config.PluginSpec) (string, string, error) {
-	rule, ok := cfg["rule"].(string)
-	if !ok || rule == "" {
-		return "", "", fmt.Errorf("配置 'rule' 缺失或类型不正确")
+// ValidateConfig 校验 rule 已在 rate_limiting.rules 中定义且 strategy 受支持
+func (p *Plugin) ValidateConfig(pluginCfg config.PluginSpec) error {
+	opts, err := parseConfig(pluginCfg)
+	if err != nil {
+		return err
 	}
-
-	strategy, ok := cfg["strategy"].(string)
-	if !ok || strategy == "" {
-		return "", "", fmt.Errorf("配置 'strategy' 缺失或类型不正确")
+	if opts.key != "" {
+		for _, m := range keyPattern.FindAllStringSubmatch(opts.key, -1) {
+			name := m[1]
+			if !slices.Contains(keyVars, name) && !strings.HasPrefix(name, "header.") && !strings.HasPrefix(name, "query.") {
+				return fmt.Errorf("限流标识模板中有未知变量 '%s'", m[0])
+			}
+		}
+	} else if !slices.Contains(strategies, opts.strategy) {
+		return fmt.Errorf("不支持的限流策略 '%s' (可选: %s)", opts.strategy, strings.Join(strategies, ", "))
 	}
+	if !p.rateLimitSvc.HasRule(opts.rule) {
+		return fmt.Errorf("限流规则 '%s' 未定义", opts.rule)
+	}
+	for tier, rule := range opts.tiers {
+		if !p.rateLimitSvc.HasRule(rule) {
+			return fmt.Errorf("等级 '%s' 使用的限流规则 '%s' 未定义", tier, rule)
+		}
+	}
+	return nil
+}
 
-	return rule, strategy, nil
+// ReferencedRules 返回插件配置引用的全部限流规则 (rule 与 tiers 中的规则)，配置无效时返回 nil
+func ReferencedRules(pluginCfg config.PluginSpec) []string {
+	opts, err := parseConfig(pluginCfg)
+	if err != nil {
+		return nil
+	}
+	rules := []string{opts.rule}
+	for _, rule := range opts.tiers {
+		rules = append(rules, rule)
+	}
+	return rules
 }
 
-// getIdentifier 根据策略从请求中获取唯一标识符
-func (p *Plugin) getIdentifier(r *http.Request, strategy string) string {
-	switch strategy {
-	case "ip":
-		// 遵循标准实践，优先 X-Forwarded-For
-		xff := r.Header.Get(HeaderXForwardedFor)
-		if xff != "" {
-			// XFF 可能包含多个 IP: "client, proxy1, proxy2"
-			// 第一个通常是真实客户端 IP
-			ips := strings.Split(xff, ",")
-			clientIP := strings.TrimSpace(ips[0])
-			return clientIP
+// parseConfig 从配置中解析出规则名称、策略等参数
+func parseConfig(cfg config.PluginSpec) (*options, error) {
+	opts := &options{apiKeyHeader: DefaultAPIKeyHeader}
+
+	var ok bool
+	opts.rule, ok = cfg["rule"].(string)
+	if !ok || opts.rule == "" {
+		return nil, fmt.Errorf("配置 'rule' 缺失或类型不正确")
+	}
+
+	if v, exists := cfg["key"]; exists && v != nil {
+		opts.key, ok = v.(string)
+		if !ok || opts.key == "" {
+			return nil, fmt.Errorf("配置 'key' 应为非空字符串")
 		}
+	}
+
+	opts.strategy, _ = cfg["strategy"].(string)
+	if opts.key == "" && opts.strategy == "" {
+		return nil, fmt.Errorf("配置 'strategy' 或 'key' 缺失或类型不正确")
+	}
+	// "ip+path" 这类组合策略等价于 key: "{ip}:{path}"
+	if opts.key == "" && strings.Contains(opts.strategy, "+") {
+		parts := strings.Split(opts.strategy, "+")
+		vars := make([]string, 0, len(parts))
+		for _, part := range parts {
+			v, known := compositeParts[strings.TrimSpace(part)]
+			if !known {
+				return nil, fmt.Errorf("组合限流策略 '%s' 中有不支持的部分 '%s'", opts.strategy, part)
+			}
+			vars = append(vars, v)
+		}
+		opts.key = strings.Join(vars, ":")
+	}
+
+	if v, exists := cfg["api_key_header"]; exists && v != nil {
+		opts.apiKeyHeader, ok = v.(string)
+		if !ok || opts.apiKeyHeader == "" {
+			return nil, fmt.Errorf("配置 'api_key_header' 应为非空字符串")
+		}
+	}
 
-		// 其次是 X-Real-IP
-		ip := r.Header.Get(HeaderXRealIP)
-		if ip != "" {
-			return ip
+	if raw, exists := cfg["tiers"]; exists && raw != nil {
+		mapping, isMap := raw.(map[interface{}]interface{})
+		if !isMap {
+			return nil, fmt.Errorf("配置 'tiers' 应为 等级: 规则名 的映射")
 		}
+		opts.tiers = make(map[string]string, len(mapping))
+		for tier, rule := range mapping {
+			name, isString := rule.(string)
+			if !isString || name == "" {
+				return nil, fmt.Errorf("等级 '%v' 的规则名应为非空字符串", tier)
+			}
+			opts.tiers[fmt.Sprint(tier)] = name
+		}
+	}
+
+	return opts, nil
+}
 
-		// 最后回退到 RemoteAddr，它可能是直接连接的客户端或上一级代理的 IP
-		// net.SplitHostPort 用于去除可能存在的端口号
-		host, _, err := net.SplitHostPort(r.RemoteAddr)
-		if err != nil {
-			// 如果没有端口号，直接返回
-			return r.RemoteAddr
+// getIdentifier 根据策略从请求中获取唯一标识符
+// 按账号限流的标识带有 "sub:" / "key:" 前缀，避免与 IP 共用同一规则时相互冲突
+func (p *Plugin) getIdentifier(r *http.Request, opts *options) string {
+	if opts.key != "" {
+		return p.expandKey(r, opts)
+	}
+	switch opts.strategy {
+	case "jwt_sub":
+		if sub, ok := plugin.Subject(r); ok {
+			return "sub:" + sub
 		}
-		return host
+		return plugin.ClientIP(r)
+	case "api_key":
+		if key := apiKeyDigest(r, opts); key != "" {
+			return "key:" + key
+		}
+		return plugin.ClientIP(r)
+	case "ip":
+		return plugin.ClientIP(r)
 	case "path":
 		return r.URL.Path
+	case "global":
+		return "global"
 	default:
 		return ""
 	}
 }
+
+// expandKey 展开组合标识模板，例如 "{ip}:{path}" -> "1.2.3.4:/orders"
+func (p *Plugin) expandKey(r *http.Request, opts *options) string {
+	return keyPattern.ReplaceAllStringFunc(opts.key, func(match string) string {
+		name := match[1 : len(match)-1]
+		switch {
+		case name == "ip":
+			return plugin.ClientIP(r)
+		case name == "path":
+			return r.URL.Path
+		case name == "method":
+			return r.Method
+		case name == "host":
+			return r.Host
+		case name == "user":
+			if sub, ok := plugin.Subject(r); ok {
+				return sub
+			}
+			return plugin.ClientIP(r)
+		case name == "api_key":
+			return apiKeyDigest(r, opts)
+		case strings.HasPrefix(name, "header."):
+			return r.Header.Get(strings.TrimPrefix(name, "header."))
+		case strings.HasPrefix(name, "query."):
+			return r.URL.Query().Get(strings.TrimPrefix(name, "query."))
+		default:
+			return match
+		}
+	})
+}
+
+// apiKeyDigest 返回 API Key 的摘要，只使用摘要以免 API Key 原文出现在日志与限流后端中
+func apiKeyDigest(r *http.Request, opts *options) string {
+	key := r.Header.Get(opts.apiKeyHeader)
+	if key == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:16])
+}
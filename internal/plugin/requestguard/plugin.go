@@ -0,0 +1,131 @@
+// file: internal/plugin/requestguard/plugin.go
+package requestguard
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"gateway.example/go-gateway/internal/config"
+	"gateway.example/go-gateway/internal/plugin"
+	"gateway.example/go-gateway/pkg/logger"
+)
+
+const PluginName = "request_guard"
+
+// Plugin 在请求转发给上游之前校验请求体大小、Content-Type 与必需的请求头。
+// 配置示例:
+//
+//	plugins:
+//	  - name: "request_guard"
+//	    max_body_size: 1048576                     # 超过返回 413
+//	    allowed_content_types: [ "application/json", "multipart/*" ]   # 不匹配返回 415
+//	    required_headers: [ "X-Tenant-ID" ]        # 缺失返回 400
+//
+// 未声明 Content-Length 的请求体在转发过程中超过上限时同样返回 413。
+type Plugin struct {
+	log logger.Logger
+}
+
+// rules 是插件配置解析后的校验规则
+type rules struct {
+	maxBodySize     int64
+	contentTypes    []string
+	requiredHeaders []string
+}
+
+func NewPlugin(log logger.Logger) *Plugin {
+	return &Plugin{log: log}
+}
+
+func (p *Plugin) Name() string {
+	return PluginName
+}
+
+func (p *Plugin) ValidateConfig(params config.PluginSpec) error {
+	_, err := parseRules(params)
+	return err
+}
+
+func (p *Plugin) Execute(w http.ResponseWriter, r *http.Request, params config.PluginSpec) (bool, error) {
+	ctx := r.Context()
+
+	rl, err := parseRules(params)
+	if err != nil {
+		p.log.Error(ctx, fmt.Sprintf("[插件: %s] 配置错误: %v", p.Name(), err))
+		http.Error(w, "内部服务器错误: 插件配置错误", http.StatusInternalServerError)
+		return false, fmt.Errorf("插件 '%s' 配置错误: %w", p.Name(), err)
+	}
+
+	for _, h := range rl.requiredHeaders {
+		if r.Header.Get(h) == "" {
+			p.log.Info(ctx, "[插件] 请求缺少必需的请求头", "plugin", p.Name(), "header", h, "path", r.URL.Path)
+			plugin.Block(w, http.StatusBadRequest, "missing_header", fmt.Sprintf("缺少必需的请求头 %s", h))
+			return false, nil
+		}
+	}
+
+	hasBody := r.ContentLength > 0 || (r.ContentLength < 0 && r.Body != nil && r.Body != http.NoBody)
+	if !hasBody {
+		return true, nil
+	}
+
+	if len(rl.contentTypes) > 0 && !rl.allowContentType(r.Header.Get("Content-Type")) {
+		p.log.Info(ctx, "[插件] 不支持的 Content-Type", "plugin", p.Name(), "content_type", r.Header.Get("Content-Type"), "path", r.URL.Path)
+		plugin.Block(w, http.StatusUnsupportedMediaType, "unsupported_media_type", "不支持的 Content-Type")
+		return false, nil
+	}
+
+	if rl.maxBodySize > 0 {
+		if r.ContentLength > rl.maxBodySize {
+			p.log.Info(ctx, "[插件] 请求体超过限制", "plugin", p.Name(), "content_length", r.ContentLength, "limit", rl.maxBodySize)
+			plugin.Block(w, http.StatusRequestEntityTooLarge, "body_too_large", "请求体过大")
+			return false, nil
+		}
+		// 长度未知的请求体在读取时限制，超限后由代理返回 413
+		if r.ContentLength < 0 {
+			r.Body = http.MaxBytesReader(w, r.Body, rl.maxBodySize)
+		}
+	}
+	return true, nil
+}
+
+// allowContentType 按媒体类型匹配，忽略参数 (如 charset)，支持 type/* 通配
+func (rl *rules) allowContentType(header string) bool {
+	mediaType, _, err := mime.ParseMediaType(header)
+	if err != nil {
+		return false
+	}
+	for _, allowed := range rl.contentTypes {
+		if allowed == mediaType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(allowed, "/*"); ok && strings.HasPrefix(mediaType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func parseRules(params config.PluginSpec) (*rules, error) {
+	rl := &rules{}
+	if v, ok := params["max_body_size"]; ok && v != nil {
+		size, err := config.ParseByteSizeValue(v)
+		if err != nil {
+			return nil, fmt.Errorf("配置 'max_body_size' 应为字节数或带单位的大小 (如 1MB): %w", err)
+		}
+		rl.maxBodySize = int64(size)
+	}
+	var err error
+	if rl.contentTypes, err = plugin.StringList(params, "allowed_content_types"); err != nil {
+		return nil, err
+	}
+	for i, ct := range rl.contentTypes {
+		rl.contentTypes[i] = strings.ToLower(strings.TrimSpace(ct))
+	}
+	if rl.requiredHeaders, err = plugin.StringList(params, "required_headers"); err != nil {
+		return nil, err
+	}
+	return rl, nil
+}
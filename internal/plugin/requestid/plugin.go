@@ -0,0 +1,108 @@
+package requestid
+
+import (
+	"fmt"
+	"net/http"
+
+	"gateway.example/go-gateway/internal/config"
+	"gateway.example/go-gateway/pkg/logger"
+	"github.com/google/uuid"
+)
+
+const PluginName = "request_id"
+
+// 默认请求头
+const (
+	DefaultHeader            = "X-Request-ID"
+	DefaultCorrelationHeader = "X-Correlation-ID"
+	maxIDLength              = 128
+)
+
+// Plugin 为每个请求生成或沿用请求 ID 与关联 ID，
+// 写入日志上下文、转发给上游并在响应中回显
+type Plugin struct {
+	log logger.Logger
+}
+
+func NewPlugin(log logger.Logger) *Plugin {
+	return &Plugin{log: log}
+}
+
+func (p *Plugin) Name() string {
+	return PluginName
+}
+
+// Execute 支持的配置:
+//
+//	header: 请求 ID 头，默认 X-Request-ID
+//	correlation_header: 关联 ID 头，默认 X-Correlation-ID；客户端未提供时与请求 ID 相同
+func (p *Plugin) Execute(w http.ResponseWriter, r *http.Request, params config.PluginSpec) (bool, error) {
+	header, correlationHeader := headers(params)
+
+	requestID := r.Header.Get(header)
+	if !validID(requestID) {
+		requestID = uuid.New().String()
+	}
+	correlationID := r.Header.Get(correlationHeader)
+	if !validID(correlationID) {
+		correlationID = requestID
+	}
+
+	r.Header.Set(header, requestID)
+	r.Header.Set(correlationHeader, correlationID)
+	w.Header().Set(header, requestID)
+	w.Header().Set(correlationHeader, correlationID)
+
+	// 插件无法替换调用方持有的 *http.Request，只能原地更新其 context
+	ctx := logger.WithRequestID(r.Context(), requestID)
+	ctx = logger.WithTraceID(ctx, correlationID)
+	*r = *r.WithContext(ctx)
+
+	p.log.Debug(ctx, "[插件] 请求 ID 已设置", "plugin", p.Name(), "request_id", requestID, "correlation_id", correlationID)
+	return true, nil
+}
+
+// ValidateConfig 校验请求头配置的类型
+func (p *Plugin) ValidateConfig(params config.PluginSpec) error {
+	for _, key := range []string{"header", "correlation_header"} {
+		if v, ok := params[key]; ok && v != nil {
+			if _, isString := v.(string); !isString {
+				return fmt.Errorf("配置 '%s' 必须是字符串", key)
+			}
+		}
+	}
+	return nil
+}
+
+// OnResponse 去掉上游回显的同名响应头，避免与网关已写入的值重复
+func (p *Plugin) OnResponse(resp *http.Response, route *config.RouteConfig, params config.PluginSpec) error {
+	header, correlationHeader := headers(params)
+	resp.Header.Del(header)
+	resp.Header.Del(correlationHeader)
+	return nil
+}
+
+func headers(params config.PluginSpec) (string, string) {
+	header, _ := params["header"].(string)
+	if header == "" {
+		header = DefaultHeader
+	}
+	correlationHeader, _ := params["correlation_header"].(string)
+	if correlationHeader == "" {
+		correlationHeader = DefaultCorrelationHeader
+	}
+	return header, correlationHeader
+}
+
+// validID 只接受长度合理的可见 ASCII 字符，防止日志注入
+func validID(id string) bool {
+	if id == "" || len(id) > maxIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,34 @@
+package plugin
+
+import (
+	"context"
+	"net/http"
+)
+
+type subjectKey struct{}
+
+// SetSubject 由认证插件在校验通过后记录请求的主体 (如 JWT 的 sub、Basic 认证的用户名)，
+// 供限流等插件按账号而不是按 IP 区分请求
+func SetSubject(r *http.Request, subject string) {
+	*r = *r.WithContext(context.WithValue(r.Context(), subjectKey{}, subject))
+}
+
+// Subject 返回认证插件记录的请求主体，未经认证的请求返回 false
+func Subject(r *http.Request) (string, bool) {
+	subject, ok := r.Context().Value(subjectKey{}).(string)
+	return subject, ok && subject != ""
+}
+
+type tierKey struct{}
+
+// SetTier 由认证插件记录请求所属的套餐等级 (如 free / pro / enterprise)，
+// 供限流插件按等级选择规则
+func SetTier(r *http.Request, tier string) {
+	*r = *r.WithContext(context.WithValue(r.Context(), tierKey{}, tier))
+}
+
+// Tier 返回认证插件记录的套餐等级，未记录时返回 false
+func Tier(r *http.Request) (string, bool) {
+	tier, ok := r.Context().Value(tierKey{}).(string)
+	return tier, ok && tier != ""
+}
@@ -0,0 +1,20 @@
+package plugin
+
+import (
+	"context"
+	"net/http"
+)
+
+type targetServiceKey struct{}
+
+// SetTargetService 让插件把当前请求改投到另一个已配置的服务 (如 A/B 实验分桶)，
+// 插件链执行完毕后网关按该服务转发
+func SetTargetService(r *http.Request, service string) {
+	*r = *r.WithContext(context.WithValue(r.Context(), targetServiceKey{}, service))
+}
+
+// TargetService 返回插件指定的目标服务
+func TargetService(r *http.Request) (string, bool) {
+	service, ok := r.Context().Value(targetServiceKey{}).(string)
+	return service, ok && service != ""
+}
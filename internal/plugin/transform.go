@@ -0,0 +1,149 @@
+package plugin
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"gateway.example/go-gateway/internal/bodybuffer"
+	"gateway.example/go-gateway/internal/config"
+)
+
+// DefaultMaxTransformBodySize 请求体/响应体转换时允许缓冲的默认最大字节数
+const DefaultMaxTransformBodySize = 1 << 20
+
+// RequestBodyTransformer 是插件可选实现的接口，用于在转发到上游之前改写请求体
+type RequestBodyTransformer interface {
+	TransformRequestBody(r *http.Request, body []byte, params config.PluginSpec) ([]byte, error)
+}
+
+// ResponseBodyTransformer 是插件可选实现的接口，用于在返回客户端之前改写上游响应体
+type ResponseBodyTransformer interface {
+	TransformResponseBody(resp *http.Response, body []byte, params config.PluginSpec) ([]byte, error)
+}
+
+// TransformRequestBody 依次调用路由上实现了 RequestBodyTransformer 的插件改写请求体
+// 请求体超过 limit 时跳过转换并原样转发，避免为大文件或流式上传缓冲整个请求体
+func (m *Manager) TransformRequestBody(r *http.Request, pluginSpecs []config.PluginSpec, limit int64) error {
+	ctx := r.Context()
+
+	type step struct {
+		name string
+		t    RequestBodyTransformer
+		spec config.PluginSpec
+	}
+	var steps []step
+	for _, spec := range pluginSpecs {
+		name, _ := spec["name"].(string)
+		if t, ok := m.GetPlugin(name).(RequestBodyTransformer); ok {
+			steps = append(steps, step{name: name, t: t, spec: spec})
+		}
+	}
+	if len(steps) == 0 || r.Body == nil || r.Body == http.NoBody {
+		return nil
+	}
+
+	if limit <= 0 {
+		limit = DefaultMaxTransformBodySize
+	}
+	if r.ContentLength > limit {
+		m.log.Warn(ctx, "[插件管理器] 请求体超过转换上限，跳过请求体转换", "limit", limit, "action", "transform_skipped")
+		return nil
+	}
+	data, err := bodybuffer.ReadAll(r, limit)
+	if errors.Is(err, bodybuffer.ErrTooLarge) {
+		m.log.Warn(ctx, "[插件管理器] 请求体超过转换上限，跳过请求体转换", "limit", limit, "action", "transform_skipped")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("读取请求体失败: %w", err)
+	}
+
+	for _, s := range steps {
+		if data, err = s.t.TransformRequestBody(r, data, s.spec); err != nil {
+			return fmt.Errorf("插件 '%s' 转换请求体失败: %w", s.name, err)
+		}
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(data))
+	r.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+	r.ContentLength = int64(len(data))
+	r.Header.Set("Content-Length", strconv.Itoa(len(data)))
+	return nil
+}
+
+// TransformResponseBody 依次调用路由上实现了 ResponseBodyTransformer 的插件改写上游响应体
+// 流式响应、已压缩响应以及超过 limit 的响应体不做转换
+func (m *Manager) TransformResponseBody(resp *http.Response, pluginSpecs []config.PluginSpec, limit int64) error {
+	ctx := resp.Request.Context()
+
+	type step struct {
+		name string
+		t    ResponseBodyTransformer
+		spec config.PluginSpec
+	}
+	var steps []step
+	for _, spec := range pluginSpecs {
+		name, _ := spec["name"].(string)
+		if t, ok := m.GetPlugin(name).(ResponseBodyTransformer); ok {
+			steps = append(steps, step{name: name, t: t, spec: spec})
+		}
+	}
+	if len(steps) == 0 || resp.Body == nil || resp.Body == http.NoBody {
+		return nil
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if resp.Header.Get("Content-Encoding") != "" ||
+		strings.HasPrefix(contentType, "text/event-stream") ||
+		strings.HasPrefix(contentType, "application/grpc") {
+		return nil
+	}
+
+	data, rest, err := bufferBody(resp.Body, limit)
+	if err != nil {
+		return fmt.Errorf("读取响应体失败: %w", err)
+	}
+	if rest != nil {
+		m.log.Warn(ctx, "[插件管理器] 响应体超过转换上限，跳过响应体转换", "limit", limit, "action", "transform_skipped")
+		resp.Body = rest
+		return nil
+	}
+
+	for _, s := range steps {
+		if data, err = s.t.TransformResponseBody(resp, data, s.spec); err != nil {
+			return fmt.Errorf("插件 '%s' 转换响应体失败: %w", s.name, err)
+		}
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+	resp.ContentLength = int64(len(data))
+	resp.Header.Set("Content-Length", strconv.Itoa(len(data)))
+	resp.Header.Del("ETag") // 实体已改变，原 ETag 失效
+	return nil
+}
+
+// bufferBody 最多读取 limit 字节
+// 完整读取时返回全部数据；超限时 rest 为一个能从头继续读取原始内容的 io.ReadCloser
+func bufferBody(body io.ReadCloser, limit int64) ([]byte, io.ReadCloser, error) {
+	if limit <= 0 {
+		limit = DefaultMaxTransformBodySize
+	}
+	buf := &bytes.Buffer{}
+	n, err := io.CopyN(buf, body, limit+1)
+	if err != nil && err != io.EOF {
+		body.Close()
+		return nil, nil, err
+	}
+	if n <= limit {
+		body.Close()
+		return buf.Bytes(), nil, nil
+	}
+	return nil, bodybuffer.Replay(buf, body), nil
+}
@@ -0,0 +1,104 @@
+// file: internal/plugin/transform/plugin.go
+package transform
+
+import (
+	"fmt"
+	"net/http"
+
+	"gateway.example/go-gateway/internal/config"
+	"gateway.example/go-gateway/internal/plugin"
+	"gateway.example/go-gateway/pkg/logger"
+)
+
+const PluginName = "transform"
+
+// Plugin 按路由配置对请求做静态改写: 删除/设置请求头、追加查询参数、改写 Host。
+// 配置示例:
+//
+//	plugins:
+//	  - name: "transform"
+//	    remove_headers: [ "Cookie" ]
+//	    set_headers: { X-Source: "gateway" }
+//	    add_query: { api_version: "2" }
+//	    host: "internal.example.com"
+//
+// 请求头按 remove → set 顺序执行；查询参数追加在已有参数之后，同名参数不会被覆盖。
+type Plugin struct {
+	log logger.Logger
+}
+
+func NewPlugin(log logger.Logger) *Plugin {
+	return &Plugin{log: log}
+}
+
+func (p *Plugin) Name() string {
+	return PluginName
+}
+
+// rules 是插件配置解析后的改写规则
+type rules struct {
+	removeHeaders []string
+	setHeaders    map[string]string
+	addQuery      map[string]string
+	host          string
+}
+
+func (p *Plugin) Execute(w http.ResponseWriter, r *http.Request, params config.PluginSpec) (bool, error) {
+	rl, err := parseRules(params)
+	if err != nil {
+		p.log.Error(r.Context(), fmt.Sprintf("[插件: %s] 配置错误: %v", p.Name(), err))
+		http.Error(w, "内部服务器错误: 插件配置错误", http.StatusInternalServerError)
+		return false, fmt.Errorf("插件 '%s' 配置错误: %w", p.Name(), err)
+	}
+
+	for _, name := range rl.removeHeaders {
+		r.Header.Del(name)
+	}
+	for name, value := range rl.setHeaders {
+		r.Header.Set(name, value)
+	}
+
+	if len(rl.addQuery) > 0 {
+		query := r.URL.Query()
+		for name, value := range rl.addQuery {
+			query.Add(name, value)
+		}
+		r.URL.RawQuery = query.Encode()
+	}
+
+	// 反向代理不改写 Host，这里设置的值会原样发送给上游
+	if rl.host != "" {
+		r.Host = rl.host
+	}
+
+	p.log.Debug(r.Context(), "[插件] 请求已改写", "plugin", p.Name(), "path", r.URL.Path)
+	return true, nil
+}
+
+// ValidateConfig 校验各改写规则的类型
+func (p *Plugin) ValidateConfig(params config.PluginSpec) error {
+	_, err := parseRules(params)
+	return err
+}
+
+func parseRules(params config.PluginSpec) (*rules, error) {
+	rl := &rules{}
+	var err error
+	if rl.removeHeaders, err = plugin.StringList(params, "remove_headers"); err != nil {
+		return nil, err
+	}
+	if rl.setHeaders, err = plugin.StringMap(params, "set_headers"); err != nil {
+		return nil, err
+	}
+	if rl.addQuery, err = plugin.StringMap(params, "add_query"); err != nil {
+		return nil, err
+	}
+	if v, ok := params["host"]; ok && v != nil {
+		host, isString := v.(string)
+		if !isString {
+			return nil, fmt.Errorf("配置 'host' 应为字符串")
+		}
+		rl.host = host
+	}
+	return rl, nil
+}
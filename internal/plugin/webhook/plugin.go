@@ -0,0 +1,310 @@
+// file: internal/plugin/webhook/plugin.go
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gateway.example/go-gateway/internal/bodybuffer"
+	"gateway.example/go-gateway/internal/config"
+	"gateway.example/go-gateway/internal/plugin"
+	"gateway.example/go-gateway/pkg/logger"
+)
+
+const PluginName = "webhook_signature"
+
+// 支持的签名格式
+const (
+	FormatGeneric = "generic" // 自定义请求头，可选时间戳
+	FormatGitHub  = "github"  // X-Hub-Signature-256: sha256=<hex>
+	FormatStripe  = "stripe"  // Stripe-Signature: t=<ts>,v1=<hex>[,v1=<hex>]
+	FormatSlack   = "slack"   // X-Slack-Signature: v0=<hex>，X-Slack-Request-Timestamp: <ts>
+)
+
+// 默认参数
+const (
+	defaultTolerance   = 5 * time.Minute
+	defaultMaxBodySize = 1 << 20
+)
+
+// 校验失败的原因
+var (
+	errMissingSignature = errors.New("缺少签名")
+	errMissingTimestamp = errors.New("缺少时间戳")
+	errExpired          = errors.New("时间戳超出允许范围")
+	errInvalidSignature = errors.New("签名不匹配")
+)
+
+// Plugin 校验 webhook 请求体的 HMAC 签名，签名不正确或已过期的请求不会到达上游。
+// 配置示例:
+//
+//	plugins:
+//	  - name: "webhook_signature"
+//	    format: "github"           # generic / github / stripe / slack
+//	    secrets: [ "new-secret", "old-secret" ]   # 任意一个匹配即通过，便于轮换
+//	    tolerance: "5m"            # 带时间戳的格式允许的时间偏差，"0s" 表示不检查
+//	    max_body_size: 1048576
+//
+// generic 格式另外支持 header、timestamp_header、prefix、algorithm (sha1/sha256/sha512)
+// 与 encoding (hex/base64)。带时间戳时签名内容为 "<timestamp>.<body>"，否则为请求体本身。
+type Plugin struct {
+	log logger.Logger
+}
+
+// verifier 是插件配置解析后的校验参数
+type verifier struct {
+	format          string
+	secrets         [][]byte
+	header          string
+	timestampHeader string
+	prefix          string
+	hash            func() hash.Hash
+	encoding        string
+	tolerance       time.Duration
+	maxBodySize     int64
+}
+
+func NewPlugin(log logger.Logger) *Plugin {
+	return &Plugin{log: log}
+}
+
+func (p *Plugin) Name() string {
+	return PluginName
+}
+
+func (p *Plugin) ValidateConfig(params config.PluginSpec) error {
+	_, err := parseVerifier(params)
+	return err
+}
+
+func (p *Plugin) Execute(w http.ResponseWriter, r *http.Request, params config.PluginSpec) (bool, error) {
+	ctx := r.Context()
+
+	v, err := parseVerifier(params)
+	if err != nil {
+		p.log.Error(ctx, fmt.Sprintf("[插件: %s] 配置错误: %v", p.Name(), err))
+		http.Error(w, "内部服务器错误: 插件配置错误", http.StatusInternalServerError)
+		return false, fmt.Errorf("插件 '%s' 配置错误: %w", p.Name(), err)
+	}
+
+	body, err := bodybuffer.ReadAll(r, v.maxBodySize)
+	if errors.Is(err, bodybuffer.ErrTooLarge) {
+		p.log.Info(ctx, "[插件] webhook 请求体超过限制", "plugin", p.Name(), "limit", v.maxBodySize)
+		plugin.Block(w, http.StatusRequestEntityTooLarge, "body_too_large", "webhook 请求体过大")
+		return false, nil
+	}
+	if err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return false, fmt.Errorf("读取 webhook 请求体失败: %w", err)
+	}
+
+	if err := v.verify(r, body, time.Now()); err != nil {
+		p.log.Warn(ctx, "[插件] webhook 签名校验失败", "plugin", p.Name(), "format", v.format,
+			"path", r.URL.Path, "reason", err.Error(), "remote_addr", r.RemoteAddr)
+		plugin.Block(w, http.StatusUnauthorized, "invalid_signature", "webhook 签名无效")
+		return false, nil
+	}
+	return true, nil
+}
+
+// verify 提取签名与时间戳，检查时间窗口后逐个密钥比对
+func (v *verifier) verify(r *http.Request, body []byte, now time.Time) error {
+	timestamp, signatures, err := v.extract(r)
+	if err != nil {
+		return err
+	}
+	if v.timestamped() && v.tolerance > 0 {
+		sec, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			return errMissingTimestamp
+		}
+		if diff := now.Sub(time.Unix(sec, 0)); diff > v.tolerance || diff < -v.tolerance {
+			return errExpired
+		}
+	}
+
+	payload := v.payload(timestamp, body)
+	for _, secret := range v.secrets {
+		mac := hmac.New(v.hash, secret)
+		mac.Write(payload)
+		expected := mac.Sum(nil)
+		for _, sig := range signatures {
+			if decoded, ok := v.decode(sig); ok && hmac.Equal(decoded, expected) {
+				return nil
+			}
+		}
+	}
+	return errInvalidSignature
+}
+
+// extract 按签名格式从请求头中取出时间戳和候选签名
+func (v *verifier) extract(r *http.Request) (string, []string, error) {
+	value := r.Header.Get(v.header)
+	if value == "" {
+		return "", nil, errMissingSignature
+	}
+
+	if v.format == FormatStripe {
+		var timestamp string
+		var signatures []string
+		for _, part := range strings.Split(value, ",") {
+			key, val, _ := strings.Cut(strings.TrimSpace(part), "=")
+			switch key {
+			case "t":
+				timestamp = val
+			case "v1":
+				signatures = append(signatures, val)
+			}
+		}
+		if timestamp == "" {
+			return "", nil, errMissingTimestamp
+		}
+		if len(signatures) == 0 {
+			return "", nil, errMissingSignature
+		}
+		return timestamp, signatures, nil
+	}
+
+	sig, ok := strings.CutPrefix(value, v.prefix)
+	if !ok {
+		return "", nil, errInvalidSignature
+	}
+	var timestamp string
+	if v.timestampHeader != "" {
+		if timestamp = r.Header.Get(v.timestampHeader); timestamp == "" {
+			return "", nil, errMissingTimestamp
+		}
+	}
+	return timestamp, []string{sig}, nil
+}
+
+// payload 按签名格式构造参与签名的内容
+func (v *verifier) payload(timestamp string, body []byte) []byte {
+	switch {
+	case v.format == FormatSlack:
+		return append([]byte("v0:"+timestamp+":"), body...)
+	case v.timestamped():
+		return append([]byte(timestamp+"."), body...)
+	default:
+		return body
+	}
+}
+
+func (v *verifier) timestamped() bool {
+	return v.format == FormatStripe || v.timestampHeader != ""
+}
+
+func (v *verifier) decode(sig string) ([]byte, bool) {
+	var b []byte
+	var err error
+	if v.encoding == "base64" {
+		b, err = base64.StdEncoding.DecodeString(sig)
+	} else {
+		b, err = hex.DecodeString(strings.ToLower(sig))
+	}
+	return b, err == nil
+}
+
+func parseVerifier(params config.PluginSpec) (*verifier, error) {
+	v := &verifier{
+		format:      FormatGeneric,
+		hash:        sha256.New,
+		encoding:    "hex",
+		tolerance:   defaultTolerance,
+		maxBodySize: defaultMaxBodySize,
+	}
+
+	if f, ok := params["format"]; ok && f != nil {
+		v.format, _ = f.(string)
+	}
+	switch v.format {
+	case FormatGeneric:
+		v.header = "X-Signature"
+	case FormatGitHub:
+		v.header, v.prefix = "X-Hub-Signature-256", "sha256="
+	case FormatStripe:
+		v.header = "Stripe-Signature"
+	case FormatSlack:
+		v.header, v.timestampHeader, v.prefix = "X-Slack-Signature", "X-Slack-Request-Timestamp", "v0="
+	default:
+		return nil, fmt.Errorf("配置 'format' 只能是 %s、%s、%s 或 %s", FormatGeneric, FormatGitHub, FormatStripe, FormatSlack)
+	}
+
+	secrets, err := plugin.StringList(params, "secrets")
+	if err != nil {
+		return nil, err
+	}
+	if s, ok := params["secret"].(string); ok && s != "" {
+		secrets = append([]string{s}, secrets...)
+	}
+	if len(secrets) == 0 {
+		return nil, fmt.Errorf("缺少 'secret' 或 'secrets' 配置")
+	}
+	for _, s := range secrets {
+		if s == "" {
+			return nil, fmt.Errorf("配置 'secrets' 中不能有空字符串")
+		}
+		v.secrets = append(v.secrets, []byte(s))
+	}
+
+	if h, ok := params["header"]; ok && h != nil {
+		s, isString := h.(string)
+		if !isString || s == "" {
+			return nil, fmt.Errorf("配置 'header' 应为非空字符串")
+		}
+		v.header = s
+	}
+	tolerance, err := plugin.Duration(params, "tolerance", v.tolerance)
+	if err != nil {
+		return nil, err
+	}
+	v.tolerance = tolerance
+	if m, ok := params["max_body_size"]; ok && m != nil {
+		size, err := config.ParseByteSizeValue(m)
+		if err != nil || size <= 0 {
+			return nil, fmt.Errorf("配置 'max_body_size' 应为正的字节数或带单位的大小 (如 1MB)")
+		}
+		v.maxBodySize = int64(size)
+	}
+
+	// 以下参数只对 generic 格式生效，其余格式由服务商固定
+	if v.format != FormatGeneric {
+		return v, nil
+	}
+	if t, ok := params["timestamp_header"]; ok && t != nil {
+		v.timestampHeader, _ = t.(string)
+	}
+	if p, ok := params["prefix"]; ok && p != nil {
+		v.prefix, _ = p.(string)
+	}
+	if a, ok := params["algorithm"]; ok && a != nil {
+		switch a {
+		case "sha1":
+			v.hash = sha1.New
+		case "sha256":
+			v.hash = sha256.New
+		case "sha512":
+			v.hash = sha512.New
+		default:
+			return nil, fmt.Errorf("配置 'algorithm' 只能是 sha1、sha256 或 sha512")
+		}
+	}
+	if e, ok := params["encoding"]; ok && e != nil {
+		if e != "hex" && e != "base64" {
+			return nil, fmt.Errorf("配置 'encoding' 只能是 hex 或 base64")
+		}
+		v.encoding = e.(string)
+	}
+	return v, nil
+}
@@ -0,0 +1,49 @@
+// package redisclient 根据配置创建 Redis 客户端，
+// 供分布式限流等需要在多个网关实例之间共享状态的组件使用。
+package redisclient
+
+import (
+	"crypto/tls"
+	"fmt"
+	"time"
+
+	"gateway.example/go-gateway/internal/config"
+	"github.com/redis/go-redis/v9"
+)
+
+// 连接默认参数
+const (
+	defaultDialTimeout = 5 * time.Second
+	defaultTimeout     = 100 * time.Millisecond
+)
+
+// New 根据配置创建 Redis 客户端，不会立即建立连接
+func New(cfg config.RedisConfig) (*redis.Client, error) {
+	if cfg.Addr == "" {
+		return nil, fmt.Errorf("缺少 Redis 地址 'addr'")
+	}
+
+	dialTimeout := cfg.DialTimeout
+	if dialTimeout <= 0 {
+		dialTimeout = defaultDialTimeout
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	opts := &redis.Options{
+		Addr:         cfg.Addr,
+		Username:     cfg.Username,
+		Password:     cfg.Password,
+		DB:           cfg.DB,
+		PoolSize:     cfg.PoolSize,
+		DialTimeout:  dialTimeout,
+		ReadTimeout:  timeout,
+		WriteTimeout: timeout,
+	}
+	if cfg.TLS {
+		opts.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	return redis.NewClient(opts), nil
+}
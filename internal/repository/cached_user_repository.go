@@ -0,0 +1,109 @@
+// file: internal/repository/cached_user_repository.go
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"gateway.example/go-gateway/internal/cache"
+	"gateway.example/go-gateway/internal/models"
+)
+
+// 用户查询缓存在共享缓存中的键前缀
+const (
+	userByNameKeyPrefix = "auth:user:name:"
+	userByIDKeyPrefix   = "auth:user:id:"
+)
+
+// NewCachedUserRepository 在 repo 之上缓存按用户名、按 ID 的查询，登录与刷新令牌等热点查询不必每次访问底层存储。
+// 使用 Redis 缓存后端时各副本共享缓存，任一副本修改用户后立即清除对应的键，其他副本随之读到新数据。
+// 缓存不可用时直接查询 repo，ttl 是修改未经本仓库时缓存数据的最长陈旧时间
+func NewCachedUserRepository(repo UserRepository, store cache.Cache, ttl time.Duration) UserRepository {
+	return &cachedUserRepository{repo: repo, store: store, ttl: ttl}
+}
+
+type cachedUserRepository struct {
+	repo  UserRepository
+	store cache.Cache
+	ttl   time.Duration
+}
+
+func (r *cachedUserRepository) FindByUsername(username string) (*models.User, error) {
+	return r.lookup(userByNameKeyPrefix+username, func() (*models.User, error) {
+		return r.repo.FindByUsername(username)
+	})
+}
+
+func (r *cachedUserRepository) FindByID(id string) (*models.User, error) {
+	return r.lookup(userByIDKeyPrefix+id, func() (*models.User, error) {
+		return r.repo.FindByID(id)
+	})
+}
+
+// lookup 优先读缓存，未命中时查询底层仓库并写回缓存。不缓存不存在的用户，注册后可以立即登录
+func (r *cachedUserRepository) lookup(key string, load func() (*models.User, error)) (*models.User, error) {
+	ctx := context.Background()
+	if data, err := r.store.Get(ctx, key); err == nil {
+		var user models.User
+		if json.Unmarshal(data, &user) == nil {
+			return &user, nil
+		}
+	}
+	user, err := load()
+	if err != nil {
+		return nil, err
+	}
+	if data, err := json.Marshal(user); err == nil {
+		_ = r.store.Set(ctx, userByNameKeyPrefix+user.Username, data, r.ttl)
+		_ = r.store.Set(ctx, userByIDKeyPrefix+user.ID, data, r.ttl)
+	}
+	return user, nil
+}
+
+func (r *cachedUserRepository) Create(user *models.User) error {
+	return r.repo.Create(user)
+}
+
+func (r *cachedUserRepository) UpdatePasswordHash(username, passwordHash string) error {
+	defer r.invalidate(username)
+	return r.repo.UpdatePasswordHash(username, passwordHash)
+}
+
+// List 用于管理接口，不经过缓存
+func (r *cachedUserRepository) List() ([]*models.User, error) {
+	return r.repo.List()
+}
+
+func (r *cachedUserRepository) SetDisabled(username string, disabled bool) error {
+	defer r.invalidate(username)
+	return r.repo.SetDisabled(username, disabled)
+}
+
+func (r *cachedUserRepository) SetRoles(username string, roles []string) error {
+	defer r.invalidate(username)
+	return r.repo.SetRoles(username, roles)
+}
+
+func (r *cachedUserRepository) Delete(username string) error {
+	user, err := r.repo.FindByUsername(username)
+	if err != nil {
+		return err
+	}
+	if err := r.repo.Delete(username); err != nil {
+		return err
+	}
+	ctx := context.Background()
+	_ = r.store.Delete(ctx, userByNameKeyPrefix+username)
+	_ = r.store.Delete(ctx, userByIDKeyPrefix+user.ID)
+	return nil
+}
+
+// invalidate 清除用户的两个缓存键，用户 ID 从底层仓库读取，避免依赖可能已陈旧的缓存
+func (r *cachedUserRepository) invalidate(username string) {
+	ctx := context.Background()
+	_ = r.store.Delete(ctx, userByNameKeyPrefix+username)
+	if user, err := r.repo.FindByUsername(username); err == nil {
+		_ = r.store.Delete(ctx, userByIDKeyPrefix+user.ID)
+	}
+}
@@ -0,0 +1,142 @@
+// file: internal/repository/redis_session_repository.go
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	"gateway.example/go-gateway/internal/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// NewRedisSessionRepository 创建基于 Redis 的会话仓库，多个认证服务副本共享会话，
+// 在任一副本上登录、刷新或吊销都对其他副本可见。
+// 会话保存在 <keyPrefix>session:<id>，到 ExpiresAt 时由 Redis 自动过期；
+// 用户的会话 ID 集合保存在 <keyPrefix>user-sessions:<userID>，列出时顺带清理已过期的成员
+func NewRedisSessionRepository(client *redis.Client, keyPrefix string) SessionRepository {
+	return &redisSessionRepository{client: client, keyPrefix: keyPrefix}
+}
+
+type redisSessionRepository struct {
+	client    *redis.Client
+	keyPrefix string
+}
+
+func (r *redisSessionRepository) sessionKey(id string) string {
+	return r.keyPrefix + "session:" + id
+}
+
+func (r *redisSessionRepository) userKey(userID string) string {
+	return r.keyPrefix + "user-sessions:" + userID
+}
+
+func (r *redisSessionRepository) Create(session *models.Session) error {
+	ttl := time.Until(session.ExpiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+	ctx := context.Background()
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, r.sessionKey(session.ID), data, ttl)
+		pipe.SAdd(ctx, r.userKey(session.UserID), session.ID)
+		return nil
+	})
+	return err
+}
+
+func (r *redisSessionRepository) FindByID(id string) (*models.Session, error) {
+	data, err := r.client.Get(context.Background(), r.sessionKey(id)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decodeSession(data)
+}
+
+func (r *redisSessionRepository) ListByUser(userID string) ([]*models.Session, error) {
+	ctx := context.Background()
+	ids, err := r.client.SMembers(ctx, r.userKey(userID)).Result()
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = r.sessionKey(id)
+	}
+	values, err := r.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var out []*models.Session
+	var stale []interface{}
+	for i, v := range values {
+		data, ok := v.(string)
+		if !ok {
+			stale = append(stale, ids[i])
+			continue
+		}
+		s, err := decodeSession([]byte(data))
+		if err != nil {
+			return nil, err
+		}
+		if !now.After(s.ExpiresAt) {
+			out = append(out, s)
+		}
+	}
+	if len(stale) > 0 {
+		_ = r.client.SRem(ctx, r.userKey(userID), stale...).Err()
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return out, nil
+}
+
+// Touch 只更新已存在的会话 (SET XX)，会话已过期或被删除时返回 ErrSessionNotFound
+func (r *redisSessionRepository) Touch(id string, lastUsedAt, expiresAt time.Time) error {
+	s, err := r.FindByID(id)
+	if err != nil {
+		return err
+	}
+	s.LastUsedAt, s.ExpiresAt = lastUsedAt, expiresAt
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	err = r.client.SetArgs(context.Background(), r.sessionKey(id), data, redis.SetArgs{Mode: "XX", ExpireAt: expiresAt}).Err()
+	if errors.Is(err, redis.Nil) {
+		return ErrSessionNotFound
+	}
+	return err
+}
+
+func (r *redisSessionRepository) Delete(id string) error {
+	s, err := r.FindByID(id)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, r.sessionKey(id))
+		pipe.SRem(ctx, r.userKey(s.UserID), id)
+		return nil
+	})
+	return err
+}
+
+func decodeSession(data []byte) (*models.Session, error) {
+	var s models.Session
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
@@ -0,0 +1,98 @@
+// file: internal/repository/refresh_token_repository.go
+package repository
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"gateway.example/go-gateway/internal/models"
+)
+
+// ErrRefreshTokenNotFound 刷新令牌不存在或已过期
+var ErrRefreshTokenNotFound = errors.New("refresh token not found")
+
+// RefreshTokenRepository 定义了刷新令牌的存储接口
+type RefreshTokenRepository interface {
+	Save(token *models.RefreshToken) error
+	// FindByHash 按摘要查找未过期的刷新令牌，不存在或已过期时返回 ErrRefreshTokenNotFound
+	FindByHash(tokenHash string) (*models.RefreshToken, error)
+	// MarkUsed 原子地把令牌标记为已使用，令牌此前已被使用时返回 false，用于检测重放
+	MarkUsed(tokenHash string) (bool, error)
+	// RevokeFamily 删除同一家族的全部刷新令牌
+	RevokeFamily(familyID string) error
+	// RevokeUser 删除用户的全部刷新令牌，用于停用账户、重置密码等场景
+	RevokeUser(userID string) error
+}
+
+// NewInMemoryRefreshTokenRepository 创建一个基于内存的刷新令牌仓库
+func NewInMemoryRefreshTokenRepository() RefreshTokenRepository {
+	return &inMemoryRefreshTokenRepository{tokens: make(map[string]*models.RefreshToken)}
+}
+
+type inMemoryRefreshTokenRepository struct {
+	mu     sync.Mutex
+	tokens map[string]*models.RefreshToken // 摘要 -> 令牌
+}
+
+// Save 保存令牌，同时清理已过期的令牌
+func (r *inMemoryRefreshTokenRepository) Save(token *models.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	for hash, t := range r.tokens {
+		if now.After(t.ExpiresAt) {
+			delete(r.tokens, hash)
+		}
+	}
+	copied := *token
+	r.tokens[token.TokenHash] = &copied
+	return nil
+}
+
+func (r *inMemoryRefreshTokenRepository) FindByHash(tokenHash string) (*models.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.tokens[tokenHash]
+	if !ok || time.Now().After(t.ExpiresAt) {
+		return nil, ErrRefreshTokenNotFound
+	}
+	copied := *t
+	return &copied, nil
+}
+
+func (r *inMemoryRefreshTokenRepository) MarkUsed(tokenHash string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.tokens[tokenHash]
+	if !ok {
+		return false, ErrRefreshTokenNotFound
+	}
+	if t.Used {
+		return false, nil
+	}
+	t.Used = true
+	return true, nil
+}
+
+func (r *inMemoryRefreshTokenRepository) RevokeFamily(familyID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for hash, t := range r.tokens {
+		if t.FamilyID == familyID {
+			delete(r.tokens, hash)
+		}
+	}
+	return nil
+}
+
+func (r *inMemoryRefreshTokenRepository) RevokeUser(userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for hash, t := range r.tokens {
+		if t.UserID == userID {
+			delete(r.tokens, hash)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+// file: internal/repository/session_repository.go
+package repository
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"gateway.example/go-gateway/internal/models"
+)
+
+// ErrSessionNotFound 会话不存在或已过期
+var ErrSessionNotFound = errors.New("session not found")
+
+// SessionRepository 定义了登录会话的存储接口
+type SessionRepository interface {
+	Create(session *models.Session) error
+	// FindByID 查找未过期的会话，不存在或已过期时返回 ErrSessionNotFound
+	FindByID(id string) (*models.Session, error)
+	// ListByUser 返回用户未过期的会话，按创建时间倒序
+	ListByUser(userID string) ([]*models.Session, error)
+	// Touch 更新会话的最近使用时间与过期时间
+	Touch(id string, lastUsedAt, expiresAt time.Time) error
+	// Delete 删除会话，不存在时返回 ErrSessionNotFound
+	Delete(id string) error
+}
+
+// NewInMemorySessionRepository 创建一个基于内存的会话仓库
+func NewInMemorySessionRepository() SessionRepository {
+	return &inMemorySessionRepository{sessions: make(map[string]*models.Session)}
+}
+
+type inMemorySessionRepository struct {
+	mu       sync.Mutex
+	sessions map[string]*models.Session
+}
+
+// Create 保存会话，同时清理已过期的会话
+func (r *inMemorySessionRepository) Create(session *models.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	for id, s := range r.sessions {
+		if now.After(s.ExpiresAt) {
+			delete(r.sessions, id)
+		}
+	}
+	copied := *session
+	r.sessions[session.ID] = &copied
+	return nil
+}
+
+func (r *inMemorySessionRepository) FindByID(id string) (*models.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.sessions[id]
+	if !ok || time.Now().After(s.ExpiresAt) {
+		return nil, ErrSessionNotFound
+	}
+	copied := *s
+	return &copied, nil
+}
+
+func (r *inMemorySessionRepository) ListByUser(userID string) ([]*models.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	var out []*models.Session
+	for _, s := range r.sessions {
+		if s.UserID == userID && !now.After(s.ExpiresAt) {
+			copied := *s
+			out = append(out, &copied)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return out, nil
+}
+
+func (r *inMemorySessionRepository) Touch(id string, lastUsedAt, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.sessions[id]
+	if !ok {
+		return ErrSessionNotFound
+	}
+	s.LastUsedAt, s.ExpiresAt = lastUsedAt, expiresAt
+	return nil
+}
+
+func (r *inMemorySessionRepository) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.sessions[id]; !ok {
+		return ErrSessionNotFound
+	}
+	delete(r.sessions, id)
+	return nil
+}
@@ -3,33 +3,155 @@ package repository
 
 import (
 	"errors"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
 
 	"gateway.example/go-gateway/internal/models" // 注意：请将 "gateway-example" 替换为你的 go.mod 中的模块名
 )
 
+// 仓库错误定义
+var (
+	ErrUserNotFound = errors.New("user not found")
+	ErrUserExists   = errors.New("user already exists")
+)
+
 // UserRepository 定义了用户数据的操作接口
 type UserRepository interface {
 	FindByUsername(username string) (*models.User, error)
+	// FindByID 按用户 ID 查找，用户不存在时返回 ErrUserNotFound
+	FindByID(id string) (*models.User, error)
+	// Create 保存新用户，用户名已存在时返回 ErrUserExists；user.ID 为空时由仓库分配
+	Create(user *models.User) error
+	// UpdatePasswordHash 更新用户的密码哈希，用户不存在时返回 ErrUserNotFound
+	UpdatePasswordHash(username, passwordHash string) error
+	// List 返回全部用户，按 ID 排序
+	List() ([]*models.User, error)
+	// SetDisabled 停用或启用用户，用户不存在时返回 ErrUserNotFound
+	SetDisabled(username string, disabled bool) error
+	// SetRoles 替换用户的角色，用户不存在时返回 ErrUserNotFound
+	SetRoles(username string, roles []string) error
+	// Delete 删除用户，用户不存在时返回 ErrUserNotFound
+	Delete(username string) error
 }
 
 // NewInMemoryUserRepository 创建一个基于内存的用户仓库实例，用于测试
 func NewInMemoryUserRepository() UserRepository {
-	// 创建一些假数据
+	// 创建一些假数据 (明文密码，演示首次登录后迁移为 bcrypt 哈希)
 	users := map[string]*models.User{
-		"admin": {ID: "1", Username: "xcq", Password: "password123"},
-		"user":  {ID: "2", Username: "user", Password: "password456"},
-		"xcq":   {ID: "2", Username: "xcq", Password: "xxx"},
+		"admin": {ID: "1", Username: "admin", PasswordHash: "password123", Roles: []string{"admin"}, TenantID: "default"},
+		"user":  {ID: "2", Username: "user", PasswordHash: "password456", Roles: []string{"user"}, TenantID: "default", Scopes: []string{"orders:read"}},
+		"xcq":   {ID: "3", Username: "xcq", PasswordHash: "xxx"},
 	}
-	return &inMemoryUserRepository{users: users}
+	return &inMemoryUserRepository{users: users, nextID: 4}
 }
 
 type inMemoryUserRepository struct {
-	users map[string]*models.User
+	mu     sync.RWMutex
+	users  map[string]*models.User
+	nextID int
 }
 
+// FindByUsername 返回用户的副本，调用方修改返回值不会影响仓库中的数据
 func (r *inMemoryUserRepository) FindByUsername(username string) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	if user, ok := r.users[username]; ok {
-		return user, nil
+		return cloneUser(user), nil
+	}
+	return nil, ErrUserNotFound
+}
+
+func (r *inMemoryUserRepository) FindByID(id string) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, user := range r.users {
+		if user.ID == id {
+			return cloneUser(user), nil
+		}
+	}
+	return nil, ErrUserNotFound
+}
+
+func (r *inMemoryUserRepository) Create(user *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.users[user.Username]; ok {
+		return ErrUserExists
+	}
+	if user.ID == "" {
+		user.ID = strconv.Itoa(r.nextID)
+		r.nextID++
+	}
+	r.users[user.Username] = cloneUser(user)
+	return nil
+}
+
+func (r *inMemoryUserRepository) UpdatePasswordHash(username, passwordHash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[username]
+	if !ok {
+		return ErrUserNotFound
+	}
+	user.PasswordHash = passwordHash
+	return nil
+}
+
+func (r *inMemoryUserRepository) List() ([]*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	users := make([]*models.User, 0, len(r.users))
+	for _, user := range r.users {
+		users = append(users, cloneUser(user))
 	}
-	return nil, errors.New("user not found")
+	// ID 为数字字符串，先按长度再按字典序即为数值顺序
+	sort.Slice(users, func(i, j int) bool {
+		if len(users[i].ID) != len(users[j].ID) {
+			return len(users[i].ID) < len(users[j].ID)
+		}
+		return users[i].ID < users[j].ID
+	})
+	return users, nil
+}
+
+func (r *inMemoryUserRepository) SetDisabled(username string, disabled bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[username]
+	if !ok {
+		return ErrUserNotFound
+	}
+	user.Disabled = disabled
+	return nil
+}
+
+func (r *inMemoryUserRepository) SetRoles(username string, roles []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[username]
+	if !ok {
+		return ErrUserNotFound
+	}
+	user.Roles = slices.Clone(roles)
+	return nil
+}
+
+func (r *inMemoryUserRepository) Delete(username string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.users[username]; !ok {
+		return ErrUserNotFound
+	}
+	delete(r.users, username)
+	return nil
+}
+
+// cloneUser 深拷贝用户，切片字段不与仓库共享
+func cloneUser(user *models.User) *models.User {
+	copied := *user
+	copied.Roles = slices.Clone(user.Roles)
+	copied.Scopes = slices.Clone(user.Scopes)
+	return &copied
 }
@@ -0,0 +1,32 @@
+// package response 定义了网关统一的 JSON 错误响应格式，供核心层与插件层共用。
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ErrorBody 网关统一的错误响应结构
+type ErrorBody struct {
+	Error   string `json:"error"`             // 错误简述
+	Status  int    `json:"status"`            // HTTP 状态码
+	Message string `json:"message,omitempty"` // 面向客户端的详细说明
+}
+
+// WriteError 以统一的 JSON 格式写出错误响应
+func WriteError(w http.ResponseWriter, status int, message string) {
+	WriteErrorBody(w, status, ErrorBody{
+		Error:   http.StatusText(status),
+		Status:  status,
+		Message: message,
+	})
+}
+
+// WriteErrorBody 写出自定义的错误响应体，body 可以是内嵌 ErrorBody 的扩展结构
+func WriteErrorBody(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Del("Content-Length")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"slices"
+	"strings"
+
+	"gateway.example/go-gateway/internal/models"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Claims 访问令牌携带的声明。scope 按 OAuth 2.0 惯例以空格分隔，
+// 网关的 auth 插件可以据此校验 required_roles / required_scopes，或通过 claims_to_headers 透传给上游
+type Claims struct {
+	Roles    []string `json:"roles,omitempty"`
+	TenantID string   `json:"tenant_id,omitempty"`
+	Scope    string   `json:"scope,omitempty"`
+	// SessionID 签发该令牌的登录会话，会话被吊销后令牌随之失效
+	SessionID string `json:"sid,omitempty"`
+	// ClientID 通过 client_credentials 签发的机器令牌携带客户端 ID，用户令牌没有该声明
+	ClientID string `json:"client_id,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// newClaims 根据用户信息生成访问令牌的自定义声明
+func newClaims(user *models.User) *Claims {
+	return &Claims{
+		Roles:    slices.Clone(user.Roles),
+		TenantID: user.TenantID,
+		Scope:    strings.Join(user.Scopes, " "),
+	}
+}
+
+// Scopes 返回令牌授予的 scope 列表
+func (c *Claims) Scopes() []string {
+	return strings.Fields(c.Scope)
+}
+
+// IsMachine 判断令牌是否为签发给服务客户端的机器令牌
+func (c *Claims) IsMachine() bool {
+	return c.ClientID != ""
+}
+
+// HasRole 判断令牌是否拥有指定角色
+func (c *Claims) HasRole(role string) bool {
+	return slices.Contains(c.Roles, role)
+}
+
+// HasScope 判断令牌是否被授予指定 scope
+func (c *Claims) HasScope(scope string) bool {
+	return slices.Contains(c.Scopes(), scope)
+}
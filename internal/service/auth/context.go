@@ -0,0 +1,40 @@
+package auth
+
+import "context"
+
+type clientIPKey struct{}
+
+// WithClientIP 把请求的客户端 IP 放入 context，供登录限制等按来源统计的功能使用
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey{}, ip)
+}
+
+// clientIP 返回 WithClientIP 放入的客户端 IP，没有时返回空串
+func clientIP(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey{}).(string)
+	return ip
+}
+
+type userAgentKey struct{}
+
+// WithUserAgent 把请求的 User-Agent 放入 context，登录时记录到会话中用于识别设备
+func WithUserAgent(ctx context.Context, userAgent string) context.Context {
+	return context.WithValue(ctx, userAgentKey{}, userAgent)
+}
+
+func userAgent(ctx context.Context) string {
+	ua, _ := ctx.Value(userAgentKey{}).(string)
+	return ua
+}
+
+type actorKey struct{}
+
+// WithActor 把发起操作的已认证用户 (访问令牌的 sub) 放入 context，记录到审计日志的 actor 字段
+func WithActor(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, actorKey{}, userID)
+}
+
+func actorID(ctx context.Context) string {
+	id, _ := ctx.Value(actorKey{}).(string)
+	return id
+}
@@ -0,0 +1,165 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"gateway.example/go-gateway/internal/config"
+	"gateway.example/go-gateway/internal/models"
+	"gateway.example/go-gateway/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// 身份源类型
+const (
+	ProviderLocal = "local"
+	ProviderLDAP  = "ldap"
+	ProviderOIDC  = "oidc"
+)
+
+// 身份源错误定义
+var (
+	ErrIdentityProviderUnavailable = errors.New("identity provider unavailable")
+	ErrExternalAccount             = errors.New("password is managed by the identity provider")
+)
+
+// IdentityProvider 校验用户名与密码的外部身份源。
+// 凭证错误时返回 ErrInvalidCredentials，身份源无法访问等其他错误原样返回
+type IdentityProvider interface {
+	Name() string
+	Authenticate(ctx context.Context, username, password string) (*Identity, error)
+}
+
+// Identity 外部身份源确认的用户
+type Identity struct {
+	Username string
+	Roles    []string // nil 表示身份源没有提供角色，保留本地账户的角色
+}
+
+// newIdentityProvider 按配置创建外部身份源，type 为 local (默认) 时返回 nil
+func newIdentityProvider(cfg config.IdentityProviderConfig) (IdentityProvider, error) {
+	switch cfg.Type {
+	case "", ProviderLocal:
+		return nil, nil
+	case ProviderLDAP:
+		return newLDAPProvider(cfg.LDAP)
+	case ProviderOIDC:
+		return newOIDCProvider(cfg.OIDC)
+	default:
+		return nil, fmt.Errorf("auth service: unsupported identity provider '%s'", cfg.Type)
+	}
+}
+
+// authenticate 校验登录凭证并返回对应的本地账户。
+// 配置了外部身份源时由身份源校验，首次登录的用户自动创建本地账户
+func (s *authService) authenticate(ctx context.Context, username, password string) (*models.User, error) {
+	if s.idp == nil {
+		return s.authenticateLocal(ctx, username, password)
+	}
+
+	identity, err := s.idp.Authenticate(ctx, username, password)
+	if err != nil {
+		if errors.Is(err, ErrInvalidCredentials) {
+			s.log.Warn(ctx, "Identity provider rejected credentials",
+				"username", username,
+				"provider", s.idp.Name(),
+				"service", "auth",
+				"action", "login_failed")
+			return nil, ErrInvalidCredentials
+		}
+		s.log.Error(ctx, "Identity provider unavailable",
+			"username", username,
+			"provider", s.idp.Name(),
+			"error", err.Error(),
+			"service", "auth",
+			"action", "login_failed")
+		return nil, fmt.Errorf("%w: %v", ErrIdentityProviderUnavailable, err)
+	}
+	return s.provisionUser(ctx, identity)
+}
+
+// authenticateLocal 按本地用户表校验密码，成功后把明文或成本过期的哈希迁移为当前成本
+func (s *authService) authenticateLocal(ctx context.Context, username, password string) (*models.User, error) {
+	user, err := s.userRepo.FindByUsername(username)
+	if err != nil {
+		// 与密码错误耗时相同，避免通过响应时间枚举用户名
+		_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		s.log.Warn(ctx, "User not found or repository error",
+			"username", username,
+			"error", err.Error(),
+			"service", "auth",
+			"action", "login_failed")
+		return nil, ErrInvalidCredentials
+	}
+	// 外部身份源创建的账户没有本地密码
+	if user.Provider != "" {
+		_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		s.log.Warn(ctx, "Local login rejected for externally managed account",
+			"username", username,
+			"provider", user.Provider,
+			"service", "auth",
+			"action", "login_failed")
+		return nil, ErrInvalidCredentials
+	}
+
+	ok, rehash := verifyPassword(user.PasswordHash, password, s.passwordCost)
+	if !ok {
+		s.log.Warn(ctx, "Invalid password for user",
+			"username", username,
+			"service", "auth",
+			"action", "login_failed")
+		return nil, ErrInvalidCredentials
+	}
+	if rehash {
+		s.migratePassword(ctx, username, password)
+	}
+	return user, nil
+}
+
+// provisionUser 返回外部身份源用户对应的本地账户，首次登录时创建；身份源提供角色时同步到本地账户。
+// 同名的本地账户或其他身份源的账户不会被接管
+func (s *authService) provisionUser(ctx context.Context, identity *Identity) (*models.User, error) {
+	user, err := s.userRepo.FindByUsername(identity.Username)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		user = &models.User{Username: identity.Username, Provider: s.idp.Name(), Roles: identity.Roles}
+		if user.Roles == nil {
+			user.Roles = slices.Clone(s.defaultRoles)
+		}
+		if err = s.userRepo.Create(user); err == nil {
+			s.log.Info(ctx, "User provisioned from identity provider",
+				"username", user.Username,
+				"user_id", user.ID,
+				"provider", user.Provider,
+				"service", "auth",
+				"action", "user_provisioned")
+			return user, nil
+		}
+		if !errors.Is(err, repository.ErrUserExists) {
+			return nil, s.userUpdateFailed(ctx, identity.Username, "user_provision_failed", err)
+		}
+		// 并发的首次登录已创建账户
+		user, err = s.userRepo.FindByUsername(identity.Username)
+	}
+	if err != nil {
+		return nil, s.userUpdateFailed(ctx, identity.Username, "user_provision_failed", err)
+	}
+
+	if user.Provider != s.idp.Name() {
+		s.log.Warn(ctx, "Username belongs to an account of another identity provider",
+			"username", user.Username,
+			"provider", s.idp.Name(),
+			"account_provider", user.Provider,
+			"service", "auth",
+			"action", "login_failed")
+		return nil, ErrInvalidCredentials
+	}
+	if identity.Roles != nil && !slices.Equal(user.Roles, identity.Roles) {
+		if err := s.userRepo.SetRoles(user.Username, identity.Roles); err != nil {
+			return nil, s.userUpdateFailed(ctx, user.Username, "user_provision_failed", err)
+		}
+		user.Roles = slices.Clone(identity.Roles)
+	}
+	return user, nil
+}
@@ -0,0 +1,311 @@
+package auth
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"gateway.example/go-gateway/internal/config"
+)
+
+// defaultIdPTimeout 访问外部身份源的默认超时
+const defaultIdPTimeout = 5 * time.Second
+
+// LDAP 协议中用到的 BER 标签与结果码 (RFC 4511)
+const (
+	berSequence    = 0x30
+	berInteger     = 0x02
+	berOctetString = 0x04
+	berEnumerated  = 0x0a
+
+	ldapBindRequest     = 0x60 // [APPLICATION 0] constructed
+	ldapBindResponse    = 0x61 // [APPLICATION 1] constructed
+	ldapUnbindRequest   = 0x42 // [APPLICATION 2] primitive
+	ldapExtendedRequest = 0x77 // [APPLICATION 23] constructed
+	ldapExtendedResp    = 0x78 // [APPLICATION 24] constructed
+	ldapSimpleAuth      = 0x80 // [0] primitive
+	ldapExtRequestName  = 0x80 // [0] primitive
+
+	ldapResultSuccess            = 0
+	ldapResultInvalidCredentials = 49
+
+	ldapStartTLSOID = "1.3.6.1.4.1.1466.20037"
+	// maxLDAPMessageSize 响应的上限，bind 响应通常只有几十字节
+	maxLDAPMessageSize = 64 << 10
+)
+
+// ldapProvider 以用户自己的 DN 和密码向目录服务发起 simple bind，bind 成功即认为密码正确
+type ldapProvider struct {
+	addr     string
+	ldaps    bool
+	startTLS bool
+	tls      *tls.Config
+	bindDN   string
+	timeout  time.Duration
+}
+
+func newLDAPProvider(cfg config.LDAPConfig) (*ldapProvider, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("auth service: invalid ldap url '%s'", cfg.URL)
+	}
+	p := &ldapProvider{
+		startTLS: cfg.StartTLS,
+		bindDN:   cfg.BindDN,
+		timeout:  cmp.Or(cfg.Timeout, defaultIdPTimeout),
+	}
+	port := u.Port()
+	switch u.Scheme {
+	case "ldap":
+		port = cmp.Or(port, "389")
+	case "ldaps":
+		port = cmp.Or(port, "636")
+		p.ldaps = true
+		if cfg.StartTLS {
+			return nil, errors.New("auth service: ldap start_tls cannot be used with ldaps://")
+		}
+	default:
+		return nil, fmt.Errorf("auth service: ldap url must use ldap:// or ldaps://, got '%s'", u.Scheme)
+	}
+	p.addr = net.JoinHostPort(u.Hostname(), port)
+	p.tls = &tls.Config{ServerName: u.Hostname(), MinVersion: tls.VersionTLS12, InsecureSkipVerify: cfg.InsecureSkipVerify}
+
+	if !strings.Contains(cfg.BindDN, "{username}") {
+		return nil, errors.New("auth service: ldap bind_dn must contain {username}")
+	}
+	return p, nil
+}
+
+func (p *ldapProvider) Name() string { return ProviderLDAP }
+
+// Authenticate 以 bind_dn 模板生成用户 DN 并发起 simple bind。
+// 空密码会被目录服务当作匿名 bind 而成功，必须在本地拒绝
+func (p *ldapProvider) Authenticate(ctx context.Context, username, password string) (*Identity, error) {
+	if username == "" || password == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", p.addr)
+	if err != nil {
+		return nil, fmt.Errorf("ldap dial: %w", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	if p.ldaps {
+		conn = tls.Client(conn, p.tls)
+	}
+	msgID := 1
+	if p.startTLS {
+		req := berTLV(ldapExtendedRequest, berTLV(ldapExtRequestName, []byte(ldapStartTLSOID)))
+		if err := p.roundTrip(conn, msgID, req, ldapExtendedResp); err != nil {
+			return nil, fmt.Errorf("ldap start_tls: %w", err)
+		}
+		conn = tls.Client(conn, p.tls)
+		msgID++
+	}
+
+	dn := strings.ReplaceAll(p.bindDN, "{username}", escapeDNValue(username))
+	bind := berTLV(ldapBindRequest, concat(
+		berInt(berInteger, 3), // LDAPv3
+		berTLV(berOctetString, []byte(dn)),
+		berTLV(ldapSimpleAuth, []byte(password)),
+	))
+	err = p.roundTrip(conn, msgID, bind, ldapBindResponse)
+	var le *ldapResultError
+	if errors.As(err, &le) && le.code == ldapResultInvalidCredentials {
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ldap bind: %w", err)
+	}
+
+	// 只用于校验密码，立即解除绑定
+	_, _ = conn.Write(ldapMessage(msgID+1, []byte{ldapUnbindRequest, 0}))
+	return &Identity{Username: username}, nil
+}
+
+// ldapResultError 目录服务返回的非成功结果码
+type ldapResultError struct {
+	code    int
+	message string
+}
+
+func (e *ldapResultError) Error() string {
+	return fmt.Sprintf("result code %d: %s", e.code, e.message)
+}
+
+// roundTrip 发送一个请求并读取对应的响应，结果码不为 success 时返回 ldapResultError
+func (p *ldapProvider) roundTrip(conn net.Conn, msgID int, op []byte, respTag byte) error {
+	if _, err := conn.Write(ldapMessage(msgID, op)); err != nil {
+		return err
+	}
+	msg, err := readBER(bufio.NewReader(conn), berSequence)
+	if err != nil {
+		return err
+	}
+	id, rest, err := nextBER(msg, berInteger)
+	if err != nil {
+		return err
+	}
+	if parseBERInt(id) != msgID {
+		return fmt.Errorf("unexpected message id %d", parseBERInt(id))
+	}
+	resp, _, err := nextBER(rest, respTag)
+	if err != nil {
+		return err
+	}
+	code, resp, err := nextBER(resp, berEnumerated)
+	if err != nil {
+		return err
+	}
+	_, resp, _ = nextBER(resp, berOctetString) // matchedDN
+	diagnostic, _, _ := nextBER(resp, berOctetString)
+	if c := parseBERInt(code); c != ldapResultSuccess {
+		return &ldapResultError{code: c, message: string(diagnostic)}
+	}
+	return nil
+}
+
+func ldapMessage(msgID int, op []byte) []byte {
+	return berTLV(berSequence, concat(berInt(berInteger, msgID), op))
+}
+
+// berTLV 按 BER 定长格式编码一个元素
+func berTLV(tag byte, value []byte) []byte {
+	n := len(value)
+	out := []byte{tag}
+	switch {
+	case n < 0x80:
+		out = append(out, byte(n))
+	case n <= 0xff:
+		out = append(out, 0x81, byte(n))
+	case n <= 0xffff:
+		out = append(out, 0x82, byte(n>>8), byte(n))
+	default:
+		out = append(out, 0x83, byte(n>>16), byte(n>>8), byte(n))
+	}
+	return append(out, value...)
+}
+
+// berInt 编码非负整数 (消息 ID、协议版本)
+func berInt(tag byte, n int) []byte {
+	var b []byte
+	for {
+		b = append([]byte{byte(n)}, b...)
+		n >>= 8
+		if n == 0 {
+			break
+		}
+	}
+	if b[0]&0x80 != 0 {
+		b = append([]byte{0}, b...)
+	}
+	return berTLV(tag, b)
+}
+
+func parseBERInt(b []byte) int {
+	n := 0
+	for _, c := range b {
+		n = n<<8 | int(c)
+	}
+	return n
+}
+
+// readBER 从连接读取一个完整的 BER 元素并返回其内容
+func readBER(r *bufio.Reader, tag byte) ([]byte, error) {
+	t, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if t != tag {
+		return nil, fmt.Errorf("unexpected ber tag 0x%02x", t)
+	}
+	l, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	n := int(l)
+	if l&0x80 != 0 {
+		size := int(l & 0x7f)
+		if size == 0 || size > 3 {
+			return nil, errors.New("unsupported ber length")
+		}
+		n = 0
+		for i := 0; i < size; i++ {
+			c, err := r.ReadByte()
+			if err != nil {
+				return nil, err
+			}
+			n = n<<8 | int(c)
+		}
+	}
+	if n > maxLDAPMessageSize {
+		return nil, fmt.Errorf("ldap message too large (%d bytes)", n)
+	}
+	body := make([]byte, n)
+	_, err = io.ReadFull(r, body)
+	return body, err
+}
+
+// nextBER 从 b 中解析下一个元素，返回其内容与剩余字节
+func nextBER(b []byte, tag byte) (value, rest []byte, err error) {
+	if len(b) < 2 || b[0] != tag {
+		return nil, nil, fmt.Errorf("expected ber tag 0x%02x", tag)
+	}
+	n, off := int(b[1]), 2
+	if b[1]&0x80 != 0 {
+		size := int(b[1] & 0x7f)
+		if size == 0 || size > 3 || len(b) < 2+size {
+			return nil, nil, errors.New("malformed ber length")
+		}
+		n = 0
+		for _, c := range b[2 : 2+size] {
+			n = n<<8 | int(c)
+		}
+		off += size
+	}
+	if len(b) < off+n {
+		return nil, nil, errors.New("truncated ber element")
+	}
+	return b[off : off+n], b[off+n:], nil
+}
+
+func concat(parts ...[]byte) []byte {
+	var out []byte
+	for _, p := range parts {
+		out = append(out, p...)
+	}
+	return out
+}
+
+// escapeDNValue 按 RFC 4514 转义 DN 中的属性值，防止用户名注入额外的 RDN
+func escapeDNValue(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		switch {
+		case strings.ContainsRune(`,+"\<>;=`, r),
+			i == 0 && (r == ' ' || r == '#'),
+			i == len(s)-1 && r == ' ':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == 0:
+			b.WriteString(`\00`)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"gateway.example/go-gateway/internal/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// oidcProvider 以 OAuth 2.0 password grant (RFC 6749 4.3) 向外部 OIDC 提供方校验用户凭证，
+// 适用于无法跳转浏览器的登录入口。配置 roles_claim 时从返回的 ID Token 中读取角色
+type oidcProvider struct {
+	tokenURL     string
+	clientID     string
+	clientSecret string
+	scope        string
+	rolesClaim   string
+	client       *http.Client
+}
+
+func newOIDCProvider(cfg config.OIDCIdPConfig) (*oidcProvider, error) {
+	u, err := url.Parse(cfg.TokenURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return nil, fmt.Errorf("auth service: invalid oidc token_url '%s'", cfg.TokenURL)
+	}
+	if cfg.ClientID == "" {
+		return nil, errors.New("auth service: oidc client_id is required")
+	}
+	return &oidcProvider{
+		tokenURL:     cfg.TokenURL,
+		clientID:     cfg.ClientID,
+		clientSecret: cfg.ClientSecret,
+		scope:        cmp.Or(cfg.Scope, "openid"),
+		rolesClaim:   cfg.RolesClaim,
+		client:       &http.Client{Timeout: cmp.Or(cfg.Timeout, defaultIdPTimeout)},
+	}, nil
+}
+
+func (p *oidcProvider) Name() string { return ProviderOIDC }
+
+// Authenticate 用用户名与密码换取令牌，提供方返回 invalid_grant 时视为凭证错误。
+// ID Token 直接来自 TLS 连接上的令牌端点，按 OIDC Core 3.1.3.7 可以不再校验签名
+func (p *oidcProvider) Authenticate(ctx context.Context, username, password string) (*Identity, error) {
+	if username == "" || password == "" {
+		return nil, ErrInvalidCredentials
+	}
+	form := url.Values{
+		"grant_type": {"password"},
+		"username":   {username},
+		"password":   {password},
+		"scope":      {p.scope},
+	}
+	if p.clientSecret == "" {
+		form.Set("client_id", p.clientID)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.clientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.clientID), url.QueryEscape(p.clientSecret))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var body struct {
+		IDToken     string `json:"id_token"`
+		AccessToken string `json:"access_token"`
+		Error       string `json:"error"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil && resp.StatusCode == http.StatusOK {
+		return nil, fmt.Errorf("decode token response: %w", err)
+	}
+	switch {
+	case resp.StatusCode == http.StatusOK:
+	case body.Error == "invalid_grant":
+		return nil, ErrInvalidCredentials
+	default:
+		return nil, fmt.Errorf("token endpoint returned status %d %s", resp.StatusCode, body.Error)
+	}
+
+	identity := &Identity{Username: username}
+	if p.rolesClaim != "" {
+		token := cmp.Or(body.IDToken, body.AccessToken)
+		claims := jwt.MapClaims{}
+		if _, _, err := jwt.NewParser().ParseUnverified(token, claims); err != nil {
+			return nil, fmt.Errorf("parse id token: %w", err)
+		}
+		identity.Roles = rolesFromClaim(claims[p.rolesClaim])
+	}
+	return identity, nil
+}
+
+// rolesFromClaim 支持字符串数组或以空格/逗号分隔的字符串
+func rolesFromClaim(v interface{}) []string {
+	roles := []string{}
+	switch val := v.(type) {
+	case []interface{}:
+		for _, item := range val {
+			if s, ok := item.(string); ok && s != "" {
+				roles = append(roles, s)
+			}
+		}
+	case string:
+		roles = append(roles, strings.FieldsFunc(val, func(r rune) bool { return r == ' ' || r == ',' })...)
+	}
+	return roles
+}
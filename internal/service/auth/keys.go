@@ -0,0 +1,211 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"gateway.example/go-gateway/internal/config"
+	"gateway.example/go-gateway/pkg/jwks"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// 支持的签名算法
+const (
+	AlgHS256 = "HS256" // 共享密钥，校验方需要持有同一密钥
+	AlgRS256 = "RS256" // RSA 私钥签名，公钥通过 JWKS 发布
+	AlgES256 = "ES256" // P-256 ECDSA 私钥签名，公钥通过 JWKS 发布
+)
+
+// minRSABits RS256 私钥的最小长度
+const minRSABits = 2048
+
+// signingKey 是一个签名密钥及其校验密钥
+type signingKey struct {
+	kid    string
+	method jwt.SigningMethod
+	sign   interface{} // []byte / *rsa.PrivateKey / *ecdsa.PrivateKey
+	verify interface{} // []byte / *rsa.PublicKey / *ecdsa.PublicKey
+}
+
+// keyRing 签发与校验令牌使用的密钥。current 签发新令牌，byKID 中的全部密钥都可用于校验，
+// 轮换后旧密钥签发的令牌在过期前仍然有效
+type keyRing struct {
+	current *signingKey
+	legacy  *signingKey // 校验没有 kid 的令牌 (轮换前签发) 使用的密钥
+	byKID   map[string]*signingKey
+}
+
+// newKeyRing 按 jwt 配置加载签名密钥。未配置 keys 时只使用单个密钥
+func newKeyRing(cfg config.JWTConfig) (*keyRing, error) {
+	ring := &keyRing{byKID: make(map[string]*signingKey)}
+	if len(cfg.Keys) == 0 {
+		key, err := loadSigningKey(cfg.Algorithm, cfg.SecretKey, cfg.PrivateKeyFile, cfg.KeyID)
+		if err != nil {
+			return nil, err
+		}
+		ring.current, ring.legacy = key, key
+		if key.kid != "" {
+			ring.byKID[key.kid] = key
+		}
+		return ring, nil
+	}
+
+	for i, kc := range cfg.Keys {
+		if kc.ID == "" {
+			return nil, fmt.Errorf("auth service: jwt.keys[%d].id cannot be empty", i)
+		}
+		if _, dup := ring.byKID[kc.ID]; dup {
+			return nil, fmt.Errorf("auth service: duplicate jwt key id %q", kc.ID)
+		}
+		key, err := loadSigningKey(kc.Algorithm, kc.SecretKey, kc.PrivateKeyFile, kc.ID)
+		if err != nil {
+			return nil, fmt.Errorf("jwt.keys[%d]: %w", i, err)
+		}
+		ring.byKID[kc.ID] = key
+	}
+
+	currentID := cfg.CurrentKeyID
+	if currentID == "" {
+		currentID = cfg.Keys[0].ID
+	}
+	var ok bool
+	if ring.current, ok = ring.byKID[currentID]; !ok {
+		return nil, fmt.Errorf("auth service: current_key_id %q not found in jwt.keys", currentID)
+	}
+
+	ring.legacy = ring.current
+	if cfg.SecretKey != "" || cfg.PrivateKeyFile != "" {
+		legacy, err := loadSigningKey(cfg.Algorithm, cfg.SecretKey, cfg.PrivateKeyFile, cfg.KeyID)
+		if err != nil {
+			return nil, err
+		}
+		ring.legacy = legacy
+		if legacy.kid != "" {
+			if _, dup := ring.byKID[legacy.kid]; !dup {
+				ring.byKID[legacy.kid] = legacy
+			}
+		}
+	}
+	return ring, nil
+}
+
+// loadSigningKey 加载一个签名密钥。非对称密钥未指定 kid 时使用公钥的 JWK 指纹
+func loadSigningKey(alg, secret, privateKeyFile, kid string) (*signingKey, error) {
+	if alg == "" {
+		alg = AlgHS256
+	}
+	switch alg {
+	case AlgHS256:
+		if secret == "" {
+			return nil, errors.New("auth service: jwt secret key cannot be empty")
+		}
+		return &signingKey{kid: kid, method: jwt.SigningMethodHS256, sign: []byte(secret), verify: []byte(secret)}, nil
+	case AlgRS256, AlgES256:
+	default:
+		return nil, fmt.Errorf("auth service: unsupported jwt algorithm %q (supported: %s, %s, %s)", alg, AlgHS256, AlgRS256, AlgES256)
+	}
+
+	if privateKeyFile == "" {
+		return nil, fmt.Errorf("auth service: jwt algorithm %s requires private_key_file", alg)
+	}
+	data, err := os.ReadFile(privateKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("auth service: read private key: %w", err)
+	}
+	priv, err := parsePrivateKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("auth service: parse private key %s: %w", privateKeyFile, err)
+	}
+
+	key := &signingKey{kid: kid}
+	switch k := priv.(type) {
+	case *rsa.PrivateKey:
+		if alg != AlgRS256 {
+			return nil, fmt.Errorf("auth service: %s requires an ECDSA P-256 key, got RSA", alg)
+		}
+		if k.N.BitLen() < minRSABits {
+			return nil, fmt.Errorf("auth service: RSA key must be at least %d bits", minRSABits)
+		}
+		key.method, key.sign, key.verify = jwt.SigningMethodRS256, k, &k.PublicKey
+	case *ecdsa.PrivateKey:
+		if alg != AlgES256 || k.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("auth service: %s requires an RSA key, or ES256 with a P-256 key", alg)
+		}
+		key.method, key.sign, key.verify = jwt.SigningMethodES256, k, &k.PublicKey
+	default:
+		return nil, fmt.Errorf("auth service: unsupported private key type %T", priv)
+	}
+
+	if key.kid == "" {
+		jwk, err := jwks.FromPublicKey("", alg, key.verify)
+		if err != nil {
+			return nil, err
+		}
+		if key.kid, err = jwk.Thumbprint(); err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+// parsePrivateKey 解析 PEM 格式的 PKCS#8、PKCS#1 (RSA) 或 SEC 1 (EC) 私钥
+func parsePrivateKey(data []byte) (interface{}, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	default:
+		return x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+}
+
+// sign 使用当前密钥签发令牌
+func (r *keyRing) sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(r.current.method, claims)
+	if r.current.kid != "" {
+		token.Header["kid"] = r.current.kid
+	}
+	return token.SignedString(r.current.sign)
+}
+
+// keyFunc 按令牌头中的 kid 选择校验密钥，没有 kid 的令牌使用轮换前的密钥；算法必须与密钥一致
+func (r *keyRing) keyFunc(token *jwt.Token) (interface{}, error) {
+	key := r.legacy
+	if kid, _ := token.Header["kid"].(string); kid != "" {
+		var ok bool
+		if key, ok = r.byKID[kid]; !ok {
+			return nil, fmt.Errorf("unknown key id %q", kid)
+		}
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return key.verify, nil
+}
+
+// jwks 返回非对称密钥的公钥集合，HS256 密钥不发布
+func (r *keyRing) jwks() jwks.Set {
+	set := jwks.Set{Keys: []jwks.Key{}}
+	for _, key := range r.byKID {
+		if key.method == jwt.SigningMethodHS256 {
+			continue
+		}
+		if jwk, err := jwks.FromPublicKey(key.kid, key.method.Alg(), key.verify); err == nil {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].Kid < set.Keys[j].Kid })
+	return set
+}
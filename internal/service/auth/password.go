@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"fmt"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// dummyHash 用户不存在时同样执行一次 bcrypt 比较，避免通过响应时间枚举用户名
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy"), bcrypt.DefaultCost)
+
+// validateCost 校验 bcrypt 成本参数，0 表示使用默认值
+func validateCost(cost int) (int, error) {
+	if cost == 0 {
+		return bcrypt.DefaultCost, nil
+	}
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		return 0, fmt.Errorf("auth service: bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+	}
+	return cost, nil
+}
+
+// hashPassword 使用 bcrypt 计算密码哈希
+func hashPassword(password string, cost int) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// verifyPassword 校验密码，返回是否匹配以及是否需要重新计算哈希。
+// 存储的值不是 bcrypt 哈希时视为迁移前的明文密码；成本与当前配置不同的哈希在登录成功后按新成本重新计算
+func verifyPassword(stored, password string, cost int) (ok, rehash bool) {
+	storedCost, err := bcrypt.Cost([]byte(stored))
+	if err != nil {
+		return subtle.ConstantTimeCompare([]byte(stored), []byte(password)) == 1, true
+	}
+	if bcrypt.CompareHashAndPassword([]byte(stored), []byte(password)) != nil {
+		return false, false
+	}
+	return true, storedCost != cost
+}
@@ -0,0 +1,227 @@
+package auth
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"gateway.example/go-gateway/internal/config"
+)
+
+// 密码策略的默认参数
+const (
+	defaultMinPasswordLength = 8
+	// maxPasswordBytes bcrypt 只使用前 72 字节，更长的密码会被截断
+	maxPasswordBytes      = 72
+	defaultBreachCheckURL = "https://api.pwnedpasswords.com/range/"
+	breachCheckTimeout    = 3 * time.Second
+)
+
+// commonPasswords 内置的常见弱密码，总是被拒绝 (不区分大小写)
+var commonPasswords = []string{
+	"password", "password1", "password123", "12345678", "123456789", "1234567890",
+	"qwerty123", "qwertyuiop", "11111111", "00000000", "iloveyou", "admin123",
+	"welcome1", "letmein1", "abc12345", "football", "baseball", "sunshine",
+	"princess", "passw0rd", "p@ssw0rd", "changeme",
+}
+
+// 密码不符合策略的原因，客户端可以据此显示对应的提示
+const (
+	ViolationTooShort         = "too_short"
+	ViolationTooLong          = "too_long"
+	ViolationMissingUpper     = "missing_uppercase"
+	ViolationMissingLower     = "missing_lowercase"
+	ViolationMissingDigit     = "missing_digit"
+	ViolationMissingSymbol    = "missing_symbol"
+	ViolationCommonPassword   = "common_password"
+	ViolationContainsUsername = "contains_username"
+	ViolationBreached         = "breached_password"
+)
+
+// ErrWeakPassword 密码不符合密码策略
+var ErrWeakPassword = errors.New("password does not meet the password policy")
+
+// PolicyViolation 是一条不符合密码策略的原因
+type PolicyViolation struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// PolicyError 列出密码不符合策略的全部原因
+type PolicyError struct {
+	Violations []PolicyViolation
+}
+
+func (e *PolicyError) Error() string {
+	codes := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		codes[i] = v.Code
+	}
+	return fmt.Sprintf("%s: %s", ErrWeakPassword, strings.Join(codes, ", "))
+}
+
+func (e *PolicyError) Unwrap() error { return ErrWeakPassword }
+
+// passwordPolicy 校验新密码，用于注册、修改密码与管理员重置密码
+type passwordPolicy struct {
+	minLength        int
+	requireUpper     bool
+	requireLower     bool
+	requireDigit     bool
+	requireSymbol    bool
+	disallowUsername bool
+	banned           map[string]struct{}
+	breachCheckURL   string // 为空时不检查泄露
+	client           *http.Client
+}
+
+// newPasswordPolicy 按配置创建密码策略，banned_passwords_file 每行一个密码
+func newPasswordPolicy(cfg config.PasswordPolicyConfig) (*passwordPolicy, error) {
+	if cfg.MinLength < 0 || cfg.MinLength > maxPasswordBytes {
+		return nil, fmt.Errorf("auth service: password_policy.min_length must be between 0 and %d", maxPasswordBytes)
+	}
+	p := &passwordPolicy{
+		minLength:        cfg.MinLength,
+		requireUpper:     cfg.RequireUppercase,
+		requireLower:     cfg.RequireLowercase,
+		requireDigit:     cfg.RequireDigit,
+		requireSymbol:    cfg.RequireSymbol,
+		disallowUsername: !cfg.AllowUsername,
+		banned:           make(map[string]struct{}),
+	}
+	if p.minLength == 0 {
+		p.minLength = defaultMinPasswordLength
+	}
+
+	banned := append(append([]string(nil), commonPasswords...), cfg.BannedPasswords...)
+	if cfg.BannedPasswordsFile != "" {
+		f, err := os.Open(cfg.BannedPasswordsFile)
+		if err != nil {
+			return nil, fmt.Errorf("auth service: read banned passwords: %w", err)
+		}
+		defer f.Close()
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			if line := strings.TrimSpace(scanner.Text()); line != "" && !strings.HasPrefix(line, "#") {
+				banned = append(banned, line)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("auth service: read banned passwords: %w", err)
+		}
+	}
+	for _, pw := range banned {
+		p.banned[strings.ToLower(pw)] = struct{}{}
+	}
+
+	if cfg.BreachCheck {
+		p.breachCheckURL = cfg.BreachCheckURL
+		if p.breachCheckURL == "" {
+			p.breachCheckURL = defaultBreachCheckURL
+		}
+		p.client = &http.Client{Timeout: breachCheckTimeout}
+	}
+	return p, nil
+}
+
+// validate 返回密码不符合策略的全部原因，符合时返回 nil。
+// 泄露检查失败 (网络错误等) 时放行，不因外部服务故障阻塞注册与改密
+func (p *passwordPolicy) validate(ctx context.Context, username, password string) error {
+	var violations []PolicyViolation
+	add := func(code, format string, args ...interface{}) {
+		violations = append(violations, PolicyViolation{Code: code, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if n := utf8.RuneCountInString(password); n < p.minLength {
+		add(ViolationTooShort, "password must be at least %d characters", p.minLength)
+	}
+	if len(password) > maxPasswordBytes {
+		add(ViolationTooLong, "password must be at most %d bytes", maxPasswordBytes)
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+	if p.requireUpper && !upper {
+		add(ViolationMissingUpper, "password must contain an uppercase letter")
+	}
+	if p.requireLower && !lower {
+		add(ViolationMissingLower, "password must contain a lowercase letter")
+	}
+	if p.requireDigit && !digit {
+		add(ViolationMissingDigit, "password must contain a digit")
+	}
+	if p.requireSymbol && !symbol {
+		add(ViolationMissingSymbol, "password must contain a symbol")
+	}
+
+	if _, ok := p.banned[strings.ToLower(password)]; ok {
+		add(ViolationCommonPassword, "password is too common")
+	}
+	if p.disallowUsername && username != "" && strings.Contains(strings.ToLower(password), strings.ToLower(username)) {
+		add(ViolationContainsUsername, "password must not contain the username")
+	}
+
+	// 其余规则已不满足时不再请求外部服务
+	if len(violations) == 0 && p.breachCheckURL != "" {
+		if breached, err := p.breached(ctx, password); err == nil && breached {
+			add(ViolationBreached, "password has appeared in a data breach")
+		}
+	}
+
+	if len(violations) > 0 {
+		return &PolicyError{Violations: violations}
+	}
+	return nil
+}
+
+// breached 使用 k-匿名范围查询 (Have I Been Pwned Passwords API) 检查密码是否出现在已泄露的密码库中，
+// 只发送 SHA-1 摘要的前 5 个十六进制字符
+func (p *passwordPolicy) breached(ctx context.Context, password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.breachCheckURL+prefix, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Add-Padding", "true")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("breach check returned status %d", resp.StatusCode)
+	}
+
+	// 每行格式为 "<后 35 位摘要>:<出现次数>"，填充行的次数为 0
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		candidate, count, ok := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if ok && strings.EqualFold(candidate, suffix) && count != "0" {
+			return true, nil
+		}
+	}
+	return false, scanner.Err()
+}
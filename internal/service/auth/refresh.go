@@ -0,0 +1,152 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"gateway.example/go-gateway/internal/models"
+	"gateway.example/go-gateway/internal/repository"
+)
+
+// defaultRefreshDuration 未配置 refresh_duration_minutes 时刷新令牌的有效期
+const defaultRefreshDuration = 7 * 24 * time.Hour
+
+// ErrInvalidRefreshToken 刷新令牌无效、已过期或已被使用
+var ErrInvalidRefreshToken = errors.New("invalid refresh token")
+
+// TokenPair 登录或刷新后返回给客户端的一对令牌
+type TokenPair struct {
+	AccessToken      string `json:"access_token"`
+	RefreshToken     string `json:"refresh_token"`
+	TokenType        string `json:"token_type"`
+	ExpiresIn        int    `json:"expires_in"`         // 访问令牌有效期 (秒)
+	RefreshExpiresIn int    `json:"refresh_expires_in"` // 刷新令牌有效期 (秒)
+}
+
+// Refresh 用刷新令牌换取一对新令牌，旧的刷新令牌随即失效 (轮换)。
+// 已使用过的刷新令牌再次出现说明令牌可能已泄露，此时作废同一家族的全部刷新令牌，持有者需要重新登录
+func (s *authService) Refresh(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	hash := hashToken(refreshToken)
+	stored, err := s.tokenRepo.FindByHash(hash)
+	if err != nil {
+		if !errors.Is(err, repository.ErrRefreshTokenNotFound) {
+			s.log.Error(ctx, "Failed to look up refresh token",
+				"error", err.Error(),
+				"service", "auth",
+				"action", "refresh_failed")
+			return nil, err
+		}
+		s.log.Warn(ctx, "Unknown or expired refresh token",
+			"service", "auth",
+			"action", "refresh_failed")
+		return nil, ErrInvalidRefreshToken
+	}
+
+	first, err := s.tokenRepo.MarkUsed(hash)
+	if err != nil && !errors.Is(err, repository.ErrRefreshTokenNotFound) {
+		return nil, err
+	}
+	if !first {
+		if err := s.tokenRepo.RevokeFamily(stored.FamilyID); err != nil {
+			s.log.Error(ctx, "Failed to revoke refresh token family",
+				"family_id", stored.FamilyID,
+				"error", err.Error(),
+				"service", "auth",
+				"action", "refresh_reuse_detected")
+			return nil, err
+		}
+		s.log.Warn(ctx, "Refresh token reuse detected, token family revoked",
+			"user_id", stored.UserID,
+			"username", stored.Username,
+			"family_id", stored.FamilyID,
+			"service", "auth",
+			"action", "refresh_reuse_detected")
+		s.audit.record(ctx, AuditRefreshReuse, outcomeFailure,
+			"user_id", stored.UserID,
+			"username", stored.Username,
+			"sid", stored.FamilyID)
+		return nil, ErrInvalidRefreshToken
+	}
+
+	// 用户可能已被删除或停用
+	user, err := s.userRepo.FindByUsername(stored.Username)
+	if err != nil || user.ID != stored.UserID || user.Disabled {
+		s.log.Warn(ctx, "Refresh token owner no longer exists or is disabled",
+			"user_id", stored.UserID,
+			"username", stored.Username,
+			"service", "auth",
+			"action", "refresh_failed")
+		_ = s.tokenRepo.RevokeFamily(stored.FamilyID)
+		return nil, ErrInvalidRefreshToken
+	}
+
+	// 会话已被远程注销时刷新令牌同样失效
+	now := time.Now()
+	if err := s.sessionRepo.Touch(stored.FamilyID, now, now.Add(s.refreshDuration)); err != nil {
+		s.log.Warn(ctx, "Refresh token session has ended",
+			"user_id", stored.UserID,
+			"sid", stored.FamilyID,
+			"service", "auth",
+			"action", "refresh_failed")
+		_ = s.tokenRepo.RevokeFamily(stored.FamilyID)
+		return nil, ErrInvalidRefreshToken
+	}
+
+	tokens, err := s.issueTokens(ctx, user, stored.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+	s.log.Info(ctx, "Tokens refreshed",
+		"user_id", user.ID,
+		"service", "auth",
+		"action", "refresh_success")
+	return tokens, nil
+}
+
+// issueTokens 签发关联 familyID 会话的访问令牌，并在该家族中签发一个新的刷新令牌
+func (s *authService) issueTokens(ctx context.Context, user *models.User, familyID string) (*TokenPair, error) {
+	access, err := s.generateToken(ctx, user, familyID)
+	if err != nil {
+		return nil, err
+	}
+
+	refresh := newTokenID()
+	now := time.Now()
+	err = s.tokenRepo.Save(&models.RefreshToken{
+		TokenHash: hashToken(refresh),
+		FamilyID:  familyID,
+		UserID:    user.ID,
+		Username:  user.Username,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(s.refreshDuration),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:      access,
+		RefreshToken:     refresh,
+		TokenType:        "Bearer",
+		ExpiresIn:        int(s.jwtDuration.Seconds()),
+		RefreshExpiresIn: int(s.refreshDuration.Seconds()),
+	}, nil
+}
+
+// newTokenID 生成 256 位随机值，用作刷新令牌与令牌家族 ID
+func newTokenID() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// hashToken 返回令牌的 SHA-256 摘要，仓库中只保存摘要
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
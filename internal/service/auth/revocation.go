@@ -0,0 +1,195 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"gateway.example/go-gateway/internal/cache"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// 吊销列表在共享缓存中的键前缀
+const (
+	revokedKeyPrefix        = "auth:revoked:"
+	revokedSessionKeyPrefix = "auth:revoked-session:"
+)
+
+// ErrTokenRevoked 令牌已被吊销
+var ErrTokenRevoked = errors.New("token revoked")
+
+// Revocation 吊销列表中的一条记录，供网关 auth 插件的本地校验模式定期同步。
+// JTI 与 SessionID 只有一个非空: 前者吊销单个访问令牌，后者吊销一个会话签发的全部访问令牌
+type Revocation struct {
+	JTI       string `json:"jti,omitempty"`
+	SessionID string `json:"sid,omitempty"`
+	ExpiresAt int64  `json:"exp"` // 记录失效时间 (Unix 秒)，此后受影响的令牌都已过期
+}
+
+// Key 返回该记录在吊销列表中的键
+func (r Revocation) Key() string {
+	if r.SessionID != "" {
+		return RevokedSessionKey(r.SessionID)
+	}
+	return RevokedTokenKey(r.JTI)
+}
+
+// revocationLog 记录本实例吊销的、尚未过期的令牌与会话。
+// 共享缓存无法枚举键，网关与认证服务不共用缓存时通过它同步吊销列表
+type revocationLog struct {
+	mu      sync.Mutex
+	entries map[Revocation]time.Time // ExpiresAt 为 0 的记录 -> 失效时间
+}
+
+func newRevocationLog() *revocationLog {
+	return &revocationLog{entries: make(map[Revocation]time.Time)}
+}
+
+func (l *revocationLog) add(rev Revocation, expiresAt time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries[rev] = expiresAt
+}
+
+// list 返回尚未过期的记录，同时清理已过期的记录
+func (l *revocationLog) list(now time.Time) []Revocation {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	out := make([]Revocation, 0, len(l.entries))
+	for rev, exp := range l.entries {
+		if !exp.After(now) {
+			delete(l.entries, rev)
+			continue
+		}
+		rev.ExpiresAt = exp.Unix()
+		out = append(out, rev)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ExpiresAt < out[j].ExpiresAt })
+	return out
+}
+
+// RevokedTokenKey 返回访问令牌 (按 jti) 在吊销列表中的键。
+// 认证服务与网关使用同一个共享缓存 (如 Redis) 时，网关的 auth 插件可以直接查询吊销列表
+func RevokedTokenKey(jti string) string {
+	return revokedKeyPrefix + jti
+}
+
+// RevokedSessionKey 返回会话在吊销列表中的键，会话被吊销后其签发的访问令牌全部失效
+func RevokedSessionKey(sid string) string {
+	return revokedSessionKeyPrefix + sid
+}
+
+// IsRevoked 查询吊销列表，没有 jti 的令牌 (升级前签发) 无法吊销
+func IsRevoked(ctx context.Context, store cache.Cache, jti string) (bool, error) {
+	if jti == "" {
+		return false, nil
+	}
+	return exists(ctx, store, RevokedTokenKey(jti))
+}
+
+// IsSessionRevoked 查询令牌所属的会话是否已被吊销 (远程注销、注销所有设备等)
+func IsSessionRevoked(ctx context.Context, store cache.Cache, sid string) (bool, error) {
+	if sid == "" {
+		return false, nil
+	}
+	return exists(ctx, store, RevokedSessionKey(sid))
+}
+
+func exists(ctx context.Context, store cache.Cache, key string) (bool, error) {
+	_, err := store.Get(ctx, key)
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, cache.ErrNotFound):
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// Logout 吊销访问令牌；refreshToken 非空时同时作废该刷新令牌所属家族，使这次登录签发的令牌全部失效
+func (s *authService) Logout(ctx context.Context, accessToken, refreshToken string) error {
+	claims, err := s.ValidateTokenWithClaims(ctx, accessToken)
+	if err != nil {
+		return err
+	}
+
+	// 吊销记录保留到令牌自然过期为止
+	ttl := s.jwtDuration
+	if claims.ExpiresAt != nil {
+		ttl = time.Until(claims.ExpiresAt.Time)
+	}
+	if claims.ID != "" && ttl > 0 {
+		if err := s.store.Set(ctx, RevokedTokenKey(claims.ID), []byte(claims.Subject), ttl); err != nil {
+			s.log.Error(ctx, "Failed to revoke access token",
+				"user_id", claims.Subject,
+				"error", err.Error(),
+				"service", "auth",
+				"action", "logout_failed")
+			return err
+		}
+		s.revocations.add(Revocation{JTI: claims.ID}, time.Now().Add(ttl))
+	}
+
+	// 令牌关联了会话时结束整个会话；旧令牌没有 sid，按请求中的刷新令牌作废其家族
+	if claims.SessionID != "" {
+		if err := s.endSession(ctx, claims.SessionID); err != nil {
+			return err
+		}
+	} else if refreshToken != "" {
+		stored, err := s.tokenRepo.FindByHash(hashToken(refreshToken))
+		if err == nil && stored.UserID == claims.Subject {
+			if err := s.tokenRepo.RevokeFamily(stored.FamilyID); err != nil {
+				return err
+			}
+		}
+	}
+
+	s.log.Info(ctx, "User logged out, token revoked",
+		"user_id", claims.Subject,
+		"jti", claims.ID,
+		"service", "auth",
+		"action", "logout")
+	s.audit.record(ctx, AuditLogout, outcomeSuccess, "user_id", claims.Subject, "jti", claims.ID, "sid", claims.SessionID)
+	return nil
+}
+
+// Revocations 实现了 AuthService 接口
+func (s *authService) Revocations(ctx context.Context) []Revocation {
+	return s.revocations.list(time.Now())
+}
+
+// checkRevoked 查询令牌或其所属会话是否已被吊销，吊销列表不可用时按吊销处理
+func (s *authService) checkRevoked(ctx context.Context, jti, sid string) error {
+	revoked, err := IsRevoked(ctx, s.store, jti)
+	if err == nil && !revoked {
+		revoked, err = IsSessionRevoked(ctx, s.store, sid)
+	}
+	if err != nil {
+		s.log.Error(ctx, "Failed to query token revocation list",
+			"error", err.Error(),
+			"service", "auth",
+			"action", "revocation_check_failed")
+		return err
+	}
+	if revoked {
+		s.log.Warn(ctx, "Revoked token presented",
+			"jti", jti,
+			"sid", sid,
+			"service", "auth",
+			"action", "token_revoked")
+		return ErrTokenRevoked
+	}
+	return nil
+}
+
+// tokenIDs 读取已校验令牌的 jti 与 sid
+func tokenIDs(token *jwt.Token) (jti, sid string) {
+	if claims, ok := token.Claims.(jwt.MapClaims); ok {
+		jti, _ = claims["jti"].(string)
+		sid, _ = claims["sid"].(string)
+	}
+	return jti, sid
+}
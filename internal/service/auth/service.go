@@ -1,100 +1,242 @@
 package auth
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
 	"time"
 
+	"gateway.example/go-gateway/internal/cache"
+	"gateway.example/go-gateway/internal/config"
 	"gateway.example/go-gateway/internal/models"
 	"gateway.example/go-gateway/internal/repository"
+	"gateway.example/go-gateway/pkg/jwks"
 	"gateway.example/go-gateway/pkg/logger"
 	"github.com/golang-jwt/jwt/v5"
 )
 
 // AuthService 定义认证服务的接口
 type AuthService interface {
-	Login(ctx context.Context, username, password string) (string, error)
+	Login(ctx context.Context, username, password string) (*TokenPair, error)
+	Refresh(ctx context.Context, refreshToken string) (*TokenPair, error)
+	Logout(ctx context.Context, accessToken, refreshToken string) error
+	Register(ctx context.Context, username, password string) (*models.User, error)
+	ClientCredentials(ctx context.Context, clientID, clientSecret, scope string) (*ClientToken, error) // 服务间调用的机器令牌
+	ChangePassword(ctx context.Context, userID, sessionID, oldPassword, newPassword string) error
 	ValidateToken(ctx context.Context, tokenString string) bool
-	ValidateTokenWithClaims(ctx context.Context, tokenString string) (*jwt.RegisteredClaims, error)
+	ValidateTokenWithClaims(ctx context.Context, tokenString string) (*Claims, error)
 	GenerateToken(ctx context.Context, user *models.User) (string, error)
+	JWKS() jwks.Set                               // 校验令牌所需的公钥集合，HS256 时为空
+	Revocations(ctx context.Context) []Revocation // 本实例吊销的、尚未过期的访问令牌与会话
+
+	// 会话管理，用户查看并远程注销自己的登录会话
+	Sessions(ctx context.Context, userID string) ([]*models.Session, error)
+	RevokeSession(ctx context.Context, userID, sessionID string) error
+	RevokeAllSessions(ctx context.Context, userID string) (int, error)
+
+	// 用户管理，供具有 admin 角色的运维人员调用
+	ListUsers(ctx context.Context) ([]*models.User, error)
+	GetUser(ctx context.Context, username string) (*models.User, error)
+	SetUserDisabled(ctx context.Context, username string, disabled bool) error
+	ResetPassword(ctx context.Context, username, newPassword string) error
+	DeleteUser(ctx context.Context, username string) error
 }
 
+// 错误定义
+var (
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	ErrUserExists         = errors.New("username already taken")
+	ErrInvalidInput       = errors.New("username and password are required")
+)
+
 // authService 是AuthService接口的具体实现
 type authService struct {
-	userRepo    repository.UserRepository
-	jwtSecret   []byte
-	jwtDuration time.Duration
-	log         logger.Logger
+	userRepo        repository.UserRepository
+	tokenRepo       repository.RefreshTokenRepository
+	sessionRepo     repository.SessionRepository
+	store           cache.Cache // 吊销列表，与网关共用同一个共享缓存时网关也能直接拒绝已吊销的令牌
+	revocations     *revocationLog
+	keys            *keyRing       // 签名与校验密钥
+	jwtDuration     time.Duration  // 访问令牌有效期
+	refreshDuration time.Duration  // 刷新令牌有效期
+	passwordCost    int            // bcrypt 成本参数
+	throttle        *loginThrottle // 登录失败限制，未开启时为 nil
+	policy          *passwordPolicy
+	clients         map[string]*serviceClient // client_credentials 的服务客户端
+	clientTokenTTL  time.Duration             // 机器令牌有效期
+	idp             IdentityProvider          // 外部身份源，使用本地用户表时为 nil
+	audit           *auditLog
+	defaultRoles    []string // 外部身份源新建账户的默认角色
+	log             logger.Logger
 }
 
 // NewAuthService 创建一个新的认证服务实例
+// jwtCfg 提供签名密钥与两种令牌的有效期，cfg 提供密码哈希等认证服务自身的配置；
+// audit 为独立的安全审计日志，为 nil 时不记录审计事件
 func NewAuthService(
 	userRepo repository.UserRepository,
-	jwtSecretKey string,
-	jwtDurationMinutes int,
+	tokenRepo repository.RefreshTokenRepository,
+	sessionRepo repository.SessionRepository,
+	store cache.Cache,
+	jwtCfg config.JWTConfig,
+	cfg config.AuthServiceConfig,
 	log logger.Logger,
+	audit logger.Logger,
 ) (AuthService, error) {
 	// 输入校验
 	if userRepo == nil {
 		return nil, errors.New("auth service: user repository cannot be nil")
 	}
-	if jwtSecretKey == "" {
-		return nil, errors.New("auth service: jwt secret key cannot be empty")
+	if tokenRepo == nil {
+		return nil, errors.New("auth service: refresh token repository cannot be nil")
+	}
+	if sessionRepo == nil {
+		return nil, errors.New("auth service: session repository cannot be nil")
+	}
+	if store == nil {
+		return nil, errors.New("auth service: revocation store cannot be nil")
+	}
+	if jwtCfg.AccessTokenTTL() <= 0 {
+		return nil, errors.New("auth service: jwt duration must be positive")
+	}
+	if jwtCfg.RefreshTokenTTL() < 0 {
+		return nil, errors.New("auth service: refresh token duration cannot be negative")
+	}
+	passwordCost, err := validateCost(cfg.BcryptCost)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := newKeyRing(jwtCfg)
+	if err != nil {
+		return nil, err
+	}
+	throttle, err := newLoginThrottle(store, cfg.LoginThrottle)
+	if err != nil {
+		return nil, err
 	}
-	if jwtDurationMinutes <= 0 {
-		return nil, errors.New("auth service: jwt duration must be a positive number")
+	policy, err := newPasswordPolicy(cfg.PasswordPolicy)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.ClientTokenTTL < 0 {
+		return nil, errors.New("auth service: client token ttl cannot be negative")
+	}
+	clients, err := newServiceClients(cfg.Clients)
+	if err != nil {
+		return nil, err
+	}
+	idp, err := newIdentityProvider(cfg.IdentityProvider)
+	if err != nil {
+		return nil, err
 	}
 
 	// 创建实例
 	service := &authService{
-		userRepo:    userRepo,
-		jwtSecret:   []byte(jwtSecretKey),
-		jwtDuration: time.Duration(jwtDurationMinutes) * time.Minute,
-		log:         log,
+		userRepo:        userRepo,
+		tokenRepo:       tokenRepo,
+		sessionRepo:     sessionRepo,
+		store:           store,
+		revocations:     newRevocationLog(),
+		keys:            keys,
+		jwtDuration:     jwtCfg.AccessTokenTTL(),
+		refreshDuration: defaultRefreshDuration,
+		passwordCost:    passwordCost,
+		throttle:        throttle,
+		policy:          policy,
+		clients:         clients,
+		clientTokenTTL:  defaultClientTokenTTL,
+		idp:             idp,
+		defaultRoles:    cfg.IdentityProvider.DefaultRoles,
+		audit:           &auditLog{log: audit},
+		log:             log,
+	}
+	if ttl := jwtCfg.RefreshTokenTTL(); ttl > 0 {
+		service.refreshDuration = ttl
+	}
+	if cfg.ClientTokenTTL > 0 {
+		service.clientTokenTTL = cfg.ClientTokenTTL
 	}
 
 	log.Info(context.Background(), "Auth service initialized successfully",
-		"jwt_duration_minutes", jwtDurationMinutes,
+		"jwt_duration", service.jwtDuration.String(),
+		"refresh_duration", service.refreshDuration.String(),
+		"algorithm", keys.current.method.Alg(),
+		"key_id", keys.current.kid,
+		"bcrypt_cost", passwordCost,
+		"login_throttle", throttle != nil,
+		"service_clients", len(clients),
+		"audit_log", audit != nil,
+		"identity_provider", cmp.Or(cfg.IdentityProvider.Type, ProviderLocal),
 		"service", "auth")
 
 	return service, nil
 }
 
-// Login 验证用户凭证并返回一个JWT
-func (s *authService) Login(ctx context.Context, username, password string) (string, error) {
+// Login 验证用户凭证并返回一个JWT。开启登录限制时，处于暂停或锁定状态的用户名/IP 返回 ThrottledError
+func (s *authService) Login(ctx context.Context, username, password string) (*TokenPair, error) {
 	s.log.Info(ctx, "User login attempt",
 		"username", username,
+		"client_ip", clientIP(ctx),
 		"service", "auth",
 		"action", "login_attempt")
 
-	user, err := s.userRepo.FindByUsername(username)
+	if s.throttle != nil {
+		if err := s.throttle.check(ctx, username, clientIP(ctx)); err != nil {
+			s.log.Warn(ctx, "Login rejected, too many failed attempts",
+				"username", username,
+				"client_ip", clientIP(ctx),
+				"retry_after", RetryAfter(err).String(),
+				"service", "auth",
+				"action", "login_throttled")
+			s.audit.record(ctx, AuditLoginFailure, outcomeFailure, "username", username, "reason", "throttled")
+			return nil, err
+		}
+	}
+
+	user, err := s.authenticate(ctx, username, password)
 	if err != nil {
-		s.log.Warn(ctx, "User not found or repository error",
+		reason := "identity_provider_unavailable"
+		if errors.Is(err, ErrInvalidCredentials) {
+			reason = "invalid_credentials"
+		}
+		s.audit.record(ctx, AuditLoginFailure, outcomeFailure, "username", username, "reason", reason)
+		if errors.Is(err, ErrInvalidCredentials) {
+			s.recordLoginFailure(ctx, username)
+		}
+		return nil, err
+	}
+	if user.Disabled {
+		s.log.Warn(ctx, "Login rejected for disabled account",
 			"username", username,
-			"error", err.Error(),
+			"user_id", user.ID,
 			"service", "auth",
 			"action", "login_failed")
-		return "", errors.New("invalid username or password")
+		s.audit.record(ctx, AuditLoginFailure, outcomeFailure, "username", username, "user_id", user.ID, "reason", "account_disabled")
+		return nil, ErrAccountDisabled
+	}
+	if s.throttle != nil {
+		s.throttle.recordSuccess(ctx, username)
 	}
 
-	// 注意：在真实项目中，这里应该用 bcrypt.CompareHashAndPassword 来比较哈希后的密码
-	if user.Password != password {
-		s.log.Warn(ctx, "Invalid password for user",
+	// 每次登录开启一个新的会话，会话 ID 同时作为刷新令牌家族 ID
+	sessionID := newTokenID()
+	if err := s.startSession(ctx, user, sessionID); err != nil {
+		s.log.Error(ctx, "Failed to create session",
 			"username", username,
+			"error", err.Error(),
 			"service", "auth",
 			"action", "login_failed")
-		return "", errors.New("invalid username or password")
+		return nil, err
 	}
-
-	token, err := s.GenerateToken(ctx, user)
+	tokens, err := s.issueTokens(ctx, user, sessionID)
 	if err != nil {
 		s.log.Error(ctx, "Failed to generate token for user",
 			"username", username,
 			"error", err.Error(),
 			"service", "auth",
 			"action", "token_generation_failed")
-		return "", err
+		return nil, err
 	}
 
 	s.log.Info(ctx, "User login successful",
@@ -102,8 +244,110 @@ func (s *authService) Login(ctx context.Context, username, password string) (str
 		"user_id", user.ID,
 		"service", "auth",
 		"action", "login_success")
+	s.audit.record(ctx, AuditLoginSuccess, outcomeSuccess, "username", username, "user_id", user.ID, "sid", sessionID)
+
+	return tokens, nil
+}
+
+// recordLoginFailure 记录登录失败，触发锁定时输出安全日志
+func (s *authService) recordLoginFailure(ctx context.Context, username string) {
+	if s.throttle == nil {
+		return
+	}
+	lockouts, err := s.throttle.recordFailure(ctx, username, clientIP(ctx))
+	if err != nil {
+		s.log.Error(ctx, "Failed to record login failure",
+			"username", username,
+			"error", err.Error(),
+			"service", "auth",
+			"action", "login_throttle_failed")
+		return
+	}
+	for _, l := range lockouts {
+		if !l.locked {
+			continue
+		}
+		s.log.Warn(ctx, "Login locked out after repeated failures",
+			"username", username,
+			"client_ip", clientIP(ctx),
+			"scope", l.scope,
+			"failures", l.failures,
+			"locked_for", l.duration.String(),
+			"service", "auth",
+			"action", "login_lockout")
+		s.audit.record(ctx, AuditLoginLockout, outcomeFailure,
+			"username", username,
+			"scope", l.scope,
+			"failures", l.failures,
+			"locked_for", l.duration.String())
+	}
+}
+
+// Register 创建新用户，密码须符合密码策略，以 bcrypt 哈希保存
+func (s *authService) Register(ctx context.Context, username, password string) (*models.User, error) {
+	if username == "" || password == "" {
+		return nil, ErrInvalidInput
+	}
+	if err := s.checkPasswordPolicy(ctx, username, password, "register_failed"); err != nil {
+		return nil, err
+	}
+
+	hash, err := hashPassword(password, s.passwordCost)
+	if err != nil {
+		s.log.Error(ctx, "Failed to hash password",
+			"username", username,
+			"error", err.Error(),
+			"service", "auth",
+			"action", "register_failed")
+		return nil, err
+	}
+
+	user := &models.User{Username: username, PasswordHash: hash}
+	if err := s.userRepo.Create(user); err != nil {
+		if errors.Is(err, repository.ErrUserExists) {
+			s.log.Warn(ctx, "Username already taken",
+				"username", username,
+				"service", "auth",
+				"action", "register_failed")
+			return nil, ErrUserExists
+		}
+		s.log.Error(ctx, "Failed to create user",
+			"username", username,
+			"error", err.Error(),
+			"service", "auth",
+			"action", "register_failed")
+		return nil, err
+	}
 
-	return token, nil
+	s.log.Info(ctx, "User registered",
+		"username", username,
+		"user_id", user.ID,
+		"service", "auth",
+		"action", "register_success")
+	s.audit.record(ctx, AuditRegister, outcomeSuccess, "username", username, "user_id", user.ID)
+	return user, nil
+}
+
+// migratePassword 在登录成功后把明文密码或成本过期的哈希替换为当前成本的 bcrypt 哈希。
+// 迁移失败不影响本次登录，下次登录时重试
+func (s *authService) migratePassword(ctx context.Context, username, password string) {
+	hash, err := hashPassword(password, s.passwordCost)
+	if err == nil {
+		err = s.userRepo.UpdatePasswordHash(username, hash)
+	}
+	if err != nil {
+		s.log.Warn(ctx, "Failed to migrate password hash",
+			"username", username,
+			"error", err.Error(),
+			"service", "auth",
+			"action", "password_migration_failed")
+		return
+	}
+	s.log.Info(ctx, "Password hash migrated",
+		"username", username,
+		"bcrypt_cost", s.passwordCost,
+		"service", "auth",
+		"action", "password_migrated")
 }
 
 // ValidateToken 验证JWT令牌的有效性
@@ -112,16 +356,8 @@ func (s *authService) ValidateToken(ctx context.Context, tokenString string) boo
 		"service", "auth",
 		"action", "token_validation_attempt")
 
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			s.log.Warn(ctx, "Unexpected signing method",
-				"method", token.Header["alg"],
-				"service", "auth",
-				"action", "token_validation_failed")
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return s.jwtSecret, nil
-	})
+	// 按 kid 选择校验密钥，算法与密钥不一致的令牌被拒绝
+	token, err := jwt.Parse(tokenString, s.keys.keyFunc)
 
 	if err != nil {
 		s.log.Warn(ctx, "Token parsing failed",
@@ -131,7 +367,8 @@ func (s *authService) ValidateToken(ctx context.Context, tokenString string) boo
 		return false
 	}
 
-	valid := token.Valid
+	jti, sid := tokenIDs(token)
+	valid := token.Valid && s.checkRevoked(ctx, jti, sid) == nil
 	if valid {
 		s.log.Debug(ctx, "Token validation successful",
 			"service", "auth",
@@ -145,23 +382,14 @@ func (s *authService) ValidateToken(ctx context.Context, tokenString string) boo
 	return valid
 }
 
-// ValidateTokenWithClaims 验证JWT令牌并返回其声明
-func (s *authService) ValidateTokenWithClaims(ctx context.Context, tokenString string) (*jwt.RegisteredClaims, error) {
+// ValidateTokenWithClaims 验证JWT令牌并返回其声明，包括角色、租户与 scope
+func (s *authService) ValidateTokenWithClaims(ctx context.Context, tokenString string) (*Claims, error) {
 	s.log.Debug(ctx, "Token validation with claims attempt",
 		"service", "auth",
 		"action", "token_claims_validation_attempt")
 
-	claims := &jwt.RegisteredClaims{}
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			s.log.Warn(ctx, "Unexpected signing method",
-				"method", token.Header["alg"],
-				"service", "auth",
-				"action", "token_claims_validation_failed")
-			return nil, errors.New("unexpected signing method")
-		}
-		return s.jwtSecret, nil
-	})
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, s.keys.keyFunc)
 
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) {
@@ -183,10 +411,14 @@ func (s *authService) ValidateTokenWithClaims(ctx context.Context, tokenString s
 			"action", "token_claims_validation_failed")
 		return nil, errors.New("token is not valid")
 	}
+	if err := s.checkRevoked(ctx, claims.ID, claims.SessionID); err != nil {
+		return nil, err
+	}
 
 	s.log.Debug(ctx, "Token validation with claims successful",
 		"subject", claims.Subject,
 		"issuer", claims.Issuer,
+		"tenant_id", claims.TenantID,
 		"service", "auth",
 		"action", "token_claims_validation_success")
 
@@ -195,20 +427,27 @@ func (s *authService) ValidateTokenWithClaims(ctx context.Context, tokenString s
 
 // GenerateToken 为用户生成JWT令牌
 func (s *authService) GenerateToken(ctx context.Context, user *models.User) (string, error) {
+	return s.generateToken(ctx, user, "")
+}
+
+// generateToken 生成访问令牌，sessionID 非空时令牌关联该会话
+func (s *authService) generateToken(ctx context.Context, user *models.User, sessionID string) (string, error) {
 	s.log.Debug(ctx, "Token generation attempt",
 		"user_id", user.ID,
 		"service", "auth",
 		"action", "token_generation_attempt")
 
-	claims := &jwt.RegisteredClaims{
+	claims := newClaims(user)
+	claims.SessionID = sessionID
+	claims.RegisteredClaims = jwt.RegisteredClaims{
 		Issuer:    "auth-service",
 		Subject:   user.ID,
 		ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.jwtDuration)),
 		IssuedAt:  jwt.NewNumericDate(time.Now()),
+		ID:        newTokenID(), // jti，用于吊销
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(s.jwtSecret)
+	tokenString, err := s.keys.sign(claims)
 	if err != nil {
 		s.log.Error(ctx, "Failed to sign token",
 			"user_id", user.ID,
@@ -226,3 +465,8 @@ func (s *authService) GenerateToken(ctx context.Context, user *models.User) (str
 
 	return tokenString, nil
 }
+
+// JWKS 实现了 AuthService 接口
+func (s *authService) JWKS() jwks.Set {
+	return s.keys.jwks()
+}
@@ -0,0 +1,118 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gateway.example/go-gateway/internal/models"
+	"gateway.example/go-gateway/internal/repository"
+)
+
+// maxUserAgentLength 会话中保存的 User-Agent 最大长度
+const maxUserAgentLength = 256
+
+// ErrSessionNotFound 会话不存在、已结束或不属于当前用户
+var ErrSessionNotFound = errors.New("session not found")
+
+// startSession 为一次登录创建会话，会话 ID 即该次登录的刷新令牌家族 ID
+func (s *authService) startSession(ctx context.Context, user *models.User, sessionID string) error {
+	ua := userAgent(ctx)
+	if len(ua) > maxUserAgentLength {
+		ua = ua[:maxUserAgentLength]
+	}
+	now := time.Now()
+	return s.sessionRepo.Create(&models.Session{
+		ID:         sessionID,
+		UserID:     user.ID,
+		Username:   user.Username,
+		UserAgent:  ua,
+		IP:         clientIP(ctx),
+		CreatedAt:  now,
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(s.refreshDuration),
+	})
+}
+
+// Sessions 返回用户当前的登录会话
+func (s *authService) Sessions(ctx context.Context, userID string) ([]*models.Session, error) {
+	return s.sessionRepo.ListByUser(userID)
+}
+
+// RevokeSession 结束用户的一个会话 (远程注销某台设备)
+func (s *authService) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	session, err := s.sessionRepo.FindByID(sessionID)
+	if err != nil || session.UserID != userID {
+		return ErrSessionNotFound
+	}
+	if err := s.endSession(ctx, sessionID); err != nil {
+		return err
+	}
+	s.log.Info(ctx, "Session revoked",
+		"user_id", userID,
+		"sid", sessionID,
+		"service", "auth",
+		"action", "session_revoked")
+	s.audit.record(ctx, AuditSessionRevoked, outcomeSuccess, "user_id", userID, "sid", sessionID)
+	return nil
+}
+
+// RevokeAllSessions 结束用户的全部会话 (注销所有设备)，返回结束的会话数
+func (s *authService) RevokeAllSessions(ctx context.Context, userID string) (int, error) {
+	n, err := s.endUserSessions(ctx, userID, "")
+	if err != nil {
+		return n, err
+	}
+	s.log.Info(ctx, "All sessions revoked",
+		"user_id", userID,
+		"sessions", n,
+		"service", "auth",
+		"action", "sessions_revoked")
+	s.audit.record(ctx, AuditSessionsRevoked, outcomeSuccess, "user_id", userID, "sessions", n)
+	return n, nil
+}
+
+// endSession 删除会话并作废其刷新令牌，同时把会话写入吊销列表，使其已签发的访问令牌立即失效。
+// 吊销记录保留一个访问令牌有效期，此后该会话签发的访问令牌都已过期
+func (s *authService) endSession(ctx context.Context, sessionID string) error {
+	if err := s.tokenRepo.RevokeFamily(sessionID); err != nil {
+		return err
+	}
+	if err := s.sessionRepo.Delete(sessionID); err != nil && !errors.Is(err, repository.ErrSessionNotFound) {
+		return err
+	}
+	if err := s.store.Set(ctx, RevokedSessionKey(sessionID), []byte("1"), s.jwtDuration); err != nil {
+		s.log.Error(ctx, "Failed to revoke session",
+			"sid", sessionID,
+			"error", err.Error(),
+			"service", "auth",
+			"action", "session_revoke_failed")
+		return err
+	}
+	s.revocations.add(Revocation{SessionID: sessionID}, time.Now().Add(s.jwtDuration))
+	return nil
+}
+
+// endUserSessions 结束用户除 keepSessionID 之外的全部会话，并作废不属于任何会话的刷新令牌
+func (s *authService) endUserSessions(ctx context.Context, userID, keepSessionID string) (int, error) {
+	sessions, err := s.sessionRepo.ListByUser(userID)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, session := range sessions {
+		if session.ID == keepSessionID {
+			continue
+		}
+		if err := s.endSession(ctx, session.ID); err != nil {
+			return n, err
+		}
+		n++
+	}
+	if keepSessionID == "" {
+		if err := s.tokenRepo.RevokeUser(userID); err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,194 @@
+package auth
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"gateway.example/go-gateway/internal/cache"
+	"gateway.example/go-gateway/internal/config"
+)
+
+// loginThrottlePrefix 登录失败计数与锁定记录在共享缓存中的键前缀
+const loginThrottlePrefix = "auth:login:"
+
+// 登录限制的默认参数
+const (
+	defaultMaxFailures     = 5
+	defaultMaxIPFailures   = 20
+	defaultFailureWindow   = 15 * time.Minute
+	defaultBaseDelay       = time.Second
+	defaultLockoutDuration = 15 * time.Minute
+)
+
+// ErrLoginThrottled 登录失败次数过多，暂时不允许登录
+var ErrLoginThrottled = errors.New("too many failed login attempts")
+
+// ThrottledError 在 ErrLoginThrottled 的基础上携带可以重试的等待时间
+type ThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *ThrottledError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", ErrLoginThrottled, e.RetryAfter.Round(time.Second))
+}
+
+func (e *ThrottledError) Unwrap() error { return ErrLoginThrottled }
+
+// RetryAfter 返回被限制的登录请求需要等待的时间，err 不是 ThrottledError 时返回 0
+func RetryAfter(err error) time.Duration {
+	var te *ThrottledError
+	if errors.As(err, &te) {
+		return te.RetryAfter
+	}
+	return 0
+}
+
+// loginThrottle 按用户名与客户端 IP 统计登录失败次数。
+// 同一用户名每次失败后按指数退避暂停登录，达到 max_failures 次后锁定 lockout_duration；
+// 同一 IP 达到 max_ip_failures 次后锁定，用于抵御撞库。计数保存在共享缓存中，多个认证服务实例共用
+type loginThrottle struct {
+	store         cache.Cache
+	mu            sync.Mutex // 缓存后端不支持原子计数时的读改写锁
+	maxFailures   int
+	maxIPFailures int
+	window        time.Duration
+	baseDelay     time.Duration
+	lockout       time.Duration
+}
+
+// newLoginThrottle 按配置创建登录限制，未开启时返回 nil
+func newLoginThrottle(store cache.Cache, cfg config.LoginThrottleConfig) (*loginThrottle, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	if cfg.MaxFailures < 0 || cfg.MaxIPFailures < 0 || cfg.Window < 0 || cfg.BaseDelay < 0 || cfg.LockoutDuration < 0 {
+		return nil, errors.New("auth service: login_throttle values cannot be negative")
+	}
+	t := &loginThrottle{
+		store:         store,
+		maxFailures:   cmp.Or(cfg.MaxFailures, defaultMaxFailures),
+		maxIPFailures: cmp.Or(cfg.MaxIPFailures, defaultMaxIPFailures),
+		window:        cmp.Or(cfg.Window, defaultFailureWindow),
+		baseDelay:     cmp.Or(cfg.BaseDelay, defaultBaseDelay),
+		lockout:       cmp.Or(cfg.LockoutDuration, defaultLockoutDuration),
+	}
+	return t, nil
+}
+
+func failureKey(scope, id string) string { return loginThrottlePrefix + "fail:" + scope + ":" + id }
+func lockKey(scope, id string) string    { return loginThrottlePrefix + "lock:" + scope + ":" + id }
+
+// check 返回用户名或 IP 仍处于暂停/锁定状态时的 ThrottledError。缓存不可用时放行，避免缓存故障导致无法登录
+func (t *loginThrottle) check(ctx context.Context, username, ip string) error {
+	keys := []string{lockKey("user", username)}
+	if ip != "" {
+		keys = append(keys, lockKey("ip", ip))
+	}
+	var wait time.Duration
+	for _, key := range keys {
+		value, err := t.store.Get(ctx, key)
+		if err != nil {
+			continue
+		}
+		until, err := strconv.ParseInt(string(value), 10, 64)
+		if err != nil {
+			continue
+		}
+		wait = max(wait, time.Until(time.UnixMilli(until)))
+	}
+	if wait > 0 {
+		return &ThrottledError{RetryAfter: wait}
+	}
+	return nil
+}
+
+// lockout 是一次登录失败后产生的暂停或锁定
+type lockout struct {
+	scope    string // user / ip
+	failures int64
+	duration time.Duration
+	locked   bool // 达到失败上限 (锁定)，否则为指数退避
+}
+
+// recordFailure 记录一次登录失败，返回因此产生的暂停或锁定
+func (t *loginThrottle) recordFailure(ctx context.Context, username, ip string) ([]lockout, error) {
+	var result []lockout
+	n, err := t.incr(ctx, failureKey("user", username))
+	if err != nil {
+		return nil, err
+	}
+	l := lockout{scope: "user", failures: n, duration: t.backoff(n)}
+	if n >= int64(t.maxFailures) {
+		l.duration, l.locked = t.lockout, true
+	}
+	if err := t.lock(ctx, lockKey("user", username), l.duration); err != nil {
+		return nil, err
+	}
+	result = append(result, l)
+
+	if ip != "" {
+		n, err := t.incr(ctx, failureKey("ip", ip))
+		if err != nil {
+			return nil, err
+		}
+		if n >= int64(t.maxIPFailures) {
+			if err := t.lock(ctx, lockKey("ip", ip), t.lockout); err != nil {
+				return nil, err
+			}
+			result = append(result, lockout{scope: "ip", failures: n, duration: t.lockout, locked: true})
+		}
+	}
+	return result, nil
+}
+
+// recordSuccess 登录成功后清除该用户名的失败计数；IP 计数保留到窗口结束，避免撞库时夹杂的成功登录重置计数
+func (t *loginThrottle) recordSuccess(ctx context.Context, username string) {
+	_ = t.store.Delete(ctx, failureKey("user", username))
+	_ = t.store.Delete(ctx, lockKey("user", username))
+}
+
+// backoff 第 n 次失败后的暂停时间: base_delay * 2^(n-1)，不超过锁定时长
+func (t *loginThrottle) backoff(n int64) time.Duration {
+	d := t.baseDelay
+	for i := int64(1); i < n && d < t.lockout; i++ {
+		d *= 2
+	}
+	return min(d, t.lockout)
+}
+
+// lock 写入锁定记录，值为解锁时间 (Unix 毫秒)，用于计算 Retry-After
+func (t *loginThrottle) lock(ctx context.Context, key string, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	until := time.Now().Add(d).UnixMilli()
+	return t.store.Set(ctx, key, []byte(strconv.FormatInt(until, 10)), d)
+}
+
+// incr 优先使用缓存后端的原子计数，不支持时在进程内加锁读改写
+func (t *loginThrottle) incr(ctx context.Context, key string) (int64, error) {
+	if counter, ok := t.store.(cache.Counter); ok {
+		return counter.Incr(ctx, key, 1, t.window)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var n int64
+	value, err := t.store.Get(ctx, key)
+	switch {
+	case errors.Is(err, cache.ErrNotFound):
+	case err != nil:
+		return 0, err
+	default:
+		if n, err = cache.ParseCount(value); err != nil {
+			return 0, fmt.Errorf("login failure counter '%s' is invalid: %w", key, err)
+		}
+	}
+	n++
+	return n, t.store.Set(ctx, key, []byte(strconv.FormatInt(n, 10)), t.window)
+}
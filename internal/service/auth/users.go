@@ -0,0 +1,203 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"gateway.example/go-gateway/internal/models"
+	"gateway.example/go-gateway/internal/repository"
+)
+
+// RoleAdmin 可以调用用户管理接口的角色
+const RoleAdmin = "admin"
+
+// 用户管理错误定义
+var (
+	ErrUserNotFound    = errors.New("user not found")
+	ErrAccountDisabled = errors.New("account disabled")
+)
+
+// ListUsers 返回全部用户
+func (s *authService) ListUsers(ctx context.Context) ([]*models.User, error) {
+	users, err := s.userRepo.List()
+	if err != nil {
+		s.log.Error(ctx, "Failed to list users",
+			"error", err.Error(),
+			"service", "auth",
+			"action", "list_users_failed")
+		return nil, err
+	}
+	return users, nil
+}
+
+// GetUser 按用户名查找用户
+func (s *authService) GetUser(ctx context.Context, username string) (*models.User, error) {
+	user, err := s.userRepo.FindByUsername(username)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return nil, ErrUserNotFound
+	}
+	return user, err
+}
+
+// SetUserDisabled 停用或启用账户。停用时结束该用户的全部会话，已签发的访问令牌随之失效
+func (s *authService) SetUserDisabled(ctx context.Context, username string, disabled bool) error {
+	user, err := s.GetUser(ctx, username)
+	if err != nil {
+		return err
+	}
+	if err := s.userRepo.SetDisabled(username, disabled); err != nil {
+		return s.userUpdateFailed(ctx, username, "set_user_disabled_failed", err)
+	}
+	if disabled {
+		if _, err := s.endUserSessions(ctx, user.ID, ""); err != nil {
+			return s.userUpdateFailed(ctx, username, "set_user_disabled_failed", err)
+		}
+	}
+
+	action := AuditUserEnabled
+	if disabled {
+		action = AuditUserDisabled
+	}
+	s.log.Info(ctx, "User account status changed",
+		"username", username,
+		"user_id", user.ID,
+		"disabled", disabled,
+		"service", "auth",
+		"action", action)
+	s.audit.record(ctx, action, outcomeSuccess, "username", username, "user_id", user.ID)
+	return nil
+}
+
+// ResetPassword 由管理员为用户设置新密码，并结束该用户的全部会话，所有设备需要用新密码重新登录
+func (s *authService) ResetPassword(ctx context.Context, username, newPassword string) error {
+	if newPassword == "" {
+		return ErrInvalidInput
+	}
+	user, err := s.GetUser(ctx, username)
+	if err != nil {
+		return err
+	}
+	if user.Provider != "" {
+		return ErrExternalAccount
+	}
+	if err := s.checkPasswordPolicy(ctx, username, newPassword, "password_reset_failed"); err != nil {
+		return err
+	}
+	hash, err := hashPassword(newPassword, s.passwordCost)
+	if err != nil {
+		return s.userUpdateFailed(ctx, username, "password_reset_failed", err)
+	}
+	if err := s.userRepo.UpdatePasswordHash(username, hash); err != nil {
+		return s.userUpdateFailed(ctx, username, "password_reset_failed", err)
+	}
+	if _, err := s.endUserSessions(ctx, user.ID, ""); err != nil {
+		return s.userUpdateFailed(ctx, username, "password_reset_failed", err)
+	}
+
+	s.log.Info(ctx, "User password reset",
+		"username", username,
+		"user_id", user.ID,
+		"service", "auth",
+		"action", "password_reset")
+	s.audit.record(ctx, AuditPasswordReset, outcomeSuccess, "username", username, "user_id", user.ID)
+	return nil
+}
+
+// DeleteUser 删除用户并结束其全部会话
+func (s *authService) DeleteUser(ctx context.Context, username string) error {
+	user, err := s.GetUser(ctx, username)
+	if err != nil {
+		return err
+	}
+	if err := s.userRepo.Delete(username); err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return ErrUserNotFound
+		}
+		return s.userUpdateFailed(ctx, username, "delete_user_failed", err)
+	}
+	if _, err := s.endUserSessions(ctx, user.ID, ""); err != nil {
+		return s.userUpdateFailed(ctx, username, "delete_user_failed", err)
+	}
+
+	s.log.Info(ctx, "User deleted",
+		"username", username,
+		"user_id", user.ID,
+		"service", "auth",
+		"action", "user_deleted")
+	s.audit.record(ctx, AuditUserDeleted, outcomeSuccess, "username", username, "user_id", user.ID)
+	return nil
+}
+
+// ChangePassword 由用户本人修改密码，需要提供当前密码。修改成功后结束除 sessionID (发起修改的会话) 之外的全部会话，
+// 其他设备需要重新登录
+func (s *authService) ChangePassword(ctx context.Context, userID, sessionID, oldPassword, newPassword string) error {
+	if oldPassword == "" || newPassword == "" {
+		return ErrInvalidInput
+	}
+	user, err := s.userRepo.FindByID(userID)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return ErrUserNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if user.Provider != "" {
+		return ErrExternalAccount
+	}
+	if ok, _ := verifyPassword(user.PasswordHash, oldPassword, s.passwordCost); !ok {
+		s.log.Warn(ctx, "Password change rejected, current password is wrong",
+			"user_id", userID,
+			"service", "auth",
+			"action", "password_change_failed")
+		s.audit.record(ctx, AuditPasswordChange, outcomeFailure, "user_id", userID, "reason", "invalid_current_password")
+		return ErrInvalidCredentials
+	}
+	if err := s.checkPasswordPolicy(ctx, user.Username, newPassword, "password_change_failed"); err != nil {
+		return err
+	}
+
+	hash, err := hashPassword(newPassword, s.passwordCost)
+	if err != nil {
+		return s.userUpdateFailed(ctx, user.Username, "password_change_failed", err)
+	}
+	if err := s.userRepo.UpdatePasswordHash(user.Username, hash); err != nil {
+		return s.userUpdateFailed(ctx, user.Username, "password_change_failed", err)
+	}
+	if _, err := s.endUserSessions(ctx, user.ID, sessionID); err != nil {
+		return s.userUpdateFailed(ctx, user.Username, "password_change_failed", err)
+	}
+
+	s.log.Info(ctx, "User changed password",
+		"username", user.Username,
+		"user_id", user.ID,
+		"service", "auth",
+		"action", "password_changed")
+	s.audit.record(ctx, AuditPasswordChange, outcomeSuccess, "username", user.Username, "user_id", user.ID)
+	return nil
+}
+
+// checkPasswordPolicy 校验新密码是否符合密码策略，不符合时记录原因并返回 PolicyError
+func (s *authService) checkPasswordPolicy(ctx context.Context, username, password, action string) error {
+	err := s.policy.validate(ctx, username, password)
+	if err != nil {
+		s.log.Warn(ctx, "Password rejected by password policy",
+			"username", username,
+			"reason", err.Error(),
+			"service", "auth",
+			"action", action)
+	}
+	return err
+}
+
+// userUpdateFailed 记录用户管理操作失败，用户在操作期间被删除时返回 ErrUserNotFound
+func (s *authService) userUpdateFailed(ctx context.Context, username, action string, err error) error {
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return ErrUserNotFound
+	}
+	s.log.Error(ctx, "User management operation failed",
+		"username", username,
+		"error", err.Error(),
+		"service", "auth",
+		"action", action)
+	return err
+}
@@ -0,0 +1,72 @@
+package circuitbreaker
+
+import (
+	"context"
+	"time"
+)
+
+// eventBufferSize 等待分发的状态变化事件上限，订阅者处理过慢时丢弃新事件
+const eventBufferSize = 256
+
+// StateChange 熔断器状态变化事件
+type StateChange struct {
+	ServiceName string    `json:"service_name"`       // 服务名
+	Instance    string    `json:"instance,omitempty"` // 实例地址，仅实例级熔断器
+	From        string    `json:"from"`               // 变化前的状态
+	To          string    `json:"to"`                 // 变化后的状态
+	Time        time.Time `json:"time"`               // 发生时间
+}
+
+// OnStateChange 实现了 Service 接口。
+// 回调在独立的协程中按事件发生顺序依次调用，不会阻塞请求处理，回调中不应长时间阻塞。
+func (s *service) OnStateChange(fn func(StateChange)) {
+	s.listenersMu.Lock()
+	defer s.listenersMu.Unlock()
+	s.listeners = append(s.listeners, fn)
+}
+
+// emit 更新状态指标，并把熔断器从 from 变化到当前状态的事件放入分发队列，调用方需持有 cb.mu
+func (s *service) emit(cb *CircuitBreaker, from State, now time.Time) {
+	if from == cb.state {
+		return
+	}
+	breakerState.WithLabelValues(cb.service, cb.instance).Set(float64(cb.state))
+	breakerTransitionTime.WithLabelValues(cb.service, cb.instance).Set(float64(now.UnixMilli()) / 1000)
+	if cb.state == StateOpen {
+		breakerTrips.WithLabelValues(cb.service, cb.instance).Inc()
+	}
+	ev := StateChange{
+		ServiceName: cb.service,
+		Instance:    cb.instance,
+		From:        from.GetState(),
+		To:          cb.state.GetState(),
+		Time:        now,
+	}
+	select {
+	case s.events <- ev:
+	default:
+		s.log.Warn(context.Background(), "Circuit breaker event queue is full, dropping event",
+			"service_name", ev.ServiceName,
+			"instance", ev.Instance,
+			"to", ev.To,
+			"service", "circuitbreaker",
+			"action", "event_dropped")
+	}
+}
+
+// dispatchEvents 把状态变化事件依次交给订阅者，直到服务关闭
+func (s *service) dispatchEvents() {
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case ev := <-s.events:
+			s.listenersMu.RLock()
+			listeners := s.listeners
+			s.listenersMu.RUnlock()
+			for _, fn := range listeners {
+				fn(ev)
+			}
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package circuitbreaker
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ForceOpen 实现了 Service 接口。
+// 强制打开期间拒绝所有请求，d 为 0 时持续到 ForceClose 或 Reset；instanceURL 非空时作用于实例级熔断器
+func (s *service) ForceOpen(ctx context.Context, serviceName, instanceURL string, d time.Duration) error {
+	return s.force(ctx, serviceName, instanceURL, StateOpen, d)
+}
+
+// ForceClose 实现了 Service 接口。
+// 强制关闭期间放行所有请求且失败不会触发熔断，d 为 0 时持续到 Reset
+func (s *service) ForceClose(ctx context.Context, serviceName, instanceURL string, d time.Duration) error {
+	return s.force(ctx, serviceName, instanceURL, StateClosed, d)
+}
+
+func (s *service) force(ctx context.Context, serviceName, instanceURL string, state State, d time.Duration) error {
+	key := serviceName
+	if instanceURL != "" {
+		if !s.settingsFor(serviceName).perInstance {
+			return fmt.Errorf("服务 '%s' 未开启 per_instance，无法操作实例级熔断器", serviceName)
+		}
+		key = InstanceKey(serviceName, instanceURL)
+	}
+	cb := s.getOrCreate(ctx, key, serviceName, instanceURL)
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	now := time.Now()
+	from := cb.state
+	cb.state = state
+	cb.failureCount = 0
+	cb.successCount = 0
+	cb.probes = 0
+	if cb.window != nil {
+		cb.window.reset()
+	}
+	if state == StateOpen {
+		cb.lastOpenTime = now
+	}
+	cb.forced = true
+	cb.forcedUntil = time.Time{}
+	if d > 0 {
+		cb.forcedUntil = now.Add(d)
+	}
+	s.emit(cb, from, now)
+
+	s.log.Warn(ctx, "Circuit breaker forced",
+		"service_name", key,
+		"state", state.GetState(),
+		"duration", d.String(),
+		"service", "circuitbreaker",
+		"action", "force")
+	return nil
+}
+
+// isForced 判断熔断器是否处于强制状态，到期后自动解除，调用方需持有 cb.mu
+func (cb *CircuitBreaker) isForced(now time.Time) bool {
+	if cb.forced && !cb.forcedUntil.IsZero() && !now.Before(cb.forcedUntil) {
+		cb.forced = false
+	}
+	return cb.forced
+}
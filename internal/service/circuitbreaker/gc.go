@@ -0,0 +1,55 @@
+package circuitbreaker
+
+import "time"
+
+// 闲置熔断器回收的默认参数
+const (
+	defaultIdleTTL   = time.Hour
+	maxSweepInterval = time.Minute
+)
+
+// sweepIdle 定期回收闲置的熔断器，直到服务关闭。
+// 服务下线、实例更换后对应的熔断器不会再被使用，不回收的话 circuitBreakers 会一直增长
+func (s *service) sweepIdle() {
+	ticker := time.NewTicker(max(min(s.idleTTL/2, maxSweepInterval), time.Second))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.removeIdle(now)
+		}
+	}
+}
+
+// removeIdle 删除超过 idle_ttl 未被使用的熔断器。
+// 只回收处于关闭状态的熔断器: 打开、半开、强制或健康检查判定不可用的熔断器仍在保护上游，需要保留
+func (s *service) removeIdle(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := 0
+	for key, cb := range s.circuitBreakers {
+		cb.mu.Lock()
+		idle := cb.state == StateClosed && !cb.isForced(now) && !cb.unhealthy &&
+			now.Sub(cb.lastUsed) > s.idleTTL
+		cb.mu.Unlock()
+		if !idle {
+			continue
+		}
+		delete(s.circuitBreakers, key)
+		breakerState.DeleteLabelValues(cb.service, cb.instance)
+		breakerTransitionTime.DeleteLabelValues(cb.service, cb.instance)
+		removed++
+	}
+
+	if removed > 0 {
+		s.log.Info(s.ctx, "Removed idle circuit breakers",
+			"removed", removed,
+			"remaining", len(s.circuitBreakers),
+			"idle_ttl", s.idleTTL.String(),
+			"service", "circuitbreaker",
+			"action", "gc")
+	}
+}
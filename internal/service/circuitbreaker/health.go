@@ -0,0 +1,58 @@
+package circuitbreaker
+
+import (
+	"context"
+	"time"
+)
+
+// SetServiceHealth 实现了 Service 接口。
+// 服务所有实例都不健康时立即打开服务级熔断器 (不存在则创建)，并在恢复健康前一直保持打开；
+// 恢复健康后进入半开状态，试探请求成功 success_threshold 次后才关闭。强制状态期间只记录健康状态
+func (s *service) SetServiceHealth(ctx context.Context, serviceName string, healthy bool) {
+	if !s.settingsFor(serviceName).healthSignals {
+		return
+	}
+
+	var cb *CircuitBreaker
+	if healthy {
+		s.mu.RLock()
+		cb = s.circuitBreakers[serviceName]
+		s.mu.RUnlock()
+		if cb == nil {
+			return
+		}
+	} else {
+		cb = s.getOrCreate(ctx, serviceName, serviceName, "")
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	now := time.Now()
+	cb.unhealthy = !healthy
+	if cb.isForced(now) {
+		return
+	}
+
+	from := cb.state
+	switch {
+	case !healthy && cb.state != StateOpen:
+		cb.state = StateOpen
+		cb.lastOpenTime = now
+	case healthy && cb.state == StateOpen:
+		cb.state = StateHalfOpen
+		cb.probes = 0
+	default:
+		return
+	}
+	cb.failureCount = 0
+	cb.successCount = 0
+	s.emit(cb, from, now)
+
+	s.log.Warn(ctx, "Circuit breaker state transition",
+		"service_name", serviceName,
+		"old_state", from.GetState(),
+		"new_state", cb.state.GetState(),
+		"reason", "health_check",
+		"service", "circuitbreaker",
+		"action", "state_transition")
+}
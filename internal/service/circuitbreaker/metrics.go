@@ -0,0 +1,24 @@
+package circuitbreaker
+
+import "gateway.example/go-gateway/pkg/metrics"
+
+var (
+	breakerState = metrics.NewGaugeVec("gateway_circuit_breaker_state",
+		"熔断器当前状态: 0 关闭 / 1 打开 / 2 半开，instance 为空表示服务级熔断器",
+		"service", "instance")
+	breakerTrips = metrics.NewCounterVec("gateway_circuit_breaker_trips_total",
+		"熔断器进入打开状态的次数",
+		"service", "instance")
+	breakerRejected = metrics.NewCounterVec("gateway_circuit_breaker_rejected_total",
+		"被熔断器拒绝的请求数",
+		"service", "instance")
+	breakerTransitionTime = metrics.NewGaugeVec("gateway_circuit_breaker_last_transition_timestamp_seconds",
+		"熔断器最近一次状态变化的时间 (Unix 秒)",
+		"service", "instance")
+	bulkheadInFlight = metrics.NewGaugeVec("gateway_circuit_breaker_bulkhead_in_flight",
+		"舱壁中当前在途的请求数",
+		"service")
+	bulkheadRejected = metrics.NewCounterVec("gateway_circuit_breaker_bulkhead_rejected_total",
+		"舱壁名额已满被拒绝的请求数",
+		"service")
+)
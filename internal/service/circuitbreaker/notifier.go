@@ -0,0 +1,105 @@
+package circuitbreaker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+
+	"gateway.example/go-gateway/internal/config"
+	"gateway.example/go-gateway/pkg/logger"
+)
+
+// 通知格式
+const (
+	NotifyFormatJSON  = "json"
+	NotifyFormatSlack = "slack"
+)
+
+// defaultNotifyTimeout 未配置 timeout 时单次通知的超时
+const defaultNotifyTimeout = 5 * time.Second
+
+// webhookNotifier 把状态变化事件推送到 webhook (如 Slack Incoming Webhook)
+type webhookNotifier struct {
+	cfg    config.CircuitBreakerNotifyConfig
+	client *http.Client
+	log    logger.Logger
+}
+
+func newWebhookNotifier(cfg config.CircuitBreakerNotifyConfig, log logger.Logger) (*webhookNotifier, error) {
+	if u, err := url.Parse(cfg.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("url '%s' 不是有效的 http(s) 地址", cfg.URL)
+	}
+	if cfg.Format == "" {
+		cfg.Format = NotifyFormatJSON
+	}
+	if cfg.Format != NotifyFormatJSON && cfg.Format != NotifyFormatSlack {
+		return nil, fmt.Errorf("不支持的 format '%s' (可选: %s, %s)", cfg.Format, NotifyFormatJSON, NotifyFormatSlack)
+	}
+	for _, state := range cfg.States {
+		if state != StateOpen.GetState() && state != StateHalfOpen.GetState() && state != StateClosed.GetState() {
+			return nil, fmt.Errorf("states 中的 '%s' 不是有效的状态", state)
+		}
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultNotifyTimeout
+	}
+	return &webhookNotifier{cfg: cfg, client: &http.Client{Timeout: cfg.Timeout}, log: log}, nil
+}
+
+// notify 推送一个事件，失败只记录日志
+func (n *webhookNotifier) notify(ev StateChange) {
+	if len(n.cfg.States) > 0 && !slices.Contains(n.cfg.States, ev.To) {
+		return
+	}
+
+	var payload any = ev
+	if n.cfg.Format == NotifyFormatSlack {
+		target := ev.ServiceName
+		if ev.Instance != "" {
+			target += " (" + ev.Instance + ")"
+		}
+		payload = map[string]string{
+			"text": fmt.Sprintf("熔断器状态变化: %s 从 %s 变为 %s (%s)", target, ev.From, ev.To, ev.Time.Format(time.RFC3339)),
+		}
+	}
+
+	ctx := context.Background()
+	if err := n.post(ctx, payload); err != nil {
+		n.log.Warn(ctx, "Failed to send circuit breaker notification",
+			"service_name", ev.ServiceName,
+			"instance", ev.Instance,
+			"to", ev.To,
+			"error", err.Error(),
+			"service", "circuitbreaker",
+			"action", "notify_failed")
+	}
+}
+
+func (n *webhookNotifier) post(ctx context.Context, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range n.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook 返回状态码 %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package circuitbreaker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gateway.example/go-gateway/internal/cache"
+	"gateway.example/go-gateway/internal/config"
+)
+
+// 持久化后端
+const (
+	PersistFile  = "file"  // 写入本地状态文件
+	PersistCache = "cache" // 写入共享缓存 (Redis 后端时多个副本共享)
+)
+
+const (
+	// persistCacheKey cache 后端保存状态使用的键
+	persistCacheKey = "circuitbreaker:state"
+	// defaultPersistTTL 未配置 ttl 时保存的状态的有效期
+	defaultPersistTTL = 10 * time.Minute
+)
+
+// persistedBreaker 是一个未关闭熔断器的持久化记录
+type persistedBreaker struct {
+	Service  string    `json:"service"`
+	Instance string    `json:"instance,omitempty"`
+	OpenedAt time.Time `json:"opened_at"`
+}
+
+// stateStore 保存所有未关闭熔断器的快照
+type stateStore interface {
+	load(ctx context.Context) ([]byte, error) // 没有快照时返回 nil, nil
+	save(ctx context.Context, data []byte) error
+}
+
+// fileStore 把快照写入本地文件
+type fileStore struct {
+	path string
+}
+
+func (f fileStore) load(ctx context.Context) ([]byte, error) {
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return data, err
+}
+
+// save 先写临时文件再重命名，避免进程退出时留下不完整的文件
+func (f fileStore) save(ctx context.Context, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path)
+}
+
+// cacheStore 把快照写入共享缓存
+type cacheStore struct {
+	store cache.Cache
+	ttl   time.Duration
+}
+
+func (c cacheStore) load(ctx context.Context) ([]byte, error) {
+	data, err := c.store.Get(ctx, persistCacheKey)
+	if errors.Is(err, cache.ErrNotFound) {
+		return nil, nil
+	}
+	return data, err
+}
+
+func (c cacheStore) save(ctx context.Context, data []byte) error {
+	return c.store.Set(ctx, persistCacheKey, data, c.ttl)
+}
+
+// newStateStore 按配置创建持久化后端
+func newStateStore(cfg config.CircuitBreakerPersistConfig, store cache.Cache) (stateStore, time.Duration, error) {
+	ttl := cfg.TTL
+	if ttl <= 0 {
+		ttl = defaultPersistTTL
+	}
+	switch cfg.Backend {
+	case PersistFile:
+		if cfg.Path == "" {
+			return nil, 0, fmt.Errorf("backend 为 file 时需要配置 path")
+		}
+		return fileStore{path: cfg.Path}, ttl, nil
+	case PersistCache:
+		if store == nil {
+			return nil, 0, fmt.Errorf("backend 为 cache 时需要共享缓存")
+		}
+		return cacheStore{store: store, ttl: ttl}, ttl, nil
+	default:
+		return nil, 0, fmt.Errorf("不支持的 backend '%s' (可选: %s, %s)", cfg.Backend, PersistFile, PersistCache)
+	}
+}
+
+// saveState 保存所有未关闭熔断器的快照，失败只记录日志
+func (s *service) saveState(ctx context.Context) {
+	snapshot := make(map[string]persistedBreaker)
+	s.mu.RLock()
+	for key, cb := range s.circuitBreakers {
+		cb.mu.Lock()
+		if cb.state != StateClosed {
+			snapshot[key] = persistedBreaker{Service: cb.service, Instance: cb.instance, OpenedAt: cb.lastOpenTime}
+		}
+		cb.mu.Unlock()
+	}
+	s.mu.RUnlock()
+
+	data, err := json.Marshal(snapshot)
+	if err == nil {
+		err = s.persist.save(ctx, data)
+	}
+	if err != nil {
+		s.log.Warn(ctx, "Failed to persist circuit breaker state",
+			"error", err.Error(),
+			"service", "circuitbreaker",
+			"action", "persist_failed")
+	}
+}
+
+// restoreState 恢复上次保存的、仍在有效期内的熔断器，恢复后处于打开状态并沿用原来的打开时间
+func (s *service) restoreState(ctx context.Context) {
+	data, err := s.persist.load(ctx)
+	if err != nil {
+		s.log.Warn(ctx, "Failed to load persisted circuit breaker state",
+			"error", err.Error(),
+			"service", "circuitbreaker",
+			"action", "restore_failed")
+		return
+	}
+	if data == nil {
+		return
+	}
+	var snapshot map[string]persistedBreaker
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		s.log.Warn(ctx, "Ignoring malformed persisted circuit breaker state",
+			"error", err.Error(),
+			"service", "circuitbreaker",
+			"action", "restore_failed")
+		return
+	}
+
+	now := time.Now()
+	restored := 0
+	for key, pb := range snapshot {
+		if pb.Service == "" || now.Sub(pb.OpenedAt) > s.persistTTL {
+			continue
+		}
+		cb := newCircuitBreaker(s.settingsFor(pb.Service))
+		cb.service, cb.instance = pb.Service, pb.Instance
+		cb.state = StateOpen
+		cb.lastOpenTime = pb.OpenedAt
+		s.circuitBreakers[key] = cb
+		breakerState.WithLabelValues(cb.service, cb.instance).Set(float64(StateOpen))
+		restored++
+	}
+	s.log.Info(ctx, "Restored persisted circuit breaker state",
+		"restored", restored,
+		"service", "circuitbreaker",
+		"action", "restore")
+}
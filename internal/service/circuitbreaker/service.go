@@ -3,9 +3,12 @@ package circuitbreaker
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync"
 	"time"
 
+	"gateway.example/go-gateway/internal/cache"
+	"gateway.example/go-gateway/internal/config"
 	"gateway.example/go-gateway/pkg/logger"
 )
 
@@ -16,6 +19,31 @@ var (
 	ErrServiceNotFound = errors.New("service not found in circuit breaker") // 服务未找到
 )
 
+// halfOpenRetryAfter 半开状态下试探请求已满时建议的重试间隔，试探结果通常很快返回
+const halfOpenRetryAfter = time.Second
+
+// OpenError 熔断器拒绝请求时返回的错误，errors.Is(err, ErrOpenState) 成立
+type OpenError struct {
+	RetryAfter time.Duration // 预计可以重试的剩余时间，0 表示无法预估
+}
+
+func (e *OpenError) Error() string {
+	return ErrOpenState.Error()
+}
+
+func (e *OpenError) Unwrap() error {
+	return ErrOpenState
+}
+
+// RetryAfter 返回熔断器拒绝请求时预计可以重试的剩余时间，err 不是 *OpenError 或无法预估时返回 0
+func RetryAfter(err error) time.Duration {
+	var openErr *OpenError
+	if errors.As(err, &openErr) {
+		return openErr.RetryAfter
+	}
+	return 0
+}
+
 // State 熔断器状态枚举
 type State int
 
@@ -39,75 +67,307 @@ func (s State) GetState() string {
 	}
 }
 
+// 熔断触发模式
+const (
+	ModeConsecutive = "consecutive" // 连续失败次数达到阈值
+	ModeErrorRate   = "error_rate"  // 滑动窗口内的失败比例达到阈值
+)
+
 // CircuitState 熔断器状态的对外展示结构（用于监控、日志等）
 type CircuitState struct {
-	ServiceName      string    `json:"service_name"`             // 服务名
-	State            string    `json:"state"`                    // 状态（字符串形式）
-	FailureCount     int       `json:"failure_count"`            // 失败次数
-	SuccessCount     int       `json:"success_count"`            // 成功次数
-	LastOpenTime     time.Time `json:"last_open_time,omitempty"` // 最后一次打开时间
-	FailureThreshold int       `json:"failure_threshold"`        // 失败阈值（达到则打开）
-	SuccessThreshold int       `json:"success_threshold"`        // 成功阈值（半开时达到则关闭）
-	ResetTimeout     string    `json:"reset_timeout"`            // 重置超时时间（字符串形式）
+	ServiceName           string     `json:"service_name"`                      // 服务名
+	Instance              string     `json:"instance,omitempty"`                // 实例地址，仅实例级熔断器
+	State                 string     `json:"state"`                             // 状态（字符串形式）
+	Mode                  string     `json:"mode"`                              // 触发模式
+	FailureCount          int        `json:"failure_count"`                     // 失败次数
+	SuccessCount          int        `json:"success_count"`                     // 成功次数
+	LastOpenTime          time.Time  `json:"last_open_time,omitempty"`          // 最后一次打开时间
+	Forced                bool       `json:"forced,omitempty"`                  // 是否处于管理接口强制的状态
+	ForcedUntil           *time.Time `json:"forced_until,omitempty"`            // 强制状态的到期时间，为空表示不自动解除
+	FailureThreshold      int        `json:"failure_threshold"`                 // 失败阈值（达到则打开）
+	SuccessThreshold      int        `json:"success_threshold"`                 // 成功阈值（半开时达到则关闭）
+	ResetTimeout          string     `json:"reset_timeout"`                     // 重置超时时间（字符串形式）
+	HalfOpenMaxRequests   int        `json:"half_open_max_requests,omitempty"`  // 半开状态下同时放行的试探请求数上限
+	HalfOpenProbes        int        `json:"half_open_probes,omitempty"`        // 半开状态下尚未返回结果的试探请求数
+	Unhealthy             bool       `json:"unhealthy,omitempty"`               // 健康检查判定服务所有实例都不健康
+	TotalRequests         int64      `json:"total_requests"`                    // 累计记录的请求结果数（只增不减，用于计算一段时间内的错误率）
+	TotalFailures         int64      `json:"total_failures"`                    // 累计记录的失败数（只增不减）
+	WindowRequests        int        `json:"window_requests,omitempty"`         // error_rate 模式下窗口内的请求数
+	WindowFailures        int        `json:"window_failures,omitempty"`         // error_rate 模式下窗口内的失败数
+	MaxConcurrentRequests int        `json:"max_concurrent_requests,omitempty"` // 舱壁的并发请求数上限，仅服务级熔断器
+	InFlight              int64      `json:"in_flight,omitempty"`               // 舱壁中当前在途的请求数
 }
 
 // Service 熔断器服务接口（定义核心能力，解耦实现与调用）
 type Service interface {
-	CheckCircuit(ctx context.Context, serviceName string) (bool, error) // 检查是否允许请求
-	RecordResult(ctx context.Context, serviceName string, success bool) // 记录请求结果（成功/失败）
-	GetAllState(ctx context.Context) map[string]CircuitState            // 获取所有服务的熔断器状态
-	Reset(ctx context.Context, serviceName string) error                // 重置指定服务的熔断器
-	Close(ctx context.Context) error                                    // 优雅关闭服务（清理资源）
+	CheckCircuit(ctx context.Context, serviceName string) (bool, error)                      // 检查是否允许请求
+	RecordResult(ctx context.Context, serviceName string, success bool)                      // 记录请求结果（成功/失败）
+	IsFailure(serviceName string, statusCode int) bool                                       // 按服务的 failure_statuses 判断上游响应是否计为失败
+	AllowInstance(ctx context.Context, serviceName, instanceURL string) bool                 // 检查实例级熔断器是否允许请求
+	RecordInstanceResult(ctx context.Context, serviceName, instanceURL string, success bool) // 记录实例的请求结果
+	GetAllState(ctx context.Context) map[string]CircuitState                                 // 获取所有服务的熔断器状态
+	Reset(ctx context.Context, serviceName string) error                                     // 重置指定服务的熔断器
+	ForceOpen(ctx context.Context, serviceName, instanceURL string, d time.Duration) error   // 强制打开熔断器，d 为 0 表示不自动解除
+	ForceClose(ctx context.Context, serviceName, instanceURL string, d time.Duration) error  // 强制关闭熔断器，d 为 0 表示不自动解除
+	SetServiceHealth(ctx context.Context, serviceName string, healthy bool)                  // 服务整体健康状态变化时调用，health_signals 开启时据此打开或恢复熔断器
+	AcquireBulkhead(ctx context.Context, serviceName string) (release func(), ok bool)       // 占用服务的并发名额 (舱壁)，名额已满时 ok 为 false
+	OnStateChange(fn func(StateChange))                                                      // 订阅熔断器状态变化事件
+	Close(ctx context.Context) error                                                         // 优雅关闭服务（清理资源）
+}
+
+// settings 单个熔断器使用的阈值（服务级配置覆盖全局配置后的结果）
+type settings struct {
+	mode               string        // 触发模式
+	failureThreshold   int           // 失败阈值（consecutive 模式）
+	successThreshold   int           // 成功阈值
+	resetTimeout       time.Duration // 重置超时时间
+	halfOpenMax        int           // 半开状态下同时放行的试探请求数，0 表示不限制
+	errorRateThreshold float64       // 失败比例阈值（error_rate 模式）
+	minRequests        int           // 窗口内的最少请求数（error_rate 模式）
+	window             time.Duration // 统计窗口（error_rate 模式）
+	perInstance        bool          // 是否为每个实例单独熔断
+	healthSignals      bool          // 是否跟随主动健康检查的结果
+	maxConcurrent      int           // 服务同时在途的请求数上限，0 表示不限制
+	failureStatuses    []statusRange // 计为失败的上游响应状态码
 }
 
 // CircuitBreaker 单个服务的熔断器实例（承载单个服务的状态）
 type CircuitBreaker struct {
-	mu           sync.Mutex // 保护当前熔断器实例的并发安全
-	state        State      // 当前状态
-	failureCount int        // 失败次数
-	successCount int        // 成功次数（主要用于半开状态）
-	lastOpenTime time.Time  // 最后一次进入打开状态的时间
+	mu           sync.Mutex     // 保护当前熔断器实例的并发安全
+	service      string         // 所属服务名
+	instance     string         // 实例地址，服务级熔断器为空
+	settings     settings       // 创建时解析出的阈值，之后不再变化
+	state        State          // 当前状态
+	failureCount int            // 连续失败次数（closed 状态下成功一次即清零）
+	successCount int            // 成功次数（主要用于半开状态）
+	probes       int            // 半开状态下已放行但尚未记录结果的试探请求数
+	lastProbe    time.Time      // 最近一次放行试探请求的时间
+	window       *rollingWindow // error_rate 模式下最近一段时间的请求结果
+	lastOpenTime time.Time      // 最后一次进入打开状态的时间
+	forced       bool           // 是否处于管理接口强制的状态，期间状态不随请求结果变化
+	forcedUntil  time.Time      // 强制状态的到期时间，零值表示不自动解除
+	unhealthy    bool           // 健康检查判定服务所有实例都不健康，期间保持打开
+	lastUsed     time.Time      // 最近一次放行判断或记录结果的时间，用于回收闲置的熔断器
+	totalResults int64          // 累计记录的请求结果数
+	totalFailed  int64          // 累计记录的失败数
 }
 
 // service Service 接口的具体实现（管理多个服务的熔断器）
 type service struct {
-	mu               sync.RWMutex               // 保护多服务熔断器映射的并发安全
-	circuitBreakers  map[string]*CircuitBreaker // 服务名 -> 熔断器实例的映射
-	FailureThreshold int                        // 全局失败阈值（默认5次）
-	SuccessThreshold int                        // 全局成功阈值（默认2次）
-	ResetTimeout     time.Duration              // 全局重置超时时间（默认1分钟）
-	log              logger.Logger              // 日志记录器
+	mu              sync.RWMutex               // 保护多服务熔断器映射的并发安全
+	circuitBreakers map[string]*CircuitBreaker // 服务名 -> 熔断器实例的映射
+	bulkheads       map[string]*bulkhead       // 服务名 -> 舱壁
+	defaults        settings                   // 全局阈值（默认失败5次、成功2次、重置1分钟）
+	overrides       map[string]settings        // 服务名 -> 服务级配置覆盖后的阈值
+	log             logger.Logger              // 日志记录器
+
+	listenersMu sync.RWMutex
+	listeners   []func(StateChange) // 状态变化事件的订阅者
+	events      chan StateChange    // 等待分发的状态变化事件
+	ctx         context.Context     // 服务关闭时取消，结束事件分发
+	cancel      context.CancelFunc
+
+	idleTTL    time.Duration // 闲置熔断器的回收时长
+	persist    stateStore    // 状态持久化后端，为 nil 时不持久化
+	persistTTL time.Duration // 保存的状态的有效期
 }
 
 // NewService 创建熔断器服务实例（返回接口类型，隐藏内部实现）
-func NewService(failureThreshold int, successThreshold int, resetTimeout time.Duration, log logger.Logger) Service {
+// perService 为各服务的覆盖配置，未设置（零值）的字段沿用全局配置；store 供 persist.backend 为 cache 时使用
+func NewService(cfg config.CircuitBreakerConfig, perService map[string]config.CircuitBreakerConfig, store cache.Cache, log logger.Logger) (Service, error) {
 	// 配置默认值（避免传入非法参数）
-	if failureThreshold <= 0 {
-		failureThreshold = 5
+	defaults := settings{
+		mode:               ModeConsecutive,
+		failureThreshold:   5,
+		successThreshold:   2,
+		resetTimeout:       1 * time.Minute,
+		errorRateThreshold: 0.5,
+		minRequests:        20,
+		window:             10 * time.Second,
+		failureStatuses:    []statusRange{{500, 599}},
 	}
-	if successThreshold <= 0 {
-		successThreshold = 2
-	}
-	if resetTimeout <= 0 {
-		resetTimeout = 1 * time.Minute
+	defaults, err := defaults.merge(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("circuit_breaker 配置错误: %w", err)
 	}
 
 	// 初始化服务实例，创建熔断器映射
 	svc := &service{
-		circuitBreakers:  make(map[string]*CircuitBreaker),
-		FailureThreshold: failureThreshold,
-		SuccessThreshold: successThreshold,
-		ResetTimeout:     resetTimeout,
-		log:              log,
+		circuitBreakers: make(map[string]*CircuitBreaker),
+		bulkheads:       make(map[string]*bulkhead),
+		defaults:        defaults,
+		overrides:       make(map[string]settings, len(perService)),
+		log:             log,
+		events:          make(chan StateChange, eventBufferSize),
+		idleTTL:         defaultIdleTTL,
+	}
+	if cfg.IdleTTL > 0 {
+		svc.idleTTL = cfg.IdleTTL
+	}
+	for name, override := range perService {
+		st, err := defaults.merge(override)
+		if err != nil {
+			return nil, fmt.Errorf("服务 '%s' 的 circuit_breaker 配置错误: %w", name, err)
+		}
+		svc.overrides[name] = st
+	}
+	if cfg.Notify != nil {
+		notifier, err := newWebhookNotifier(*cfg.Notify, log)
+		if err != nil {
+			return nil, fmt.Errorf("circuit_breaker.notify 配置错误: %w", err)
+		}
+		svc.OnStateChange(notifier.notify)
+	}
+	if cfg.Persist != nil {
+		if svc.persist, svc.persistTTL, err = newStateStore(*cfg.Persist, store); err != nil {
+			return nil, fmt.Errorf("circuit_breaker.persist 配置错误: %w", err)
+		}
+		svc.restoreState(context.Background())
+		// 每次状态变化后保存快照
+		svc.OnStateChange(func(StateChange) { svc.saveState(svc.ctx) })
 	}
+	svc.ctx, svc.cancel = context.WithCancel(context.Background())
+	go svc.dispatchEvents()
+	go svc.sweepIdle()
 
 	log.Info(context.Background(), "Circuit breaker service initialized",
-		"failure_threshold", failureThreshold,
-		"success_threshold", successThreshold,
-		"reset_timeout", resetTimeout.String(),
+		"mode", defaults.mode,
+		"failure_threshold", defaults.failureThreshold,
+		"success_threshold", defaults.successThreshold,
+		"reset_timeout", defaults.resetTimeout.String(),
+		"service_overrides", len(perService),
 		"service", "circuitbreaker")
 
-	return svc
+	return svc, nil
+}
+
+// merge 用配置中设置了的字段覆盖当前阈值，并校验合并后的结果
+func (st settings) merge(cfg config.CircuitBreakerConfig) (settings, error) {
+	if cfg.Mode != "" {
+		st.mode = cfg.Mode
+	}
+	if cfg.FailureThreshold > 0 {
+		st.failureThreshold = cfg.FailureThreshold
+	}
+	if cfg.SuccessThreshold > 0 {
+		st.successThreshold = cfg.SuccessThreshold
+	}
+	if cfg.ResetTimeout > 0 {
+		st.resetTimeout = cfg.ResetTimeout
+	}
+	if cfg.HalfOpenMaxRequests > 0 {
+		st.halfOpenMax = cfg.HalfOpenMaxRequests
+	}
+	if cfg.ErrorRateThreshold > 0 {
+		st.errorRateThreshold = cfg.ErrorRateThreshold
+	}
+	if cfg.MinRequests > 0 {
+		st.minRequests = cfg.MinRequests
+	}
+	if cfg.Window > 0 {
+		st.window = cfg.Window
+	}
+	if cfg.PerInstance {
+		st.perInstance = true
+	}
+	if cfg.HealthSignals {
+		st.healthSignals = true
+	}
+	if cfg.MaxConcurrentRequests > 0 {
+		st.maxConcurrent = cfg.MaxConcurrentRequests
+	}
+	if len(cfg.FailureStatuses) > 0 {
+		ranges, err := parseStatusRanges(cfg.FailureStatuses)
+		if err != nil {
+			return st, err
+		}
+		st.failureStatuses = ranges
+	}
+	return st, st.validate()
+}
+
+// validate 校验合并后的阈值
+func (st settings) validate() error {
+	switch {
+	case st.mode != ModeConsecutive && st.mode != ModeErrorRate:
+		return fmt.Errorf("不支持的 mode '%s' (可选: %s, %s)", st.mode, ModeConsecutive, ModeErrorRate)
+	case st.errorRateThreshold > 1:
+		return fmt.Errorf("error_rate_threshold 应在 0 到 1 之间")
+	}
+	return nil
+}
+
+// IsFailure 实现了 Service 接口
+func (s *service) IsFailure(serviceName string, statusCode int) bool {
+	for _, r := range s.settingsFor(serviceName).failureStatuses {
+		if statusCode >= r.min && statusCode <= r.max {
+			return true
+		}
+	}
+	return false
+}
+
+// InstanceKey 返回实例级熔断器的名称，可用于 Reset
+func InstanceKey(serviceName, instanceURL string) string {
+	return serviceName + "|" + instanceURL
+}
+
+// newCircuitBreaker 按阈值创建处于关闭状态的熔断器
+func newCircuitBreaker(st settings) *CircuitBreaker {
+	cb := &CircuitBreaker{state: StateClosed, settings: st}
+	if st.mode == ModeErrorRate {
+		cb.window = newRollingWindow(st.window)
+	}
+	return cb
+}
+
+// allowProbe 判断半开状态下能否再放行一个试探请求，调用方需持有 cb.mu。
+// 试探请求可能被后续插件拦截而不会记录结果，超过 reset_timeout 仍未返回的试探视为已放弃
+func (cb *CircuitBreaker) allowProbe(now time.Time) bool {
+	if cb.settings.halfOpenMax > 0 && cb.probes >= cb.settings.halfOpenMax {
+		if now.Sub(cb.lastProbe) <= cb.settings.resetTimeout {
+			return false
+		}
+		cb.probes = 0
+	}
+	cb.probes++
+	cb.lastProbe = now
+	return true
+}
+
+// retryAfter 估算被拒绝的请求多久后可以重试，无法预估时返回 0，调用方需持有 cb.mu
+func (cb *CircuitBreaker) retryAfter(now time.Time) time.Duration {
+	switch {
+	case cb.forced:
+		if cb.forcedUntil.IsZero() {
+			return 0
+		}
+		return cb.forcedUntil.Sub(now)
+	case cb.unhealthy:
+		return 0 // 取决于健康检查何时恢复
+	case cb.state == StateHalfOpen:
+		return halfOpenRetryAfter
+	default:
+		return max(cb.lastOpenTime.Add(cb.settings.resetTimeout).Sub(now), 0)
+	}
+}
+
+// shouldTrip 判断关闭状态的熔断器是否应当打开，调用方需持有 cb.mu
+func (cb *CircuitBreaker) shouldTrip(now time.Time) bool {
+	if cb.window == nil {
+		return cb.failureCount >= cb.settings.failureThreshold
+	}
+	requests, failures := cb.window.counts(now)
+	return requests >= cb.settings.minRequests &&
+		float64(failures)/float64(requests) >= cb.settings.errorRateThreshold
+}
+
+// settingsFor 返回服务使用的阈值，没有服务级配置时使用全局阈值
+func (s *service) settingsFor(serviceName string) settings {
+	if st, ok := s.overrides[serviceName]; ok {
+		return st
+	}
+	return s.defaults
 }
 
 // GetAllState 返回所有服务的熔断器状态（对外展示用）
@@ -119,16 +379,38 @@ func (s *service) GetAllState(ctx context.Context) map[string]CircuitState {
 	for serviceName, cb := range s.circuitBreakers {
 		cb.mu.Lock() // 锁单个熔断器实例，避免状态读取时被修改
 		// 组装对外的状态结构
-		result[serviceName] = CircuitState{
-			ServiceName:      serviceName,
-			State:            cb.state.GetState(),
-			FailureCount:     cb.failureCount,
-			SuccessCount:     cb.successCount,
-			LastOpenTime:     cb.lastOpenTime,
-			FailureThreshold: s.FailureThreshold,
-			SuccessThreshold: s.SuccessThreshold,
-			ResetTimeout:     s.ResetTimeout.String(),
+		st := CircuitState{
+			ServiceName:         cb.service,
+			Instance:            cb.instance,
+			State:               cb.state.GetState(),
+			Mode:                cb.settings.mode,
+			FailureCount:        cb.failureCount,
+			SuccessCount:        cb.successCount,
+			LastOpenTime:        cb.lastOpenTime,
+			FailureThreshold:    cb.settings.failureThreshold,
+			SuccessThreshold:    cb.settings.successThreshold,
+			ResetTimeout:        cb.settings.resetTimeout.String(),
+			HalfOpenMaxRequests: cb.settings.halfOpenMax,
+			HalfOpenProbes:      cb.probes,
+			Unhealthy:           cb.unhealthy,
+			TotalRequests:       cb.totalResults,
+			TotalFailures:       cb.totalFailed,
+		}
+		if cb.window != nil {
+			st.WindowRequests, st.WindowFailures = cb.window.counts(time.Now())
+		}
+		if b, ok := s.bulkheads[cb.service]; ok && cb.instance == "" {
+			st.MaxConcurrentRequests = int(b.limit)
+			st.InFlight = b.inFlight.Load()
 		}
+		if cb.isForced(time.Now()) {
+			st.Forced = true
+			if !cb.forcedUntil.IsZero() {
+				until := cb.forcedUntil
+				st.ForcedUntil = &until
+			}
+		}
+		result[serviceName] = st
 		cb.mu.Unlock()
 	}
 
@@ -157,9 +439,17 @@ func (s *service) Reset(ctx context.Context, serviceName string) error {
 	// 重置熔断器内部状态
 	cb.mu.Lock()
 	defer cb.mu.Unlock()
+	from := cb.state
 	cb.state = StateClosed
 	cb.failureCount = 0
 	cb.successCount = 0
+	cb.probes = 0
+	cb.forced = false
+	cb.unhealthy = false
+	if cb.window != nil {
+		cb.window.reset()
+	}
+	s.emit(cb, from, time.Now())
 
 	s.log.Info(ctx, "Circuit breaker reset successfully",
 		"service_name", serviceName,
@@ -171,34 +461,68 @@ func (s *service) Reset(ctx context.Context, serviceName string) error {
 
 // CheckCircuit 检查指定服务的熔断器状态，返回是否允许请求
 func (s *service) CheckCircuit(ctx context.Context, serviceName string) (bool, error) {
-	// 1. 确保服务的熔断器实例存在（不存在则创建）
+	return s.allow(ctx, serviceName, s.getOrCreate(ctx, serviceName, serviceName, ""))
+}
+
+// AllowInstance 检查服务中某个实例的熔断器，服务未开启 per_instance 时总是允许
+func (s *service) AllowInstance(ctx context.Context, serviceName, instanceURL string) bool {
+	if !s.settingsFor(serviceName).perInstance {
+		return true
+	}
+	key := InstanceKey(serviceName, instanceURL)
+	allowed, _ := s.allow(ctx, key, s.getOrCreate(ctx, key, serviceName, instanceURL))
+	return allowed
+}
+
+// getOrCreate 返回 key 对应的熔断器，不存在时按服务的阈值创建
+func (s *service) getOrCreate(ctx context.Context, key, serviceName, instanceURL string) *CircuitBreaker {
 	s.mu.Lock()
-	cb, exists := s.circuitBreakers[serviceName]
+	defer s.mu.Unlock()
+	cb, exists := s.circuitBreakers[key]
 	if !exists {
-		cb = &CircuitBreaker{state: StateClosed} // 新熔断器默认处于关闭状态
-		s.circuitBreakers[serviceName] = cb
+		cb = newCircuitBreaker(s.settingsFor(serviceName)) // 新熔断器默认处于关闭状态
+		cb.service, cb.instance = serviceName, instanceURL
+		cb.lastUsed = time.Now()
+		s.circuitBreakers[key] = cb
+		breakerState.WithLabelValues(serviceName, instanceURL).Set(float64(StateClosed))
 		s.log.Info(ctx, "Initialized circuit breaker for service",
 			"service_name", serviceName,
+			"instance", instanceURL,
 			"initial_state", "closed",
 			"service", "circuitbreaker",
 			"action", "initialize")
 	}
-	s.mu.Unlock()
+	return cb
+}
 
-	// 2. 检查熔断器状态，决定是否允许请求
+// allow 根据熔断器状态决定是否允许请求，name 仅用于日志
+func (s *service) allow(ctx context.Context, name string, cb *CircuitBreaker) (bool, error) {
 	cb.mu.Lock()
 	defer cb.mu.Unlock()
+	cb.lastUsed = time.Now()
+
+	// 管理接口强制的状态优先
+	if cb.isForced(time.Now()) {
+		if cb.state == StateOpen {
+			breakerRejected.WithLabelValues(cb.service, cb.instance).Inc()
+			return false, &OpenError{RetryAfter: cb.retryAfter(time.Now())}
+		}
+		return true, nil
+	}
 
 	switch cb.state {
 	case StateOpen:
-		// 打开状态：检查是否超过重置超时时间，超时则进入半开
-		if time.Since(cb.lastOpenTime) > s.ResetTimeout {
+		// 打开状态：检查是否超过重置超时时间，超时则进入半开；健康检查判定不可用时保持打开
+		if !cb.unhealthy && time.Since(cb.lastOpenTime) > cb.settings.resetTimeout {
 			oldState := cb.state.GetState()
 			cb.state = StateHalfOpen
 			cb.failureCount = 0
 			cb.successCount = 0
+			cb.probes = 0
+			cb.allowProbe(time.Now())
+			s.emit(cb, StateOpen, time.Now())
 			s.log.Info(ctx, "Circuit breaker state transition",
-				"service_name", serviceName,
+				"service_name", name,
 				"old_state", oldState,
 				"new_state", cb.state.GetState(),
 				"service", "circuitbreaker",
@@ -207,17 +531,28 @@ func (s *service) CheckCircuit(ctx context.Context, serviceName string) (bool, e
 		}
 		// 未超时：拒绝请求
 		s.log.Debug(ctx, "Circuit breaker is open, request rejected",
-			"service_name", serviceName,
+			"service_name", name,
 			"time_since_open", time.Since(cb.lastOpenTime).String(),
-			"reset_timeout", s.ResetTimeout.String(),
+			"reset_timeout", cb.settings.resetTimeout.String(),
 			"service", "circuitbreaker",
 			"action", "request_rejected")
-		return false, ErrOpenState
+		breakerRejected.WithLabelValues(cb.service, cb.instance).Inc()
+		return false, &OpenError{RetryAfter: cb.retryAfter(time.Now())}
 
 	case StateHalfOpen:
-		// 半开状态：允许请求（试探）
+		// 半开状态：试探请求数达到上限时拒绝，等待已放行的试探返回结果
+		if !cb.allowProbe(time.Now()) {
+			s.log.Debug(ctx, "Circuit breaker is half-open and probe limit reached, request rejected",
+				"service_name", name,
+				"in_flight_probes", cb.probes,
+				"service", "circuitbreaker",
+				"action", "request_rejected")
+			breakerRejected.WithLabelValues(cb.service, cb.instance).Inc()
+			return false, &OpenError{RetryAfter: cb.retryAfter(time.Now())}
+		}
+		// 允许请求（试探）
 		s.log.Debug(ctx, "Circuit breaker is half-open, allowing probe request",
-			"service_name", serviceName,
+			"service_name", name,
 			"service", "circuitbreaker",
 			"action", "request_allowed")
 		return true, nil
@@ -225,7 +560,7 @@ func (s *service) CheckCircuit(ctx context.Context, serviceName string) (bool, e
 	case StateClosed:
 		// 关闭状态：允许请求
 		s.log.Debug(ctx, "Circuit breaker is closed, allowing request",
-			"service_name", serviceName,
+			"service_name", name,
 			"service", "circuitbreaker",
 			"action", "request_allowed")
 		return true, nil
@@ -233,7 +568,7 @@ func (s *service) CheckCircuit(ctx context.Context, serviceName string) (bool, e
 	default:
 		// 未知状态：默认允许请求（降级策略）
 		s.log.Warn(ctx, "Circuit breaker state unknown, allowing request by default",
-			"service_name", serviceName,
+			"service_name", name,
 			"state", "unknown",
 			"service", "circuitbreaker",
 			"action", "request_allowed_fallback")
@@ -257,31 +592,74 @@ func (s *service) RecordResult(ctx context.Context, serviceName string, success
 		return
 	}
 
-	// 2. 根据请求结果更新熔断器状态
+	s.record(ctx, serviceName, cb, success)
+}
+
+// RecordInstanceResult 记录服务中某个实例的请求结果，服务未开启 per_instance 时忽略
+func (s *service) RecordInstanceResult(ctx context.Context, serviceName, instanceURL string, success bool) {
+	if !s.settingsFor(serviceName).perInstance {
+		return
+	}
+	key := InstanceKey(serviceName, instanceURL)
+	s.mu.RLock()
+	cb, exists := s.circuitBreakers[key]
+	s.mu.RUnlock()
+	if exists {
+		s.record(ctx, key, cb, success)
+	}
+}
+
+// record 根据请求结果更新熔断器状态，name 仅用于日志
+func (s *service) record(ctx context.Context, name string, cb *CircuitBreaker, success bool) {
 	cb.mu.Lock()
 	defer cb.mu.Unlock()
 
+	now := time.Now()
+	cb.lastUsed = now
+	cb.totalResults++
+	if !success {
+		cb.totalFailed++
+	}
+	// 强制状态期间只统计，不改变状态
+	if cb.isForced(now) {
+		return
+	}
+	// 半开状态的试探结果只决定是否恢复，不计入窗口
+	if cb.window != nil && cb.state == StateClosed {
+		cb.window.record(now, success)
+	}
+	if cb.state == StateHalfOpen && cb.probes > 0 {
+		cb.probes--
+	}
+
 	if success {
 		// 成功场景：处理半开状态的成功计数
 		cb.successCount++
+		if cb.state == StateClosed {
+			cb.failureCount = 0
+		}
 		s.log.Debug(ctx, "Service request succeeded",
-			"service_name", serviceName,
+			"service_name", name,
 			"success_count", cb.successCount,
 			"current_state", cb.state.GetState(),
 			"service", "circuitbreaker",
 			"action", "record_success")
 
 		// 半开状态下，成功次数达到阈值则转为关闭
-		if cb.state == StateHalfOpen && cb.successCount >= s.SuccessThreshold {
+		if cb.state == StateHalfOpen && cb.successCount >= cb.settings.successThreshold {
 			oldState := cb.state.GetState()
 			cb.state = StateClosed
 			cb.failureCount = 0
 			cb.successCount = 0
+			if cb.window != nil {
+				cb.window.reset()
+			}
+			s.emit(cb, StateHalfOpen, now)
 			s.log.Info(ctx, "Circuit breaker state transition",
-				"service_name", serviceName,
+				"service_name", name,
 				"old_state", oldState,
 				"new_state", cb.state.GetState(),
-				"success_threshold", s.SuccessThreshold,
+				"success_threshold", cb.settings.successThreshold,
 				"service", "circuitbreaker",
 				"action", "state_transition")
 		}
@@ -290,49 +668,57 @@ func (s *service) RecordResult(ctx context.Context, serviceName string, success
 		// 失败场景：处理关闭/半开状态的失败计数
 		cb.failureCount++
 		s.log.Debug(ctx, "Service request failed",
-			"service_name", serviceName,
+			"service_name", name,
 			"failure_count", cb.failureCount,
 			"current_state", cb.state.GetState(),
 			"service", "circuitbreaker",
 			"action", "record_failure")
 
-		// 关闭状态下，失败次数达到阈值则转为打开
-		if cb.state == StateClosed && cb.failureCount >= s.FailureThreshold {
-			oldState := cb.state.GetState()
-			cb.state = StateOpen
-			cb.lastOpenTime = time.Now()
-			s.log.Warn(ctx, "Circuit breaker state transition",
-				"service_name", serviceName,
-				"old_state", oldState,
-				"new_state", cb.state.GetState(),
-				"failure_threshold", s.FailureThreshold,
-				"service", "circuitbreaker",
-				"action", "state_transition")
-		}
-
 		// 半开状态下，只要失败就立即转为打开
 		if cb.state == StateHalfOpen {
 			oldState := cb.state.GetState()
 			cb.state = StateOpen
-			cb.lastOpenTime = time.Now()
+			cb.lastOpenTime = now
+			s.emit(cb, StateHalfOpen, now)
 			s.log.Warn(ctx, "Circuit breaker state transition",
-				"service_name", serviceName,
+				"service_name", name,
 				"old_state", oldState,
 				"new_state", cb.state.GetState(),
 				"service", "circuitbreaker",
 				"action", "state_transition")
 		}
 	}
+
+	// 关闭状态下，连续失败次数或窗口内的失败比例达到阈值则转为打开
+	// (error_rate 模式下成功的请求也可能使窗口达到最少请求数)
+	if cb.state == StateClosed && cb.shouldTrip(now) {
+		oldState := cb.state.GetState()
+		cb.state = StateOpen
+		cb.lastOpenTime = now
+		s.emit(cb, StateClosed, now)
+		s.log.Warn(ctx, "Circuit breaker state transition",
+			"service_name", name,
+			"old_state", oldState,
+			"new_state", cb.state.GetState(),
+			"mode", cb.settings.mode,
+			"failure_threshold", cb.settings.failureThreshold,
+			"service", "circuitbreaker",
+			"action", "state_transition")
+	}
 }
 
-// Close 优雅关闭熔断器服务（清理资源，此处无长期后台任务，主要用于日志和扩展）
+// Close 优雅关闭熔断器服务（停止事件分发与闲置回收等后台任务）
 func (s *service) Close(ctx context.Context) error {
 	s.log.Info(ctx, "Starting graceful shutdown of circuit breaker service",
 		"total_services", len(s.circuitBreakers),
 		"service", "circuitbreaker",
 		"action", "shutdown_start")
 
-	// 若后续添加了后台任务（如定期清理过期熔断器），可在此处通过 context 取消任务
+	// 停止事件分发与闲置回收，队列中尚未分发的事件被丢弃
+	s.cancel()
+	if s.persist != nil {
+		s.saveState(ctx)
+	}
 
 	s.log.Info(ctx, "Circuit breaker service shutdown completed",
 		"service", "circuitbreaker",
@@ -0,0 +1,31 @@
+package circuitbreaker
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// statusRange 是一段闭区间的 HTTP 状态码
+type statusRange struct {
+	min, max int
+}
+
+// parseStatusRanges 解析 failure_statuses，支持具体状态码 ("429") 与状态码类别 ("5xx")
+func parseStatusRanges(values []string) ([]statusRange, error) {
+	ranges := make([]statusRange, 0, len(values))
+	for _, v := range values {
+		s := strings.ToLower(strings.TrimSpace(v))
+		if len(s) == 3 && strings.HasSuffix(s, "xx") && s[0] >= '1' && s[0] <= '5' {
+			class := int(s[0]-'0') * 100
+			ranges = append(ranges, statusRange{class, class + 99})
+			continue
+		}
+		code, err := strconv.Atoi(s)
+		if err != nil || code < 100 || code > 599 {
+			return nil, fmt.Errorf("failure_statuses 中的 '%s' 不是有效的状态码或状态码类别 (如 503、5xx)", v)
+		}
+		ranges = append(ranges, statusRange{code, code})
+	}
+	return ranges, nil
+}
@@ -0,0 +1,57 @@
+package circuitbreaker
+
+import "time"
+
+// windowBuckets 滑动窗口被划分的桶数，桶越多统计越平滑
+const windowBuckets = 10
+
+// windowBucket 滑动窗口中一个桶的计数
+type windowBucket struct {
+	start    time.Time // 桶的起始时间，与当前时间不匹配时说明桶已过期
+	requests int
+	failures int
+}
+
+// rollingWindow 最近一段时间内的请求数与失败数（error_rate 模式使用）
+// 窗口被划分为固定数量的桶，过期的桶在被复用时清零，不需要后台任务
+type rollingWindow struct {
+	window     time.Duration
+	bucketSize time.Duration
+	buckets    [windowBuckets]windowBucket
+}
+
+func newRollingWindow(window time.Duration) *rollingWindow {
+	return &rollingWindow{
+		window:     window,
+		bucketSize: max(window/windowBuckets, time.Millisecond),
+	}
+}
+
+// record 记录一次请求结果
+func (w *rollingWindow) record(now time.Time, success bool) {
+	start := now.Truncate(w.bucketSize)
+	b := &w.buckets[(start.UnixNano()/int64(w.bucketSize))%windowBuckets]
+	if !b.start.Equal(start) {
+		*b = windowBucket{start: start}
+	}
+	b.requests++
+	if !success {
+		b.failures++
+	}
+}
+
+// counts 返回窗口内的请求数与失败数
+func (w *rollingWindow) counts(now time.Time) (requests, failures int) {
+	for _, b := range w.buckets {
+		if now.Sub(b.start) < w.window {
+			requests += b.requests
+			failures += b.failures
+		}
+	}
+	return requests, failures
+}
+
+// reset 清空窗口
+func (w *rollingWindow) reset() {
+	w.buckets = [windowBuckets]windowBucket{}
+}
@@ -0,0 +1,231 @@
+// file: internal/service/quota/service.go
+package quota
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"gateway.example/go-gateway/internal/cache"
+	"gateway.example/go-gateway/internal/config"
+	"gateway.example/go-gateway/pkg/logger"
+)
+
+// 支持的计数周期
+const (
+	PeriodDaily   = "daily"
+	PeriodMonthly = "monthly"
+)
+
+// keyPrefix 配额计数器在共享缓存中的键前缀
+const keyPrefix = "quota:"
+
+// ErrRuleNotFound 配额规则未定义
+var ErrRuleNotFound = errors.New("quota rule not found")
+
+// Usage 是某个标识在当前周期的配额使用情况
+type Usage struct {
+	Rule       string    `json:"rule"`
+	Identifier string    `json:"identifier"`
+	Period     string    `json:"period"`
+	Limit      int64     `json:"limit"`
+	Used       int64     `json:"used"`
+	Remaining  int64     `json:"remaining"`
+	ResetAt    time.Time `json:"reset_at"` // 当前周期结束、计数清零的时间
+}
+
+// Service 定义了配额服务的接口。
+// 与限流不同，配额按天或按月累计，计数保存在共享缓存中，网关重启后不会丢失 (取决于缓存后端)。
+type Service interface {
+	// Consume 为标识消耗一次配额，超出时 allowed 为 false 且不计入使用量
+	Consume(ctx context.Context, ruleName, identifier string) (usage Usage, allowed bool, err error)
+	// Usage 查询标识在当前周期的使用情况
+	Usage(ctx context.Context, ruleName, identifier string) (Usage, error)
+	// Reset 清零标识在当前周期的使用量
+	Reset(ctx context.Context, ruleName, identifier string) error
+	HasRule(ruleName string) bool
+}
+
+// rule 是配额规则解析后的参数
+type rule struct {
+	name   string
+	period string
+	limit  int64
+	loc    *time.Location
+}
+
+// service 是 Service 接口的具体实现。
+type service struct {
+	rules map[string]*rule
+	store cache.Cache
+	// 缓存后端不支持原子计数时，用互斥锁保证单实例内的计数准确
+	mu  sync.Mutex
+	log logger.Logger
+}
+
+// NewService 创建一个新的配额服务实例。
+func NewService(cfg config.QuotaConfig, store cache.Cache, log logger.Logger) (Service, error) {
+	s := &service{
+		rules: make(map[string]*rule, len(cfg.Rules)),
+		store: store,
+		log:   log,
+	}
+	for _, rc := range cfg.Rules {
+		if rc.Name == "" {
+			return nil, fmt.Errorf("配额规则缺少 name")
+		}
+		if _, exists := s.rules[rc.Name]; exists {
+			return nil, fmt.Errorf("配额规则 '%s' 重复定义", rc.Name)
+		}
+		if rc.Period != PeriodDaily && rc.Period != PeriodMonthly {
+			return nil, fmt.Errorf("配额规则 '%s' 的 period 应为 %s 或 %s", rc.Name, PeriodDaily, PeriodMonthly)
+		}
+		if rc.Limit <= 0 {
+			return nil, fmt.Errorf("配额规则 '%s' 的 limit 必须为正数", rc.Name)
+		}
+		loc := time.UTC
+		if rc.Timezone != "" {
+			var err error
+			if loc, err = time.LoadLocation(rc.Timezone); err != nil {
+				return nil, fmt.Errorf("配额规则 '%s' 的 timezone 无效: %w", rc.Name, err)
+			}
+		}
+		s.rules[rc.Name] = &rule{name: rc.Name, period: rc.Period, limit: rc.Limit, loc: loc}
+	}
+
+	if _, ok := store.(cache.Counter); !ok && len(s.rules) > 0 {
+		log.Warn(context.Background(), "Cache backend does not support atomic counters, quota counts are only accurate within a single instance",
+			"service", "quota",
+			"action", "initialize")
+	}
+	log.Info(context.Background(), "Quota service initialized",
+		"total_rules", len(s.rules),
+		"service", "quota",
+		"action", "initialize")
+	return s, nil
+}
+
+// Consume 实现了 Service 接口。
+func (s *service) Consume(ctx context.Context, ruleName, identifier string) (Usage, bool, error) {
+	rl, ok := s.rules[ruleName]
+	if !ok {
+		return Usage{}, false, fmt.Errorf("%w: %s", ErrRuleNotFound, ruleName)
+	}
+	now := time.Now()
+	key, end := rl.window(now, identifier)
+
+	used, err := s.incr(ctx, key, 1, end.Sub(now))
+	if err != nil {
+		return Usage{}, false, err
+	}
+	if used > rl.limit {
+		// 被拒绝的请求不计入使用量
+		if _, err := s.incr(ctx, key, -1, end.Sub(now)); err != nil {
+			s.log.Warn(ctx, "Failed to roll back quota counter",
+				"rule_name", ruleName,
+				"identifier", identifier,
+				"error", err.Error(),
+				"service", "quota")
+		}
+		return rl.usage(identifier, rl.limit, end), false, nil
+	}
+	return rl.usage(identifier, used, end), true, nil
+}
+
+// Usage 实现了 Service 接口。
+func (s *service) Usage(ctx context.Context, ruleName, identifier string) (Usage, error) {
+	rl, ok := s.rules[ruleName]
+	if !ok {
+		return Usage{}, fmt.Errorf("%w: %s", ErrRuleNotFound, ruleName)
+	}
+	key, end := rl.window(time.Now(), identifier)
+
+	var used int64
+	value, err := s.store.Get(ctx, key)
+	switch {
+	case errors.Is(err, cache.ErrNotFound):
+	case err != nil:
+		return Usage{}, err
+	default:
+		if used, err = cache.ParseCount(value); err != nil {
+			return Usage{}, fmt.Errorf("配额计数器 '%s' 的值无效: %w", key, err)
+		}
+	}
+	return rl.usage(identifier, used, end), nil
+}
+
+// Reset 实现了 Service 接口。
+func (s *service) Reset(ctx context.Context, ruleName, identifier string) error {
+	rl, ok := s.rules[ruleName]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrRuleNotFound, ruleName)
+	}
+	key, _ := rl.window(time.Now(), identifier)
+	return s.store.Delete(ctx, key)
+}
+
+// HasRule 实现了 Service 接口。
+func (s *service) HasRule(ruleName string) bool {
+	_, ok := s.rules[ruleName]
+	return ok
+}
+
+// incr 优先使用缓存后端的原子计数，不支持时在进程内加锁读改写
+func (s *service) incr(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	if counter, ok := s.store.(cache.Counter); ok {
+		return counter.Incr(ctx, key, delta, ttl)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var n int64
+	value, err := s.store.Get(ctx, key)
+	switch {
+	case errors.Is(err, cache.ErrNotFound):
+	case err != nil:
+		return 0, err
+	default:
+		if n, err = cache.ParseCount(value); err != nil {
+			return 0, fmt.Errorf("配额计数器 '%s' 的值无效: %w", key, err)
+		}
+	}
+	n += delta
+	return n, s.store.Set(ctx, key, []byte(strconv.FormatInt(n, 10)), ttl)
+}
+
+// window 返回当前周期计数器的键与周期结束时间，键中带有周期起点，跨周期后自然使用新的计数器
+func (r *rule) window(now time.Time, identifier string) (string, time.Time) {
+	now = now.In(r.loc)
+	var start, end time.Time
+	if r.period == PeriodMonthly {
+		start = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, r.loc)
+		end = start.AddDate(0, 1, 0)
+	} else {
+		start = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, r.loc)
+		end = start.AddDate(0, 0, 1)
+	}
+	return keyPrefix + r.name + ":" + start.Format("20060102") + ":" + identifier, end
+}
+
+func (r *rule) usage(identifier string, used int64, end time.Time) Usage {
+	return Usage{
+		Rule:       r.name,
+		Identifier: identifier,
+		Period:     r.period,
+		Limit:      r.limit,
+		Used:       used,
+		Remaining:  max(r.limit-used, 0),
+		ResetAt:    end,
+	}
+}
+
+// APIKeyIdentifier 返回 API Key 对应的配额标识，只使用摘要以免 API Key 原文出现在缓存与日志中
+func APIKeyIdentifier(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return "key:" + hex.EncodeToString(sum[:16])
+}
@@ -0,0 +1,216 @@
+// file: internal/service/ratelimit/adaptive.go
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"gateway.example/go-gateway/internal/config"
+	svc_circuitbreaker "gateway.example/go-gateway/internal/service/circuitbreaker"
+	"gateway.example/go-gateway/pkg/logger"
+)
+
+// DefaultAdaptiveInterval 自适应控制器检查上游状态的周期
+const DefaultAdaptiveInterval = 5 * time.Second
+
+// 自适应配置的默认值
+const (
+	defaultAdaptiveMinFactor    = 0.5
+	defaultAdaptiveErrorRate    = 0.5
+	defaultAdaptiveMinRequests  = 10
+	defaultAdaptiveRecoveryStep = 0.1
+)
+
+// AdaptiveController 根据上游熔断器的状态自动调整配置了 adaptive 的限流规则。
+// 上游熔断器半开/打开，或一个周期内的错误率超过阈值时，立即把限额降到 minFactor；
+// 上游恢复后每个周期放宽 recoveryStep，直到恢复原始限额。
+// 调整以 OverrideAdaptive 来源的临时调整实现，有效期为三个周期，控制器停止后自动恢复；
+// 管理接口设置的调整优先，存在时控制器不会改动该规则。
+type AdaptiveController struct {
+	rateLimit      Service
+	circuitBreaker svc_circuitbreaker.Service
+	interval       time.Duration
+	log            logger.Logger
+
+	// 规则名 -> 当前生效的比例，只在 run 所在的 goroutine 中访问
+	factors map[string]float64
+	// 服务名 -> 上一个周期的熔断器累计计数，用于计算周期内的错误率
+	last map[string]svc_circuitbreaker.CircuitState
+
+	stopChan  chan struct{}
+	closeOnce sync.Once
+}
+
+// NewAdaptiveController 创建自适应控制器，调用 Start 后开始工作。
+func NewAdaptiveController(rateLimit Service, circuitBreaker svc_circuitbreaker.Service, interval time.Duration, log logger.Logger) *AdaptiveController {
+	if interval <= 0 {
+		interval = DefaultAdaptiveInterval
+	}
+	return &AdaptiveController{
+		rateLimit:      rateLimit,
+		circuitBreaker: circuitBreaker,
+		interval:       interval,
+		log:            log,
+		factors:        make(map[string]float64),
+		last:           make(map[string]svc_circuitbreaker.CircuitState),
+		stopChan:       make(chan struct{}),
+	}
+}
+
+// Start 在后台周期性地调整规则
+func (c *AdaptiveController) Start() {
+	go c.run()
+}
+
+// Stop 停止后台调整，已应用的调整在有效期结束后自动恢复
+func (c *AdaptiveController) Stop() {
+	c.closeOnce.Do(func() {
+		close(c.stopChan)
+	})
+}
+
+func (c *AdaptiveController) run() {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.adjust(context.Background())
+		case <-c.stopChan:
+			return
+		}
+	}
+}
+
+// adjust 执行一个周期的检查与调整
+func (c *AdaptiveController) adjust(ctx context.Context) {
+	states := c.circuitBreaker.GetAllState(ctx)
+
+	// 每个服务在本周期内记录的请求数与失败数
+	type window struct{ requests, failures int64 }
+	windows := make(map[string]window, len(states))
+	for name, st := range states {
+		prev := c.last[name]
+		windows[name] = window{requests: st.TotalRequests - prev.TotalRequests, failures: st.TotalFailures - prev.TotalFailures}
+		c.last[name] = st
+	}
+
+	seen := make(map[string]bool)
+	for _, rule := range c.rateLimit.Rules() {
+		if rule.Adaptive == nil {
+			continue
+		}
+		seen[rule.Name] = true
+		if rule.Override != nil && rule.Override.Source != OverrideAdaptive {
+			continue
+		}
+		settings := adaptiveDefaults(*rule.Adaptive)
+
+		degraded, reason := false, ""
+		if st, known := states[settings.Service]; known {
+			w := windows[settings.Service]
+			switch {
+			case st.State == "open" || st.State == "half-open":
+				degraded, reason = true, "circuit_"+st.State
+			case w.requests >= int64(settings.MinRequests) && float64(w.failures)/float64(w.requests) >= settings.ErrorRateThreshold:
+				degraded, reason = true, "error_rate"
+			}
+		}
+
+		factor, adjusted := c.factors[rule.Name]
+		if !adjusted {
+			factor = 1
+		}
+		next := min(factor+settings.RecoveryStep, 1)
+		if degraded {
+			next = settings.MinFactor
+		}
+
+		if next >= 1 {
+			delete(c.factors, rule.Name)
+			if rule.Override != nil {
+				if _, err := c.rateLimit.ClearOverride(rule.Name); err != nil {
+					c.log.Warn(ctx, "Failed to restore adaptive rate limit rule",
+						"rule_name", rule.Name,
+						"error", err.Error(),
+						"service", "ratelimit",
+						"action", "adaptive_restore_failed")
+					continue
+				}
+				c.log.Info(ctx, "Adaptive rate limit restored",
+					"rule_name", rule.Name,
+					"upstream", settings.Service,
+					"service", "ratelimit",
+					"action", "adaptive_restored")
+			}
+			continue
+		}
+
+		if _, err := c.rateLimit.SetOverride(rule.Name, next, 3*c.interval, OverrideAdaptive); err != nil {
+			c.log.Warn(ctx, "Failed to apply adaptive rate limit",
+				"rule_name", rule.Name,
+				"factor", next,
+				"error", err.Error(),
+				"service", "ratelimit",
+				"action", "adaptive_failed")
+			continue
+		}
+		c.factors[rule.Name] = next
+		if next != factor {
+			c.log.Info(ctx, "Adaptive rate limit adjusted",
+				"rule_name", rule.Name,
+				"upstream", settings.Service,
+				"factor", next,
+				"reason", reason,
+				"service", "ratelimit",
+				"action", "adaptive_adjusted")
+		}
+	}
+
+	// 重新加载后不再存在或不再自适应的规则
+	for name := range c.factors {
+		if !seen[name] {
+			delete(c.factors, name)
+		}
+	}
+}
+
+// adaptiveDefaults 为未配置的参数填充默认值
+func adaptiveDefaults(a config.AdaptiveSettings) config.AdaptiveSettings {
+	if a.MinFactor == 0 {
+		a.MinFactor = defaultAdaptiveMinFactor
+	}
+	if a.ErrorRateThreshold == 0 {
+		a.ErrorRateThreshold = defaultAdaptiveErrorRate
+	}
+	if a.MinRequests == 0 {
+		a.MinRequests = defaultAdaptiveMinRequests
+	}
+	if a.RecoveryStep == 0 {
+		a.RecoveryStep = defaultAdaptiveRecoveryStep
+	}
+	return a
+}
+
+// validateAdaptive 校验规则的自适应配置
+func validateAdaptive(rule config.RateLimiterRule) error {
+	a := rule.Adaptive
+	switch {
+	case a.Service == "":
+		return fmt.Errorf("规则 '%s' 的 adaptive.service 不能为空", rule.Name)
+	case a.MinFactor < 0 || a.MinFactor >= 1:
+		return fmt.Errorf("规则 '%s' 的 adaptive.minFactor 应在 0 到 1 之间", rule.Name)
+	case a.ErrorRateThreshold < 0 || a.ErrorRateThreshold > 1:
+		return fmt.Errorf("规则 '%s' 的 adaptive.errorRateThreshold 应在 0 到 1 之间", rule.Name)
+	case a.MinRequests < 0:
+		return fmt.Errorf("规则 '%s' 的 adaptive.minRequests 不能为负数", rule.Name)
+	case a.RecoveryStep < 0 || a.RecoveryStep > 1:
+		return fmt.Errorf("规则 '%s' 的 adaptive.recoveryStep 应在 0 到 1 之间", rule.Name)
+	}
+	if _, err := scaleRule(rule, 1); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+// file: internal/service/ratelimit/bypass.go
+package ratelimit
+
+import (
+	"fmt"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"gateway.example/go-gateway/internal/config"
+)
+
+// Bypass 是规则的免限流名单，命中的请求不经过限流器。
+// 用于健康探针、内部服务与可信合作方。
+type Bypass struct {
+	prefixes []netip.Prefix
+	apiKeys  map[string]struct{}
+	headers  map[string]map[string]struct{} // 规范化的请求头名 -> 允许的值
+}
+
+// newBypass 编译规则的免限流配置，未配置时返回 nil
+func newBypass(rule config.RateLimiterRule) (*Bypass, error) {
+	cfg := rule.Bypass
+	if cfg == nil {
+		return nil, nil
+	}
+	b := &Bypass{
+		apiKeys: make(map[string]struct{}, len(cfg.APIKeys)),
+		headers: make(map[string]map[string]struct{}, len(cfg.Headers)),
+	}
+	for _, cidr := range cfg.CIDRs {
+		// 单个 IP 等价于 /32 或 /128
+		if !strings.Contains(cidr, "/") {
+			addr, err := netip.ParseAddr(cidr)
+			if err != nil {
+				return nil, fmt.Errorf("规则 '%s' 的 bypass.cidrs 中有无效地址 '%s'", rule.Name, cidr)
+			}
+			b.prefixes = append(b.prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("规则 '%s' 的 bypass.cidrs 中有无效网段 '%s'", rule.Name, cidr)
+		}
+		b.prefixes = append(b.prefixes, prefix.Masked())
+	}
+	for _, key := range cfg.APIKeys {
+		if key == "" {
+			return nil, fmt.Errorf("规则 '%s' 的 bypass.apiKeys 中不能有空值", rule.Name)
+		}
+		b.apiKeys[key] = struct{}{}
+	}
+	for name, values := range cfg.Headers {
+		canonical := http.CanonicalHeaderKey(name)
+		if b.headers[canonical] == nil {
+			b.headers[canonical] = make(map[string]struct{}, len(values))
+		}
+		for _, v := range values {
+			b.headers[canonical][v] = struct{}{}
+		}
+	}
+	return b, nil
+}
+
+// Match 判断请求是否在免限流名单中。clientIP 应为网关按可信代理规则解析出的地址，
+// 不能直接使用 X-Forwarded-For，否则客户端可以伪造来源绕过限流。
+func (b *Bypass) Match(clientIP string, header http.Header, apiKeyHeader string) bool {
+	if len(b.prefixes) > 0 {
+		if addr, err := netip.ParseAddr(clientIP); err == nil {
+			addr = addr.Unmap()
+			for _, prefix := range b.prefixes {
+				if prefix.Contains(addr) {
+					return true
+				}
+			}
+		}
+	}
+	if len(b.apiKeys) > 0 && apiKeyHeader != "" {
+		if key := header.Get(apiKeyHeader); key != "" {
+			if _, ok := b.apiKeys[key]; ok {
+				return true
+			}
+		}
+	}
+	for name, allowed := range b.headers {
+		for _, v := range header.Values(name) {
+			if _, ok := allowed[v]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
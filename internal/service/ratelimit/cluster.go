@@ -0,0 +1,166 @@
+// file: internal/service/ratelimit/cluster.go
+package ratelimit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"gateway.example/go-gateway/internal/config"
+	"gateway.example/go-gateway/internal/core/limiter"
+)
+
+// DefaultClusterSyncInterval 未配置 sync_interval 时推送计数的周期
+const DefaultClusterSyncInterval = time.Second
+
+// ClusterSyncPath 接收其他副本计数的管理接口路径 (相对于管理接口前缀)
+const ClusterSyncPath = "/ratelimit/cluster/sync"
+
+// ClusterCounts 是一个副本推送给其他副本的 cluster_window 规则计数
+type ClusterCounts struct {
+	Node  string                  `json:"node"`
+	Rules map[string]WindowCounts `json:"rules"`
+}
+
+// WindowCounts 是一条规则在某个窗口内的本地计数
+type WindowCounts struct {
+	Start  int64            `json:"start"` // 窗口起始时间 (Unix 毫秒)
+	Counts map[string]int64 `json:"counts"`
+}
+
+// nodeID 返回本副本的标识，未配置时使用主机名
+func nodeID(cfg config.ClusterConfig) string {
+	if cfg.NodeID != "" {
+		return cfg.NodeID
+	}
+	if host, err := os.Hostname(); err == nil && host != "" {
+		return host
+	}
+	return "gateway"
+}
+
+// MergeClusterCounts 实现了 Service 接口。本副本自己的计数与未知规则会被忽略。
+func (s *service) MergeClusterCounts(counts ClusterCounts) error {
+	if counts.Node == "" {
+		return fmt.Errorf("node 不能为空")
+	}
+	if counts.Node == s.nodeID {
+		return nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for name, window := range counts.Rules {
+		entry, exists := s.rules[name]
+		if !exists {
+			continue
+		}
+		if cw, ok := entry.limiter.(*limiter.ClusterWindow); ok {
+			cw.Merge(counts.Node, time.UnixMilli(window.Start), window.Counts)
+		}
+	}
+	return nil
+}
+
+// clusterCounts 收集所有 cluster_window 规则在当前窗口内的本地计数
+func (s *service) clusterCounts() ClusterCounts {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	counts := ClusterCounts{Node: s.nodeID, Rules: make(map[string]WindowCounts)}
+	for name, entry := range s.rules {
+		if cw, ok := entry.limiter.(*limiter.ClusterWindow); ok {
+			start, local := cw.Snapshot()
+			counts.Rules[name] = WindowCounts{Start: start.UnixMilli(), Counts: local}
+		}
+	}
+	return counts
+}
+
+// syncCluster 定期把本地计数推送给所有其他副本，直到服务关闭。
+// 推送失败只影响限流的准确性，不影响请求处理；每个副本只在状态变化时记录日志。
+func (s *service) syncCluster(cfg config.ClusterConfig) {
+	interval := cfg.SyncInterval
+	if interval <= 0 {
+		interval = DefaultClusterSyncInterval
+	}
+	client := &http.Client{Timeout: interval}
+	failing := make(map[string]bool, len(cfg.Peers))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		counts := s.clusterCounts()
+		if len(counts.Rules) == 0 {
+			continue
+		}
+		body, err := json.Marshal(counts)
+		if err != nil {
+			continue
+		}
+
+		errs := make([]error, len(cfg.Peers))
+		var wg sync.WaitGroup
+		for i, peer := range cfg.Peers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs[i] = pushCounts(s.ctx, client, peer, cfg.Token, body)
+			}()
+		}
+		wg.Wait()
+		if s.ctx.Err() != nil {
+			return
+		}
+
+		for i, peer := range cfg.Peers {
+			switch {
+			case errs[i] != nil && !failing[peer]:
+				failing[peer] = true
+				s.log.Warn(s.ctx, "Failed to sync rate limit counts to peer",
+					"peer", peer,
+					"error", errs[i].Error(),
+					"service", "ratelimit",
+					"action", "cluster_sync_failed")
+			case errs[i] == nil && failing[peer]:
+				failing[peer] = false
+				s.log.Info(s.ctx, "Rate limit counts sync to peer recovered",
+					"peer", peer,
+					"service", "ratelimit",
+					"action", "cluster_sync_recovered")
+			}
+		}
+	}
+}
+
+// pushCounts 把计数推送给一个副本
+func pushCounts(ctx context.Context, client *http.Client, peer, token string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(peer, "/")+ClusterSyncPath, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("副本返回状态码 %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+package ratelimit
+
+import "gateway.example/go-gateway/pkg/metrics"
+
+// 限流判定结果，用作指标标签
+const (
+	resultAllowed = "allowed"
+	resultBlocked = "blocked"
+)
+
+var ruleDecisions = metrics.NewCounterVec("gateway_ratelimit_decisions_total",
+	"限流判定次数，按规则与结果 (allowed / blocked) 统计",
+	"rule", "result")
@@ -0,0 +1,204 @@
+// file: internal/service/ratelimit/offenders.go
+package ratelimit
+
+import (
+	"container/heap"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// offenderWindow 统计窗口长度，报告覆盖上一个与当前窗口，即最近 1~2 分钟
+	offenderWindow = time.Minute
+	// maxTrackedIdentifiers 每条规则每个窗口最多统计的标识符数量，避免大量随机标识 (如伪造的 API Key) 耗尽内存。
+	// 超出后按 space-saving 算法淘汰计数最少的标识符，新标识符继承其计数，窗口后期出现的高频标识符仍会被统计到
+	maxTrackedIdentifiers = 10000
+)
+
+// IdentifierStats 是一个标识符在统计窗口内的放行与拒绝次数
+type IdentifierStats struct {
+	Identifier string `json:"identifier"`
+	Allowed    int64  `json:"allowed"`
+	Blocked    int64  `json:"blocked"`
+	// MaxError 计数可能多算的上限: 标识符替换被淘汰的标识符时继承的计数，真实次数不少于计数减去该值
+	MaxError int64 `json:"max_error,omitempty"`
+}
+
+// TopReport 是一条规则在统计窗口内请求最多与被拒绝最多的标识符
+type TopReport struct {
+	Rule  string    `json:"rule"`
+	Since time.Time `json:"since"`
+	// Consuming 按放行次数排序，Blocked 按拒绝次数排序
+	Consuming []IdentifierStats `json:"consuming"`
+	Blocked   []IdentifierStats `json:"blocked"`
+	// Truncated 表示窗口内标识符数量超过上限，计数少的标识符被淘汰，报告中的计数为近似值 (见 MaxError)
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// identifierCount 是单个窗口内的计数
+type identifierCount struct {
+	identifier       string
+	allowed, blocked int64
+	overcount        int64 // 从被淘汰的标识符继承的计数
+	index            int   // 在 countHeap 中的位置
+}
+
+func (c *identifierCount) total() int64 { return c.allowed + c.blocked }
+
+// countHeap 按总次数排列的最小堆，堆顶是淘汰时替换的标识符
+type countHeap []*identifierCount
+
+func (h countHeap) Len() int           { return len(h) }
+func (h countHeap) Less(i, j int) bool { return h[i].total() < h[j].total() }
+func (h countHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+func (h *countHeap) Push(x any) {
+	c := x.(*identifierCount)
+	c.index = len(*h)
+	*h = append(*h, c)
+}
+func (h *countHeap) Pop() any {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
+
+// offenderCounts 单个窗口内各标识符的计数
+type offenderCounts struct {
+	counts  map[string]*identifierCount
+	heap    countHeap
+	evicted bool // 是否有标识符被淘汰
+}
+
+func newOffenderCounts(capacity int) *offenderCounts {
+	return &offenderCounts{counts: make(map[string]*identifierCount, capacity)}
+}
+
+// offenderTracker 按两个相邻的固定窗口统计一条规则中各标识符的放行与拒绝次数
+type offenderTracker struct {
+	mu       sync.Mutex
+	started  time.Time // 当前窗口的开始时间
+	current  *offenderCounts
+	previous *offenderCounts
+}
+
+func newOffenderTracker(now time.Time) *offenderTracker {
+	return &offenderTracker{
+		started: now.Truncate(offenderWindow),
+		current: newOffenderCounts(0),
+	}
+}
+
+// rotate 在窗口结束后切换到新窗口，调用方需持有锁
+func (t *offenderTracker) rotate(now time.Time) {
+	elapsed := now.Sub(t.started)
+	if elapsed < offenderWindow {
+		return
+	}
+	if elapsed < 2*offenderWindow {
+		t.previous = t.current
+	} else {
+		// 超过一个完整窗口没有请求，旧数据已不属于报告范围
+		t.previous = nil
+	}
+	capacity := 0
+	if t.previous != nil {
+		capacity = len(t.previous.counts)
+	}
+	t.current = newOffenderCounts(capacity)
+	t.started = now.Truncate(offenderWindow)
+}
+
+// record 记录一次限流判定
+func (t *offenderTracker) record(identifier string, allowed bool, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rotate(now)
+
+	w := t.current
+	c, exists := w.counts[identifier]
+	if !exists {
+		if len(w.counts) >= maxTrackedIdentifiers {
+			// space-saving: 替换计数最少的标识符并继承其计数
+			c = w.heap[0]
+			delete(w.counts, c.identifier)
+			c.identifier = identifier
+			c.overcount = c.total()
+			w.evicted = true
+		} else {
+			c = &identifierCount{identifier: identifier}
+			heap.Push(&w.heap, c)
+		}
+		w.counts[identifier] = c
+	}
+	if allowed {
+		c.allowed++
+	} else {
+		c.blocked++
+	}
+	heap.Fix(&w.heap, c.index)
+}
+
+// top 汇总最近两个窗口，返回放行与拒绝次数各自最多的 n 个标识符
+func (t *offenderTracker) top(rule string, n int, now time.Time) TopReport {
+	t.mu.Lock()
+	t.rotate(now)
+	since := t.started
+	if t.previous != nil {
+		since = since.Add(-offenderWindow)
+	}
+	truncated := false
+	merged := make(map[string]IdentifierStats)
+	for _, window := range []*offenderCounts{t.previous, t.current} {
+		if window == nil {
+			continue
+		}
+		truncated = truncated || window.evicted
+		for id, c := range window.counts {
+			s := merged[id]
+			s.Identifier = id
+			s.Allowed += c.allowed
+			s.Blocked += c.blocked
+			s.MaxError += c.overcount
+			merged[id] = s
+		}
+	}
+	t.mu.Unlock()
+
+	all := make([]IdentifierStats, 0, len(merged))
+	for _, s := range merged {
+		all = append(all, s)
+	}
+	return TopReport{
+		Rule:      rule,
+		Since:     since,
+		Consuming: topBy(all, n, func(s IdentifierStats) int64 { return s.Allowed }),
+		Blocked:   topBy(all, n, func(s IdentifierStats) int64 { return s.Blocked }),
+		Truncated: truncated,
+	}
+}
+
+// topBy 返回按 count 降序的前 n 项，count 为 0 的项不计入
+func topBy(all []IdentifierStats, n int, count func(IdentifierStats) int64) []IdentifierStats {
+	result := make([]IdentifierStats, 0, min(n, len(all)))
+	for _, s := range all {
+		if count(s) > 0 {
+			result = append(result, s)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if ci, cj := count(result[i]), count(result[j]); ci != cj {
+			return ci > cj
+		}
+		return result[i].Identifier < result[j].Identifier
+	})
+	if len(result) > n {
+		result = result[:n]
+	}
+	return result
+}
@@ -3,26 +3,97 @@ package ratelimit
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
+	"reflect"
+	"sort"
 	"sync"
+	"time"
 
 	"gateway.example/go-gateway/internal/config"
 	"gateway.example/go-gateway/internal/core/limiter"
+	"gateway.example/go-gateway/internal/redisclient"
 	"gateway.example/go-gateway/pkg/logger"
+	"github.com/redis/go-redis/v9"
 )
 
+// ErrRuleNotFound 限流规则未定义
+var ErrRuleNotFound = errors.New("rate limit rule not found")
+
 // Service 定义了限流服务的接口。
 // 它解耦合了插件层与具体的限流逻辑实现。
 type Service interface {
-	CheckLimit(ctx context.Context, ruleName, identifier string) (bool, error)
+	// Allow 按规则判断标识符的请求是否放行。并发限流规则放行时会占用一个名额，
+	// 调用方必须在请求结束后调用 Decision.Release
+	Allow(ctx context.Context, ruleName, identifier string) (limiter.Decision, error)
+	HasRule(ruleName string) bool
+	// RuleType 返回规则配置的限流器类型
+	RuleType(ruleName string) (string, bool)
+	// Bypass 返回规则的免限流名单，未配置时为 nil
+	Bypass(ruleName string) *Bypass
+	// Reload 按新的配置重建全部限流器，任何规则创建失败时保持原有规则不变
+	Reload(cfg config.RateLimitingConfig) error
+	// Rules 返回所有规则当前生效的参数与临时调整
+	Rules() []RuleState
+	// SetOverride 在 ttl 内把规则的限额按 factor 缩放 (<1 收紧，>1 放宽)，到期后自动恢复；
+	// source 标明调整来自管理接口 (OverrideAdmin) 还是自适应控制器 (OverrideAdaptive)
+	SetOverride(ruleName string, factor float64, ttl time.Duration, source string) (RuleState, error)
+	// ClearOverride 立即撤销规则的临时调整
+	ClearOverride(ruleName string) (RuleState, error)
+	// TopIdentifiers 返回规则最近 1~2 分钟内放行与拒绝次数最多的 n 个标识符，用于实时排查滥用
+	TopIdentifiers(ruleName string, n int) (TopReport, error)
+	// MergeClusterCounts 合并其他副本推送的 cluster_window 规则计数
+	MergeClusterCounts(counts ClusterCounts) error
 	Close() error
 }
 
+// 临时调整的来源
+const (
+	OverrideAdmin    = "admin"
+	OverrideAdaptive = "adaptive"
+)
+
+// Override 是对规则的临时调整
+type Override struct {
+	Factor    float64   `json:"factor"`
+	Source    string    `json:"source"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// RuleState 是规则当前的状态，Limits 为调整后实际生效的限额
+type RuleState struct {
+	Name     string         `json:"name"`
+	Type     string         `json:"type"`
+	Limits   map[string]any `json:"limits,omitempty"`
+	Override *Override      `json:"override,omitempty"`
+	// Adaptive 是规则的自适应配置，供自适应控制器读取
+	Adaptive *config.AdaptiveSettings `json:"-"`
+}
+
+// ruleEntry 是一条规则及其限流器实例
+type ruleEntry struct {
+	rule     config.RateLimiterRule // 配置中的原始规则
+	limiter  limiter.Limiter
+	cancel   context.CancelFunc // 停止限流器的后台任务
+	bypass   *Bypass
+	override *Override
+	timer    *time.Timer // 临时调整到期后恢复原始规则
+}
+
 // service 是 Service 接口的具体实现。
 type service struct {
 	mu sync.RWMutex
-	// 只需存储限流器实例即可，规则配置已在实例内部。
-	limiters map[string]limiter.Limiter
+	// 规则名到规则及限流器实例的映射，规则配置已在实例内部。
+	rules map[string]*ruleEntry
+	// 分布式限流器共用的 Redis 客户端，没有此类规则时为 nil。
+	redis    *redis.Client
+	redisCfg config.RedisConfig
+	// cluster_window 规则在副本之间交换计数的设置与本副本的标识
+	clusterCfg config.ClusterConfig
+	nodeID     string
+	// 规则名 -> *offenderTracker，按规则名保存，重新加载或临时调整规则后统计不中断
+	offenders sync.Map
 	// 用于管理所有限流器生命周期的 context。
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -35,10 +106,13 @@ func NewService(cfg config.RateLimitingConfig, log logger.Logger) (Service, erro
 	ctx, cancel := context.WithCancel(context.Background())
 
 	s := &service{
-		limiters: make(map[string]limiter.Limiter),
-		ctx:      ctx,
-		cancel:   cancel,
-		log:      log,
+		rules:      make(map[string]*ruleEntry),
+		redisCfg:   cfg.Redis,
+		clusterCfg: cfg.Cluster,
+		nodeID:     nodeID(cfg.Cluster),
+		ctx:        ctx,
+		cancel:     cancel,
+		log:        log,
 	}
 
 	log.Info(ctx, "Initializing rate limit service",
@@ -46,62 +120,215 @@ func NewService(cfg config.RateLimitingConfig, log logger.Logger) (Service, erro
 		"service", "ratelimit",
 		"action", "initialize")
 
-	for _, rule := range cfg.Rules {
-		// 复制 rule 变量，防止闭包问题
-		currentRule := rule
+	rules, err := s.buildRules(cfg.Rules)
+	if err != nil {
+		cancel()
+		if s.redis != nil {
+			_ = s.redis.Close()
+		}
+		return nil, err
+	}
+	s.rules = rules
+
+	if len(cfg.Cluster.Peers) > 0 {
+		log.Info(ctx, "Rate limit cluster sync enabled",
+			"node_id", s.nodeID,
+			"peers", len(cfg.Cluster.Peers),
+			"service", "ratelimit",
+			"action", "cluster_sync_start")
+		go s.syncCluster(cfg.Cluster)
+	}
 
+	log.Info(ctx, "Rate limit service initialization completed",
+		"active_limiters", len(s.rules),
+		"service", "ratelimit",
+		"action", "initialization_completed")
+
+	return s, nil
+}
+
+// buildRules 为每条规则创建限流器，任何一条失败时停止已创建的限流器并返回错误。
+func (s *service) buildRules(rules []config.RateLimiterRule) (map[string]*ruleEntry, error) {
+	entries := make(map[string]*ruleEntry, len(rules))
+	for _, rule := range rules {
+		if _, exists := entries[rule.Name]; exists {
+			stopEntries(entries)
+			return nil, fmt.Errorf("限流规则 '%s' 重复定义", rule.Name)
+		}
+		bypass, err := newBypass(rule)
+		if err == nil && rule.Adaptive != nil {
+			err = validateAdaptive(rule)
+		}
 		var lim limiter.Limiter
-		var err error
-
-		switch currentRule.Type {
-		case "memory_token_bucket":
-			// 使用新的构造函数，并传入 service 的 context。
-			lim = limiter.NewMemoryTokenBucket(
-				s.ctx, // 传入 context
-				currentRule.TokenBucket.Capacity,
-				currentRule.TokenBucket.RefillRate,
-				currentRule.Name,
-			)
-		case "", "noop":
-			// 引用 core/limiter 包中的 NoOpLimiter。
-			lim = &limiter.NoOpLimiter{}
-		default:
-			err = fmt.Errorf("未知的限流器类型: %s for rule %s", currentRule.Type, currentRule.Name)
+		var cancel context.CancelFunc
+		if err == nil {
+			lim, cancel, err = s.newLimiter(rule)
 		}
-
 		if err != nil {
 			// 如果有任何一个限流器创建失败，则立即取消上下文并返回错误。
-			log.Error(ctx, "Failed to initialize rate limiter",
-				"rule_name", currentRule.Name,
-				"limiter_type", currentRule.Type,
+			s.log.Error(s.ctx, "Failed to initialize rate limiter",
+				"rule_name", rule.Name,
+				"limiter_type", rule.Type,
 				"error", err.Error(),
 				"service", "ratelimit",
 				"action", "initialization_failed")
-			cancel()
+			stopEntries(entries)
 			return nil, err
 		}
-
-		s.limiters[currentRule.Name] = lim
-		log.Info(ctx, "Successfully initialized rate limit rule",
-			"rule_name", currentRule.Name,
+		entries[rule.Name] = &ruleEntry{rule: rule, limiter: lim, cancel: cancel, bypass: bypass}
+		s.log.Info(s.ctx, "Successfully initialized rate limit rule",
+			"rule_name", rule.Name,
 			"limiter_type", lim.Name(),
 			"service", "ratelimit",
 			"action", "rule_initialized")
 	}
+	return entries, nil
+}
 
-	log.Info(ctx, "Rate limit service initialization completed",
-		"active_limiters", len(s.limiters),
-		"service", "ratelimit",
-		"action", "initialization_completed")
+// newLimiter 按规则创建限流器，返回的 cancel 用于停止其后台任务。
+func (s *service) newLimiter(rule config.RateLimiterRule) (limiter.Limiter, context.CancelFunc, error) {
+	ctx, cancel := context.WithCancel(s.ctx)
 
-	return s, nil
+	var lim limiter.Limiter
+	var err error
+
+	switch rule.Type {
+	case "memory_token_bucket":
+		// 使用新的构造函数，并传入规则自己的 context。
+		lim = limiter.NewMemoryTokenBucket(
+			ctx, // 传入 context
+			rule.TokenBucket.Capacity,
+			rule.TokenBucket.RefillRate,
+			rule.TokenBucket.MaxBuckets,
+			rule.Name,
+		)
+	case "sliding_window":
+		settings := rule.SlidingWindow
+		if settings.Window <= 0 || settings.MaxRequests <= 0 {
+			err = fmt.Errorf("规则 '%s' 的 window 和 maxRequests 必须为正数", rule.Name)
+			break
+		}
+		lim = limiter.NewSlidingWindow(ctx, settings.Window, settings.MaxRequests, rule.Name)
+	case "concurrency":
+		settings := rule.Concurrency
+		switch {
+		case settings.MaxInFlight <= 0:
+			err = fmt.Errorf("规则 '%s' 的 maxInFlight 必须为正数", rule.Name)
+		case settings.QueueSize < 0:
+			err = fmt.Errorf("规则 '%s' 的 queueSize 不能为负数", rule.Name)
+		case settings.QueueSize > 0 && settings.QueueTimeout <= 0:
+			err = fmt.Errorf("规则 '%s' 配置了 queueSize 时 queueTimeout 必须为正数", rule.Name)
+		default:
+			lim = limiter.NewConcurrency(settings.MaxInFlight, settings.QueueSize, settings.QueueTimeout, rule.Name)
+		}
+	case "leaky_bucket":
+		settings := rule.LeakyBucket
+		if settings.Rate <= 0 || settings.MaxDelay < 0 {
+			err = fmt.Errorf("规则 '%s' 的 rate 必须为正数且 maxDelay 不能为负数", rule.Name)
+			break
+		}
+		lim = limiter.NewLeakyBucket(ctx, settings.Rate, settings.MaxDelay, rule.Name)
+	case "cluster_window":
+		settings := rule.ClusterWindow
+		if settings.Window <= 0 || settings.MaxRequests <= 0 {
+			err = fmt.Errorf("规则 '%s' 的 window 和 maxRequests 必须为正数", rule.Name)
+			break
+		}
+		lim = limiter.NewClusterWindow(settings.Window, settings.MaxRequests, rule.Name)
+	case "redis_token_bucket":
+		lim, err = s.newRedisTokenBucket(rule)
+	case "", "noop":
+		// 引用 core/limiter 包中的 NoOpLimiter。
+		lim = &limiter.NoOpLimiter{}
+	default:
+		err = fmt.Errorf("未知的限流器类型: %s for rule %s", rule.Type, rule.Name)
+	}
+
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	return lim, cancel, nil
+}
+
+// newRedisTokenBucket 创建 Redis 令牌桶，首次使用时建立共享的 Redis 客户端。
+func (s *service) newRedisTokenBucket(rule config.RateLimiterRule) (limiter.Limiter, error) {
+	if rule.TokenBucket.Capacity <= 0 || rule.TokenBucket.RefillRate <= 0 {
+		return nil, fmt.Errorf("规则 '%s' 的 capacity 和 refillRate 必须为正数", rule.Name)
+	}
+	var failOpen bool
+	switch rule.FailurePolicy {
+	case "", "open":
+		failOpen = true
+	case "closed":
+		failOpen = false
+	default:
+		return nil, fmt.Errorf("规则 '%s' 的 failure_policy 只能是 open 或 closed", rule.Name)
+	}
+
+	if s.redis == nil {
+		client, err := redisclient.New(s.redisCfg)
+		if err != nil {
+			return nil, fmt.Errorf("规则 '%s' 需要 rate_limiting.redis 配置: %w", rule.Name, err)
+		}
+		// Redis 暂时不可用不影响启动，由 failure_policy 决定请求如何处理
+		if err := client.Ping(s.ctx).Err(); err != nil {
+			s.log.Warn(s.ctx, "Redis is unreachable at startup",
+				"addr", s.redisCfg.Addr,
+				"error", err.Error(),
+				"service", "ratelimit",
+				"action", "redis_ping_failed")
+		}
+		s.redis = client
+	}
+
+	return limiter.NewRedisTokenBucket(s.redis, rule.TokenBucket.Capacity, rule.TokenBucket.RefillRate,
+		rule.Name, s.redisCfg.KeyPrefix, failOpen, s.log), nil
+}
+
+// HasRule 判断限流规则是否已定义，供插件在启动时校验配置。
+func (s *service) HasRule(ruleName string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, exists := s.rules[ruleName]
+	return exists
 }
 
-// CheckLimit 实现了 Service 接口。它检查给定的标识符是否被特定规则所允许。
-func (s *service) CheckLimit(ctx context.Context, ruleName, identifier string) (bool, error) {
+// RuleType 返回规则配置的限流器类型，未配置类型的规则为 "noop"。
+func (s *service) RuleType(ruleName string) (string, bool) {
 	s.mu.RLock()
-	lim, exists := s.limiters[ruleName]
-	s.mu.RUnlock()
+	defer s.mu.RUnlock()
+	entry, exists := s.rules[ruleName]
+	if !exists {
+		return "", false
+	}
+	return ruleType(entry.rule), true
+}
+
+// Bypass 实现了 Service 接口。
+func (s *service) Bypass(ruleName string) *Bypass {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if entry, exists := s.rules[ruleName]; exists {
+		return entry.bypass
+	}
+	return nil
+}
+
+// limiterFor 返回规则当前的限流器实例。
+func (s *service) limiterFor(ruleName string) (limiter.Limiter, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	entry, exists := s.rules[ruleName]
+	if !exists {
+		return nil, false
+	}
+	return entry.limiter, true
+}
+
+// Allow 实现了 Service 接口。它检查给定的标识符是否被特定规则所允许。
+func (s *service) Allow(ctx context.Context, ruleName, identifier string) (limiter.Decision, error) {
+	lim, exists := s.limiterFor(ruleName)
 
 	if !exists {
 		// 这是一个配置错误：插件引用了一个不存在的规则。
@@ -110,15 +337,15 @@ func (s *service) CheckLimit(ctx context.Context, ruleName, identifier string) (
 			"identifier", identifier,
 			"service", "ratelimit",
 			"action", "rule_not_found")
-		return false, fmt.Errorf("限流规则 '%s' 未定义", ruleName)
+		return limiter.Decision{}, fmt.Errorf("限流规则 '%s' 未定义", ruleName)
 	}
 
-	// 调用新的、简化的 Allow 接口。
 	// 注意: 这里传入的 ctx 是来自上游请求的 context，用于处理请求级别的超时。
 	// 而限流器内部运行的后台任务使用的是 service 级别的 ctx。
-	isAllowed := lim.Allow(ctx, identifier)
+	decision := lim.Allow(ctx, identifier)
+	s.observe(ruleName, identifier, decision.Allowed)
 
-	if isAllowed {
+	if decision.Allowed {
 		s.log.Debug(ctx, "Rate limit check passed",
 			"rule_name", ruleName,
 			"identifier", identifier,
@@ -134,20 +361,273 @@ func (s *service) CheckLimit(ctx context.Context, ruleName, identifier string) (
 			"action", "check_failed")
 	}
 
-	return isAllowed, nil
+	return decision, nil
+}
+
+// observe 记录一次限流判定的指标与标识符统计
+func (s *service) observe(ruleName, identifier string, allowed bool) {
+	result := resultAllowed
+	if !allowed {
+		result = resultBlocked
+	}
+	ruleDecisions.WithLabelValues(ruleName, result).Inc()
+
+	now := time.Now()
+	tracker, ok := s.offenders.Load(ruleName)
+	if !ok {
+		tracker, _ = s.offenders.LoadOrStore(ruleName, newOffenderTracker(now))
+	}
+	tracker.(*offenderTracker).record(identifier, allowed, now)
+}
+
+// TopIdentifiers 实现了 Service 接口。
+func (s *service) TopIdentifiers(ruleName string, n int) (TopReport, error) {
+	if !s.HasRule(ruleName) {
+		return TopReport{}, fmt.Errorf("%w: %s", ErrRuleNotFound, ruleName)
+	}
+	now := time.Now()
+	tracker, ok := s.offenders.Load(ruleName)
+	if !ok {
+		return TopReport{Rule: ruleName, Since: now.Truncate(offenderWindow), Consuming: []IdentifierStats{}, Blocked: []IdentifierStats{}}, nil
+	}
+	return tracker.(*offenderTracker).top(ruleName, n, now), nil
+}
+
+// Reload 实现了 Service 接口。
+// 未到期的临时调整会应用到新规则上；新限流器从空状态开始计数。
+// Redis 连接在首次创建后保持不变，修改 rate_limiting.redis 需要重启网关。
+func (s *service) Reload(cfg config.RateLimitingConfig) error {
+	s.log.Info(s.ctx, "Reloading rate limit rules",
+		"total_rules", len(cfg.Rules),
+		"service", "ratelimit",
+		"action", "reload_start")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.redis == nil {
+		s.redisCfg = cfg.Redis
+	} else if cfg.Redis != s.redisCfg {
+		s.log.Warn(s.ctx, "Redis settings changed, restart required to apply",
+			"service", "ratelimit",
+			"action", "reload_redis_ignored")
+	}
+	if !reflect.DeepEqual(cfg.Cluster, s.clusterCfg) {
+		s.log.Warn(s.ctx, "Cluster settings changed, restart required to apply",
+			"service", "ratelimit",
+			"action", "reload_cluster_ignored")
+	}
+
+	entries, err := s.buildRules(cfg.Rules)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for name, old := range s.rules {
+		if old.timer != nil {
+			old.timer.Stop()
+		}
+		entry, kept := entries[name]
+		if !kept || old.override == nil || !old.override.ExpiresAt.After(now) {
+			continue
+		}
+		if err := s.applyOverride(entry, old.override); err != nil {
+			s.log.Warn(s.ctx, "Dropped rate limit override after reload",
+				"rule_name", name,
+				"error", err.Error(),
+				"service", "ratelimit",
+				"action", "reload_override_dropped")
+		}
+	}
+	stopEntries(s.rules)
+	s.rules = entries
+	s.offenders.Range(func(name, _ any) bool {
+		if _, kept := entries[name.(string)]; !kept {
+			s.offenders.Delete(name)
+		}
+		return true
+	})
+
+	s.log.Info(s.ctx, "Rate limit rules reloaded",
+		"active_limiters", len(s.rules),
+		"service", "ratelimit",
+		"action", "reload_completed")
+	return nil
+}
+
+// Rules 实现了 Service 接口，按规则名排序。
+func (s *service) Rules() []RuleState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	states := make([]RuleState, 0, len(s.rules))
+	for _, entry := range s.rules {
+		states = append(states, entry.state())
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Name < states[j].Name })
+	return states
+}
+
+// SetOverride 实现了 Service 接口。
+// 调整就地修改限流器的限额，该规则已有的计数 (令牌、窗口、在途请求) 保留。
+func (s *service) SetOverride(ruleName string, factor float64, ttl time.Duration, source string) (RuleState, error) {
+	if factor <= 0 || math.IsInf(factor, 0) || math.IsNaN(factor) {
+		return RuleState{}, fmt.Errorf("factor 必须为正数")
+	}
+	if ttl <= 0 {
+		return RuleState{}, fmt.Errorf("ttl 必须为正数")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, exists := s.rules[ruleName]
+	if !exists {
+		return RuleState{}, fmt.Errorf("%w: %s", ErrRuleNotFound, ruleName)
+	}
+	expiresAt := time.Now().Add(ttl)
+	// 比例不变时只延长有效期
+	if o := entry.override; o != nil && o.Factor == factor && o.Source == source {
+		o.ExpiresAt = expiresAt
+		entry.timer.Reset(ttl)
+		return entry.state(), nil
+	}
+	if err := s.applyOverride(entry, &Override{Factor: factor, Source: source, ExpiresAt: expiresAt}); err != nil {
+		return RuleState{}, err
+	}
+
+	s.log.Warn(s.ctx, "Rate limit override applied",
+		"rule_name", ruleName,
+		"factor", factor,
+		"ttl", ttl.String(),
+		"source", source,
+		"service", "ratelimit",
+		"action", "override_applied")
+	return entry.state(), nil
+}
+
+// ClearOverride 实现了 Service 接口。
+func (s *service) ClearOverride(ruleName string) (RuleState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, exists := s.rules[ruleName]
+	if !exists {
+		return RuleState{}, fmt.Errorf("%w: %s", ErrRuleNotFound, ruleName)
+	}
+	if entry.override != nil {
+		if err := s.restore(entry); err != nil {
+			return RuleState{}, err
+		}
+	}
+	return entry.state(), nil
+}
+
+// applyOverride 按调整后的限额就地调整限流器，并在到期后恢复原始规则。调用方需持有写锁。
+// 限流器保留各标识符的令牌、计数与已占用的并发名额，调整不会让客户端重新获得完整的突发额度
+func (s *service) applyOverride(entry *ruleEntry, o *Override) error {
+	scaled, err := scaleRule(entry.rule, o.Factor)
+	if err != nil {
+		return err
+	}
+	if err := s.rescale(entry, scaled); err != nil {
+		return err
+	}
+	entry.override = o
+
+	if entry.timer != nil {
+		entry.timer.Stop()
+	}
+	entry.timer = time.AfterFunc(time.Until(o.ExpiresAt), func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		// 规则可能已被重新加载或调整，只恢复仍是本次调整且未被延期的规则
+		if current, exists := s.rules[entry.rule.Name]; !exists || current != entry || entry.override != o || time.Now().Before(o.ExpiresAt) {
+			return
+		}
+		if err := s.restore(entry); err != nil {
+			s.log.Error(s.ctx, "Failed to restore rate limit rule after override expired",
+				"rule_name", entry.rule.Name,
+				"error", err.Error(),
+				"service", "ratelimit",
+				"action", "override_restore_failed")
+			return
+		}
+		s.log.Info(s.ctx, "Rate limit override expired",
+			"rule_name", entry.rule.Name,
+			"service", "ratelimit",
+			"action", "override_expired")
+	})
+	return nil
+}
+
+// rescale 把限流器的限额调整为 rule 中的值；限流器不支持就地调整时按 rule 重建。调用方需持有写锁。
+func (s *service) rescale(entry *ruleEntry, rule config.RateLimiterRule) error {
+	switch lim := entry.limiter.(type) {
+	case *limiter.MemoryTokenBucket:
+		lim.SetLimits(rule.TokenBucket.Capacity, rule.TokenBucket.RefillRate)
+	case *limiter.RedisTokenBucket:
+		lim.SetLimits(rule.TokenBucket.Capacity, rule.TokenBucket.RefillRate)
+	case *limiter.SlidingWindow:
+		lim.SetMaxRequests(rule.SlidingWindow.MaxRequests)
+	case *limiter.ClusterWindow:
+		lim.SetMaxRequests(rule.ClusterWindow.MaxRequests)
+	case *limiter.Concurrency:
+		lim.SetMaxInFlight(rule.Concurrency.MaxInFlight)
+	case *limiter.LeakyBucket:
+		lim.SetRate(rule.LeakyBucket.Rate)
+	default:
+		next, cancel, err := s.newLimiter(rule)
+		if err != nil {
+			return err
+		}
+		entry.swap(next, cancel)
+	}
+	return nil
+}
+
+// restore 按原始规则就地恢复限流器的限额并撤销临时调整。调用方需持有写锁。
+func (s *service) restore(entry *ruleEntry) error {
+	if err := s.rescale(entry, entry.rule); err != nil {
+		return err
+	}
+	entry.override = nil
+	if entry.timer != nil {
+		entry.timer.Stop()
+		entry.timer = nil
+	}
+	return nil
 }
 
 // Close 优雅地关闭所有限流器（例如，停止后台的清理goroutine）。
 func (s *service) Close() error {
 	ctx := context.Background()
 
+	s.mu.Lock()
+	activeLimiters := len(s.rules)
+	for _, entry := range s.rules {
+		if entry.timer != nil {
+			entry.timer.Stop()
+		}
+	}
+	s.mu.Unlock()
+
 	s.log.Info(ctx, "Starting graceful shutdown of rate limit service",
-		"active_limiters", len(s.limiters),
+		"active_limiters", activeLimiters,
 		"service", "ratelimit",
 		"action", "shutdown_start")
 
 	// 通过取消 context 来通知所有子 goroutine 停止。
 	s.cancel()
+	if s.redis != nil {
+		if err := s.redis.Close(); err != nil {
+			s.log.Warn(ctx, "Failed to close redis client",
+				"error", err.Error(),
+				"service", "ratelimit",
+				"action", "shutdown_redis")
+		}
+	}
 	// 通常，这里可以加一个等待组（WaitGroup）来确保所有 goroutine 都已退出，
 	// 但对于 MemoryTokenBucket 的简单清理任务，直接 cancel 已经足够。
 
@@ -157,3 +637,85 @@ func (s *service) Close() error {
 
 	return nil
 }
+
+// swap 替换限流器并停止旧限流器的后台任务。
+func (e *ruleEntry) swap(lim limiter.Limiter, cancel context.CancelFunc) {
+	e.cancel()
+	e.limiter, e.cancel = lim, cancel
+}
+
+func (e *ruleEntry) state() RuleState {
+	rule := e.rule
+	var override *Override
+	if e.override != nil {
+		// 调整成功应用过，缩放不会失败
+		rule, _ = scaleRule(rule, e.override.Factor)
+		o := *e.override
+		override = &o
+	}
+	return RuleState{
+		Name:     rule.Name,
+		Type:     ruleType(rule),
+		Limits:   ruleLimits(rule),
+		Override: override,
+		Adaptive: e.rule.Adaptive,
+	}
+}
+
+func stopEntries(entries map[string]*ruleEntry) {
+	for _, entry := range entries {
+		entry.cancel()
+	}
+}
+
+func ruleType(rule config.RateLimiterRule) string {
+	if rule.Type == "" {
+		return "noop"
+	}
+	return rule.Type
+}
+
+// scaleRule 按比例缩放规则的限额，缩放后至少为 1。
+func scaleRule(rule config.RateLimiterRule, factor float64) (config.RateLimiterRule, error) {
+	scale := func(n int) int {
+		return max(int(math.Round(float64(n)*factor)), 1)
+	}
+	switch rule.Type {
+	case "memory_token_bucket", "redis_token_bucket":
+		rule.TokenBucket.Capacity = scale(rule.TokenBucket.Capacity)
+		rule.TokenBucket.RefillRate = scale(rule.TokenBucket.RefillRate)
+	case "sliding_window":
+		rule.SlidingWindow.MaxRequests = scale(rule.SlidingWindow.MaxRequests)
+	case "cluster_window":
+		rule.ClusterWindow.MaxRequests = scale(rule.ClusterWindow.MaxRequests)
+	case "concurrency":
+		rule.Concurrency.MaxInFlight = scale(rule.Concurrency.MaxInFlight)
+	case "leaky_bucket":
+		rule.LeakyBucket.Rate = scale(rule.LeakyBucket.Rate)
+	default:
+		return rule, fmt.Errorf("规则 '%s' 不限流，无法调整", rule.Name)
+	}
+	return rule, nil
+}
+
+// ruleLimits 返回规则的限额参数，键名与配置文件一致。
+func ruleLimits(rule config.RateLimiterRule) map[string]any {
+	switch rule.Type {
+	case "memory_token_bucket", "redis_token_bucket":
+		return map[string]any{"capacity": rule.TokenBucket.Capacity, "refillRate": rule.TokenBucket.RefillRate}
+	case "sliding_window":
+		return map[string]any{"window": rule.SlidingWindow.Window.String(), "maxRequests": rule.SlidingWindow.MaxRequests}
+	case "cluster_window":
+		return map[string]any{"window": rule.ClusterWindow.Window.String(), "maxRequests": rule.ClusterWindow.MaxRequests}
+	case "concurrency":
+		return map[string]any{
+			"maxInFlight":  rule.Concurrency.MaxInFlight,
+			"queueSize":    rule.Concurrency.QueueSize,
+			"queueTimeout": rule.Concurrency.QueueTimeout.String(),
+		}
+	case "leaky_bucket":
+		return map[string]any{"rate": rule.LeakyBucket.Rate, "maxDelay": rule.LeakyBucket.MaxDelay.String()}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,156 @@
+// package jwks 实现 JSON Web Key (RFC 7517) 公钥的编码与解析。
+// 认证服务通过 /.well-known/jwks.json 发布验证令牌所需的公钥，网关和上游服务可以引入本包解析后自行校验令牌。
+package jwks
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// 解析失败的原因
+var (
+	ErrUnsupportedKey = errors.New("jwks: unsupported key type")
+	ErrInvalidKey     = errors.New("jwks: invalid key")
+)
+
+// Key 是一个公钥的 JWK 表示，只支持 RSA 与 P-256 椭圆曲线公钥
+type Key struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// EC
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// Set 是 /.well-known/jwks.json 返回的公钥集合
+type Set struct {
+	Keys []Key `json:"keys"`
+}
+
+// Find 按 kid 查找公钥
+func (s *Set) Find(kid string) (Key, bool) {
+	for _, k := range s.Keys {
+		if k.Kid == kid {
+			return k, true
+		}
+	}
+	return Key{}, false
+}
+
+// FromPublicKey 把公钥编码为用于签名校验 (use=sig) 的 JWK
+func FromPublicKey(kid, alg string, pub crypto.PublicKey) (Key, error) {
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		return Key{
+			Kty: "RSA", Kid: kid, Use: "sig", Alg: alg,
+			N: encode(k.N.Bytes()),
+			E: encode(big.NewInt(int64(k.E)).Bytes()),
+		}, nil
+	case *ecdsa.PublicKey:
+		if k.Curve != elliptic.P256() {
+			return Key{}, fmt.Errorf("%w: only P-256 curve is supported", ErrUnsupportedKey)
+		}
+		size := (k.Curve.Params().BitSize + 7) / 8
+		return Key{
+			Kty: "EC", Kid: kid, Use: "sig", Alg: alg, Crv: "P-256",
+			X: encode(k.X.FillBytes(make([]byte, size))),
+			Y: encode(k.Y.FillBytes(make([]byte, size))),
+		}, nil
+	default:
+		return Key{}, ErrUnsupportedKey
+	}
+}
+
+// PublicKey 解析 JWK 中的公钥
+func (k Key) PublicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decode(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if len(n) == 0 || len(e) == 0 || len(e) > 4 {
+			return nil, ErrInvalidKey
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("%w: curve %q", ErrUnsupportedKey, k.Crv)
+		}
+		x, err := decode(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		pub := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !pub.Curve.IsOnCurve(pub.X, pub.Y) {
+			return nil, ErrInvalidKey
+		}
+		return pub, nil
+	default:
+		return nil, fmt.Errorf("%w: kty %q", ErrUnsupportedKey, k.Kty)
+	}
+}
+
+// Thumbprint 计算 JWK 的 SHA-256 指纹 (RFC 7638)，常用作 kid
+func (k Key) Thumbprint() (string, error) {
+	var members interface{}
+	switch k.Kty {
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{k.E, k.Kty, k.N}
+	case "EC":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		}{k.Crv, k.Kty, k.X, k.Y}
+	default:
+		return "", ErrUnsupportedKey
+	}
+	data, err := json.Marshal(members)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return encode(sum[:]), nil
+}
+
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decode(s string) ([]byte, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKey, err)
+	}
+	return b, nil
+}
@@ -16,8 +16,8 @@ func DefaultNew() (Logger, error) {
 	return NewWithConfigFile("configs/logs/log.yaml")
 }
 
-// NewWithConfigFile 从YAML配置文件创建并返回一个Logger实例
-func NewWithConfigFile(configPath string) (Logger, error) {
+// NewWithConfigFile 从YAML配置文件创建并返回一个Logger实例，opts 在文件配置之后应用，用于覆盖个别选项
+func NewWithConfigFile(configPath string, opts ...Option) (Logger, error) {
 	// 读取YAML配置文件
 	content, err := os.ReadFile(configPath)
 	if err != nil {
@@ -31,7 +31,7 @@ func NewWithConfigFile(configPath string) (Logger, error) {
 	}
 
 	// 使用解析后的配置创建日志器
-	return new(WithOptions(*options))
+	return new(append([]Option{WithOptions(*options)}, opts...)...)
 }
 
 // WithOptions 从完整的Options结构体创建Option
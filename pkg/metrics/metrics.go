@@ -0,0 +1,373 @@
+// Package metrics 提供轻量的计数器、仪表盘与直方图，
+// 并以 Prometheus 文本格式 (0.0.4) 导出，供网关各组件记录运行指标。
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// DefBuckets 默认的耗时直方图分桶 (秒)
+var DefBuckets = []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// labelSeparator 拼接标签值作为 map 键，使用不会出现在合法 UTF-8 中的字节
+const labelSeparator = "\xff"
+
+// collector 是可以被导出的指标族
+type collector interface {
+	name() string
+	write(w io.Writer)
+}
+
+// Registry 保存一组指标，按名称排序导出
+type Registry struct {
+	mu         sync.RWMutex
+	collectors map[string]collector
+}
+
+func NewRegistry() *Registry {
+	return &Registry{collectors: make(map[string]collector)}
+}
+
+// Default 是包级构造函数使用的默认注册表
+var Default = NewRegistry()
+
+// register 注册指标族，名称重复说明存在编程错误，直接 panic
+func (r *Registry) register(c collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.collectors[c.name()]; exists {
+		panic(fmt.Sprintf("metrics: 指标 '%s' 重复注册", c.name()))
+	}
+	r.collectors[c.name()] = c
+}
+
+// Write 以 Prometheus 文本格式写出全部指标
+func (r *Registry) Write(w io.Writer) {
+	r.mu.RLock()
+	names := make([]string, 0, len(r.collectors))
+	for name := range r.collectors {
+		names = append(names, name)
+	}
+	r.mu.RUnlock()
+	sort.Strings(names)
+
+	for _, name := range names {
+		r.mu.RLock()
+		c := r.collectors[name]
+		r.mu.RUnlock()
+		c.write(w)
+	}
+}
+
+// Handler 返回导出指标的 HTTP 处理器
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.Write(w)
+	})
+}
+
+// Handler 返回导出默认注册表的 HTTP 处理器
+func Handler() http.Handler {
+	return Default.Handler()
+}
+
+// family 是带标签的指标族的公共部分
+type family[T any] struct {
+	metricName string
+	help       string
+	typ        string
+	labels     []string
+
+	mu      sync.RWMutex
+	members map[string]*T
+	newT    func() *T
+}
+
+func (f *family[T]) name() string {
+	return f.metricName
+}
+
+// with 按标签值取得 (或创建) 一条时间序列
+func (f *family[T]) with(values []string) *T {
+	if len(values) != len(f.labels) {
+		panic(fmt.Sprintf("metrics: 指标 '%s' 需要 %d 个标签值，实际为 %d", f.metricName, len(f.labels), len(values)))
+	}
+	key := strings.Join(values, labelSeparator)
+
+	f.mu.RLock()
+	m, ok := f.members[key]
+	f.mu.RUnlock()
+	if ok {
+		return m
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if m, ok = f.members[key]; !ok {
+		m = f.newT()
+		f.members[key] = m
+	}
+	return m
+}
+
+// delete 删除一条时间序列
+func (f *family[T]) delete(values []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.members, strings.Join(values, labelSeparator))
+}
+
+// each 按标签值排序遍历所有时间序列
+func (f *family[T]) each(fn func(labels string, m *T)) {
+	f.mu.RLock()
+	keys := make([]string, 0, len(f.members))
+	for key := range f.members {
+		keys = append(keys, key)
+	}
+	f.mu.RUnlock()
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		f.mu.RLock()
+		m, ok := f.members[key]
+		f.mu.RUnlock()
+		if ok { // 遍历期间可能被删除
+			fn(f.formatLabels(key), m)
+		}
+	}
+}
+
+func (f *family[T]) writeHeader(w io.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", f.metricName, escapeHelp(f.help), f.metricName, f.typ)
+}
+
+// formatLabels 把拼接的标签值格式化为 {k="v",...}
+func (f *family[T]) formatLabels(key string) string {
+	if len(f.labels) == 0 {
+		return ""
+	}
+	values := strings.Split(key, labelSeparator)
+	pairs := make([]string, len(f.labels))
+	for i, label := range f.labels {
+		pairs[i] = label + `="` + escapeLabel(values[i]) + `"`
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// value 是可原子更新的 float64
+type value struct {
+	bits uint64
+}
+
+func (v *value) add(delta float64) {
+	for {
+		old := atomic.LoadUint64(&v.bits)
+		next := math.Float64bits(math.Float64frombits(old) + delta)
+		if atomic.CompareAndSwapUint64(&v.bits, old, next) {
+			return
+		}
+	}
+}
+
+func (v *value) set(x float64) {
+	atomic.StoreUint64(&v.bits, math.Float64bits(x))
+}
+
+func (v *value) get() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&v.bits))
+}
+
+// Counter 单调递增的计数器
+type Counter struct {
+	v value
+}
+
+func (c *Counter) Inc() {
+	c.v.add(1)
+}
+
+// Add 增加计数，delta 不能为负
+func (c *Counter) Add(delta float64) {
+	if delta < 0 {
+		panic("metrics: 计数器不能减少")
+	}
+	c.v.add(delta)
+}
+
+// CounterVec 按标签区分的一组计数器
+type CounterVec struct {
+	family[Counter]
+}
+
+// NewCounterVec 创建计数器并注册到默认注册表
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	v := &CounterVec{family[Counter]{
+		metricName: name, help: help, typ: "counter", labels: labels,
+		members: make(map[string]*Counter),
+		newT:    func() *Counter { return &Counter{} },
+	}}
+	Default.register(v)
+	return v
+}
+
+func (v *CounterVec) WithLabelValues(values ...string) *Counter {
+	return v.with(values)
+}
+
+func (v *CounterVec) write(w io.Writer) {
+	v.writeHeader(w)
+	v.each(func(labels string, c *Counter) {
+		fmt.Fprintf(w, "%s%s %s\n", v.metricName, labels, formatFloat(c.v.get()))
+	})
+}
+
+// Gauge 可增可减的瞬时值
+type Gauge struct {
+	v value
+}
+
+func (g *Gauge) Set(x float64) {
+	g.v.set(x)
+}
+
+func (g *Gauge) Inc() {
+	g.v.add(1)
+}
+
+func (g *Gauge) Dec() {
+	g.v.add(-1)
+}
+
+func (g *Gauge) Add(delta float64) {
+	g.v.add(delta)
+}
+
+// GaugeVec 按标签区分的一组仪表盘
+type GaugeVec struct {
+	family[Gauge]
+}
+
+// NewGaugeVec 创建仪表盘并注册到默认注册表
+func NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	v := &GaugeVec{family[Gauge]{
+		metricName: name, help: help, typ: "gauge", labels: labels,
+		members: make(map[string]*Gauge),
+		newT:    func() *Gauge { return &Gauge{} },
+	}}
+	Default.register(v)
+	return v
+}
+
+func (v *GaugeVec) WithLabelValues(values ...string) *Gauge {
+	return v.with(values)
+}
+
+// DeleteLabelValues 删除一条时间序列，用于标签值对应的对象已不存在时
+func (v *GaugeVec) DeleteLabelValues(values ...string) {
+	v.delete(values)
+}
+
+func (v *GaugeVec) write(w io.Writer) {
+	v.writeHeader(w)
+	v.each(func(labels string, g *Gauge) {
+		fmt.Fprintf(w, "%s%s %s\n", v.metricName, labels, formatFloat(g.v.get()))
+	})
+}
+
+// Histogram 记录观测值的分布
+type Histogram struct {
+	upperBounds []float64
+	counts      []uint64 // 每个分桶的非累计计数，最后一个为 +Inf
+	count       uint64
+	sum         value
+}
+
+func (h *Histogram) Observe(x float64) {
+	i := sort.SearchFloat64s(h.upperBounds, x)
+	atomic.AddUint64(&h.counts[i], 1)
+	atomic.AddUint64(&h.count, 1)
+	h.sum.add(x)
+}
+
+// HistogramVec 按标签区分的一组直方图
+type HistogramVec struct {
+	family[Histogram]
+}
+
+// NewHistogramVec 创建直方图并注册到默认注册表，buckets 为空时使用 DefBuckets
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if len(buckets) == 0 {
+		buckets = DefBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+
+	v := &HistogramVec{family[Histogram]{
+		metricName: name, help: help, typ: "histogram", labels: labels,
+		members: make(map[string]*Histogram),
+		newT: func() *Histogram {
+			return &Histogram{upperBounds: buckets, counts: make([]uint64, len(buckets)+1)}
+		},
+	}}
+	Default.register(v)
+	return v
+}
+
+func (v *HistogramVec) WithLabelValues(values ...string) *Histogram {
+	return v.with(values)
+}
+
+func (v *HistogramVec) write(w io.Writer) {
+	v.writeHeader(w)
+	v.each(func(labels string, h *Histogram) {
+		// le 标签追加在已有标签之后
+		open := "{"
+		if labels != "" {
+			open = labels[:len(labels)-1] + ","
+		}
+		var cumulative uint64
+		for i, bound := range h.upperBounds {
+			cumulative += atomic.LoadUint64(&h.counts[i])
+			fmt.Fprintf(w, "%s_bucket%sle=\"%s\"} %d\n", v.metricName, open, formatFloat(bound), cumulative)
+		}
+		count := atomic.LoadUint64(&h.count)
+		fmt.Fprintf(w, "%s_bucket%sle=\"+Inf\"} %d\n", v.metricName, open, count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", v.metricName, labels, formatFloat(h.sum.get()))
+		fmt.Fprintf(w, "%s_count%s %d\n", v.metricName, labels, count)
+	})
+}
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	case math.IsNaN(f):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}